@@ -16,6 +16,7 @@ require (
 	github.com/graham/rrule v0.0.0-20200104195902-7382dbb0d849
 	github.com/jaytaylor/html2text v0.0.0-20200412013138-3577fbdbcff7 // indirect
 	github.com/jhillyerd/enmime v0.8.3
+	github.com/jung-kurt/gofpdf v1.16.2
 	github.com/kvannotten/mailstrip v0.0.0-20200711213611-0002f5c0467e
 	github.com/magiconair/properties v1.8.4 // indirect
 	github.com/mattn/go-runewidth v0.0.9 // indirect
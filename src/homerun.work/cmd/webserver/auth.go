@@ -29,6 +29,7 @@ const (
 	resetPwdTokenExpiration    = 20 * time.Minute
 	verifyEmailTokenByteLength = 16
 	verifyEmailTokenExpiration = 24 * time.Hour
+	waitlistTokenByteLength    = 16
 )
 
 //oauth types
@@ -313,6 +314,25 @@ func CreateEmailVerifyToken() (string, error) {
 	return hashedStr, nil
 }
 
+//CreateWaitlistToken : create a token used to claim a waitlist offer
+func CreateWaitlistToken() (string, error) {
+	//create a random array of bytes for the token
+	bytes, err := CreateRandomBytes(waitlistTokenByteLength)
+	if err != nil {
+		return "", errors.Wrap(err, "create random bytes")
+	}
+
+	//hash the token
+	hashedBytes, err := SHA256HashBytes(bytes)
+	if err != nil {
+		return "", errors.Wrap(err, "hash random bytes")
+	}
+
+	//create a base64 url-safe string
+	hashedStr := base64.URLEncoding.EncodeToString(hashedBytes)
+	return hashedStr, nil
+}
+
 //OAuthClaims : claims to include in the OAuth JWT
 type OAuthClaims struct {
 	jwt.StandardClaims
@@ -19,6 +19,7 @@ import (
 	"github.com/aws/aws-sdk-go/service/sns"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/gofrs/uuid"
+	"github.com/jhillyerd/enmime"
 	"github.com/pkg/errors"
 )
 
@@ -242,12 +243,11 @@ func (s *AWSSession) SendEmail(ctx context.Context, msg *Message) (context.Conte
 	}
 
 	//prepare the email
-	sourceEmail := GetEmailSender()
-	if msg.SenderName != "" {
-		sourceEmail = fmt.Sprintf("%s <%s>", msg.SenderName, sourceEmail)
-	} else {
-		sourceEmail = fmt.Sprintf("%s <%s>", GetEmailSenderName(), sourceEmail)
+	senderName := msg.SenderName
+	if senderName == "" {
+		senderName = GetEmailSenderName()
 	}
+	sourceEmail := fmt.Sprintf("%s <%s>", senderName, GetEmailSender())
 	in = &ses.SendEmailInput{
 		Source: aws.String(sourceEmail),
 		Destination: &ses.Destination{
@@ -299,6 +299,45 @@ func (s *AWSSession) SendEmail(ctx context.Context, msg *Message) (context.Conte
 		in.ReplyToAddresses = []*string{aws.String(replyTo)}
 	}
 
+	//send the email as a raw message if there are attachments
+	if len(msg.Attachments) > 0 {
+		builder := enmime.Builder().
+			From(senderName, GetEmailSender()).
+			To("", msg.ToEmail).
+			Subject(msg.Subject)
+		if msg.BodyHTML != "" {
+			builder = builder.HTML([]byte(msg.BodyHTML))
+		}
+		if msg.BodyText != "" {
+			builder = builder.Text([]byte(msg.BodyText))
+		}
+		for _, replyTo := range in.ReplyToAddresses {
+			builder = builder.ReplyTo("", *replyTo)
+		}
+		for _, attachment := range msg.Attachments {
+			builder = builder.AddAttachment(attachment.Data, attachment.ContentType, attachment.FileName)
+		}
+		part, err := builder.Build()
+		if err != nil {
+			return ctx, errors.Wrap(err, "build raw email")
+		}
+		var buffer bytes.Buffer
+		err = part.Encode(&buffer)
+		if err != nil {
+			return ctx, errors.Wrap(err, "encode raw email")
+		}
+		inRaw := &ses.SendRawEmailInput{
+			RawMessage: &ses.RawMessage{
+				Data: buffer.Bytes(),
+			},
+		}
+		_, err = s.clientSES.SendRawEmail(inRaw)
+		if err != nil {
+			return ctx, errors.Wrap(err, "send raw email")
+		}
+		return ctx, nil
+	}
+
 	//send the email
 	var err error
 	result, err = s.clientSES.SendEmail(in)
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -19,10 +20,14 @@ const (
 
 //booking constants
 const (
-	recurringGenerateMonths  = 2 //how far out to generate recurring events
-	recurringLookAheadMonths = 1 //how far out to look ahead for recurring events
+	recurringGenerateMonths  = 2  //how far out to generate recurring events
+	recurringLookAheadMonths = 1  //how far out to look ahead for recurring events
+	paymentExpirationMinutes = 30 //how long a booking requiring payment to book is held pending payment
 )
 
+//ErrBookingTimeTaken : error indicating the time of the booking is no longer available
+var ErrBookingTimeTaken = errors.New("booking time taken")
+
 //Booking : definition of a booking
 type Booking struct {
 	ID                    *uuid.UUID          `json:"-"`
@@ -31,6 +36,7 @@ type Booking struct {
 	RecurrenceFreq        RecurrenceInterval  `json:"RecurrenceFreq"`
 	RecurrenceFreqChange  bool                `json:"-"`
 	RecurrenceFreqLabel   string              `json:"RecurrenceFreqLabel"`
+	RecurrenceOptions     *RecurrenceOptions  `json:"RecurrenceOptions"`
 	RecurrenceRules       []string            `json:"-"`
 	RecurrenceInstanceEnd *time.Time          `json:"-"`
 	Location              string              `json:"Location"`
@@ -44,6 +50,15 @@ type Booking struct {
 	ServicePrice          float32             `json:"ServicePrice"`
 	ServicePriceOriginal  float32             `json:"ServicePriceOriginal"`
 	ServicePriceType      PriceType           `json:"ServicePriceType"`
+	ServiceDeposit        float32             `json:"ServiceDeposit"`
+	ServiceDepositType    FeeType             `json:"ServiceDepositType"`
+	ServicePayToBook      bool                `json:"ServicePayToBook"`
+	ServiceCancelFee      float32             `json:"ServiceCancelFee"`
+	ServiceCancelFeeType  FeeType             `json:"ServiceCancelFeeType"`
+	ServiceCancelFeeHours int                 `json:"ServiceCancelFeeHours"`
+	ServiceNoShowFee      float32             `json:"ServiceNoShowFee"`
+	ServiceNoShowFeeType  FeeType             `json:"ServiceNoShowFeeType"`
+	ServiceTaxRate        float32             `json:"ServiceTaxRate"` //percent
 	ServiceDuration       int                 `json:"ServiceDuration"`
 	ServiceDurationLabel  string              `json:"ServiceDurationLabel"`
 	CouponCode            string              `json:"CouponCode"`
@@ -54,7 +69,13 @@ type Booking struct {
 	TimeFromPadded        time.Time           `json:"-"`
 	TimeToPadded          time.Time           `json:"-"`
 	TimeChange            bool                `json:"-"`
+	TimeFromOriginal      time.Time           `json:"-"`
+	HoldID                *uuid.UUID          `json:"-"`
+	ClientPackageID       *uuid.UUID          `json:"-"`
+	ClientMembershipID    *uuid.UUID          `json:"-"`
+	PaymentExpiration     *time.Time          `json:"-"`
 	Confirmed             bool                `json:"-"`
+	NoShow                bool                `json:"NoShow"`
 	Deleted               bool                `json:"-"`
 	Created               time.Time           `json:"-"`
 
@@ -78,6 +99,7 @@ type Booking struct {
 	ServiceType    ServiceType   `json:"-"`
 	Client         *Client       `json:"-"`
 	Payment        *Payment      `json:"-"`
+	Deposit        *Payment      `json:"-"`
 }
 
 //GetUser : return the user that created the booking
@@ -120,16 +142,36 @@ func (b *Booking) IsApptOnly() bool {
 	return IsApptOnly(b.ServiceType)
 }
 
+//IsClass : check if a group class
+func (b *Booking) IsClass() bool {
+	return IsClass(b.ServiceType)
+}
+
 //IsRecurring : flag indicating if the booking is recurring
 func (b *Booking) IsRecurring() bool {
 	return b.RecurrenceFreq != RecurrenceIntervalOnce
 }
 
+//IsRecurringParent : flag indicating if the booking holds the recurrence rules for the series
+func (b *Booking) IsRecurringParent() bool {
+	return b.ID != nil && b.ParentID != nil && b.ID.String() == b.ParentID.String()
+}
+
+//IsRecurringChild : flag indicating if the booking is an occurrence generated for the series
+func (b *Booking) IsRecurringChild() bool {
+	return b.ID != nil && b.ParentID != nil && b.ID.String() != b.ParentID.String()
+}
+
 //IsCancelled : check if a booking has been cancelled
 func (b *Booking) IsCancelled() bool {
 	return b.Deleted
 }
 
+//AllowNoShow : check if the client can be marked as a no-show
+func (b *Booking) AllowNoShow(now time.Time) bool {
+	return !b.Deleted && !b.NoShow && now.After(b.TimeFrom)
+}
+
 //IsInvoiced : check if a payment has been invoiced
 func (b *Booking) IsInvoiced() bool {
 	return b.Payment != nil && b.Payment.IsInvoiced()
@@ -152,38 +194,92 @@ func (b *Booking) IsEditable(now time.Time) bool {
 
 //SupportsPayment : check if payments are supported
 func (b *Booking) SupportsPayment() bool {
-	return b.ServicePrice != 0 && b.Provider.SupportsPayment()
+	return !b.IsPrepaid() && b.ServicePrice != 0 && b.ComputeServicePriceBalance() > 0 && b.Provider.SupportsPayment()
+}
+
+//HasDeposit : check if a deposit is required
+func (b *Booking) HasDeposit() bool {
+	return !b.IsPrepaid() && b.ServiceDeposit > 0
+}
+
+//IsPackageCredit : check if the booking is paid for with a credit from a prepaid package
+func (b *Booking) IsPackageCredit() bool {
+	return b.ClientPackageID != nil
+}
+
+//IsMembershipCredit : check if the booking is covered by a membership
+func (b *Booking) IsMembershipCredit() bool {
+	return b.ClientMembershipID != nil
+}
+
+//IsPrepaid : check if the booking is covered by a package or membership, requiring no payment
+func (b *Booking) IsPrepaid() bool {
+	return b.IsPackageCredit() || b.IsMembershipCredit()
+}
+
+//IsDepositPaid : check if the deposit has been paid
+func (b *Booking) IsDepositPaid() bool {
+	return b.Deposit != nil && (b.Deposit.IsPaid() || b.Deposit.IsCaptured())
 }
 
-//SetRecurrenceFreq : set the recurrence frequency
-func (b *Booking) SetRecurrenceFreq(freq *RecurrenceFreq, resetStart bool) error {
+//IsDepositDue : check if a deposit has been requested but not yet paid
+func (b *Booking) IsDepositDue() bool {
+	return b.Deposit != nil && !b.IsDepositPaid()
+}
+
+//IsPaymentPending : check if the booking is waiting on the payment required to book
+func (b *Booking) IsPaymentPending() bool {
+	return b.PaymentExpiration != nil && !b.Confirmed && !b.IsPaid() && !b.IsCaptured()
+}
+
+//SetPaymentExpiration : set the time by which the payment required to book must be made
+func (b *Booking) SetPaymentExpiration(now time.Time) {
+	expiration := now.Add(paymentExpirationMinutes * time.Minute)
+	b.PaymentExpiration = &expiration
+}
+
+//FormatPaymentExpiration : format the time by which the payment required to book must be made
+func (b *Booking) FormatPaymentExpiration(timeZone string) string {
+	if b.PaymentExpiration == nil {
+		return ""
+	}
+	return FormatDateTimeLocal(*b.PaymentExpiration, timeZone)
+}
+
+//SetRecurrenceFreq : set the recurrence frequency and any additional options
+func (b *Booking) SetRecurrenceFreq(freq *RecurrenceFreq, opts *RecurrenceOptions, resetStart bool) error {
 	//default the frequency if not set
 	if freq == nil {
 		freq = &RecurrenceFreqOnce
 		b.RecurrenceStart = nil
 	}
+	if freq.Value == RecurrenceIntervalOnce {
+		opts = nil
+	}
 
-	//update the frequency
-	if b.RecurrenceFreq != freq.Value {
+	//process the recurrence frequency
+	recurrenceRule, err := CreateRecurrenceRule(freq, opts, b.TimeFrom)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("create rule: %v", freq))
+	}
+
+	//update the frequency, also checking for a change to the options
+	if b.RecurrenceFreq != freq.Value || (len(b.RecurrenceRules) > 0 && b.RecurrenceRules[0] != recurrenceRule) {
 		b.RecurrenceFreqChange = true
 		b.EventGoogleUpdate = true
 		b.MeetingZoomUpdate = b.EnableZoom
 	}
 	b.RecurrenceFreq = freq.Value
 	b.RecurrenceFreqLabel = freq.Label
-
-	//process the recurrence frequency
-	recurrenceRule, err := CreateRecurrenceRule(freq, b.TimeFrom)
-	if err != nil {
-		return errors.Wrap(err, fmt.Sprintf("create rule: %v", freq))
-	}
+	b.RecurrenceOptions = opts
 	if recurrenceRule == "" {
 		b.RecurrenceStart = nil
 		b.RecurrenceRules = nil
 		return nil
 	}
 	if b.RecurrenceStart == nil || resetStart {
-		b.RecurrenceStart = &b.TimeFrom
+		recurrenceStart := b.TimeFrom
+		b.RecurrenceStart = &recurrenceStart
 	}
 	b.RecurrenceRules = []string{recurrenceRule}
 	return nil
@@ -214,13 +310,32 @@ func (b *Booking) SetProvider(provider *Provider) {
 	b.Provider = provider
 }
 
+//GetCurrency : get the currency of the provider
+func (b *Booking) GetCurrency() Currency {
+	if b.Provider == nil {
+		return CurrencyDefault
+	}
+	return b.Provider.GetCurrency()
+}
+
 //SetService : set the service
 func (b *Booking) SetService(svc *Service) {
+	//keep the fees agreed to when booking unless the service changes
+	if b.Service == nil || b.Service.ID == nil || svc.ID == nil || b.Service.ID.String() != svc.ID.String() {
+		b.ServiceCancelFee = svc.CancelFee
+		b.ServiceCancelFeeType = svc.GetCancelFeeType()
+		b.ServiceCancelFeeHours = svc.CancelFeeWindow
+		b.ServiceNoShowFee = svc.NoShowFee
+		b.ServiceNoShowFeeType = svc.GetNoShowFeeType()
+	}
 	b.Service = svc
 	b.EnableZoom = svc.EnableZoom
 	b.LocationType = svc.LocationType
 	b.ServicePadding = svc.Padding
 	b.ServicePriceType = svc.PriceType
+	b.ServiceDeposit = svc.Deposit
+	b.ServiceDepositType = svc.GetDepositType()
+	b.ServicePayToBook = svc.PayToBook
 
 	//check for the type changing
 	if b.ServiceType != svc.Type {
@@ -255,6 +370,9 @@ func (b *Booking) SetService(svc *Service) {
 //SetTimeFrom : set the from-time
 func (b *Booking) SetTimeFrom(t time.Time) {
 	if !b.TimeFrom.Equal(t) {
+		if b.TimeFromOriginal.IsZero() {
+			b.TimeFromOriginal = b.TimeFrom
+		}
 		b.TimeChange = true
 		b.EventGoogleUpdate = true
 		b.MeetingZoomUpdate = b.EnableZoom
@@ -270,6 +388,14 @@ func (b *Booking) SetTimeFrom(t time.Time) {
 	}
 }
 
+//GetTimeFromOriginal : get the from-time prior to any change
+func (b *Booking) GetTimeFromOriginal() time.Time {
+	if b.TimeFromOriginal.IsZero() {
+		return b.TimeFrom
+	}
+	return b.TimeFromOriginal
+}
+
 //SetTimeTo : set the to-time
 func (b *Booking) SetTimeTo(t time.Time) {
 	if !b.TimeTo.Equal(t) {
@@ -290,6 +416,22 @@ func (b *Booking) SetLocation(location string) {
 	b.Location = location
 }
 
+//SetTaxRate : set the tax rate for the location, or the provider location if none, unless the service is tax exempt
+func (b *Booking) SetTaxRate() {
+	b.ServiceTaxRate = 0
+	if b.Provider == nil || b.Service == nil || b.Service.TaxExempt {
+		return
+	}
+	location := b.Location
+	if location == "" {
+		location = b.Provider.Location
+	}
+	taxRate := b.Provider.FindTaxRate(location)
+	if taxRate != nil {
+		b.ServiceTaxRate = taxRate.Rate
+	}
+}
+
 //SetDescription : set the description
 func (b *Booking) SetDescription(desc string) {
 	if b.Description != desc {
@@ -370,6 +512,14 @@ func (b *Booking) FormatRecurrenceFreq() string {
 	return b.RecurrenceFreqLabel
 }
 
+//FormatRecurrenceOptions : format the additional recurrence options
+func (b *Booking) FormatRecurrenceOptions(timeZone string) string {
+	if b.RecurrenceFreq == RecurrenceIntervalOnce || b.RecurrenceOptions == nil {
+		return ""
+	}
+	return b.RecurrenceOptions.Format(LoadRecurrenceFreq(b.RecurrenceFreq), timeZone)
+}
+
 //FormatServicePaymentDescription : format the service description for payment
 func (b *Booking) FormatServicePaymentDescription(timeZone string) string {
 	return fmt.Sprintf("%s on %s", b.ServiceName, b.FormatDateTime(timeZone))
@@ -380,16 +530,161 @@ func (b *Booking) AllowUnPay() bool {
 	return b.Payment != nil && b.Payment.AllowUnPay()
 }
 
-//FormatServicePrice : format the price
+//FormatServicePrice : format the price, noting any tax
 func (b *Booking) FormatServicePrice() string {
-	return b.ServicePriceType.Format(b.ServicePrice)
+	price := b.ServicePriceType.Format(b.ServicePrice, b.GetCurrency())
+	if b.HasTax() {
+		return fmt.Sprintf("%s + %s%% tax", price, FormatRate(b.ServiceTaxRate))
+	}
+	return price
 }
 
-//ComputeServicePrice : compute the price of the service
+//ComputeServicePrice : compute the price of the service, excluding tax
 func (b *Booking) ComputeServicePrice() float32 {
 	return b.ServicePriceType.Compute(b.ServicePrice, b.ServiceDuration)
 }
 
+//HasTax : check if tax applies to the price of the service
+func (b *Booking) HasTax() bool {
+	return b.ServiceTaxRate > 0 && b.ComputeServicePrice() > 0
+}
+
+//ComputeServiceTax : compute the tax on the price of the service
+func (b *Booking) ComputeServiceTax() float32 {
+	if !b.HasTax() {
+		return 0
+	}
+	return b.GetCurrency().Round(b.ComputeServicePrice() * b.ServiceTaxRate / 100)
+}
+
+//ComputeServicePriceTotal : compute the price of the service, including tax
+func (b *Booking) ComputeServicePriceTotal() float32 {
+	return b.ComputeServicePrice() + b.ComputeServiceTax()
+}
+
+//ComputeDeposit : compute the deposit, which is capped at the price
+func (b *Booking) ComputeDeposit() float32 {
+	if !b.HasDeposit() {
+		return 0
+	}
+	price := b.ComputeServicePrice()
+	return float32(math.Min(float64(b.ServiceDepositType.Compute(b.ServiceDeposit, price)), float64(price)))
+}
+
+//HasCancelFee : check if a late cancellation fee applies
+func (b *Booking) HasCancelFee() bool {
+	return b.ServiceCancelFeeHours > 0 && b.ServiceCancelFee > 0
+}
+
+//HasNoShowFee : check if a no-show fee applies
+func (b *Booking) HasNoShowFee() bool {
+	return b.ServiceNoShowFee > 0
+}
+
+//FormatCancelFee : format the late cancellation fee
+func (b *Booking) FormatCancelFee() string {
+	return b.ServiceCancelFeeType.Format(b.ServiceCancelFee, b.GetCurrency())
+}
+
+//FormatNoShowFee : format the no-show fee
+func (b *Booking) FormatNoShowFee() string {
+	return b.ServiceNoShowFeeType.Format(b.ServiceNoShowFee, b.GetCurrency())
+}
+
+//ComputeCancelFee : compute the fee for cancelling the booking at the given time, which is zero if outside the fee window and capped at the price
+func (b *Booking) ComputeCancelFee(now time.Time) float32 {
+	if !b.HasCancelFee() {
+		return 0
+	}
+	window := time.Duration(b.ServiceCancelFeeHours) * time.Hour
+	if now.Add(window).Before(b.TimeFrom) {
+		return 0
+	}
+	price := b.ComputeServicePrice()
+	return float32(math.Min(float64(b.ServiceCancelFeeType.Compute(b.ServiceCancelFee, price)), float64(price)))
+}
+
+//ComputeNoShowFee : compute the fee for the client not showing up, which is capped at the price
+func (b *Booking) ComputeNoShowFee() float32 {
+	if !b.HasNoShowFee() {
+		return 0
+	}
+	price := b.ComputeServicePrice()
+	return float32(math.Min(float64(b.ServiceNoShowFeeType.Compute(b.ServiceNoShowFee, price)), float64(price)))
+}
+
+//ComputeServicePriceBalance : compute the balance of the price, including tax, remaining after any deposit paid
+func (b *Booking) ComputeServicePriceBalance() float32 {
+	price := b.ComputeServicePriceTotal()
+	if b.IsDepositPaid() {
+		price = float32(math.Max(float64(price-b.Deposit.GetAmount()), 0))
+	}
+	return price
+}
+
+//CreatePaymentItems : create the line items for the balance of the price, itemizing any coupon and deposit paid
+func (b *Booking) CreatePaymentItems() []*PaymentItem {
+	//bill hourly services by the hour
+	quantity := float32(1)
+	if b.ServicePriceType == PriceTypeHourly && b.ServiceDuration > 0 {
+		quantity = float32(b.ServiceDuration) / 60
+	}
+
+	//show the original price with the coupon as a discount
+	currency := b.GetCurrency()
+	price := b.ComputeServicePrice()
+	items := make([]*PaymentItem, 0, 3)
+	if b.CouponApplied() && b.ServicePriceOriginal > b.ServicePrice {
+		items = append(items, NewPaymentItem(currency, PaymentItemTypeService, b.ServiceName, quantity, b.ServicePriceOriginal))
+		discount := b.ServicePriceType.Compute(b.ServicePriceOriginal, b.ServiceDuration) - price
+		items = append(items, NewPaymentItem(currency, PaymentItemTypeDiscount, fmt.Sprintf("Coupon %s", b.Coupon.Code), 1, discount))
+	} else {
+		items = append(items, NewPaymentItem(currency, PaymentItemTypeService, b.ServiceName, quantity, b.ServicePrice))
+	}
+
+	//credit the deposit
+	if b.IsDepositPaid() {
+		deposit := float32(math.Min(float64(b.Deposit.GetAmount()), float64(price)))
+		items = append(items, NewPaymentItem(currency, PaymentItemTypeCredit, "Deposit paid", 1, deposit))
+	}
+	return items
+}
+
+//ComputeAmountPaid : compute the amount paid towards the price, including any deposit, less any refunds
+func (b *Booking) ComputeAmountPaid() float32 {
+	var paid float32
+	if b.IsDepositPaid() {
+		paid += b.Deposit.GetAmountNet()
+	}
+	if b.IsPaid() || b.IsCaptured() {
+		paid += b.Payment.GetAmountNet()
+	}
+	return paid
+}
+
+//ComputeAmountOutstanding : compute the amount of the price still outstanding
+func (b *Booking) ComputeAmountOutstanding() float32 {
+	return float32(math.Max(float64(b.ComputeServicePriceTotal()-b.ComputeAmountPaid()), 0))
+}
+
+//FormatDeposit : format the deposit, preferring the amount requested from the client
+func (b *Booking) FormatDeposit() string {
+	if b.Deposit != nil {
+		return b.Deposit.FormatAmount()
+	}
+	return b.GetCurrency().FormatAmount(b.ComputeDeposit())
+}
+
+//FormatAmountPaid : format the amount paid
+func (b *Booking) FormatAmountPaid() string {
+	return b.GetCurrency().FormatAmount(b.ComputeAmountPaid())
+}
+
+//FormatAmountOutstanding : format the amount outstanding
+func (b *Booking) FormatAmountOutstanding() string {
+	return b.GetCurrency().FormatAmount(b.ComputeAmountOutstanding())
+}
+
 //SetCouponCode : set the coupon code
 func (b *Booking) SetCouponCode(code string) {
 	if b.CouponCode != code {
@@ -406,7 +701,7 @@ func (b *Booking) CouponApplied() bool {
 //FormatCoupon : display the coupon
 func (b *Booking) FormatCoupon() string {
 	if b.Coupon != nil {
-		return fmt.Sprintf("%s - %s", b.Coupon.Code, b.Coupon.FormatValue())
+		return fmt.Sprintf("%s - %s", b.Coupon.Code, b.Coupon.FormatValue(b.GetCurrency()))
 	}
 	return ""
 }
@@ -416,7 +711,7 @@ func bookingQueryCreate(whereStmt string, orderStmt string, limit int) string {
 	if orderStmt == "" {
 		orderStmt = "b.time_start,b.updated"
 	}
-	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(p.id),p.url_name,p.url_name_friendly,p.calendar_google_id,p.calendar_google_update,p.calendar_google_data,p.data,BIN_TO_UUID(u.id),u.email,u.token_zoom_data,u.data,s.type,BIN_TO_UUID(s.id),s.data,BIN_TO_UUID(c.id),c.email,c.disable_emails,c.data,BIN_TO_UUID(b.id),BIN_TO_UUID(b.parent_id),b.service_type,b.time_start,b.time_end,b.time_start_padded,b.time_end_padded,b.confirmed,b.client_created,b.recurrence_start,b.recurrence_rules,b.recurrence_instance_end,b.event_google_id,b.event_google_update,b.event_google_delete,b.meeting_zoom_id,b.meeting_zoom_update,b.meeting_zoom_delete,b.meeting_zoom_data,b.deleted,b.created,b.data,BIN_TO_UUID(pmt.id),pmt.friendly_id,pmt.type,pmt.amount,pmt.invoiced,pmt.paid,pmt.captured,pmt.stripe_id,pmt.paypal_id,pmt.data,BIN_TO_UUID(pu.id),pu.login,pu.data,BIN_TO_UUID(puu.id),puu.email,puu.token_zoom_data,puu.data FROM %s b INNER JOIN %s s ON s.id=b.service_id INNER JOIN %s p ON p.id=s.provider_id INNER JOIN %s c ON c.id=b.client_id INNER JOIN %s u ON u.id=p.user_id LEFT JOIN %s pmt ON pmt.secondary_id=b.id AND pmt.deleted=0 LEFT JOIN %s pu ON pu.id=b.provider_user_id AND pu.deleted=0 LEFT JOIN %s puu ON puu.id=pu.user_id AND puu.deleted=0 WHERE %s ORDER BY %s", dbTableBooking, dbTableService, dbTableProvider, dbTableClient, dbTableUser, dbTablePayment, dbTableProviderUser, dbTableUser, whereStmt, orderStmt)
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(p.id),p.url_name,p.url_name_friendly,p.calendar_google_id,p.calendar_google_update,p.calendar_google_data,p.data,BIN_TO_UUID(u.id),u.email,u.token_zoom_data,u.data,s.type,BIN_TO_UUID(s.id),s.data,BIN_TO_UUID(c.id),c.email,c.disable_emails,c.data,BIN_TO_UUID(b.id),BIN_TO_UUID(b.parent_id),b.service_type,b.time_start,b.time_end,b.time_start_padded,b.time_end_padded,b.confirmed,b.client_created,b.recurrence_start,b.recurrence_rules,b.recurrence_instance_end,b.payment_expiration,b.event_google_id,b.event_google_update,b.event_google_delete,b.meeting_zoom_id,b.meeting_zoom_update,b.meeting_zoom_delete,b.meeting_zoom_data,b.deleted,b.created,b.data,BIN_TO_UUID(pmt.id),pmt.friendly_id,pmt.type,pmt.amount,pmt.invoiced,pmt.paid,pmt.captured,pmt.stripe_id,pmt.paypal_id,pmt.data,BIN_TO_UUID(pd.id),pd.amount,pd.invoiced,pd.paid,pd.captured,pd.data,BIN_TO_UUID(pu.id),pu.login,pu.data,BIN_TO_UUID(puu.id),puu.email,puu.token_zoom_data,puu.data,BIN_TO_UUID(cpu.client_package_id),BIN_TO_UUID(cmu.client_membership_id) FROM %s b INNER JOIN %s s ON s.id=b.service_id INNER JOIN %s p ON p.id=s.provider_id INNER JOIN %s c ON c.id=b.client_id INNER JOIN %s u ON u.id=p.user_id LEFT JOIN %s pmt ON pmt.secondary_id=b.id AND pmt.type=%d AND pmt.deleted=0 LEFT JOIN %s pd ON pd.secondary_id=b.id AND pd.type=%d AND pd.deleted=0 LEFT JOIN %s pu ON pu.id=b.provider_user_id AND pu.deleted=0 LEFT JOIN %s puu ON puu.id=pu.user_id AND puu.deleted=0 LEFT JOIN %s cpu ON cpu.booking_id=b.id AND cpu.deleted=0 LEFT JOIN %s cmu ON cmu.booking_id=b.id AND cmu.deleted=0 WHERE %s ORDER BY %s", dbTableBooking, dbTableService, dbTableProvider, dbTableClient, dbTableUser, dbTablePayment, PaymentTypeBooking, dbTablePayment, PaymentTypeDeposit, dbTableProviderUser, dbTableUser, dbTableClientPackageUse, dbTableClientMembershipUse, whereStmt, orderStmt)
 	if limit > 0 {
 		stmt = fmt.Sprintf("%s LIMIT %d", stmt, limit)
 	}
@@ -425,7 +720,7 @@ func bookingQueryCreate(whereStmt string, orderStmt string, limit int) string {
 
 //create the statement to count bookings
 func bookingCountCreate(whereStmt string) string {
-	stmt := fmt.Sprintf("SELECT COUNT(*) FROM %s b INNER JOIN %s s ON s.id=b.service_id INNER JOIN %s p ON p.id=s.provider_id INNER JOIN %s c ON c.id=b.client_id INNER JOIN %s u ON u.id=p.user_id LEFT JOIN %s pmt ON pmt.secondary_id=b.id AND pmt.deleted=0 LEFT JOIN %s pu ON pu.id=b.provider_user_id AND pu.deleted=0 LEFT JOIN %s puu ON puu.id=pu.user_id AND puu.deleted=0 WHERE %s", dbTableBooking, dbTableService, dbTableProvider, dbTableClient, dbTableUser, dbTablePayment, dbTableProviderUser, dbTableUser, whereStmt)
+	stmt := fmt.Sprintf("SELECT COUNT(*) FROM %s b INNER JOIN %s s ON s.id=b.service_id INNER JOIN %s p ON p.id=s.provider_id INNER JOIN %s c ON c.id=b.client_id INNER JOIN %s u ON u.id=p.user_id LEFT JOIN %s pmt ON pmt.secondary_id=b.id AND pmt.type=%d AND pmt.deleted=0 LEFT JOIN %s pu ON pu.id=b.provider_user_id AND pu.deleted=0 LEFT JOIN %s puu ON puu.id=pu.user_id AND puu.deleted=0 WHERE %s", dbTableBooking, dbTableService, dbTableProvider, dbTableClient, dbTableUser, dbTablePayment, PaymentTypeBooking, dbTableProviderUser, dbTableUser, whereStmt)
 	return stmt
 }
 
@@ -462,6 +757,7 @@ func bookingQueryParse(rowFn ScanFn) (*Booking, error) {
 	var recurrenceStart sql.NullTime
 	var recurrenceRules sql.NullString
 	var recurrenceInstanceEnd sql.NullTime
+	var paymentExpiration sql.NullTime
 	var eventGoogleID sql.NullString
 	var eventGoogleUpdateBit string
 	var eventGoogleDeleteBit string
@@ -482,6 +778,12 @@ func bookingQueryParse(rowFn ScanFn) (*Booking, error) {
 	var paymentStripeID sql.NullString
 	var paymentPayPalID sql.NullString
 	var paymentData sql.NullString
+	var depositIDStr sql.NullString
+	var depositAmount sql.NullInt32
+	var depositInvoiced sql.NullTime
+	var depositPaid sql.NullTime
+	var depositCaptured sql.NullTime
+	var depositData sql.NullString
 	var providerUserIDStr sql.NullString
 	var providerUserLogin sql.NullString
 	var providerUserData sql.NullString
@@ -489,6 +791,8 @@ func bookingQueryParse(rowFn ScanFn) (*Booking, error) {
 	var providerUserUserEmail sql.NullString
 	var providerUserUserTokenZoomDataStr sql.NullString
 	var providerUserUserData sql.NullString
+	var clientPackageIDStr sql.NullString
+	var clientMembershipIDStr sql.NullString
 	err := rowFn(
 		//provider
 		&providerIDStr,
@@ -529,6 +833,7 @@ func bookingQueryParse(rowFn ScanFn) (*Booking, error) {
 		&recurrenceStart,
 		&recurrenceRules,
 		&recurrenceInstanceEnd,
+		&paymentExpiration,
 		&eventGoogleID,
 		&eventGoogleUpdateBit,
 		&eventGoogleDeleteBit,
@@ -552,6 +857,14 @@ func bookingQueryParse(rowFn ScanFn) (*Booking, error) {
 		&paymentPayPalID,
 		&paymentData,
 
+		//deposit
+		&depositIDStr,
+		&depositAmount,
+		&depositInvoiced,
+		&depositPaid,
+		&depositCaptured,
+		&depositData,
+
 		//provider user
 		&providerUserIDStr,
 		&providerUserLogin,
@@ -560,6 +873,10 @@ func bookingQueryParse(rowFn ScanFn) (*Booking, error) {
 		&providerUserUserEmail,
 		&providerUserUserTokenZoomDataStr,
 		&providerUserUserData,
+
+		//package and membership
+		&clientPackageIDStr,
+		&clientMembershipIDStr,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -626,6 +943,7 @@ func bookingQueryParse(rowFn ScanFn) (*Booking, error) {
 	provider.User = &user
 	provider.ID = &providerID
 	provider.URLName = providerURLName
+	provider.migrateSchedules()
 	provider.URLNameFriendly = providerURLNameFriendly
 	if providerCalenderGoogleID.Valid {
 		provider.GoogleCalendarID = &providerCalenderGoogleID.String
@@ -689,6 +1007,9 @@ func bookingQueryParse(rowFn ScanFn) (*Booking, error) {
 	if recurrenceInstanceEnd.Valid {
 		book.RecurrenceInstanceEnd = &recurrenceInstanceEnd.Time
 	}
+	if paymentExpiration.Valid {
+		book.PaymentExpiration = &paymentExpiration.Time
+	}
 	if eventGoogleID.Valid {
 		book.EventGoogleID = &eventGoogleID.String
 	}
@@ -751,6 +1072,54 @@ func bookingQueryParse(rowFn ScanFn) (*Booking, error) {
 		book.Payment = &payment
 	}
 
+	//unmarshal the deposit
+	if depositData.Valid {
+		var deposit Payment
+		err = json.Unmarshal([]byte(depositData.String), &deposit)
+		if err != nil {
+			return nil, errors.Wrap(err, "unjson deposit")
+		}
+		if depositIDStr.Valid {
+			depositUUID, err := uuid.FromString(depositIDStr.String)
+			if err != nil {
+				return nil, errors.Wrap(err, "parse uuid deposit")
+			}
+			deposit.ID = &depositUUID
+		}
+		deposit.Type = PaymentTypeDeposit
+		if depositAmount.Valid {
+			deposit.Amount = int(depositAmount.Int32)
+		}
+		if depositInvoiced.Valid {
+			deposit.Invoiced = &depositInvoiced.Time
+		}
+		if depositPaid.Valid {
+			deposit.Paid = &depositPaid.Time
+		}
+		if depositCaptured.Valid {
+			deposit.Captured = &depositCaptured.Time
+		}
+		book.Deposit = &deposit
+	}
+
+	//parse the package credit used for the booking
+	if clientPackageIDStr.Valid {
+		clientPackageID, err := uuid.FromString(clientPackageIDStr.String)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse uuid client package")
+		}
+		book.ClientPackageID = &clientPackageID
+	}
+
+	//parse the membership used for the booking
+	if clientMembershipIDStr.Valid {
+		clientMembershipID, err := uuid.FromString(clientMembershipIDStr.String)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse uuid client membership")
+		}
+		book.ClientMembershipID = &clientMembershipID
+	}
+
 	//unmarshal the provider user
 	var providerUser ProviderUser
 	if providerUserData.Valid {
@@ -770,6 +1139,9 @@ func bookingQueryParse(rowFn ScanFn) (*Booking, error) {
 		}
 		book.ProviderUserID = providerUser.ID
 		book.ProviderUser = &providerUser
+		if providerUser.Schedule != nil {
+			providerUser.Schedule.Migrate(user.TimeZone)
+		}
 
 		//unmarshal the associated user
 		var providerUserUser User
@@ -827,6 +1199,26 @@ func loadBooking(ctx context.Context, db *DB, updateViewed bool, whereStmt strin
 	return ctx, book, nil
 }
 
+//LoadBookingNextByParentID : load the next booking in a series following the time, returning nil if there is none
+func LoadBookingNextByParentID(ctx context.Context, db *DB, parentID *uuid.UUID, from time.Time) (context.Context, *Booking, error) {
+	whereStmt := "b.deleted=0 AND b.parent_id=UUID_TO_BIN(?) AND b.id!=UUID_TO_BIN(?) AND b.time_start>?"
+	ctx, booking, err := loadBooking(ctx, db, false, whereStmt, parentID, parentID, from)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, fmt.Sprintf("load booking next: %s", parentID))
+	}
+	return ctx, booking, nil
+}
+
+//LoadBookingUpcomingByParentID : load the first upcoming booking in a series, returning nil if there is none
+func LoadBookingUpcomingByParentID(ctx context.Context, db *DB, parentID *uuid.UUID, now time.Time) (context.Context, *Booking, error) {
+	whereStmt := "b.deleted=0 AND b.parent_id=UUID_TO_BIN(?) AND b.time_start>=?"
+	ctx, booking, err := loadBooking(ctx, db, false, whereStmt, parentID, now)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, fmt.Sprintf("load booking upcoming: %s", parentID))
+	}
+	return ctx, booking, nil
+}
+
 //LoadBookingByID : load a booking by id
 func LoadBookingByID(ctx context.Context, db *DB, id *uuid.UUID, updateViewed bool, includeDeleted bool) (context.Context, *Booking, error) {
 	var whereStmt string
@@ -856,9 +1248,9 @@ func saveBooking(ctx context.Context, db *DB, book *Booking, confirmed bool, isC
 		book.ID = &id
 	}
 
-	//propagate the deleted flag
-	book.EventGoogleDelete = deleted
-	book.MeetingZoomDelete = deleted
+	//propagate the deleted flag, if there is an event or meeting to delete
+	book.EventGoogleDelete = deleted && book.EventGoogleID != nil
+	book.MeetingZoomDelete = deleted && book.MeetingZoomID != nil
 
 	//json encode the meeting data
 	var err error
@@ -877,8 +1269,8 @@ func saveBooking(ctx context.Context, db *DB, book *Booking, confirmed bool, isC
 	}
 
 	//insert the booking
-	stmt := fmt.Sprintf("INSERT INTO %s(id,parent_id,provider_id,provider_user_id,service_type,service_id,client_id,time_start,time_end,time_start_padded,time_end_padded,confirmed,client_created,recurrence_start,recurrence_rules,recurrence_instance_end,event_google_delete,event_google_update,meeting_zoom_id,meeting_zoom_update,meeting_zoom_delete,meeting_zoom_data,deleted,data) VALUES (UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),?,UUID_TO_BIN(?),UUID_TO_BIN(?),?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE parent_id=VALUES(parent_id),provider_id=VALUES(provider_id),provider_user_id=VALUES(provider_user_id),service_type=VALUES(service_type),service_id=VALUES(service_id),client_id=VALUES(client_id),time_start=VALUES(time_start),time_end=VALUES(time_end),time_start_padded=VALUES(time_start_padded),time_end_padded=VALUES(time_end_padded),confirmed=VALUES(confirmed),client_created=VALUES(client_created),recurrence_start=VALUES(recurrence_start),recurrence_rules=VALUES(recurrence_rules),recurrence_instance_end=VALUES(recurrence_instance_end),event_google_delete=VALUES(event_google_delete),event_google_update=VALUES(event_google_update),meeting_zoom_id=VALUES(meeting_zoom_id),meeting_zoom_update=VALUES(meeting_zoom_update),meeting_zoom_delete=VALUES(meeting_zoom_delete),meeting_zoom_data=VALUES(meeting_zoom_data),deleted=VALUES(deleted),data=VALUES(data)", dbTableBooking)
-	ctx, result, err := db.Exec(ctx, stmt, book.ID, book.ParentID, book.Provider.ID, book.ProviderUserID, book.ServiceType, book.Service.ID, book.Client.ID, book.TimeFrom.UTC(), book.TimeTo.UTC(), book.TimeFromPadded.UTC(), book.TimeToPadded.UTC(), confirmed, isClient, book.RecurrenceStart, book.GetRecurrenceRules(), book.RecurrenceInstanceEnd, book.EventGoogleDelete, book.EventGoogleUpdate, book.MeetingZoomID, book.MeetingZoomUpdate, book.MeetingZoomDelete, meetingJSON, deleted, dataJSON)
+	stmt := fmt.Sprintf("INSERT INTO %s(id,parent_id,provider_id,provider_user_id,service_type,service_id,client_id,time_start,time_end,time_start_padded,time_end_padded,confirmed,client_created,recurrence_start,recurrence_rules,recurrence_instance_end,payment_expiration,event_google_delete,event_google_update,meeting_zoom_id,meeting_zoom_update,meeting_zoom_delete,meeting_zoom_data,deleted,data) VALUES (UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),?,UUID_TO_BIN(?),UUID_TO_BIN(?),?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?) ON DUPLICATE KEY UPDATE parent_id=VALUES(parent_id),provider_id=VALUES(provider_id),provider_user_id=VALUES(provider_user_id),service_type=VALUES(service_type),service_id=VALUES(service_id),client_id=VALUES(client_id),time_start=VALUES(time_start),time_end=VALUES(time_end),time_start_padded=VALUES(time_start_padded),time_end_padded=VALUES(time_end_padded),confirmed=VALUES(confirmed),client_created=VALUES(client_created),recurrence_start=VALUES(recurrence_start),recurrence_rules=VALUES(recurrence_rules),recurrence_instance_end=VALUES(recurrence_instance_end),payment_expiration=VALUES(payment_expiration),event_google_delete=VALUES(event_google_delete),event_google_update=VALUES(event_google_update),meeting_zoom_id=VALUES(meeting_zoom_id),meeting_zoom_update=VALUES(meeting_zoom_update),meeting_zoom_delete=VALUES(meeting_zoom_delete),meeting_zoom_data=VALUES(meeting_zoom_data),deleted=VALUES(deleted),data=VALUES(data)", dbTableBooking)
+	ctx, result, err := db.Exec(ctx, stmt, book.ID, book.ParentID, book.Provider.ID, book.ProviderUserID, book.ServiceType, book.Service.ID, book.Client.ID, book.TimeFrom.UTC(), book.TimeTo.UTC(), book.TimeFromPadded.UTC(), book.TimeToPadded.UTC(), confirmed, isClient, book.RecurrenceStart, book.GetRecurrenceRules(), book.RecurrenceInstanceEnd, book.PaymentExpiration, book.EventGoogleDelete, book.EventGoogleUpdate, book.MeetingZoomID, book.MeetingZoomUpdate, book.MeetingZoomDelete, meetingJSON, deleted, dataJSON)
 	if err != nil {
 		return ctx, errors.Wrap(err, "insert booking")
 	}
@@ -894,8 +1286,25 @@ func saveBooking(ctx context.Context, db *DB, book *Booking, confirmed bool, isC
 	return ctx, nil
 }
 
+//use a membership of the client for a new booking, falling back to a session credit from a prepaid package
+func applyBookingCredit(ctx context.Context, db *DB, book *Booking) (context.Context, error) {
+	ctx, err := ApplyBookingMembership(ctx, db, book)
+	if err != nil {
+		return ctx, errors.Wrap(err, "apply membership")
+	}
+	if book.IsMembershipCredit() {
+		book.ClientPackageID = nil
+		return ctx, nil
+	}
+	ctx, err = ApplyBookingPackage(ctx, db, book)
+	if err != nil {
+		return ctx, errors.Wrap(err, "apply package")
+	}
+	return ctx, nil
+}
+
 //SaveBooking : save a booking
-func SaveBooking(ctx context.Context, db *DB, provider *Provider, svc *Service, book *Booking, now time.Time, changeAllFollowing bool, confirmed bool, isClient bool, deleted bool) (context.Context, error) {
+func SaveBooking(ctx context.Context, db *DB, provider *Provider, svc *Service, book *Booking, now time.Time, scope RecurrenceScope, confirmed bool, isClient bool, deleted bool) (context.Context, error) {
 	var err error
 
 	//create the booking id if necessary
@@ -912,6 +1321,14 @@ func SaveBooking(ctx context.Context, db *DB, provider *Provider, svc *Service,
 		create = true
 	}
 	ctx, err = db.ProcessTx(ctx, "save booking", func(ctx context.Context, tx *DB) (context.Context, error) {
+		//check that the time is still available, which locks the overlapping bookings until the booking is saved
+		if !deleted && book.IsApptOnly() && book.TimeChange {
+			ctx, err := checkBookingTimeAvailable(ctx, db, svc, book, now)
+			if err != nil {
+				return ctx, errors.Wrap(err, "check booking time")
+			}
+		}
+
 		//use the specified client id if set, otherwise probe for a client
 		isNewClient := false
 		if book.Client.ID == nil {
@@ -970,31 +1387,37 @@ func SaveBooking(ctx context.Context, db *DB, provider *Provider, svc *Service,
 			book.Client = client
 		}
 
-		//apply the coupon
-		if book.Coupon != nil {
-			book.ServicePriceOriginal = svc.Price
-			book.ServicePrice = book.Coupon.AdjustPrice(svc.Price, book.Service.ID, isNewClient, now)
+		//use a membership or a session credit from a prepaid package of the client for a new booking
+		if create && !deleted {
+			ctx, err = applyBookingCredit(ctx, db, book)
+			if err != nil {
+				return ctx, errors.Wrap(err, "apply credit")
+			}
+		}
+
+		//apply the coupon, releasing the redemption if the booking is deleted
+		if deleted {
+			ctx, err = DeleteCouponRedemptionByBookingID(ctx, db, book.ID)
+			if err != nil {
+				return ctx, errors.Wrap(err, "delete coupon redemption")
+			}
+		} else {
+			ctx, err = ApplyBookingCoupon(ctx, db, svc, book, isNewClient, now)
+			if err != nil {
+				return ctx, errors.Wrap(err, "apply coupon")
+			}
 		}
 
 		//load the parent
 		saveParentBook := false
 		parentBook := book
-		if book.ParentID != nil && book.ID.String() != book.ParentID.String() {
+		if book.IsRecurringChild() {
 			ctx, parentBook, err = LoadBookingByID(ctx, db, book.ParentID, false, true)
 			if err != nil {
 				return ctx, errors.Wrap(err, fmt.Sprintf("load booking: %s", book.ParentID))
 			}
 		}
 
-		//check for a time change, in which case regenerate the recurring bookings
-		if !create && (book.TimeChange || deleted) && changeAllFollowing {
-			DeleteBookingsByParentID(ctx, db, book)
-
-			//reset the instance end date to force regeneration
-			parentBook.RecurrenceInstanceEnd = nil
-			saveParentBook = true
-		}
-
 		//save recurring bookings, checking if new ones should be generated
 		if create && !deleted && book.GenerateRecurring(now) {
 			ctx, ruleEnd, err := SaveBookingsRecurring(ctx, db, book, book.TimeFrom, book.Confirmed, isClient)
@@ -1003,18 +1426,18 @@ func SaveBooking(ctx context.Context, db *DB, provider *Provider, svc *Service,
 			}
 			parentBook.RecurrenceInstanceEnd = &ruleEnd
 			saveParentBook = true
-		} else if changeAllFollowing {
-			UpdateBookingsByParent(ctx, db, book)
-
-			//create new recurring bookings if the time changes
-			if book.TimeChange {
-				ctx, ruleEnd, err := SaveBookingsRecurring(ctx, db, book, book.TimeFrom, book.Confirmed, isClient)
+		} else if !create && book.ParentID != nil {
+			//apply the change to the series based on the scope
+			if scope == RecurrenceScopeOnce {
+				ctx, saveParentBook, err = saveBookingOnce(ctx, db, book, parentBook, isClient, deleted)
+				if err != nil {
+					return ctx, errors.Wrap(err, "save booking once")
+				}
+			} else {
+				ctx, saveParentBook, err = saveBookingFollowing(ctx, db, book, parentBook, isClient, deleted)
 				if err != nil {
-					return ctx, errors.Wrap(err, "save bookings recurring")
+					return ctx, errors.Wrap(err, "save booking following")
 				}
-				book.RecurrenceInstanceEnd = &ruleEnd
-				parentBook.RecurrenceInstanceEnd = nil
-				saveParentBook = true
 			}
 		}
 
@@ -1031,6 +1454,14 @@ func SaveBooking(ctx context.Context, db *DB, provider *Provider, svc *Service,
 				return ctx, errors.Wrap(err, "save booking parent")
 			}
 		}
+
+		//release the hold on the time
+		if book.HoldID != nil {
+			ctx, err := DeleteBookingHold(ctx, db, book.HoldID)
+			if err != nil {
+				return ctx, errors.Wrap(err, "delete booking hold")
+			}
+		}
 		return ctx, nil
 	})
 	if err != nil {
@@ -1039,6 +1470,196 @@ func SaveBooking(ctx context.Context, db *DB, provider *Provider, svc *Service,
 	return ctx, nil
 }
 
+//check for bookings and holds by other clients that conflict with the time of a booking, locking the rows for the duration of the transaction
+func checkBookingTimeAvailable(ctx context.Context, db *DB, svc *Service, book *Booking, now time.Time) (context.Context, error) {
+	user := book.GetUser()
+	serviceTypesStmt, serviceTypesArgs := createServiceTypesIn(ApptOnlyServiceTypes)
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(b.service_id),b.time_start FROM %s b INNER JOIN %s p ON p.id=b.provider_id LEFT JOIN %s pu ON pu.id=b.provider_user_id AND pu.deleted=0 WHERE b.deleted=0 AND b.provider_id=UUID_TO_BIN(?) AND b.id<>UUID_TO_BIN(?) AND COALESCE(pu.user_id,p.user_id)=UUID_TO_BIN(?) AND b.time_start_padded<? AND b.time_end_padded>? AND b.service_type IN (%s) FOR UPDATE", dbTableBooking, dbTableProvider, dbTableProviderUser, serviceTypesStmt)
+	args := []interface{}{book.Provider.ID, book.ID, user.ID, book.TimeTo.UTC(), book.TimeFrom.UTC()}
+	args = append(args, serviceTypesArgs...)
+	ctx, rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		return ctx, errors.Wrap(err, "query bookings time")
+	}
+	defer rows.Close()
+
+	//count the conflicts, where other clients in the same class do not conflict provided seats remain
+	count := 0
+	seats := 0
+	for rows.Next() {
+		var svcIDStr string
+		var timeStart time.Time
+		err := rows.Scan(&svcIDStr, &timeStart)
+		if err != nil {
+			return ctx, errors.Wrap(err, "rows scan bookings time")
+		}
+		if book.IsClass() && svcIDStr == svc.ID.String() && timeStart.Equal(book.TimeFrom) {
+			seats++
+			continue
+		}
+		count++
+	}
+	if count > 0 || seats >= svc.GetCapacity() {
+		return ctx, ErrBookingTimeTaken
+	}
+
+	//check for holds by other clients
+	ctx, count, err = CountBookingHoldsForTime(ctx, db, user.ID, book.HoldID, book.TimeFrom, book.TimeTo, now)
+	if err != nil {
+		return ctx, errors.Wrap(err, "count booking holds")
+	}
+	if count > 0 {
+		return ctx, ErrBookingTimeTaken
+	}
+	return ctx, nil
+}
+
+//save a change to a single occurrence of a recurring series, returning if the parent should be saved
+func saveBookingOnce(ctx context.Context, db *DB, book *Booking, parentBook *Booking, isClient bool, deleted bool) (context.Context, bool, error) {
+	//the occurrence only leaves the series if the time changes or if cancelled
+	if !book.TimeChange && !deleted {
+		return ctx, false, nil
+	}
+	timeOriginal := book.GetTimeFromOriginal()
+
+	//hand the series over to the next occurrence if this booking holds the series
+	if book.IsRecurringParent() {
+		ctx, err := promoteBookingSeries(ctx, db, book, isClient)
+		if err != nil {
+			return ctx, false, errors.Wrap(err, "promote booking series")
+		}
+		return ctx, false, nil
+	}
+
+	//skip the occurrence in the series
+	parentBook.RecurrenceRules = AddRecurrenceExDate(parentBook.RecurrenceRules, timeOriginal)
+	parentBook.EventGoogleUpdate = true
+	book.detachSeries(parentBook)
+	return ctx, true, nil
+}
+
+//save a change to an occurrence and all following occurrences of a recurring series, returning if the parent should be saved
+func saveBookingFollowing(ctx context.Context, db *DB, book *Booking, parentBook *Booking, isClient bool, deleted bool) (context.Context, bool, error) {
+	timeOriginal := book.GetTimeFromOriginal()
+
+	//update the following occurrences in place if the time is unchanged
+	if !book.TimeChange && !book.RecurrenceFreqChange && !deleted {
+		ctx, err := UpdateBookingsByParent(ctx, db, book, timeOriginal)
+		if err != nil {
+			return ctx, false, errors.Wrap(err, "update bookings by parent")
+		}
+		return ctx, false, nil
+	}
+
+	//carry over the remaining occurrences if the series is limited by a count
+	opts := book.RecurrenceOptions
+	if opts != nil && opts.Count > 0 {
+		seriesStart := parentBook.TimeFrom
+		if parentBook.RecurrenceStart != nil {
+			seriesStart = *parentBook.RecurrenceStart
+		}
+		count, err := CountRecurrenceRules(parentBook.RecurrenceRules, seriesStart, timeOriginal)
+		if err != nil {
+			return ctx, false, errors.Wrap(err, "count recurrence rules")
+		}
+		remainingOpts := *opts
+		remainingOpts.Count = opts.Count - count
+		if remainingOpts.Count < 1 {
+			remainingOpts.Count = 1
+		}
+		opts = &remainingOpts
+	}
+
+	//remove the following occurrences, which are regenerated as necessary
+	ctx, err := DeleteBookingsByParentID(ctx, db, parentBook, timeOriginal)
+	if err != nil {
+		return ctx, false, errors.Wrap(err, "delete bookings by parent")
+	}
+
+	//end the series prior to the occurrence, in which case the occurrence starts a new series
+	saveParentBook := false
+	if book.IsRecurringChild() {
+		rules, err := TerminateRecurrenceRules(parentBook.RecurrenceRules, timeOriginal.Add(-time.Second))
+		if err != nil {
+			return ctx, false, errors.Wrap(err, "terminate recurrence rules")
+		}
+		recurrenceInstanceEnd := MaxTime
+		parentBook.RecurrenceRules = rules
+		parentBook.RecurrenceInstanceEnd = &recurrenceInstanceEnd
+		parentBook.EventGoogleUpdate = true
+		saveParentBook = true
+		book.detachSeries(parentBook)
+		book.ParentID = book.ID
+		book.EventGoogleUpdate = true
+	}
+	if deleted {
+		return ctx, saveParentBook, nil
+	}
+
+	//regenerate the series from the occurrence
+	err = book.SetRecurrenceFreq(LoadRecurrenceFreq(book.RecurrenceFreq), opts, true)
+	if err != nil {
+		return ctx, false, errors.Wrap(err, "set recurrence freq")
+	}
+	ctx, ruleEnd, err := SaveBookingsRecurring(ctx, db, book, book.TimeFrom, book.Confirmed, isClient)
+	if err != nil {
+		return ctx, false, errors.Wrap(err, "save bookings recurring")
+	}
+	book.RecurrenceInstanceEnd = &ruleEnd
+	return ctx, saveParentBook, nil
+}
+
+//hand over a recurring series to the next occurrence, allowing the current holder of the series to change on its own
+func promoteBookingSeries(ctx context.Context, db *DB, book *Booking, isClient bool) (context.Context, error) {
+	timeOriginal := book.GetTimeFromOriginal()
+	ctx, nextBook, err := LoadBookingNextByParentID(ctx, db, book.ID, timeOriginal)
+	if err != nil {
+		return ctx, errors.Wrap(err, fmt.Sprintf("load booking next: %s", book.ID))
+	}
+
+	//skip the occurrence if there is no other occurrence to take over the series
+	if nextBook == nil {
+		book.RecurrenceRules = AddRecurrenceExDate(book.RecurrenceRules, timeOriginal)
+		return ctx, nil
+	}
+
+	//move the series to the next occurrence, skipping the current occurrence
+	nextBook.ParentID = nextBook.ID
+	nextBook.RecurrenceStart = book.RecurrenceStart
+	nextBook.RecurrenceRules = AddRecurrenceExDate(book.RecurrenceRules, timeOriginal)
+	nextBook.RecurrenceInstanceEnd = book.RecurrenceInstanceEnd
+	nextBook.EventGoogleUpdate = true
+
+	//the next occurrence takes over the event and meeting of the series unless it has its own
+	if nextBook.EventGoogleID == nil || (book.EventGoogleID != nil && *nextBook.EventGoogleID == *book.EventGoogleID) {
+		nextBook.EventGoogleID = book.EventGoogleID
+		book.EventGoogleID = nil
+	}
+	if nextBook.MeetingZoomID == nil || (book.MeetingZoomID != nil && *nextBook.MeetingZoomID == *book.MeetingZoomID) {
+		nextBook.MeetingZoomID = book.MeetingZoomID
+		nextBook.MeetingZoomData = book.MeetingZoomData
+		book.MeetingZoomID = nil
+		book.MeetingZoomData = nil
+		book.MeetingZoomUpdate = book.EnableZoom
+	}
+	ctx, err = UpdateBookingsParentID(ctx, db, book.ID, nextBook.ID)
+	if err != nil {
+		return ctx, errors.Wrap(err, "update bookings parent id")
+	}
+	ctx, err = saveBooking(ctx, db, nextBook, nextBook.Confirmed, isClient, false)
+	if err != nil {
+		return ctx, errors.Wrap(err, "save booking next")
+	}
+
+	//the current booking becomes a regular occurrence in the series
+	book.ParentID = nextBook.ID
+	book.RecurrenceStart = nil
+	book.RecurrenceRules = nil
+	book.RecurrenceInstanceEnd = nil
+	book.EventGoogleUpdate = true
+	return ctx, nil
+}
+
 //SaveBookingsRecurring : generate and save recurring bookings
 func SaveBookingsRecurring(ctx context.Context, db *DB, book *Booking, ruleStart time.Time, confirmed bool, isClient bool) (context.Context, time.Time, error) {
 	ctx, logger := GetLogger(ctx)
@@ -1061,49 +1682,87 @@ func SaveBookingsRecurring(ctx context.Context, db *DB, book *Booking, ruleStart
 	//pad the times based on the service padding
 	padding := time.Duration(book.ServicePadding) * time.Minute
 
+	//anchor the rules to the start of the series, so that intervals and counts are relative to the first occurrence
+	seriesStart := book.TimeFrom
+	if book.RecurrenceStart != nil {
+		seriesStart = *book.RecurrenceStart
+	}
+
+	//find the skipped occurrences
+	exDates := make(map[int64]bool)
+	for _, rule := range rules {
+		exDate, ok := ParseRecurrenceExDate(rule)
+		if ok {
+			exDates[exDate.Unix()] = true
+		}
+	}
+
 	//process the recurrence rules across a time window and find all times that match the recurrence rules
-	count := 0
+	finished := true
 	for _, rule := range rules {
+		if _, ok := ParseRecurrenceExDate(rule); ok {
+			continue
+		}
 		parsedRule, err := ParseRecurrenceRule(rule)
 		if err != nil {
 			return ctx, time.Time{}, errors.Wrap(err, fmt.Sprintf("parse recurrence rule: %s", rule))
 		}
-		parsedRule.DtStart = ruleStart
+		parsedRule.DtStart = seriesStart
 		logger.Debugw("recurrence rule", "rule", parsedRule, "start", ruleStart, "end", ruleEnd)
 
-		//find the event times based on the rule
+		//find the event times based on the rule, skipping times before the window
+		count := 0
 		var ruleTime time.Time
-		ruleIterator := parsedRule.Iterator().Between(ruleStart, ruleEnd)
+		ruleIterator := parsedRule.Iterator().Before(ruleEnd)
 		for ruleIterator.Step(&ruleTime) {
 			count++
+			if !ruleTime.After(ruleStart) {
+				continue
+			}
 
-			//save a booking for the new times, ignoring the current booking times
-			if ruleTime.Equal(book.TimeFrom) {
+			//save a booking for the new times, ignoring the current booking times and skipped occurrences
+			if ruleTime.Equal(book.TimeFrom) || exDates[ruleTime.Unix()] {
 				continue
 			}
-			ruleBook.ID = nil
+			ruleID, err := uuid.NewV4()
+			if err != nil {
+				return ctx, time.Time{}, errors.Wrap(err, "new uuid booking")
+			}
+			ruleBook.ID = &ruleID
 			ruleBook.TimeFrom = ruleTime
 			ruleBook.TimeFromPadded = ruleBook.TimeFrom.Add(-padding)
 			ruleBook.TimeTo = ruleTime.Add(ruleDurationMin)
 			ruleBook.TimeToPadded = ruleBook.TimeTo.Add(padding)
 			logger.Debugw("recurring instance", "from", ruleBook.TimeFrom, "to", ruleBook.TimeTo)
+
+			//use a membership or a session credit from a prepaid package of the client for each occurrence
+			ctx, err = applyBookingCredit(ctx, db, &ruleBook)
+			if err != nil {
+				return ctx, time.Time{}, errors.Wrap(err, "apply credit")
+			}
 			ctx, err = saveBooking(ctx, db, &ruleBook, confirmed, isClient, false)
 			if err != nil {
 				return ctx, time.Time{}, errors.Wrap(err, fmt.Sprintf("save recurring booking: %s: %s-%s", book.ID, ruleBook.TimeFrom, ruleBook.TimeTo))
 			}
 			bookEnd = ruleBook.TimeTo
 		}
+
+		//check if the rule has ended based on the count or until date
+		ruleFinished := (parsedRule.Count > 0 && count >= parsedRule.Count) || (!parsedRule.Until.IsZero() && parsedRule.Until.Before(ruleEnd))
+		if !ruleFinished {
+			finished = false
+		}
 	}
 
-	//if the count is zero, then there are no more recurring events, so use the maximum date to avoid further processing
-	if count == 0 {
-		ruleEnd = MaxTime
+	//if all rules have ended, then there are no more recurring events, so use the maximum date to avoid further processing
+	if finished {
+		bookEnd = MaxTime
 	}
 	return ctx, bookEnd, nil
 }
 
-//UpdateBookingsByParent : update child booking by the parent
-func UpdateBookingsByParent(ctx context.Context, db *DB, book *Booking) (context.Context, error) {
+//UpdateBookingsByParent : update child bookings following the time by the parent
+func UpdateBookingsByParent(ctx context.Context, db *DB, book *Booking, from time.Time) (context.Context, error) {
 	//json encode the booking data
 	dataJSON, err := json.Marshal(book)
 	if err != nil {
@@ -1112,13 +1771,36 @@ func UpdateBookingsByParent(ctx context.Context, db *DB, book *Booking) (context
 
 	//update
 	stmt := fmt.Sprintf("UPDATE %s SET service_type=?,service_id=UUID_TO_BIN(?),client_id=UUID_TO_BIN(?),data=? WHERE deleted=0 AND time_start>? AND parent_id=UUID_TO_BIN(?)", dbTableBooking)
-	ctx, _, err = db.Exec(ctx, stmt, book.ServiceType, book.Service.ID, book.Client.ID, dataJSON, book.TimeFrom, book.ParentID)
+	ctx, _, err = db.Exec(ctx, stmt, book.ServiceType, book.Service.ID, book.Client.ID, dataJSON, from, book.ParentID)
 	if err != nil {
 		return ctx, errors.Wrap(err, "update booking parent")
 	}
 	return ctx, nil
 }
 
+//UpdateBookingsParentID : move the child bookings of a parent to a new parent
+func UpdateBookingsParentID(ctx context.Context, db *DB, parentID *uuid.UUID, newParentID *uuid.UUID) (context.Context, error) {
+	stmt := fmt.Sprintf("UPDATE %s SET parent_id=UUID_TO_BIN(?) WHERE parent_id=UUID_TO_BIN(?) AND id!=UUID_TO_BIN(?)", dbTableBooking)
+	ctx, _, err := db.Exec(ctx, stmt, newParentID, parentID, parentID)
+	if err != nil {
+		return ctx, errors.Wrap(err, "update booking parent id")
+	}
+	return ctx, nil
+}
+
+//detach an occurrence from the event and meeting shared with the parent of the series
+func (b *Booking) detachSeries(parentBook *Booking) {
+	if b.EventGoogleID != nil && parentBook.EventGoogleID != nil && *b.EventGoogleID == *parentBook.EventGoogleID {
+		b.EventGoogleID = nil
+		b.EventGoogleUpdate = true
+	}
+	if b.MeetingZoomID != nil && parentBook.MeetingZoomID != nil && *b.MeetingZoomID == *parentBook.MeetingZoomID {
+		b.MeetingZoomID = nil
+		b.MeetingZoomData = nil
+		b.MeetingZoomUpdate = b.EnableZoom
+	}
+}
+
 //DeleteBooking : delete a booking
 func DeleteBooking(ctx context.Context, db *DB, id *uuid.UUID) (context.Context, error) {
 	stmt := fmt.Sprintf("UPDATE %s SET deleted=1,event_google_delete=1,meeting_zoom_delete=1 WHERE deleted=0 AND id=UUID_TO_BIN(?)", dbTableBooking)
@@ -1129,10 +1811,10 @@ func DeleteBooking(ctx context.Context, db *DB, id *uuid.UUID) (context.Context,
 	return ctx, nil
 }
 
-//DeleteBookingsByParentID : delete a booking by the parent id
-func DeleteBookingsByParentID(ctx context.Context, db *DB, book *Booking) (context.Context, error) {
-	stmt := fmt.Sprintf("UPDATE %s SET deleted=1,event_google_delete=1,meeting_zoom_delete=1 WHERE deleted=0 AND time_start>? AND parent_id=UUID_TO_BIN(?)", dbTableBooking)
-	ctx, _, err := db.Exec(ctx, stmt, book.TimeFrom, book.ParentID)
+//DeleteBookingsByParentID : delete the child bookings following the time by the parent, leaving any event or meeting shared with the parent
+func DeleteBookingsByParentID(ctx context.Context, db *DB, parentBook *Booking, from time.Time) (context.Context, error) {
+	stmt := fmt.Sprintf("UPDATE %s SET deleted=1,event_google_delete=(event_google_id IS NOT NULL AND NOT event_google_id<=>?),meeting_zoom_delete=(meeting_zoom_id IS NOT NULL AND NOT meeting_zoom_id<=>?) WHERE deleted=0 AND time_start>? AND parent_id=UUID_TO_BIN(?) AND id!=UUID_TO_BIN(?)", dbTableBooking)
+	ctx, _, err := db.Exec(ctx, stmt, parentBook.EventGoogleID, parentBook.MeetingZoomID, from, parentBook.ID, parentBook.ID)
 	if err != nil {
 		return ctx, errors.Wrap(err, "delete booking by parent")
 	}
@@ -1214,10 +1896,28 @@ func ListBookingsByProviderIDAndTime(ctx context.Context, db *DB, providerID *uu
 	return listBookings(ctx, db, whereStmt, "", args...)
 }
 
-//ListBookingsByProviderIDAndTypeAndTime : load the bookings for a provider or a specified type over a time span
-func ListBookingsByProviderIDAndTypeAndTime(ctx context.Context, db *DB, providerID *uuid.UUID, user *User, serviceType ServiceType, fromTime time.Time, toTime time.Time) (context.Context, []*Booking, error) {
-	whereStmt := "b.deleted=0 AND c.provider_id=UUID_TO_BIN(?) AND service_type=? AND ((b.time_start_padded>=? AND b.time_start_padded<=?) OR (b.time_end_padded>=? AND b.time_end_padded<=?))"
-	args := []interface{}{providerID, serviceType, fromTime.UTC(), toTime.UTC(), fromTime.UTC(), toTime.UTC()}
+//create the in-clause for a list of service types
+func createServiceTypesIn(serviceTypes []ServiceType) (string, []interface{}) {
+	args := make([]interface{}, 0, len(serviceTypes))
+	for _, serviceType := range serviceTypes {
+		args = append(args, serviceType)
+	}
+	return strings.TrimSuffix(strings.Repeat("?,", len(serviceTypes)), ","), args
+}
+
+//ListBookingsPaymentExpired : load the unconfirmed bookings for which the payment required to book has not been made in time
+func ListBookingsPaymentExpired(ctx context.Context, db *DB, now time.Time) (context.Context, []*Booking, error) {
+	whereStmt := "b.deleted=0 AND b.confirmed=0 AND b.payment_expiration<=? AND pmt.paid IS NULL AND pmt.captured IS NULL"
+	return listBookings(ctx, db, whereStmt, "", now.UTC())
+}
+
+//ListBookingsByProviderIDAndTypeAndTime : load the bookings for a provider or the specified types over a time span
+func ListBookingsByProviderIDAndTypeAndTime(ctx context.Context, db *DB, providerID *uuid.UUID, user *User, serviceTypes []ServiceType, fromTime time.Time, toTime time.Time) (context.Context, []*Booking, error) {
+	serviceTypesStmt, serviceTypesArgs := createServiceTypesIn(serviceTypes)
+	whereStmt := fmt.Sprintf("b.deleted=0 AND c.provider_id=UUID_TO_BIN(?) AND b.service_type IN (%s) AND ((b.time_start_padded>=? AND b.time_start_padded<=?) OR (b.time_end_padded>=? AND b.time_end_padded<=?))", serviceTypesStmt)
+	args := []interface{}{providerID}
+	args = append(args, serviceTypesArgs...)
+	args = append(args, fromTime.UTC(), toTime.UTC(), fromTime.UTC(), toTime.UTC())
 
 	//match the user if set
 	if user != nil {
@@ -1336,9 +2036,9 @@ func MarkBookingConfirmed(ctx context.Context, db *DB, svc *Service, book *Booki
 		}
 
 		//apply the coupon
-		if book.Coupon != nil {
-			book.ServicePriceOriginal = svc.Price
-			book.ServicePrice = book.Coupon.AdjustPrice(svc.Price, book.Service.ID, false, now)
+		ctx, err := ApplyBookingCoupon(ctx, db, svc, book, false, now)
+		if err != nil {
+			return ctx, errors.Wrap(err, "apply coupon")
 		}
 
 		//json encode the booking data
@@ -1348,12 +2048,13 @@ func MarkBookingConfirmed(ctx context.Context, db *DB, svc *Service, book *Booki
 		}
 
 		//update the booking, triggering a calendar update
-		stmt := fmt.Sprintf("UPDATE %s SET confirmed=1,event_google_update=1,data=? WHERE id=UUID_TO_BIN(?) OR parent_id=UUID_TO_BIN(?)", dbTableBooking)
+		stmt := fmt.Sprintf("UPDATE %s SET confirmed=1,payment_expiration=NULL,event_google_update=1,data=? WHERE id=UUID_TO_BIN(?) OR parent_id=UUID_TO_BIN(?)", dbTableBooking)
 		ctx, _, err = db.Exec(ctx, stmt, dataJSON, book.ID, book.ID)
 		if err != nil {
 			return ctx, errors.Wrap(err, "update booking confirmed")
 		}
 		book.Confirmed = true
+		book.PaymentExpiration = nil
 		return ctx, nil
 	})
 	if err != nil {
@@ -1423,11 +2124,13 @@ func CountBookingsForClient(ctx context.Context, db *DB, clientID *uuid.UUID) (c
 }
 
 //CountBookingsForProviderAndTime : find the number bookings for a provider over the given time
-func CountBookingsForProviderAndTime(ctx context.Context, db *DB, providerID *uuid.UUID, user *User, start time.Time, end time.Time, serviceType ServiceType) (context.Context, int, error) {
+func CountBookingsForProviderAndTime(ctx context.Context, db *DB, providerID *uuid.UUID, user *User, start time.Time, end time.Time, serviceTypes []ServiceType) (context.Context, int, error) {
 	start = start.UTC()
 	end = end.UTC()
-	stmt := fmt.Sprintf("SELECT COUNT(*) FROM %s b INNER JOIN %s s ON s.id=b.service_id INNER JOIN %s p ON p.id=s.provider_id LEFT JOIN %s pu ON pu.id=b.provider_user_id AND pu.deleted=0 LEFT JOIN %s puu ON puu.id=pu.user_id AND puu.deleted=0 WHERE b.deleted=0 AND p.id=UUID_TO_BIN(?) AND ((b.time_start_padded>=? AND b.time_start_padded<?) OR (b.time_end_padded>? AND b.time_end_padded<=?)) AND b.service_type=?", dbTableBooking, dbTableService, dbTableProvider, dbTableProviderUser, dbTableUser)
-	args := []interface{}{providerID, start.UTC(), end.UTC(), start.UTC(), end.UTC(), serviceType}
+	serviceTypesStmt, serviceTypesArgs := createServiceTypesIn(serviceTypes)
+	stmt := fmt.Sprintf("SELECT COUNT(*) FROM %s b INNER JOIN %s s ON s.id=b.service_id INNER JOIN %s p ON p.id=s.provider_id LEFT JOIN %s pu ON pu.id=b.provider_user_id AND pu.deleted=0 LEFT JOIN %s puu ON puu.id=pu.user_id AND puu.deleted=0 WHERE b.deleted=0 AND p.id=UUID_TO_BIN(?) AND ((b.time_start_padded>=? AND b.time_start_padded<?) OR (b.time_end_padded>? AND b.time_end_padded<=?)) AND b.service_type IN (%s)", dbTableBooking, dbTableService, dbTableProvider, dbTableProviderUser, dbTableUser, serviceTypesStmt)
+	args := []interface{}{providerID, start.UTC(), end.UTC(), start.UTC(), end.UTC()}
+	args = append(args, serviceTypesArgs...)
 
 	//match the user if set
 	if user != nil {
@@ -1451,6 +2154,50 @@ func CountBookingsForProviderAndTime(ctx context.Context, db *DB, providerID *uu
 	return ctx, count, nil
 }
 
+//CountBookingsForServiceAndTime : find the number of bookings for a service starting at the given time, used to count the seats taken in a class
+func CountBookingsForServiceAndTime(ctx context.Context, db *DB, svcID *uuid.UUID, providerUserID *uuid.UUID, start time.Time) (context.Context, int, error) {
+	stmt := fmt.Sprintf("SELECT COUNT(*) FROM %s b WHERE b.deleted=0 AND b.service_id=UUID_TO_BIN(?) AND b.time_start=?", dbTableBooking)
+	args := []interface{}{svcID, start.UTC()}
+
+	//match the provider user teaching the class
+	if providerUserID != nil {
+		stmt = fmt.Sprintf("%s AND b.provider_user_id=UUID_TO_BIN(?)", stmt)
+		args = append(args, providerUserID)
+	} else {
+		stmt = fmt.Sprintf("%s AND b.provider_user_id IS NULL", stmt)
+	}
+	ctx, row, err := db.QueryRow(ctx, stmt, args...)
+	if err != nil {
+		return ctx, 0, errors.Wrap(err, "query row bookings count service time")
+	}
+
+	//read the row
+	var count int
+	err = row.Scan(&count)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx, 0, nil
+		}
+		return ctx, 0, errors.Wrap(err, "select bookings count service time")
+	}
+	return ctx, count, nil
+}
+
+//ListBookingsByServiceIDAndTime : load the bookings for a service starting at the given time, used to list the attendees of a class
+func ListBookingsByServiceIDAndTime(ctx context.Context, db *DB, svcID *uuid.UUID, providerUserID *uuid.UUID, start time.Time) (context.Context, []*Booking, error) {
+	whereStmt := "b.deleted=0 AND b.service_id=UUID_TO_BIN(?) AND b.time_start=?"
+	args := []interface{}{svcID, start.UTC()}
+
+	//match the provider user teaching the class
+	if providerUserID != nil {
+		whereStmt = fmt.Sprintf("%s AND b.provider_user_id=UUID_TO_BIN(?)", whereStmt)
+		args = append(args, providerUserID)
+	} else {
+		whereStmt = fmt.Sprintf("%s AND b.provider_user_id IS NULL", whereStmt)
+	}
+	return listBookings(ctx, db, whereStmt, "b.created,b.id", args...)
+}
+
 //ListBookingEventsToProcessForGoogle : list booking events to process for Google
 func ListBookingEventsToProcessForGoogle(ctx context.Context, db *DB, limit int) (context.Context, []*Booking, error) {
 	ctx, logger := GetLogger(ctx)
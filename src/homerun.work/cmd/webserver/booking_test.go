@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCheckBookingTimeAvailable(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	start := now.Add(24 * time.Hour)
+	otherID := newFakeID(t)
+	tests := []struct {
+		name     string
+		svcType  ServiceType
+		capacity int
+		booked   []time.Time
+		others   int
+		holds    int64
+		want     error
+	}{
+		{"class with seats remaining", ServiceTypeClass, 3, []time.Time{start, start}, 0, 0, nil},
+		{"class full", ServiceTypeClass, 3, []time.Time{start, start, start}, 0, 0, ErrBookingTimeTaken},
+		{"class at another start overlapping", ServiceTypeClass, 3, []time.Time{start.Add(30 * time.Minute)}, 0, 0, ErrBookingTimeTaken},
+		{"class overlapping another service", ServiceTypeClass, 3, nil, 1, 0, ErrBookingTimeTaken},
+		{"class held by another client", ServiceTypeClass, 3, nil, 0, 1, ErrBookingTimeTaken},
+		{"appointment free", ServiceTypeAppt, 0, nil, 0, 0, nil},
+		{"appointment overlapping the same service", ServiceTypeAppt, 0, []time.Time{start}, 0, 0, ErrBookingTimeTaken},
+		{"appointment held by another client", ServiceTypeAppt, 0, nil, 0, 1, ErrBookingTimeTaken},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, s := newFakeDB(t)
+			user := &User{ID: newFakeID(t), Email: "provider@example.com"}
+			provider := &Provider{ID: newFakeID(t), User: user}
+			svc := &Service{ID: newFakeID(t), Type: test.svcType, Capacity: test.capacity, Provider: provider}
+			book := &Booking{
+				ID:          newFakeID(t),
+				Provider:    provider,
+				Service:     svc,
+				ServiceType: svc.Type,
+				TimeFrom:    start,
+				TimeTo:      start.Add(time.Hour),
+			}
+
+			//bookings of the same service and of other services overlapping the time
+			rows := make([][]driver.Value, 0, len(test.booked)+test.others)
+			for _, timeStart := range test.booked {
+				rows = append(rows, []driver.Value{svc.ID.String(), timeStart})
+			}
+			for i := 0; i < test.others; i++ {
+				rows = append(rows, []driver.Value{otherID.String(), start})
+			}
+			db.onRows("b.time_start_padded<? AND b.time_end_padded>?", rows...)
+			db.onRows(fmt.Sprintf("FROM %s h", dbTableBookingHold), []driver.Value{test.holds})
+
+			_, err := checkBookingTimeAvailable(context.Background(), s.getDB(), svc, book, now)
+			if err != test.want {
+				t.Errorf("got %v, want %v", err, test.want)
+			}
+		})
+	}
+}
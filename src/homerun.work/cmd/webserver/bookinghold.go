@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+//booking hold db tables
+const (
+	dbTableBookingHold = "service_booking_hold"
+)
+
+//BookingHoldDuration : duration a time is held for a client during checkout
+const BookingHoldDuration = 10 * time.Minute
+
+//BookingHold : definition of a time temporarily reserved while a client completes a booking
+type BookingHold struct {
+	ID             *uuid.UUID
+	ProviderID     *uuid.UUID
+	UserID         *uuid.UUID
+	ServiceID      *uuid.UUID
+	TimeFrom       time.Time
+	TimeTo         time.Time
+	TimeFromPadded time.Time
+	TimeToPadded   time.Time
+	Expiration     time.Time
+}
+
+//CreateBookingHold : create a hold for the booking time of a service
+func CreateBookingHold(id *uuid.UUID, provider *Provider, user *User, svc *Service, timeFrom time.Time, now time.Time) *BookingHold {
+	timeTo := svc.ComputeTimeTo(timeFrom)
+	padding := time.Duration(svc.Padding) * time.Minute
+	return &BookingHold{
+		ID:             id,
+		ProviderID:     provider.ID,
+		UserID:         user.ID,
+		ServiceID:      svc.ID,
+		TimeFrom:       timeFrom,
+		TimeTo:         timeTo,
+		TimeFromPadded: timeFrom.Add(-padding),
+		TimeToPadded:   timeTo.Add(padding),
+		Expiration:     now.Add(BookingHoldDuration),
+	}
+}
+
+//SaveBookingHold : save a hold, returning false if the time is already held by another client
+func SaveBookingHold(ctx context.Context, db *DB, hold *BookingHold, now time.Time) (context.Context, bool, error) {
+	ok := false
+	ctx, err := db.ProcessTx(ctx, "save booking hold", func(ctx context.Context, db *DB) (context.Context, error) {
+		//generate an id if necessary
+		if hold.ID == nil {
+			id, err := uuid.NewV4()
+			if err != nil {
+				return ctx, errors.Wrap(err, "new uuid booking hold")
+			}
+			hold.ID = &id
+		}
+
+		//check for other holds on the time
+		ctx, count, err := CountBookingHoldsForTime(ctx, db, hold.UserID, hold.ID, hold.TimeFrom, hold.TimeTo, now)
+		if err != nil {
+			return ctx, errors.Wrap(err, "count booking holds")
+		}
+		if count > 0 {
+			return ctx, nil
+		}
+
+		//save to the db
+		stmt := fmt.Sprintf("INSERT INTO %s(id,provider_id,user_id,service_id,time_start,time_end,time_start_padded,time_end_padded,expiration) VALUES (UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),?,?,?,?,?) ON DUPLICATE KEY UPDATE provider_id=VALUES(provider_id),user_id=VALUES(user_id),service_id=VALUES(service_id),time_start=VALUES(time_start),time_end=VALUES(time_end),time_start_padded=VALUES(time_start_padded),time_end_padded=VALUES(time_end_padded),expiration=VALUES(expiration)", dbTableBookingHold)
+		ctx, result, err := db.Exec(ctx, stmt, hold.ID, hold.ProviderID, hold.UserID, hold.ServiceID, hold.TimeFrom.UTC(), hold.TimeTo.UTC(), hold.TimeFromPadded.UTC(), hold.TimeToPadded.UTC(), hold.Expiration.UTC())
+		if err != nil {
+			return ctx, errors.Wrap(err, "insert booking hold")
+		}
+		count64, err := result.RowsAffected()
+		if err != nil {
+			return ctx, errors.Wrap(err, "insert booking hold rows affected")
+		}
+
+		//0 indicated no update, 1 an insert, 2 an update
+		if count64 < 0 || count64 > 2 {
+			return ctx, fmt.Errorf("unable to insert booking hold: %s", hold.ID)
+		}
+		ok = true
+		return ctx, nil
+	})
+	if err != nil {
+		return ctx, false, errors.Wrap(err, "save booking hold")
+	}
+	return ctx, ok, nil
+}
+
+//CountBookingHoldsForTime : count the unexpired holds by other clients overlapping the given time, locking the holds for the duration of a transaction
+func CountBookingHoldsForTime(ctx context.Context, db *DB, userID *uuid.UUID, holdID *uuid.UUID, start time.Time, end time.Time, now time.Time) (context.Context, int, error) {
+	stmt := fmt.Sprintf("SELECT COUNT(*) FROM %s h WHERE h.user_id=UUID_TO_BIN(?) AND h.expiration>? AND h.time_start_padded<? AND h.time_end_padded>?", dbTableBookingHold)
+	args := []interface{}{userID, now.UTC(), end.UTC(), start.UTC()}
+
+	//exclude the hold of the client
+	if holdID != nil {
+		stmt = fmt.Sprintf("%s AND h.id<>UUID_TO_BIN(?)", stmt)
+		args = append(args, holdID)
+	}
+	stmt = fmt.Sprintf("%s FOR UPDATE", stmt)
+	ctx, row, err := db.QueryRow(ctx, stmt, args...)
+	if err != nil {
+		return ctx, 0, errors.Wrap(err, "query row booking holds time")
+	}
+
+	//read the row
+	var count int
+	err = row.Scan(&count)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx, 0, nil
+		}
+		return ctx, 0, errors.Wrap(err, "select booking holds time")
+	}
+	return ctx, count, nil
+}
+
+//ListBookingHoldTimesByProviderIDAndTime : load the times of the unexpired holds by other clients for a provider over a time span
+func ListBookingHoldTimesByProviderIDAndTime(ctx context.Context, db *DB, providerID *uuid.UUID, user *User, holdID *uuid.UUID, fromTime time.Time, toTime time.Time) (context.Context, []*TimePeriod, error) {
+	stmt := fmt.Sprintf("SELECT h.time_start_padded,h.time_end_padded FROM %s h WHERE h.provider_id=UUID_TO_BIN(?) AND h.expiration>CURRENT_TIMESTAMP() AND h.time_start_padded<=? AND h.time_end_padded>=?", dbTableBookingHold)
+	args := []interface{}{providerID, toTime.UTC(), fromTime.UTC()}
+
+	//match the user if set
+	if user != nil {
+		stmt = fmt.Sprintf("%s AND h.user_id=UUID_TO_BIN(?)", stmt)
+		args = append(args, user.ID)
+	}
+
+	//exclude the hold of the client
+	if holdID != nil {
+		stmt = fmt.Sprintf("%s AND h.id<>UUID_TO_BIN(?)", stmt)
+		args = append(args, holdID)
+	}
+	ctx, rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "query booking holds")
+	}
+	defer rows.Close()
+
+	//read the rows
+	timePeriods := make([]*TimePeriod, 0, 2)
+	for rows.Next() {
+		var timePeriod TimePeriod
+		err := rows.Scan(&timePeriod.Start, &timePeriod.End)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "rows scan booking holds")
+		}
+		timePeriods = append(timePeriods, &timePeriod)
+	}
+	return ctx, timePeriods, nil
+}
+
+//DeleteBookingHold : release a hold
+func DeleteBookingHold(ctx context.Context, db *DB, id *uuid.UUID) (context.Context, error) {
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE id=UUID_TO_BIN(?)", dbTableBookingHold)
+	ctx, _, err := db.Exec(ctx, stmt, id)
+	if err != nil {
+		return ctx, errors.Wrap(err, "delete booking hold")
+	}
+	return ctx, nil
+}
+
+//DeleteBookingHoldsExpired : release the expired holds
+func DeleteBookingHoldsExpired(ctx context.Context, db *DB, now time.Time) (context.Context, int64, error) {
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE expiration<=?", dbTableBookingHold)
+	ctx, result, err := db.Exec(ctx, stmt, now.UTC())
+	if err != nil {
+		return ctx, 0, errors.Wrap(err, "delete booking holds expired")
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return ctx, 0, errors.Wrap(err, "delete booking holds expired rows affected")
+	}
+	return ctx, count, nil
+}
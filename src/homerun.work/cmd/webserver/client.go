@@ -29,6 +29,26 @@ type Client struct {
 	Location      string     `json:"Location"`
 	Phone         string     `json:"Phone"`
 	TimeZone      string     `json:"TimeZone"`
+
+	//opt-out of reminders for unpaid invoices
+	DisablePaymentReminders bool `json:"DisablePaymentReminders"`
+
+	//stripe metadata
+	StripeCustomerID    *string              `json:"-"`
+	PaymentMethodStripe *PaymentMethodStripe `json:"-"`
+}
+
+//HasCard : check if a card has been saved for the client
+func (c *Client) HasCard() bool {
+	return c.StripeCustomerID != nil && c.PaymentMethodStripe != nil && c.PaymentMethodStripe.PaymentMethod != nil
+}
+
+//FormatCard : format the saved card
+func (c *Client) FormatCard() string {
+	if !c.HasCard() {
+		return ""
+	}
+	return c.PaymentMethodStripe.FormatCard()
 }
 
 //SetEmail : set the email
@@ -50,7 +70,7 @@ func (c *Client) GetEmail() string {
 //load a client
 func loadClient(ctx context.Context, db *DB, whereStmt string, args ...interface{}) (context.Context, *Client, error) {
 	//create the final query
-	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(id),BIN_TO_UUID(provider_id),email,invited,disable_emails,data FROM %s WHERE %s", dbTableClient, whereStmt)
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(id),BIN_TO_UUID(provider_id),email,invited,disable_emails,stripe_customer_id,stripe_data,data FROM %s WHERE %s", dbTableClient, whereStmt)
 
 	//load the client
 	ctx, row, err := db.QueryRow(ctx, stmt, args...)
@@ -64,8 +84,10 @@ func loadClient(ctx context.Context, db *DB, whereStmt string, args ...interface
 	var email string
 	var invited sql.NullTime
 	var disableEmailsBit string
+	var stripeCustomerID sql.NullString
+	var stripeData sql.NullString
 	var dataStr string
-	err = row.Scan(&idStr, &providerIDStr, &email, &invited, &disableEmailsBit, &dataStr)
+	err = row.Scan(&idStr, &providerIDStr, &email, &invited, &disableEmailsBit, &stripeCustomerID, &stripeData, &dataStr)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return ctx, nil, nil
@@ -96,6 +118,18 @@ func loadClient(ctx context.Context, db *DB, whereStmt string, args ...interface
 	if invited.Valid {
 		client.Invited = &invited.Time
 	}
+
+	//read the stripe data
+	if stripeCustomerID.Valid {
+		client.StripeCustomerID = &stripeCustomerID.String
+	}
+	if stripeData.Valid {
+		paymentMethod, err := ParsePaymentMethodStripe([]byte(stripeData.String))
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "parse client stripe data")
+		}
+		client.PaymentMethodStripe = paymentMethod
+	}
 	return ctx, &client, nil
 }
 
@@ -269,6 +303,32 @@ func ListClientsByProviderID(ctx context.Context, db *DB, providerID *uuid.UUID)
 	return ctx, clients, nil
 }
 
+//UpdateClientStripe : update the Stripe customer and saved card for a client
+func UpdateClientStripe(ctx context.Context, db *DB, id *uuid.UUID, stripeCustomerID *string, paymentMethod *PaymentMethodStripe) (context.Context, error) {
+	//json encode the payment method
+	var stripeData *string
+	if paymentMethod != nil {
+		paymentMethodJSON, err := json.Marshal(paymentMethod)
+		if err != nil {
+			return ctx, errors.Wrap(err, "json client payment method")
+		}
+		paymentMethodStr := string(paymentMethodJSON)
+		stripeData = &paymentMethodStr
+	}
+
+	//update
+	stmt := fmt.Sprintf("UPDATE %s SET stripe_customer_id=?,stripe_data=? WHERE id=UUID_TO_BIN(?)", dbTableClient)
+	ctx, result, err := db.Exec(ctx, stmt, stripeCustomerID, stripeData, id)
+	if err != nil {
+		return ctx, errors.Wrap(err, "update client stripe")
+	}
+	_, err = result.RowsAffected()
+	if err != nil {
+		return ctx, errors.Wrap(err, "update client stripe rows affected")
+	}
+	return ctx, nil
+}
+
 //UpdateClientInvited : update the client invited
 func UpdateClientInvited(ctx context.Context, db *DB, id *uuid.UUID) (context.Context, error) {
 	stmt := fmt.Sprintf("UPDATE %s SET invited=CURRENT_TIMESTAMP() WHERE id=UUID_TO_BIN(?)", dbTableClient)
@@ -0,0 +1,441 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+//client membership db tables
+const (
+	dbTableClientMembership    = "client_membership"
+	dbTableClientMembershipUse = "client_membership_use"
+)
+
+//MembershipStatus : status of a client membership
+type MembershipStatus int
+
+//membership statuses
+const (
+	MembershipStatusPending MembershipStatus = iota + 1
+	MembershipStatusActive
+	MembershipStatusPastDue
+	MembershipStatusCancelled
+)
+
+//Label : label for the membership status
+func (m MembershipStatus) Label() string {
+	switch m {
+	case MembershipStatusPending:
+		return "Pending"
+	case MembershipStatusActive:
+		return "Active"
+	case MembershipStatusPastDue:
+		return "Past Due"
+	case MembershipStatusCancelled:
+		return "Cancelled"
+	}
+	return ""
+}
+
+//ClientMembership : definition of a membership of a client, billed monthly through a Stripe subscription
+type ClientMembership struct {
+	ID                   *uuid.UUID       `json:"-"`
+	ProviderID           *uuid.UUID       `json:"-"`
+	MembershipPlanID     *uuid.UUID       `json:"-"`
+	ClientID             *uuid.UUID       `json:"-"`
+	ClientName           string           `json:"-"`
+	ClientEmail          string           `json:"-"`
+	StripeSubscriptionID *string          `json:"-"`
+	Status               MembershipStatus `json:"-"`
+	PeriodStart          *time.Time       `json:"-"`
+	PeriodEnd            *time.Time       `json:"-"`
+	SessionsUsed         int              `json:"-"` //in the current period
+	Created              time.Time        `json:"-"`
+	Name                 string           `json:"Name"`
+	Price                float32          `json:"Price"`
+	Currency             Currency         `json:"Currency"`
+	Sessions             int              `json:"Sessions"` //per month, where zero indicates unlimited
+	ServiceIDs           []*uuid.UUID     `json:"ServiceIDs"`
+	ServiceNames         []string         `json:"ServiceNames"`
+	StripePlanID         string           `json:"StripePlanID"`
+}
+
+//IsActive : check if the membership is active for the current period
+func (c *ClientMembership) IsActive() bool {
+	return c.Status == MembershipStatusActive
+}
+
+//IsPending : check if the membership is waiting on the client to subscribe
+func (c *ClientMembership) IsPending() bool {
+	return c.Status == MembershipStatusPending
+}
+
+//IsCancellable : check if the subscription for the membership can be cancelled
+func (c *ClientMembership) IsCancellable() bool {
+	return c.StripeSubscriptionID != nil && c.Status != MembershipStatusCancelled
+}
+
+//IsUnlimited : check if the membership has unlimited sessions
+func (c *ClientMembership) IsUnlimited() bool {
+	return c.Sessions == 0
+}
+
+//ComputeSessionsRemaining : compute the number of sessions remaining in the current period
+func (c *ClientMembership) ComputeSessionsRemaining() int {
+	return Max(c.Sessions-c.SessionsUsed, 0)
+}
+
+//FormatPrice : format the monthly price
+func (c *ClientMembership) FormatPrice() string {
+	return (&MembershipPlan{Price: c.Price}).FormatPrice(c.Currency)
+}
+
+//FormatSessions : format the sessions remaining in the current period
+func (c *ClientMembership) FormatSessions() string {
+	if c.IsUnlimited() {
+		return fmt.Sprintf("Unlimited, %d used", c.SessionsUsed)
+	}
+	return fmt.Sprintf("%d of %d", c.ComputeSessionsRemaining(), c.Sessions)
+}
+
+//FormatSessionsPlan : format the number of sessions per month
+func (c *ClientMembership) FormatSessionsPlan() string {
+	return formatMembershipSessions(c.Sessions)
+}
+
+//FormatServices : format the services covered by the membership
+func (c *ClientMembership) FormatServices() string {
+	return strings.Join(c.ServiceNames, ", ")
+}
+
+//FormatStatus : format the status
+func (c *ClientMembership) FormatStatus() string {
+	return c.Status.Label()
+}
+
+//FormatPeriod : format the current period
+func (c *ClientMembership) FormatPeriod(timeZone string) string {
+	if c.PeriodStart == nil || c.PeriodEnd == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s - %s", FormatDateLocal(*c.PeriodStart, timeZone), FormatDateLocal(*c.PeriodEnd, timeZone))
+}
+
+//FormatCreated : format the date the membership was started
+func (c *ClientMembership) FormatCreated(timeZone string) string {
+	return FormatDateLocal(c.Created, timeZone)
+}
+
+//create the statement to load a client membership, counting the sessions used by bookings in the current period that have not been cancelled
+func clientMembershipQueryCreate(whereStmt string) string {
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(cm.id),BIN_TO_UUID(cm.provider_id),BIN_TO_UUID(cm.membership_plan_id),BIN_TO_UUID(cm.client_id),c.email,c.data->>'$.Name',cm.stripe_subscription_id,cm.status,cm.period_start,cm.period_end,cm.created,cm.data,(SELECT COUNT(*) FROM %s u INNER JOIN %s b ON b.id=u.booking_id WHERE u.deleted=0 AND u.client_membership_id=cm.id AND b.deleted=0 AND b.time_start>=cm.period_start AND b.time_start<cm.period_end) FROM %s cm INNER JOIN %s c ON c.id=cm.client_id WHERE cm.deleted=0 AND %s ORDER BY cm.created DESC", dbTableClientMembershipUse, dbTableBooking, dbTableClientMembership, dbTableClient, whereStmt)
+	return stmt
+}
+
+//parse a client membership
+func clientMembershipQueryParse(rowFn ScanFn) (*ClientMembership, error) {
+	//read the row
+	var idStr string
+	var providerIDStr string
+	var membershipPlanIDStr string
+	var clientIDStr string
+	var clientEmail string
+	var clientName sql.NullString
+	var subscriptionID sql.NullString
+	var status int
+	var periodStart sql.NullTime
+	var periodEnd sql.NullTime
+	var created time.Time
+	var dataStr string
+	var sessionsUsed int
+	err := rowFn(&idStr, &providerIDStr, &membershipPlanIDStr, &clientIDStr, &clientEmail, &clientName, &subscriptionID, &status, &periodStart, &periodEnd, &created, &dataStr, &sessionsUsed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "scan client membership")
+	}
+
+	//parse the uuids
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse uuid client membership id")
+	}
+	providerID, err := uuid.FromString(providerIDStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse uuid provider id")
+	}
+	membershipPlanID, err := uuid.FromString(membershipPlanIDStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse uuid membership plan id")
+	}
+	clientID, err := uuid.FromString(clientIDStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse uuid client id")
+	}
+
+	//unmarshal the data
+	var membership ClientMembership
+	err = json.Unmarshal([]byte(dataStr), &membership)
+	if err != nil {
+		return nil, errors.Wrap(err, "unjson client membership")
+	}
+	membership.ID = &id
+	membership.ProviderID = &providerID
+	membership.MembershipPlanID = &membershipPlanID
+	membership.ClientID = &clientID
+	membership.ClientEmail = clientEmail
+	membership.ClientName = clientName.String
+	membership.Status = MembershipStatus(status)
+	membership.SessionsUsed = sessionsUsed
+	membership.Created = created
+	if subscriptionID.Valid {
+		membership.StripeSubscriptionID = &subscriptionID.String
+	}
+	if periodStart.Valid {
+		membership.PeriodStart = &periodStart.Time
+	}
+	if periodEnd.Valid {
+		membership.PeriodEnd = &periodEnd.Time
+	}
+	return &membership, nil
+}
+
+//load a client membership
+func loadClientMembership(ctx context.Context, db *DB, whereStmt string, args ...interface{}) (context.Context, *ClientMembership, error) {
+	stmt := clientMembershipQueryCreate(whereStmt)
+	ctx, row, err := db.QueryRow(ctx, stmt, args...)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "query row client membership")
+	}
+	membership, err := clientMembershipQueryParse(row.Scan)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "client membership parse")
+	}
+	return ctx, membership, nil
+}
+
+//LoadClientMembershipByID : load a client membership by id
+func LoadClientMembershipByID(ctx context.Context, db *DB, id *uuid.UUID) (context.Context, *ClientMembership, error) {
+	whereStmt := "cm.id=UUID_TO_BIN(?)"
+	return loadClientMembership(ctx, db, whereStmt, id)
+}
+
+//LoadClientMembershipByProviderIDAndID : load a client membership by provider id and id
+func LoadClientMembershipByProviderIDAndID(ctx context.Context, db *DB, providerID *uuid.UUID, id *uuid.UUID) (context.Context, *ClientMembership, error) {
+	whereStmt := "cm.provider_id=UUID_TO_BIN(?) AND cm.id=UUID_TO_BIN(?)"
+	return loadClientMembership(ctx, db, whereStmt, providerID, id)
+}
+
+//LoadClientMembershipBySubscriptionID : load a client membership by the Stripe subscription id
+func LoadClientMembershipBySubscriptionID(ctx context.Context, db *DB, subscriptionID string) (context.Context, *ClientMembership, error) {
+	whereStmt := "cm.stripe_subscription_id=?"
+	return loadClientMembership(ctx, db, whereStmt, subscriptionID)
+}
+
+//SaveClientMembership : save a new client membership waiting on the client to subscribe
+func SaveClientMembership(ctx context.Context, db *DB, membership *ClientMembership) (context.Context, error) {
+	//generate an id if necessary
+	if membership.ID == nil {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return ctx, errors.Wrap(err, "new uuid client membership")
+		}
+		membership.ID = &id
+	}
+	membership.Status = MembershipStatusPending
+
+	//json encode the data
+	dataJSON, err := json.Marshal(membership)
+	if err != nil {
+		return ctx, errors.Wrap(err, "json client membership")
+	}
+
+	//save to the db
+	stmt := fmt.Sprintf("INSERT INTO %s(id,provider_id,membership_plan_id,client_id,status,data) VALUES (UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),?,?)", dbTableClientMembership)
+	ctx, result, err := db.Exec(ctx, stmt, membership.ID, membership.ProviderID, membership.MembershipPlanID, membership.ClientID, membership.Status, dataJSON)
+	if err != nil {
+		return ctx, errors.Wrap(err, "insert client membership")
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return ctx, errors.Wrap(err, "insert client membership rows affected")
+	}
+	if count == 0 {
+		return ctx, fmt.Errorf("unable to insert client membership: %s", membership.ClientID)
+	}
+	return ctx, nil
+}
+
+//UpdateClientMembershipSubscription : link the Stripe subscription created by the checkout to the client membership
+func UpdateClientMembershipSubscription(ctx context.Context, db *DB, id *uuid.UUID, subscriptionID string) (context.Context, error) {
+	stmt := fmt.Sprintf("UPDATE %s SET stripe_subscription_id=? WHERE id=UUID_TO_BIN(?)", dbTableClientMembership)
+	ctx, _, err := db.Exec(ctx, stmt, subscriptionID, id)
+	if err != nil {
+		return ctx, errors.Wrap(err, "update client membership subscription")
+	}
+	return ctx, nil
+}
+
+//UpdateClientMembershipStatusBySubscriptionID : update the status of the client membership for a Stripe subscription, which is final once cancelled
+func UpdateClientMembershipStatusBySubscriptionID(ctx context.Context, db *DB, subscriptionID string, status MembershipStatus) (context.Context, error) {
+	stmt := fmt.Sprintf("UPDATE %s SET status=? WHERE stripe_subscription_id=? AND status<>?", dbTableClientMembership)
+	ctx, _, err := db.Exec(ctx, stmt, status, subscriptionID, MembershipStatusCancelled)
+	if err != nil {
+		return ctx, errors.Wrap(err, "update client membership status")
+	}
+	return ctx, nil
+}
+
+//SaveClientMembershipPeriod : activate the client membership for a paid period, recording the payment if any
+func SaveClientMembershipPeriod(ctx context.Context, db *DB, membership *ClientMembership, subscriptionID string, start time.Time, end time.Time, payment *Payment, stripeID string, stripeAccountID string, stripeData string) (context.Context, error) {
+	ctx, err := db.ProcessTx(ctx, "save client membership period", func(ctx context.Context, db *DB) (context.Context, error) {
+		//update the membership, which is no longer cancellable once cancelled
+		stmt := fmt.Sprintf("UPDATE %s SET stripe_subscription_id=?,status=?,period_start=?,period_end=? WHERE id=UUID_TO_BIN(?) AND status<>?", dbTableClientMembership)
+		ctx, _, err := db.Exec(ctx, stmt, subscriptionID, MembershipStatusActive, start.UTC(), end.UTC(), membership.ID, MembershipStatusCancelled)
+		if err != nil {
+			return ctx, errors.Wrap(err, "update client membership period")
+		}
+
+		//record the payment
+		if payment == nil {
+			return ctx, nil
+		}
+		ctx, err = SavePayment(ctx, db, payment, false, false)
+		if err != nil {
+			return ctx, errors.Wrap(err, "save payment")
+		}
+		ctx, err = UpdatePaymentStripeID(ctx, db, payment.ID, &stripeID, nil, &stripeAccountID, &stripeData)
+		if err != nil {
+			return ctx, errors.Wrap(err, "update payment stripe id")
+		}
+		return ctx, nil
+	})
+	if err != nil {
+		return ctx, errors.Wrap(err, "save client membership period")
+	}
+	return ctx, nil
+}
+
+//lock the active memberships of a client that cover the service for the period including the time
+func lockClientMembershipsBooking(ctx context.Context, db *DB, clientID *uuid.UUID, svcID *uuid.UUID, at time.Time) (context.Context, []*uuid.UUID, error) {
+	ctx, logger := GetLogger(ctx)
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(cm.id) FROM %s cm WHERE cm.deleted=0 AND cm.status=? AND cm.client_id=UUID_TO_BIN(?) AND cm.period_start<=? AND cm.period_end>? AND JSON_CONTAINS(cm.data,JSON_QUOTE(?),'$.ServiceIDs') ORDER BY cm.created FOR UPDATE", dbTableClientMembership)
+	ctx, rows, err := db.Query(ctx, stmt, MembershipStatusActive, clientID, at, at, svcID.String())
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "select client memberships lock")
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Warnw("rows close", "error", err)
+		}
+	}()
+
+	//read the rows
+	ids := make([]*uuid.UUID, 0, 1)
+	var idStr string
+	for rows.Next() {
+		err := rows.Scan(&idStr)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "rows scan client memberships lock")
+		}
+		id, err := uuid.FromString(idStr)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "parse uuid client membership id")
+		}
+		ids = append(ids, &id)
+	}
+	return ctx, ids, nil
+}
+
+//ApplyBookingMembership : use a session from an active membership of the client for a new booking in the current period
+func ApplyBookingMembership(ctx context.Context, db *DB, book *Booking) (context.Context, error) {
+	book.ClientMembershipID = nil
+	if book.Client == nil || book.Client.ID == nil || book.Service == nil || book.Service.ID == nil {
+		return ctx, nil
+	}
+	ctx, err := db.ProcessTx(ctx, "apply booking membership", func(ctx context.Context, db *DB) (context.Context, error) {
+		//lock the memberships that can be used for the booking
+		ctx, ids, err := lockClientMembershipsBooking(ctx, db, book.Client.ID, book.Service.ID, book.TimeFrom)
+		if err != nil {
+			return ctx, errors.Wrap(err, "lock client memberships")
+		}
+
+		//use the first membership with a session remaining
+		for _, id := range ids {
+			ctx, membership, err := LoadClientMembershipByID(ctx, db, id)
+			if err != nil {
+				return ctx, errors.Wrap(err, "load client membership")
+			}
+			if membership == nil || (!membership.IsUnlimited() && membership.ComputeSessionsRemaining() == 0) {
+				continue
+			}
+			useID, err := uuid.NewV4()
+			if err != nil {
+				return ctx, errors.Wrap(err, "new uuid client membership use")
+			}
+			stmt := fmt.Sprintf("INSERT INTO %s(id,client_membership_id,booking_id) VALUES (UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?)) ON DUPLICATE KEY UPDATE client_membership_id=VALUES(client_membership_id),deleted=0", dbTableClientMembershipUse)
+			ctx, _, err = db.Exec(ctx, stmt, &useID, membership.ID, book.ID)
+			if err != nil {
+				return ctx, errors.Wrap(err, "insert client membership use")
+			}
+
+			//the booking is covered by the membership, so no payment is required to book
+			book.ClientMembershipID = membership.ID
+			book.PaymentExpiration = nil
+			return ctx, nil
+		}
+		return ctx, nil
+	})
+	if err != nil {
+		return ctx, errors.Wrap(err, "apply booking membership")
+	}
+	return ctx, nil
+}
+
+//list client memberships
+func listClientMemberships(ctx context.Context, db *DB, whereStmt string, args ...interface{}) (context.Context, []*ClientMembership, error) {
+	ctx, logger := GetLogger(ctx)
+	stmt := clientMembershipQueryCreate(whereStmt)
+	ctx, rows, err := db.Query(ctx, stmt, args...)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "select client memberships")
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Warnw("rows close", "error", err)
+		}
+	}()
+
+	//read the client memberships
+	memberships := make([]*ClientMembership, 0, 2)
+	for rows.Next() {
+		membership, err := clientMembershipQueryParse(rows.Scan)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "client membership parse")
+		}
+		memberships = append(memberships, membership)
+	}
+	return ctx, memberships, nil
+}
+
+//ListClientMembershipsByClientID : list the memberships of a client, excluding those never subscribed
+func ListClientMembershipsByClientID(ctx context.Context, db *DB, providerID *uuid.UUID, clientID *uuid.UUID) (context.Context, []*ClientMembership, error) {
+	whereStmt := "cm.provider_id=UUID_TO_BIN(?) AND cm.client_id=UUID_TO_BIN(?) AND cm.stripe_subscription_id IS NOT NULL"
+	return listClientMemberships(ctx, db, whereStmt, providerID, clientID)
+}
+
+//ListClientMembershipsByMembershipPlanID : list the memberships for a plan, excluding those never subscribed
+func ListClientMembershipsByMembershipPlanID(ctx context.Context, db *DB, providerID *uuid.UUID, membershipPlanID *uuid.UUID) (context.Context, []*ClientMembership, error) {
+	whereStmt := "cm.provider_id=UUID_TO_BIN(?) AND cm.membership_plan_id=UUID_TO_BIN(?) AND cm.stripe_subscription_id IS NOT NULL"
+	return listClientMemberships(ctx, db, whereStmt, providerID, membershipPlanID)
+}
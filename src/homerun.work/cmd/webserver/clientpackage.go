@@ -0,0 +1,410 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+//client package db tables
+const (
+	dbTableClientPackage    = "client_package"
+	dbTableClientPackageUse = "client_package_use"
+)
+
+//ClientPackage : definition of a session package purchased by a client
+type ClientPackage struct {
+	ID               *uuid.UUID   `json:"-"`
+	ProviderID       *uuid.UUID   `json:"-"`
+	SessionPackageID *uuid.UUID   `json:"-"`
+	ClientID         *uuid.UUID   `json:"-"`
+	PaymentID        *uuid.UUID   `json:"-"`
+	Sessions         int          `json:"-"`
+	SessionsUsed     int          `json:"-"`
+	Expiration       *time.Time   `json:"-"`
+	Activated        *time.Time   `json:"-"`
+	Created          time.Time    `json:"-"`
+	Name             string       `json:"Name"`
+	ServiceIDs       []*uuid.UUID `json:"ServiceIDs"`
+	ServiceNames     []string     `json:"ServiceNames"`
+}
+
+//IsActive : check if the package has been paid for
+func (c *ClientPackage) IsActive() bool {
+	return c.Activated != nil
+}
+
+//IsExpired : check if the package has expired
+func (c *ClientPackage) IsExpired(now time.Time) bool {
+	return c.Expiration != nil && !now.Before(*c.Expiration)
+}
+
+//ComputeSessionsRemaining : compute the number of sessions remaining
+func (c *ClientPackage) ComputeSessionsRemaining() int {
+	return Max(c.Sessions-c.SessionsUsed, 0)
+}
+
+//FormatSessions : format the sessions remaining
+func (c *ClientPackage) FormatSessions() string {
+	return fmt.Sprintf("%d of %d", c.ComputeSessionsRemaining(), c.Sessions)
+}
+
+//FormatServices : format the services covered by the package
+func (c *ClientPackage) FormatServices() string {
+	return (&SessionPackage{ServiceNames: c.ServiceNames}).FormatServices()
+}
+
+//FormatExpiration : format the expiration
+func (c *ClientPackage) FormatExpiration(timeZone string) string {
+	if c.Expiration == nil {
+		return "Never"
+	}
+	return FormatDateLocal(*c.Expiration, timeZone)
+}
+
+//FormatCreated : format the date the package was purchased
+func (c *ClientPackage) FormatCreated(timeZone string) string {
+	return FormatDateLocal(c.Created, timeZone)
+}
+
+//FormatStatus : format the status of the package
+func (c *ClientPackage) FormatStatus(now time.Time) string {
+	if !c.IsActive() {
+		return "Unpaid"
+	}
+	if c.ComputeSessionsRemaining() == 0 {
+		return "Used"
+	}
+	if c.IsExpired(now) {
+		return "Expired"
+	}
+	return "Active"
+}
+
+//ClientPackageUse : definition of a session credit used for a booking
+type ClientPackageUse struct {
+	ID              *uuid.UUID
+	ClientPackageID *uuid.UUID
+	BookingID       *uuid.UUID
+	ServiceName     string
+	TimeFrom        time.Time
+	Created         time.Time
+	Cancelled       bool
+}
+
+//FormatTimeFrom : format the time of the booking
+func (c *ClientPackageUse) FormatTimeFrom(timeZone string) string {
+	return FormatDateTimeLocal(c.TimeFrom, timeZone)
+}
+
+//create the statement to load a client package, counting the credits used by bookings that have not been cancelled
+func clientPackageQueryCreate(whereStmt string) string {
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(cp.id),BIN_TO_UUID(cp.provider_id),BIN_TO_UUID(cp.session_package_id),BIN_TO_UUID(cp.client_id),BIN_TO_UUID(cp.payment_id),cp.sessions,cp.expiration,cp.created,cp.data,p.captured,(SELECT COUNT(*) FROM %s u LEFT JOIN %s b ON b.id=u.booking_id WHERE u.deleted=0 AND u.client_package_id=cp.id AND (b.id IS NULL OR b.deleted=0)) FROM %s cp INNER JOIN %s p ON p.id=cp.payment_id WHERE cp.deleted=0 AND p.deleted=0 AND %s ORDER BY cp.created DESC", dbTableClientPackageUse, dbTableBooking, dbTableClientPackage, dbTablePayment, whereStmt)
+	return stmt
+}
+
+//parse a client package
+func clientPackageQueryParse(rowFn ScanFn) (*ClientPackage, error) {
+	//read the row
+	var idStr string
+	var providerIDStr string
+	var sessionPackageIDStr string
+	var clientIDStr string
+	var paymentIDStr string
+	var sessions int
+	var expiration sql.NullTime
+	var created time.Time
+	var dataStr string
+	var captured sql.NullTime
+	var sessionsUsed int
+	err := rowFn(&idStr, &providerIDStr, &sessionPackageIDStr, &clientIDStr, &paymentIDStr, &sessions, &expiration, &created, &dataStr, &captured, &sessionsUsed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "scan client package")
+	}
+
+	//parse the uuids
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse uuid client package id")
+	}
+	providerID, err := uuid.FromString(providerIDStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse uuid provider id")
+	}
+	sessionPackageID, err := uuid.FromString(sessionPackageIDStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse uuid session package id")
+	}
+	clientID, err := uuid.FromString(clientIDStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse uuid client id")
+	}
+	paymentID, err := uuid.FromString(paymentIDStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse uuid payment id")
+	}
+
+	//unmarshal the data
+	var pkg ClientPackage
+	err = json.Unmarshal([]byte(dataStr), &pkg)
+	if err != nil {
+		return nil, errors.Wrap(err, "unjson client package")
+	}
+	pkg.ID = &id
+	pkg.ProviderID = &providerID
+	pkg.SessionPackageID = &sessionPackageID
+	pkg.ClientID = &clientID
+	pkg.PaymentID = &paymentID
+	pkg.Sessions = sessions
+	pkg.SessionsUsed = sessionsUsed
+	pkg.Created = created
+	if expiration.Valid {
+		pkg.Expiration = &expiration.Time
+	}
+	if captured.Valid {
+		pkg.Activated = &captured.Time
+	}
+	return &pkg, nil
+}
+
+//loadClientPackage : load a client package
+func loadClientPackage(ctx context.Context, db *DB, whereStmt string, args ...interface{}) (context.Context, *ClientPackage, error) {
+	stmt := clientPackageQueryCreate(whereStmt)
+	ctx, row, err := db.QueryRow(ctx, stmt, args...)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "query row client package")
+	}
+	pkg, err := clientPackageQueryParse(row.Scan)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "client package parse")
+	}
+	return ctx, pkg, nil
+}
+
+//LoadClientPackageByPaymentID : load the client package purchased with a payment
+func LoadClientPackageByPaymentID(ctx context.Context, db *DB, paymentID *uuid.UUID) (context.Context, *ClientPackage, error) {
+	whereStmt := "cp.payment_id=UUID_TO_BIN(?)"
+	return loadClientPackage(ctx, db, whereStmt, paymentID)
+}
+
+//SavePaymentClientPackage : save the payment for the purchase of a session package, issuing the package to the client
+func SavePaymentClientPackage(ctx context.Context, db *DB, payment *Payment, sessionPkg *SessionPackage, now time.Time) (context.Context, *ClientPackage, error) {
+	var pkg *ClientPackage
+	ctx, err := db.ProcessTx(ctx, "save payment client package", func(ctx context.Context, db *DB) (context.Context, error) {
+		//save the payment
+		ctx, err := SavePayment(ctx, db, payment, payment.DirectCapture, false)
+		if err != nil {
+			return ctx, errors.Wrap(err, "save payment")
+		}
+
+		//generate an id
+		id, err := uuid.NewV4()
+		if err != nil {
+			return ctx, errors.Wrap(err, "new uuid client package")
+		}
+		pkg = &ClientPackage{
+			ID:               &id,
+			ProviderID:       payment.ProviderID,
+			SessionPackageID: sessionPkg.ID,
+			ClientID:         payment.SecondaryID,
+			PaymentID:        payment.ID,
+			Sessions:         sessionPkg.Sessions,
+			Expiration:       sessionPkg.ComputeExpiration(now),
+			Created:          now,
+			Name:             sessionPkg.Name,
+			ServiceIDs:       sessionPkg.ServiceIDs,
+			ServiceNames:     sessionPkg.ServiceNames,
+		}
+
+		//json encode the data
+		dataJSON, err := json.Marshal(pkg)
+		if err != nil {
+			return ctx, errors.Wrap(err, "json client package")
+		}
+
+		//save the client package
+		var expiration *time.Time
+		if pkg.Expiration != nil {
+			utc := pkg.Expiration.UTC()
+			expiration = &utc
+		}
+		stmt := fmt.Sprintf("INSERT INTO %s(id,provider_id,session_package_id,client_id,payment_id,sessions,expiration,data) VALUES (UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),?,?,?)", dbTableClientPackage)
+		ctx, _, err = db.Exec(ctx, stmt, pkg.ID, pkg.ProviderID, pkg.SessionPackageID, pkg.ClientID, pkg.PaymentID, pkg.Sessions, expiration, dataJSON)
+		if err != nil {
+			return ctx, errors.Wrap(err, "insert client package")
+		}
+		return ctx, nil
+	})
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "save payment client package")
+	}
+	return ctx, pkg, nil
+}
+
+//lock the paid packages of a client that cover the service and are valid at the time, ordered by the package expiring first
+func lockClientPackagesBooking(ctx context.Context, db *DB, clientID *uuid.UUID, svcID *uuid.UUID, at time.Time) (context.Context, []*uuid.UUID, error) {
+	ctx, logger := GetLogger(ctx)
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(cp.id) FROM %s cp INNER JOIN %s p ON p.id=cp.payment_id WHERE cp.deleted=0 AND p.deleted=0 AND p.captured IS NOT NULL AND cp.client_id=UUID_TO_BIN(?) AND (cp.expiration IS NULL OR cp.expiration>?) AND JSON_CONTAINS(cp.data,JSON_QUOTE(?),'$.ServiceIDs') ORDER BY cp.expiration IS NULL,cp.expiration,cp.created FOR UPDATE", dbTableClientPackage, dbTablePayment)
+	ctx, rows, err := db.Query(ctx, stmt, clientID, at, svcID.String())
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "select client packages lock")
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Warnw("rows close", "error", err)
+		}
+	}()
+
+	//read the rows
+	ids := make([]*uuid.UUID, 0, 1)
+	var idStr string
+	for rows.Next() {
+		err := rows.Scan(&idStr)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "rows scan client packages lock")
+		}
+		id, err := uuid.FromString(idStr)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "parse uuid client package id")
+		}
+		ids = append(ids, &id)
+	}
+	return ctx, ids, nil
+}
+
+//ApplyBookingPackage : use a session credit from a paid package of the client for a new booking, where the package expiring first is used
+func ApplyBookingPackage(ctx context.Context, db *DB, book *Booking) (context.Context, error) {
+	book.ClientPackageID = nil
+	if book.Client == nil || book.Client.ID == nil || book.Service == nil || book.Service.ID == nil {
+		return ctx, nil
+	}
+	ctx, err := db.ProcessTx(ctx, "apply booking package", func(ctx context.Context, db *DB) (context.Context, error) {
+		//lock the packages that can be used for the booking
+		ctx, ids, err := lockClientPackagesBooking(ctx, db, book.Client.ID, book.Service.ID, book.TimeFrom)
+		if err != nil {
+			return ctx, errors.Wrap(err, "lock client packages")
+		}
+
+		//use the first package with a remaining credit
+		for _, id := range ids {
+			ctx, pkg, err := loadClientPackage(ctx, db, "cp.id=UUID_TO_BIN(?)", id)
+			if err != nil {
+				return ctx, errors.Wrap(err, "load client package")
+			}
+			if pkg == nil || pkg.ComputeSessionsRemaining() == 0 {
+				continue
+			}
+			useID, err := uuid.NewV4()
+			if err != nil {
+				return ctx, errors.Wrap(err, "new uuid client package use")
+			}
+			stmt := fmt.Sprintf("INSERT INTO %s(id,client_package_id,booking_id) VALUES (UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?)) ON DUPLICATE KEY UPDATE client_package_id=VALUES(client_package_id),deleted=0", dbTableClientPackageUse)
+			ctx, _, err = db.Exec(ctx, stmt, &useID, pkg.ID, book.ID)
+			if err != nil {
+				return ctx, errors.Wrap(err, "insert client package use")
+			}
+
+			//the booking is prepaid, so no payment is required to book
+			book.ClientPackageID = pkg.ID
+			book.PaymentExpiration = nil
+			return ctx, nil
+		}
+		return ctx, nil
+	})
+	if err != nil {
+		return ctx, errors.Wrap(err, "apply booking package")
+	}
+	return ctx, nil
+}
+
+//ListClientPackagesByClientID : list the packages purchased by a client
+func ListClientPackagesByClientID(ctx context.Context, db *DB, providerID *uuid.UUID, clientID *uuid.UUID) (context.Context, []*ClientPackage, error) {
+	ctx, logger := GetLogger(ctx)
+	stmt := clientPackageQueryCreate("cp.provider_id=UUID_TO_BIN(?) AND cp.client_id=UUID_TO_BIN(?)")
+	ctx, rows, err := db.Query(ctx, stmt, providerID, clientID)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "select client packages")
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Warnw("rows close", "error", err)
+		}
+	}()
+
+	//read the client packages
+	pkgs := make([]*ClientPackage, 0, 2)
+	for rows.Next() {
+		pkg, err := clientPackageQueryParse(rows.Scan)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "client package parse")
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return ctx, pkgs, nil
+}
+
+//ListClientPackageUsesByClientID : list the session credits used by a client across all packages
+func ListClientPackageUsesByClientID(ctx context.Context, db *DB, providerID *uuid.UUID, clientID *uuid.UUID) (context.Context, []*ClientPackageUse, error) {
+	ctx, logger := GetLogger(ctx)
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(u.id),BIN_TO_UUID(u.client_package_id),BIN_TO_UUID(u.booking_id),u.created,b.time_start,b.data->>'$.ServiceName',b.deleted FROM %s u INNER JOIN %s cp ON cp.id=u.client_package_id INNER JOIN %s b ON b.id=u.booking_id WHERE u.deleted=0 AND cp.deleted=0 AND cp.provider_id=UUID_TO_BIN(?) AND cp.client_id=UUID_TO_BIN(?) ORDER BY b.time_start DESC", dbTableClientPackageUse, dbTableClientPackage, dbTableBooking)
+	ctx, rows, err := db.Query(ctx, stmt, providerID, clientID)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "select client package uses")
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Warnw("rows close", "error", err)
+		}
+	}()
+
+	//read the rows
+	uses := make([]*ClientPackageUse, 0, 2)
+	var idStr string
+	var pkgIDStr string
+	var bookIDStr string
+	var created time.Time
+	var timeFrom time.Time
+	var svcName sql.NullString
+	var deletedBit string
+	for rows.Next() {
+		err := rows.Scan(&idStr, &pkgIDStr, &bookIDStr, &created, &timeFrom, &svcName, &deletedBit)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "rows scan client package uses")
+		}
+
+		//parse the uuids
+		id, err := uuid.FromString(idStr)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "parse uuid")
+		}
+		pkgID, err := uuid.FromString(pkgIDStr)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "parse uuid client package id")
+		}
+		bookID, err := uuid.FromString(bookIDStr)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "parse uuid booking id")
+		}
+		uses = append(uses, &ClientPackageUse{
+			ID:              &id,
+			ClientPackageID: &pkgID,
+			BookingID:       &bookID,
+			ServiceName:     svcName.String,
+			TimeFrom:        timeFrom,
+			Created:         created,
+			Cancelled:       deletedBit == "\x01",
+		})
+	}
+	return ctx, uses, nil
+}
@@ -24,13 +24,17 @@ const (
 	cfgKeyBatchSizeProcessGoogleEvents      = "BATCH_SIZE_PROCESS_GOOGLE_EVENTS"
 	cfgKeyBatchSizeProcessImgs              = "BATCH_SIZE_PROCESS_IMGS"
 	cfgKeyBatchSizeProcessNotifications     = "BATCH_SIZE_PROCESS_NOTIFICATIONS"
+	cfgKeyBatchSizeProcessPaymentReminders  = "BATCH_SIZE_PROCESS_PAYMENT_REMINDERS"
 	cfgKeyBatchSizeProcessRecurringBookings = "BATCH_SIZE_PROCESS_RECURRING_BOOKINGS"
 	cfgKeyBatchSizeProcessZoomMeetings      = "BATCH_SIZE_PROCESS_ZOOM_MEETINGS"
 	cfgKeyBitlyAccessToken                  = "BITLY_ACCESS_TOKEN"
+	cfgKeyCronProcessBookingHolds           = "CRON_PROCESS_BOOKING_HOLDS"
+	cfgKeyCronProcessBookingsUnpaid         = "CRON_PROCESS_BOOKINGS_UNPAID"
 	cfgKeyCronProcessGoogle                 = "CRON_PROCESS_GOOGLE"
 	cfgKeyCronProcessImgs                   = "CRON_PROCESS_IMGS"
 	cfgKeyCronProcessMsgs                   = "CRON_PROCESS_MSGS"
 	cfgKeyCronProcessNotifications          = "CRON_PROCESS_NOTIFICATIONS"
+	cfgKeyCronProcessPaymentReminders       = "CRON_PROCESS_PAYMENT_REMINDERS"
 	cfgKeyCronProcessRecurringBookings      = "CRON_PROCESS_RECURRING_BOOKINGS"
 	cfgKeyCronProcessZoom                   = "CRON_PROCESS_ZOOM"
 	cfgKeyDBAddress                         = "DB_ADDRESS"
@@ -68,6 +72,7 @@ const (
 	cfgKeyLogFileEnable                     = "LOG_FILE_ENABLE"
 	cfgKeyLogLevel                          = "LOG_LEVEL"
 	cfgKeyNotificationBookingReminderMin    = "NOTIFICATION_BOOKING_REMINDER_MIN"
+	cfgKeyNotificationPaymentReminderMin    = "NOTIFICATION_PAYMENT_REMINDER_MIN"
 	cfgKeyNotificationEmails                = "NOTIFICATION_EMAILS"
 	cfgKeyPageSizeProviders                 = "PAGE_SIZE_PROVIDERS"
 	cfgKeyPanicHandlerDisable               = "PANIC_HANDLER_DISABLE"
@@ -108,6 +113,7 @@ const (
 	cfgKeyURLTwitter                        = "URL_TWITTER"
 	cfgKeyURLUploads                        = "URL_UPLOADS"
 	cfgKeyURLYouTube                        = "URL_YOUTUBE"
+	cfgKeyWaitlistOfferExpirationMin        = "WAITLIST_OFFER_EXPIRATION_MIN"
 	cfgKeyZoomClientID                      = "ZOOM_CLIENT_ID"
 	cfgKeyZoomClientSecret                  = "ZOOM_CLIENT_SECRET"
 	cfgKeyZoomVerificationToken             = "ZOOM_VERIFICATION_TOKEN"
@@ -127,13 +133,17 @@ func init() {
 	viper.SetDefault(cfgKeyBatchSizeProcessGoogleEvents, 10)
 	viper.SetDefault(cfgKeyBatchSizeProcessImgs, 10)
 	viper.SetDefault(cfgKeyBatchSizeProcessNotifications, 10)
+	viper.SetDefault(cfgKeyBatchSizeProcessPaymentReminders, 10)
 	viper.SetDefault(cfgKeyBatchSizeProcessRecurringBookings, 10)
 	viper.SetDefault(cfgKeyBatchSizeProcessZoomMeetings, 10)
 	viper.SetDefault(cfgKeyBitlyAccessToken, "18b94bbe6eb8c4d10dcf6ff28e71e6f15ef707b7")
+	viper.SetDefault(cfgKeyCronProcessBookingHolds, "*/15 * * * *")   //every 15 minutes
+	viper.SetDefault(cfgKeyCronProcessBookingsUnpaid, "*/5 * * * *")  //every 5 minutes
 	viper.SetDefault(cfgKeyCronProcessGoogle, "*/1 * * * *")          //once a minute
 	viper.SetDefault(cfgKeyCronProcessImgs, "*/1 * * * *")            //once a minute
 	viper.SetDefault(cfgKeyCronProcessMsgs, "*/1 * * * *")            //once a minute
 	viper.SetDefault(cfgKeyCronProcessNotifications, "0,30 * * * *")  //every 30 minute
+	viper.SetDefault(cfgKeyCronProcessPaymentReminders, "15 * * * *") //once an hour
 	viper.SetDefault(cfgKeyCronProcessRecurringBookings, "0 0 * * *") //once a day at beginning of the day
 	viper.SetDefault(cfgKeyCronProcessZoom, "*/1 * * * *")            //once a minute
 	viper.SetDefault(cfgKeyDBAddress, "172.31.28.102")
@@ -168,7 +178,8 @@ func init() {
 	viper.SetDefault(cfgKeyLogDevEnable, false)
 	viper.SetDefault(cfgKeyLogFileEnable, false)
 	viper.SetDefault(cfgKeyLogLevel, "info")
-	viper.SetDefault(cfgKeyNotificationBookingReminderMin, 60) //1 hour
+	viper.SetDefault(cfgKeyNotificationBookingReminderMin, 60)   //1 hour
+	viper.SetDefault(cfgKeyNotificationPaymentReminderMin, 1440) //1 day
 	viper.SetDefault(cfgKeyNotificationEmails, "dev@homerun.work")
 	viper.SetDefault(cfgKeyPageSizeProviders, 10)
 	viper.SetDefault(cfgKeyPanicHandlerDisable, false)
@@ -208,6 +219,7 @@ func init() {
 	viper.SetDefault(cfgKeyURLTwitter, "https://twitter.com/homerunworkpro")
 	viper.SetDefault(cfgKeyURLUploads, "/asset")
 	viper.SetDefault(cfgKeyURLYouTube, "")
+	viper.SetDefault(cfgKeyWaitlistOfferExpirationMin, 120) //2 hours
 	viper.SetDefault(cfgKeyZoomClientID, "aND6YdfgTVeCjAu_1MqWcQ")
 	viper.SetDefault(cfgKeyZoomClientSecret, "TDPi9P5zGz5vW12ZwHfHv6b6mpY5S3BQ")
 	viper.SetDefault(cfgKeyZoomVerificationToken, "")
@@ -278,6 +290,11 @@ func GetBatchSizeProcessNotifications() int {
 	return viper.GetInt(cfgKeyBatchSizeProcessNotifications)
 }
 
+//GetBatchSizeProcessPaymentReminders : batch size for processing payment reminders
+func GetBatchSizeProcessPaymentReminders() int {
+	return viper.GetInt(cfgKeyBatchSizeProcessPaymentReminders)
+}
+
 //GetBatchSizeProcessRecurringBookings : batch size for processing recurring bookings
 func GetBatchSizeProcessRecurringBookings() int {
 	return viper.GetInt(cfgKeyBatchSizeProcessRecurringBookings)
@@ -293,6 +310,16 @@ func GetBitlyAccessToken() string {
 	return viper.GetString(cfgKeyBitlyAccessToken)
 }
 
+//GetCronProcessBookingHolds : cron schedule for releasing expired booking holds
+func GetCronProcessBookingHolds() string {
+	return viper.GetString(cfgKeyCronProcessBookingHolds)
+}
+
+//GetCronProcessBookingsUnpaid : cron schedule for cancelling bookings not paid in time
+func GetCronProcessBookingsUnpaid() string {
+	return viper.GetString(cfgKeyCronProcessBookingsUnpaid)
+}
+
 //GetCronProcessGoogle : cron schedule for processing Google calendars and events
 func GetCronProcessGoogle() string {
 	return viper.GetString(cfgKeyCronProcessGoogle)
@@ -313,6 +340,11 @@ func GetCronProcessNotifications() string {
 	return viper.GetString(cfgKeyCronProcessNotifications)
 }
 
+//GetCronProcessPaymentReminders : cron schedule for processing payment reminders
+func GetCronProcessPaymentReminders() string {
+	return viper.GetString(cfgKeyCronProcessPaymentReminders)
+}
+
 //GetCronProcessRecurringBookings : cron schedule for processing recurring bookings
 func GetCronProcessRecurringBookings() string {
 	return viper.GetString(cfgKeyCronProcessRecurringBookings)
@@ -498,6 +530,11 @@ func GetNotificationBookingReminderMin() int {
 	return viper.GetInt(cfgKeyNotificationBookingReminderMin)
 }
 
+//GetNotificationPaymentReminderMin : minimum minutes between payment reminders for the same payment
+func GetNotificationPaymentReminderMin() int {
+	return viper.GetInt(cfgKeyNotificationPaymentReminderMin)
+}
+
 //GetNotificationEmails : notification emails
 func GetNotificationEmails() string {
 	return viper.GetString(cfgKeyNotificationEmails)
@@ -713,6 +750,11 @@ func GetURLYouTube() string {
 	return viper.GetString(cfgKeyURLYouTube)
 }
 
+//GetWaitlistOfferExpirationMin : minutes a waitlist client has to claim an opening
+func GetWaitlistOfferExpirationMin() int {
+	return viper.GetInt(cfgKeyWaitlistOfferExpirationMin)
+}
+
 //GetZoomClientID : Zoom client id
 func GetZoomClientID() string {
 	return viper.GetString(cfgKeyZoomClientID)
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/gofrs/uuid"
 	"github.com/pkg/errors"
 )
 
@@ -11,6 +12,7 @@ import (
 const (
 	CookieExpirationAlert = 1
 	CookieExpirationSec   = 300
+	CookieBookingHold     = "bookingHold"
 	CookieErr             = "err"
 	CookieFlag            = "flag"
 	CookieHost            = "host"
@@ -33,6 +35,40 @@ func createBaseCookie() *http.Cookie {
 	}
 }
 
+//SetCookieBookingHold : store the id of the hold on a booking time in a cookie
+func (s *Server) SetCookieBookingHold(w http.ResponseWriter, id *uuid.UUID) {
+	cookie := createBaseCookie()
+	cookie.Name = CookieBookingHold
+	cookie.Value = id.String()
+	cookie.MaxAge = int(BookingHoldDuration.Seconds())
+	http.SetCookie(w, cookie)
+}
+
+//GetCookieBookingHold : retrieve the id of the hold on a booking time from the cookie
+func (s *Server) GetCookieBookingHold(r *http.Request) (*uuid.UUID, error) {
+	cookie, err := r.Cookie(CookieBookingHold)
+	if err != nil {
+		if err == http.ErrNoCookie {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "get cookie")
+	}
+	id, err := uuid.FromString(cookie.Value)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse uuid cookie")
+	}
+	return &id, nil
+}
+
+//DeleteCookieBookingHold : delete the booking hold cookie
+func (s *Server) DeleteCookieBookingHold(w http.ResponseWriter) {
+	cookie := &http.Cookie{
+		Name:   CookieBookingHold,
+		MaxAge: -1,
+	}
+	http.SetCookie(w, cookie)
+}
+
 //SetCookieErr : store the error in a cookie
 func (s *Server) SetCookieErr(w http.ResponseWriter, key ErrKey, args ...interface{}) {
 	cookie := createBaseCookie()
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"strconv"
 	"time"
 
 	"github.com/gofrs/uuid"
@@ -14,22 +15,23 @@ import (
 
 //coupon db tables
 const (
-	dbTableCoupon = "coupon"
+	dbTableCoupon           = "coupon"
+	dbTableCouponRedemption = "coupon_redemption"
 )
 
 //CouponType : type of coupon
 type CouponType string
 
-//coupon types
+//coupon types, where the value of a fixed amount is kept for existing coupons
 const (
 	CouponTypePercentage CouponType = "%"
-	CouponTypeUSD                   = "USD"
+	CouponTypeAmount                = "USD"
 )
 
 //CouponTypes : coupon types
 var CouponTypes []CouponType = []CouponType{
 	CouponTypePercentage,
-	CouponTypeUSD,
+	CouponTypeAmount,
 }
 
 //ParseCouponType : parse a coupon type by label
@@ -45,6 +47,14 @@ func ParseCouponType(couponTypeStr string) *CouponType {
 	return nil
 }
 
+//Label : label for the coupon type, showing the currency for a fixed amount
+func (c CouponType) Label(currency Currency) string {
+	if c == CouponTypeAmount {
+		return currency.Code()
+	}
+	return string(c)
+}
+
 //Coupon : definition of a provider coupon
 type Coupon struct {
 	ID          *uuid.UUID `json:"-"`
@@ -58,17 +68,36 @@ type Coupon struct {
 	ServiceID   *uuid.UUID `json:"ServiceID"`
 	ServiceName string     `json:"ServiceName"`
 	NewClients  bool       `json:"NewClients"`
+
+	//usage limits, where zero indicates no limit
+	UsageLimit       int     `json:"UsageLimit"`
+	UsageLimitClient int     `json:"UsageLimitClient"`
+	MinAmount        float32 `json:"MinAmount"`
+	FirstBookings    int     `json:"FirstBookings"`
+
+	//usage counts from the redemptions
+	Redemptions       int `json:"-"`
+	RedemptionClients int `json:"-"`
+}
+
+//CouponUsage : usage of a coupon used to check if the coupon can be applied to a booking
+type CouponUsage struct {
+	IsNewClient       bool
+	ClientBookings    int
+	ClientRedemptions int
+	Redemptions       int
+	Amount            float32
 }
 
 //FormatValue : format the value
-func (c *Coupon) FormatValue() string {
+func (c *Coupon) FormatValue(currency Currency) string {
 	//determine if a currency symbol is necessary
 	var valueStr string
 	switch c.Type {
 	case CouponTypePercentage:
 		valueStr = fmt.Sprintf("%s%%", FormatFloat(c.Value))
-	case CouponTypeUSD:
-		valueStr = FormatPrice(c.Value)
+	case CouponTypeAmount:
+		valueStr = currency.FormatAmount(c.Value)
 	}
 	return fmt.Sprintf("%s OFF", valueStr)
 }
@@ -86,9 +115,63 @@ func (c *Coupon) FormatTarget() string {
 	if c.NewClients {
 		return "New Clients"
 	}
+	if c.FirstBookings == 1 {
+		return "First Booking"
+	}
+	if c.FirstBookings > 1 {
+		return fmt.Sprintf("First %d Bookings", c.FirstBookings)
+	}
 	return "All Clients"
 }
 
+//FormatRedemptions : format the usage of the coupon
+func (c *Coupon) FormatRedemptions() string {
+	if c.UsageLimit > 0 {
+		return fmt.Sprintf("Used %d of %d", c.Redemptions, c.UsageLimit)
+	}
+	return fmt.Sprintf("Used %d", c.Redemptions)
+}
+
+//FormatRedemptionClients : format the number of clients that used the coupon
+func (c *Coupon) FormatRedemptionClients() string {
+	if c.RedemptionClients == 1 {
+		return "1 Client"
+	}
+	return fmt.Sprintf("%d Clients", c.RedemptionClients)
+}
+
+//FormatUsageLimit : format the total redemption limit for the form
+func (c *Coupon) FormatUsageLimit() string {
+	if c.UsageLimit == 0 {
+		return ""
+	}
+	return strconv.Itoa(c.UsageLimit)
+}
+
+//FormatUsageLimitClient : format the per-client redemption limit for the form
+func (c *Coupon) FormatUsageLimitClient() string {
+	if c.UsageLimitClient == 0 {
+		return ""
+	}
+	return strconv.Itoa(c.UsageLimitClient)
+}
+
+//FormatMinAmount : format the minimum booking amount for the form
+func (c *Coupon) FormatMinAmount() string {
+	if c.MinAmount == 0 {
+		return ""
+	}
+	return FormatFloat(c.MinAmount)
+}
+
+//FormatFirstBookings : format the number of first bookings for the form
+func (c *Coupon) FormatFirstBookings() string {
+	if c.FirstBookings == 0 {
+		return ""
+	}
+	return strconv.Itoa(c.FirstBookings)
+}
+
 //FormatStart : format the start date
 func (c *Coupon) FormatStart(timeZone string) string {
 	return FormatDateLocal(c.Start, timeZone)
@@ -109,7 +192,7 @@ func (c *Coupon) SetService(svc *Service) {
 }
 
 //AdjustPrice : adjust the price based on the coupon
-func (c *Coupon) AdjustPrice(price float32, svcID *uuid.UUID, isNewClient bool, now time.Time) float32 {
+func (c *Coupon) AdjustPrice(price float32, svcID *uuid.UUID, usage *CouponUsage, now time.Time) float32 {
 	//check if the coupon is still valid
 	if c.Start.After(now) || c.End.Before(now) {
 		return price
@@ -121,7 +204,25 @@ func (c *Coupon) AdjustPrice(price float32, svcID *uuid.UUID, isNewClient bool,
 	}
 
 	//check if the coupon applies to new clients
-	if c.NewClients && !isNewClient {
+	if c.NewClients && !usage.IsNewClient {
+		return price
+	}
+
+	//check if the coupon only applies to the first bookings of a client
+	if c.FirstBookings > 0 && usage.ClientBookings >= c.FirstBookings {
+		return price
+	}
+
+	//check the redemption limits
+	if c.UsageLimit > 0 && usage.Redemptions >= c.UsageLimit {
+		return price
+	}
+	if c.UsageLimitClient > 0 && usage.ClientRedemptions >= c.UsageLimitClient {
+		return price
+	}
+
+	//check the minimum booking amount
+	if c.MinAmount > 0 && usage.Amount < c.MinAmount {
 		return price
 	}
 
@@ -129,7 +230,7 @@ func (c *Coupon) AdjustPrice(price float32, svcID *uuid.UUID, isNewClient bool,
 	switch c.Type {
 	case CouponTypePercentage:
 		price = price * (1 - (c.Value / 100))
-	case CouponTypeUSD:
+	case CouponTypeAmount:
 		price = price - c.Value
 	}
 
@@ -295,7 +396,7 @@ func DeleteCoupon(ctx context.Context, db *DB, providerID *uuid.UUID, id *uuid.U
 //ListCouponsByProviderID : list all coupons for the provider
 func ListCouponsByProviderID(ctx context.Context, db *DB, provider *Provider) (context.Context, []*Coupon, error) {
 	ctx, logger := GetLogger(ctx)
-	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(id),code,start,end,data FROM %s WHERE deleted=0 AND provider_id=UUID_TO_BIN(?) ORDER BY created", dbTableCoupon)
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(c.id),c.code,c.start,c.end,c.data,COUNT(r.id),COUNT(DISTINCT r.client_id) FROM %s c LEFT JOIN %s r ON r.coupon_id=c.id AND r.deleted=0 WHERE c.deleted=0 AND c.provider_id=UUID_TO_BIN(?) GROUP BY c.id ORDER BY c.created", dbTableCoupon, dbTableCouponRedemption)
 	ctx, rows, err := db.Query(ctx, stmt, provider.ID)
 	if err != nil {
 		return ctx, nil, errors.Wrap(err, "select coupons")
@@ -314,8 +415,10 @@ func ListCouponsByProviderID(ctx context.Context, db *DB, provider *Provider) (c
 	var start time.Time
 	var end time.Time
 	var dataStr string
+	var redemptions int
+	var redemptionClients int
 	for rows.Next() {
-		err := rows.Scan(&idStr, &code, &start, &end, &dataStr)
+		err := rows.Scan(&idStr, &code, &start, &end, &dataStr, &redemptions, &redemptionClients)
 		if err != nil {
 			return ctx, nil, errors.Wrap(err, "rows scan coupons")
 		}
@@ -337,6 +440,8 @@ func ListCouponsByProviderID(ctx context.Context, db *DB, provider *Provider) (c
 		coupon.Code = code
 		coupon.Start = start
 		coupon.End = end
+		coupon.Redemptions = redemptions
+		coupon.RedemptionClients = redemptionClients
 		coupons = append(coupons, &coupon)
 	}
 	return ctx, coupons, nil
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+//CouponRedemption : definition of a coupon applied to a booking
+type CouponRedemption struct {
+	ID                *uuid.UUID
+	CouponID          *uuid.UUID
+	ProviderID        *uuid.UUID
+	ClientID          *uuid.UUID
+	BookingID         *uuid.UUID
+	PaymentID         *uuid.UUID
+	Amount            int //non-decimal, in the minor unit of the provider currency
+	Created           time.Time
+	ClientName        string
+	ClientEmail       string
+	PaymentFriendlyID string
+}
+
+//FormatAmount : format the discount amount
+func (c *CouponRedemption) FormatAmount(currency Currency) string {
+	return currency.FormatAmountMinorUnits(c.Amount)
+}
+
+//FormatCreated : format the date the coupon was redeemed
+func (c *CouponRedemption) FormatCreated(timeZone string) string {
+	return FormatDateLocal(c.Created, timeZone)
+}
+
+//check if a redemption differs from the existing redemption, in which case the existing redemption is replaced
+func isCouponRedemptionChange(existing *CouponRedemption, redemption *CouponRedemption) bool {
+	if existing == nil || redemption == nil {
+		return existing != redemption
+	}
+	return existing.CouponID.String() != redemption.CouponID.String() || existing.Amount != redemption.Amount
+}
+
+//update the redemption for a booking only if it changed
+func updateBookingCouponRedemption(ctx context.Context, db *DB, bookID *uuid.UUID, existing *CouponRedemption, redemption *CouponRedemption) (context.Context, error) {
+	if !isCouponRedemptionChange(existing, redemption) {
+		return ctx, nil
+	}
+	if redemption == nil {
+		ctx, err := DeleteCouponRedemptionByBookingID(ctx, db, bookID)
+		if err != nil {
+			return ctx, errors.Wrap(err, "delete coupon redemption")
+		}
+		return ctx, nil
+	}
+	if existing != nil {
+		redemption.ID = existing.ID
+	}
+	ctx, err := SaveCouponRedemption(ctx, db, redemption)
+	if err != nil {
+		return ctx, errors.Wrap(err, "save coupon redemption")
+	}
+	return ctx, nil
+}
+
+//ApplyBookingCoupon : apply the coupon to the price of the booking, recording the redemption if the coupon was applied
+func ApplyBookingCoupon(ctx context.Context, db *DB, svc *Service, book *Booking, isNewClient bool, now time.Time) (context.Context, error) {
+	//load the existing redemption
+	ctx, existing, err := LoadCouponRedemptionByBookingID(ctx, db, book.ID)
+	if err != nil {
+		return ctx, errors.Wrap(err, "load coupon redemption")
+	}
+
+	//remove any previous redemption if the coupon was removed or the booking is prepaid by a package or membership
+	if book.Coupon == nil || book.IsPrepaid() {
+		ctx, err = updateBookingCouponRedemption(ctx, db, book.ID, existing, nil)
+		if err != nil {
+			return ctx, errors.Wrap(err, "update coupon redemption")
+		}
+		return ctx, nil
+	}
+
+	//load the current usage of the coupon
+	ctx, usage, err := LoadCouponUsage(ctx, db, book.Coupon, book.Client.ID, book.ID)
+	if err != nil {
+		return ctx, errors.Wrap(err, "load coupon usage")
+	}
+	usage.IsNewClient = isNewClient
+	usage.Amount = book.ServicePriceType.Compute(svc.Price, book.ServiceDuration)
+
+	//adjust the price
+	book.ServicePriceOriginal = svc.Price
+	book.ServicePrice = book.Coupon.AdjustPrice(svc.Price, book.Service.ID, usage, now)
+	if book.ServicePrice >= book.ServicePriceOriginal {
+		ctx, err = updateBookingCouponRedemption(ctx, db, book.ID, existing, nil)
+		if err != nil {
+			return ctx, errors.Wrap(err, "update coupon redemption")
+		}
+		return ctx, nil
+	}
+
+	//record the redemption
+	currency := book.GetCurrency()
+	discount := usage.Amount - book.ServicePriceType.Compute(book.ServicePrice, book.ServiceDuration)
+	redemption := &CouponRedemption{
+		CouponID:   book.Coupon.ID,
+		ProviderID: book.Coupon.ProviderID,
+		ClientID:   book.Client.ID,
+		BookingID:  book.ID,
+		Amount:     currency.ToMinorUnits(discount),
+	}
+	ctx, err = updateBookingCouponRedemption(ctx, db, book.ID, existing, redemption)
+	if err != nil {
+		return ctx, errors.Wrap(err, "update coupon redemption")
+	}
+	return ctx, nil
+}
+
+//LoadCouponUsage : load the usage of a coupon, excluding the booking, which locks the coupon until the redemption is saved
+func LoadCouponUsage(ctx context.Context, db *DB, coupon *Coupon, clientID *uuid.UUID, bookID *uuid.UUID) (context.Context, *CouponUsage, error) {
+	usage := &CouponUsage{}
+	ctx, err := db.ProcessTx(ctx, "load coupon usage", func(ctx context.Context, db *DB) (context.Context, error) {
+		//lock the coupon
+		stmt := fmt.Sprintf("SELECT 1 FROM %s WHERE id=UUID_TO_BIN(?) FOR UPDATE", dbTableCoupon)
+		ctx, row, err := db.QueryRow(ctx, stmt, coupon.ID)
+		if err != nil {
+			return ctx, errors.Wrap(err, "query row coupon lock")
+		}
+		var locked int
+		err = row.Scan(&locked)
+		if err != nil {
+			return ctx, errors.Wrap(err, "select coupon lock")
+		}
+
+		//count the redemptions
+		stmt = fmt.Sprintf("SELECT COUNT(*),COALESCE(SUM(client_id=UUID_TO_BIN(?)),0) FROM %s WHERE deleted=0 AND coupon_id=UUID_TO_BIN(?) AND booking_id<>UUID_TO_BIN(?)", dbTableCouponRedemption)
+		ctx, row, err = db.QueryRow(ctx, stmt, clientID, coupon.ID, bookID)
+		if err != nil {
+			return ctx, errors.Wrap(err, "query row coupon redemptions")
+		}
+		err = row.Scan(&usage.Redemptions, &usage.ClientRedemptions)
+		if err != nil {
+			return ctx, errors.Wrap(err, "select coupon redemptions")
+		}
+
+		//count the earlier bookings of the client, counting a recurring booking once
+		stmt = fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE deleted=0 AND client_id=UUID_TO_BIN(?) AND id<>UUID_TO_BIN(?) AND (parent_id IS NULL OR parent_id=id) AND created<=COALESCE((SELECT b.created FROM %s b WHERE b.id=UUID_TO_BIN(?)),NOW())", dbTableBooking, dbTableBooking)
+		ctx, row, err = db.QueryRow(ctx, stmt, clientID, bookID, bookID)
+		if err != nil {
+			return ctx, errors.Wrap(err, "query row client bookings")
+		}
+		err = row.Scan(&usage.ClientBookings)
+		if err != nil {
+			return ctx, errors.Wrap(err, "select client bookings")
+		}
+		return ctx, nil
+	})
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "load coupon usage")
+	}
+	return ctx, usage, nil
+}
+
+//SaveCouponRedemption : save the redemption of a coupon for a booking, replacing any previous redemption for the booking
+func SaveCouponRedemption(ctx context.Context, db *DB, redemption *CouponRedemption) (context.Context, error) {
+	//generate an id if necessary
+	if redemption.ID == nil {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return ctx, errors.Wrap(err, "new uuid coupon redemption")
+		}
+		redemption.ID = &id
+	}
+
+	//save to the db
+	stmt := fmt.Sprintf("INSERT INTO %s(id,coupon_id,provider_id,client_id,booking_id,payment_id,amount) VALUES (UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),?) ON DUPLICATE KEY UPDATE coupon_id=VALUES(coupon_id),client_id=VALUES(client_id),amount=VALUES(amount),deleted=0", dbTableCouponRedemption)
+	ctx, result, err := db.Exec(ctx, stmt, redemption.ID, redemption.CouponID, redemption.ProviderID, redemption.ClientID, redemption.BookingID, redemption.PaymentID, redemption.Amount)
+	if err != nil {
+		return ctx, errors.Wrap(err, "insert coupon redemption")
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return ctx, errors.Wrap(err, "insert coupon redemption rows affected")
+	}
+
+	//0 indicated no update, 1 an insert, 2 an update
+	if count < 0 || count > 2 {
+		return ctx, fmt.Errorf("unable to insert coupon redemption: %s", redemption.BookingID)
+	}
+	return ctx, nil
+}
+
+//LoadCouponRedemptionByBookingID : load the coupon redemption for a booking
+func LoadCouponRedemptionByBookingID(ctx context.Context, db *DB, bookID *uuid.UUID) (context.Context, *CouponRedemption, error) {
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(id),BIN_TO_UUID(coupon_id),amount FROM %s WHERE deleted=0 AND booking_id=UUID_TO_BIN(?)", dbTableCouponRedemption)
+	ctx, row, err := db.QueryRow(ctx, stmt, bookID)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "query row coupon redemption")
+	}
+
+	//read the row
+	var idStr string
+	var couponIDStr string
+	var amount int
+	err = row.Scan(&idStr, &couponIDStr, &amount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx, nil, nil
+		}
+		return ctx, nil, errors.Wrap(err, "select coupon redemption")
+	}
+
+	//parse the uuids
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "parse uuid")
+	}
+	couponID, err := uuid.FromString(couponIDStr)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "parse uuid coupon id")
+	}
+	redemption := &CouponRedemption{
+		ID:        &id,
+		CouponID:  &couponID,
+		BookingID: bookID,
+		Amount:    amount,
+	}
+	return ctx, redemption, nil
+}
+
+//UpdateCouponRedemptionPayment : link the payment for a booking to the coupon redemption
+func UpdateCouponRedemptionPayment(ctx context.Context, db *DB, bookID *uuid.UUID, paymentID *uuid.UUID) (context.Context, error) {
+	stmt := fmt.Sprintf("UPDATE %s SET payment_id=UUID_TO_BIN(?) WHERE deleted=0 AND booking_id=UUID_TO_BIN(?)", dbTableCouponRedemption)
+	ctx, _, err := db.Exec(ctx, stmt, paymentID, bookID)
+	if err != nil {
+		return ctx, errors.Wrap(err, "update coupon redemption payment")
+	}
+	return ctx, nil
+}
+
+//DeleteCouponRedemptionByBookingID : delete the coupon redemption for a booking
+func DeleteCouponRedemptionByBookingID(ctx context.Context, db *DB, bookID *uuid.UUID) (context.Context, error) {
+	stmt := fmt.Sprintf("UPDATE %s SET deleted=1 WHERE deleted=0 AND booking_id=UUID_TO_BIN(?)", dbTableCouponRedemption)
+	ctx, _, err := db.Exec(ctx, stmt, bookID)
+	if err != nil {
+		return ctx, errors.Wrap(err, "delete coupon redemption")
+	}
+	return ctx, nil
+}
+
+//ListCouponRedemptionsByCouponID : list the redemptions of a coupon
+func ListCouponRedemptionsByCouponID(ctx context.Context, db *DB, providerID *uuid.UUID, couponID *uuid.UUID) (context.Context, []*CouponRedemption, error) {
+	ctx, logger := GetLogger(ctx)
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(r.id),BIN_TO_UUID(r.client_id),BIN_TO_UUID(r.booking_id),BIN_TO_UUID(r.payment_id),r.amount,r.created,c.email,c.data->>'$.Name',p.friendly_id FROM %s r INNER JOIN %s c ON c.id=r.client_id LEFT JOIN %s p ON p.id=r.payment_id WHERE r.deleted=0 AND r.provider_id=UUID_TO_BIN(?) AND r.coupon_id=UUID_TO_BIN(?) ORDER BY r.created DESC", dbTableCouponRedemption, dbTableClient, dbTablePayment)
+	ctx, rows, err := db.Query(ctx, stmt, providerID, couponID)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "select coupon redemptions")
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Warnw("rows close", "error", err)
+		}
+	}()
+
+	//read the rows
+	redemptions := make([]*CouponRedemption, 0, 2)
+	var idStr string
+	var clientIDStr string
+	var bookIDStr string
+	var paymentIDStr *string
+	var amount int
+	var created time.Time
+	var clientEmail string
+	var clientName *string
+	var paymentFriendlyID *string
+	for rows.Next() {
+		err := rows.Scan(&idStr, &clientIDStr, &bookIDStr, &paymentIDStr, &amount, &created, &clientEmail, &clientName, &paymentFriendlyID)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "rows scan coupon redemptions")
+		}
+
+		//parse the uuids
+		id, err := uuid.FromString(idStr)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "parse uuid")
+		}
+		clientID, err := uuid.FromString(clientIDStr)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "parse uuid client id")
+		}
+		bookID, err := uuid.FromString(bookIDStr)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "parse uuid booking id")
+		}
+		redemption := &CouponRedemption{
+			ID:          &id,
+			CouponID:    couponID,
+			ProviderID:  providerID,
+			ClientID:    &clientID,
+			BookingID:   &bookID,
+			Amount:      amount,
+			Created:     created,
+			ClientEmail: clientEmail,
+		}
+		if paymentIDStr != nil {
+			paymentID, err := uuid.FromString(*paymentIDStr)
+			if err != nil {
+				return ctx, nil, errors.Wrap(err, "parse uuid payment id")
+			}
+			redemption.PaymentID = &paymentID
+		}
+		if clientName != nil {
+			redemption.ClientName = *clientName
+		}
+		if paymentFriendlyID != nil {
+			redemption.PaymentFriendlyID = *paymentFriendlyID
+		}
+		redemptions = append(redemptions, redemption)
+	}
+	return ctx, redemptions, nil
+}
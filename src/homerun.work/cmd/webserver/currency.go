@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//Currency : ISO 4217 currency code
+type Currency string
+
+//currencies
+const (
+	CurrencyUSD Currency = "USD"
+	CurrencyCAD Currency = "CAD"
+	CurrencyGBP Currency = "GBP"
+	CurrencyEUR Currency = "EUR"
+	CurrencyAUD Currency = "AUD"
+	CurrencyNZD Currency = "NZD"
+	CurrencyJPY Currency = "JPY"
+)
+
+//CurrencyDefault : default currency, also used for platform pricing
+const CurrencyDefault = CurrencyUSD
+
+//Currencies : supported currencies
+var Currencies []Currency = []Currency{
+	CurrencyUSD,
+	CurrencyCAD,
+	CurrencyGBP,
+	CurrencyEUR,
+	CurrencyAUD,
+	CurrencyNZD,
+	CurrencyJPY,
+}
+
+//currencyFormat : formatting of amounts in a currency, based on the locale in which it is most commonly used
+type currencyFormat struct {
+	Name         string
+	Symbol       string
+	SymbolAfter  bool
+	SepDecimal   string
+	SepThousands string
+	Digits       int //digits in the minor unit
+}
+
+//currency formats
+var currencyFormats = map[Currency]*currencyFormat{
+	CurrencyUSD: {Name: "US Dollar", Symbol: "$", SepDecimal: ".", SepThousands: ",", Digits: 2},
+	CurrencyCAD: {Name: "Canadian Dollar", Symbol: "$", SepDecimal: ".", SepThousands: ",", Digits: 2},
+	CurrencyGBP: {Name: "British Pound", Symbol: "£", SepDecimal: ".", SepThousands: ",", Digits: 2},
+	CurrencyEUR: {Name: "Euro", Symbol: "€", SymbolAfter: true, SepDecimal: ",", SepThousands: ".", Digits: 2},
+	CurrencyAUD: {Name: "Australian Dollar", Symbol: "$", SepDecimal: ".", SepThousands: ",", Digits: 2},
+	CurrencyNZD: {Name: "New Zealand Dollar", Symbol: "$", SepDecimal: ".", SepThousands: ",", Digits: 2},
+	CurrencyJPY: {Name: "Japanese Yen", Symbol: "¥", SepDecimal: ".", SepThousands: ",", Digits: 0},
+}
+
+//ParseCurrency : parse a currency code
+func ParseCurrency(in string) Currency {
+	in = strings.ToUpper(strings.TrimSpace(in))
+	for _, currency := range Currencies {
+		if in == string(currency) {
+			return currency
+		}
+	}
+	return ""
+}
+
+//get the format, defaulting if the currency is not set
+func (c Currency) getFormat() *currencyFormat {
+	format, ok := currencyFormats[c]
+	if !ok {
+		return currencyFormats[CurrencyDefault]
+	}
+	return format
+}
+
+//Code : get the currency code, defaulting if the currency is not set
+func (c Currency) Code() string {
+	if _, ok := currencyFormats[c]; !ok {
+		return string(CurrencyDefault)
+	}
+	return string(c)
+}
+
+//CodeStripe : get the currency code used by Stripe
+func (c Currency) CodeStripe() string {
+	return strings.ToLower(c.Code())
+}
+
+//Label : label for the currency
+func (c Currency) Label() string {
+	return fmt.Sprintf("%s - %s", c.Code(), c.getFormat().Name)
+}
+
+//Symbol : symbol for the currency
+func (c Currency) Symbol() string {
+	return c.getFormat().Symbol
+}
+
+//Digits : digits in the minor unit
+func (c Currency) Digits() int {
+	return c.getFormat().Digits
+}
+
+//Step : smallest amount that can be entered for the currency
+func (c Currency) Step() string {
+	return c.FormatDecimal(c.FromMinorUnits(1))
+}
+
+//ToMinorUnits : convert an amount to a fractionless number in the minor unit, such as pennies
+func (c Currency) ToMinorUnits(amount float32) int {
+	return int(math.Round(float64(amount) * math.Pow10(c.getFormat().Digits)))
+}
+
+//FromMinorUnits : convert a fractionless number in the minor unit to an amount
+func (c Currency) FromMinorUnits(amount int) float32 {
+	return float32(float64(amount) / math.Pow10(c.getFormat().Digits))
+}
+
+//Round : round an amount to the minor unit
+func (c Currency) Round(amount float32) float32 {
+	return c.FromMinorUnits(c.ToMinorUnits(amount))
+}
+
+//FormatDecimal : format an amount as a plain decimal number with all digits of the minor unit, as required by payment processors
+func (c Currency) FormatDecimal(amount float32) string {
+	return strconv.FormatFloat(float64(amount), 'f', c.getFormat().Digits, 32)
+}
+
+//FormatAmount : format an amount with the symbol and separators, omitting the minor unit for whole amounts
+func (c Currency) FormatAmount(amount float32) string {
+	return c.FormatAmountMinorUnits(c.ToMinorUnits(amount))
+}
+
+//FormatAmountMinorUnits : format a fractionless number in the minor unit
+func (c Currency) FormatAmountMinorUnits(minor int) string {
+	format := c.getFormat()
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+
+	//split the whole and fractional parts
+	factor := int(math.Pow10(format.Digits))
+	whole := strconv.Itoa(minor / factor)
+	frac := minor % factor
+
+	//group the thousands
+	var grouped strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteString(format.SepThousands)
+		}
+		grouped.WriteRune(r)
+	}
+	amountStr := grouped.String()
+	if frac != 0 {
+		amountStr = fmt.Sprintf("%s%s%0*d", amountStr, format.SepDecimal, format.Digits, frac)
+	}
+	if format.SymbolAfter {
+		return fmt.Sprintf("%s%s %s", sign, amountStr, format.Symbol)
+	}
+	return fmt.Sprintf("%s%s%s", sign, format.Symbol, amountStr)
+}
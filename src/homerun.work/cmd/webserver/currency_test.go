@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestCurrencyToMinorUnits(t *testing.T) {
+	tests := []struct {
+		currency Currency
+		amount   float32
+		want     int
+	}{
+		{CurrencyUSD, 0.30, 30},
+		{CurrencyUSD, 1.07, 107},
+		{CurrencyUSD, 2.13, 213},
+		{CurrencyUSD, 19.99, 1999},
+		{CurrencyUSD, 0.125, 13},
+		{CurrencyEUR, 99.25, 9925},
+		{CurrencyJPY, 1500, 1500},
+		{CurrencyJPY, 100.25, 100},
+	}
+	for _, test := range tests {
+		got := test.currency.ToMinorUnits(test.amount)
+		if got != test.want {
+			t.Errorf("%s %v: got %d, want %d", test.currency, test.amount, got, test.want)
+		}
+	}
+}
+
+func TestPaymentSetAmountExact(t *testing.T) {
+	//every price entered in cents up to $500 must be charged exactly
+	payment := &Payment{Currency: CurrencyUSD}
+	for cents := 0; cents <= 50000; cents++ {
+		amount := float32(cents) / 100
+		payment.SetAmount(amount)
+		if payment.Amount != cents {
+			t.Fatalf("%v: got %d, want %d", amount, payment.Amount, cents)
+		}
+		if payment.GetAmount() != amount {
+			t.Fatalf("%d: got %v, want %v", cents, payment.GetAmount(), amount)
+		}
+	}
+}
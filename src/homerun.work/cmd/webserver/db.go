@@ -7,7 +7,7 @@ import (
 	"runtime/debug"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 	"github.com/pkg/errors"
 )
 
@@ -17,6 +17,11 @@ const (
 	processTimeout        = 10 * time.Second
 )
 
+//mysql error numbers
+const (
+	mysqlErrDupEntry = 1062
+)
+
 //ScanFn : function for scanning a row
 type ScanFn func(desc ...interface{}) error
 
@@ -222,3 +227,9 @@ func (db *DB) ProcessTx(ctx context.Context, op string, fn txFunc) (returnCtx co
 	ctx, err = fn(ctx, db)
 	return ctx, err
 }
+
+//IsErrDuplicate : check if the error is from violating a unique key
+func IsErrDuplicate(err error) bool {
+	mysqlErr, ok := errors.Cause(err).(*mysql.MySQLError)
+	return ok && mysqlErr.Number == mysqlErrDupEntry
+}
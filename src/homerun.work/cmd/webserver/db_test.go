@@ -0,0 +1,397 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+//fake database driver, answering each statement from the first result registered for a fragment of the statement
+const fakeDBDriver = "fakedb"
+
+var fakeDBs = struct {
+	sync.Mutex
+	dbs map[string]*fakeDB
+}{
+	dbs: make(map[string]*fakeDB),
+}
+
+func init() {
+	sql.Register(fakeDBDriver, &fakeDBDriverImpl{})
+}
+
+//fakeDBResult : scripted result of a statement
+type fakeDBResult struct {
+	match    string
+	rows     [][]driver.Value
+	affected int64
+	err      error
+}
+
+//fakeDBStmt : statement executed against the fake database
+type fakeDBStmt struct {
+	stmt string
+	args []driver.NamedValue
+}
+
+//fakeDB : fake database recording the executed statements
+type fakeDB struct {
+	sync.Mutex
+	t       *testing.T
+	results []*fakeDBResult
+	stmts   []*fakeDBStmt
+}
+
+//create a fake database and the server using it
+func newFakeDB(t *testing.T) (*fakeDB, *Server) {
+	f := &fakeDB{t: t}
+	fakeDBs.Lock()
+	name := fmt.Sprintf("%s-%d", t.Name(), len(fakeDBs.dbs))
+	fakeDBs.dbs[name] = f
+	fakeDBs.Unlock()
+	sqlDB, err := sql.Open(fakeDBDriver, name)
+	if err != nil {
+		t.Fatalf("open fake db: %v", err)
+	}
+	return f, &Server{db: &DB{db: sqlDB}}
+}
+
+//answer a statement containing the fragment with the rows
+func (f *fakeDB) onRows(match string, rows ...[]driver.Value) {
+	f.Lock()
+	defer f.Unlock()
+	f.results = append(f.results, &fakeDBResult{match: match, rows: rows})
+}
+
+//answer a statement containing the fragment with the count of affected rows
+func (f *fakeDB) onExec(match string, affected int64) {
+	f.Lock()
+	defer f.Unlock()
+	f.results = append(f.results, &fakeDBResult{match: match, affected: affected})
+}
+
+//answer a statement containing the fragment with the error
+func (f *fakeDB) onErr(match string, err error) {
+	f.Lock()
+	defer f.Unlock()
+	f.results = append(f.results, &fakeDBResult{match: match, err: err})
+}
+
+//find the executed statements containing the fragment
+func (f *fakeDB) executed(match string) []*fakeDBStmt {
+	f.Lock()
+	defer f.Unlock()
+	stmts := make([]*fakeDBStmt, 0, 1)
+	for _, stmt := range f.stmts {
+		if strings.Contains(stmt.stmt, match) {
+			stmts = append(stmts, stmt)
+		}
+	}
+	return stmts
+}
+
+//record a statement and find its result, failing the test for an unexpected statement
+func (f *fakeDB) process(stmt string, args []driver.NamedValue) (*fakeDBResult, error) {
+	f.Lock()
+	defer f.Unlock()
+	f.stmts = append(f.stmts, &fakeDBStmt{stmt: stmt, args: args})
+	for _, result := range f.results {
+		if strings.Contains(stmt, result.match) {
+			return result, result.err
+		}
+	}
+	f.t.Errorf("unexpected statement: %s", stmt)
+	return nil, fmt.Errorf("unexpected statement: %s", stmt)
+}
+
+//driver implementation
+type fakeDBDriverImpl struct{}
+
+func (d *fakeDBDriverImpl) Open(name string) (driver.Conn, error) {
+	fakeDBs.Lock()
+	defer fakeDBs.Unlock()
+	f, ok := fakeDBs.dbs[name]
+	if !ok {
+		return nil, fmt.Errorf("no fake db: %s", name)
+	}
+	return &fakeDBConn{f}, nil
+}
+
+type fakeDBConn struct {
+	db *fakeDB
+}
+
+func (c *fakeDBConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepare not supported: %s", query)
+}
+
+func (c *fakeDBConn) Close() error {
+	return nil
+}
+
+func (c *fakeDBConn) Begin() (driver.Tx, error) {
+	c.db.Lock()
+	defer c.db.Unlock()
+	c.db.stmts = append(c.db.stmts, &fakeDBStmt{stmt: "BEGIN"})
+	return &fakeDBTx{c.db}, nil
+}
+
+func (c *fakeDBConn) CheckNamedValue(v *driver.NamedValue) error {
+	return nil
+}
+
+func (c *fakeDBConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result, err := c.db.process(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeDBRows{rows: result.rows}, nil
+}
+
+func (c *fakeDBConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	result, err := c.db.process(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(result.affected), nil
+}
+
+type fakeDBTx struct {
+	db *fakeDB
+}
+
+func (t *fakeDBTx) Commit() error {
+	t.db.Lock()
+	defer t.db.Unlock()
+	t.db.stmts = append(t.db.stmts, &fakeDBStmt{stmt: "COMMIT"})
+	return nil
+}
+
+func (t *fakeDBTx) Rollback() error {
+	t.db.Lock()
+	defer t.db.Unlock()
+	t.db.stmts = append(t.db.stmts, &fakeDBStmt{stmt: "ROLLBACK"})
+	return nil
+}
+
+type fakeDBRows struct {
+	rows [][]driver.Value
+	idx  int
+}
+
+func (r *fakeDBRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return []string{}
+	}
+	cols := make([]string, len(r.rows[0]))
+	for i := range cols {
+		cols[i] = fmt.Sprintf("c%d", i)
+	}
+	return cols
+}
+
+func (r *fakeDBRows) Close() error {
+	return nil
+}
+
+func (r *fakeDBRows) Next(dest []driver.Value) error {
+	if r.idx >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.idx])
+	r.idx++
+	return nil
+}
+
+//create a new id
+func newFakeID(t *testing.T) *uuid.UUID {
+	id, err := uuid.NewV4()
+	if err != nil {
+		t.Fatalf("new uuid: %v", err)
+	}
+	return &id
+}
+
+//json encode the data of a row
+func fakeJSON(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	return string(data)
+}
+
+//convert optional values of a row
+func fakeTime(v *time.Time) driver.Value {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func fakeString(v *string) driver.Value {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func fakeBit(v bool) driver.Value {
+	if v {
+		return "\x01"
+	}
+	return "\x00"
+}
+
+//create the row read by paymentQueryParse
+func fakePaymentRow(t *testing.T, payment *Payment) []driver.Value {
+	return []driver.Value{
+		payment.ID.String(),
+		payment.ProviderID.String(),
+		payment.SecondaryID.String(),
+		payment.FriendlyID,
+		int64(payment.Type),
+		int64(payment.Amount),
+		fakeTime(payment.Invoiced),
+		fakeTime(payment.Paid),
+		fakeTime(payment.Captured),
+		fakeString(payment.StripeID),
+		fakeString(payment.StripeSessionID),
+		fakeString(payment.StripeAccountID),
+		fakeString(payment.PayPalID),
+		fakeJSON(t, payment),
+		nil,
+		nil,
+	}
+}
+
+//create the row read by loadProvider
+func fakeProviderRow(t *testing.T, provider *Provider) []driver.Value {
+	row := []driver.Value{
+		provider.User.ID.String(),
+		provider.User.Email,
+		fakeBit(true),
+		fakeBit(false),
+		fakeBit(false),
+		nil,
+		fakeJSON(t, provider.User),
+		provider.ID.String(),
+		provider.URLName,
+		nil,
+		nil,
+		nil,
+		nil,
+		fakeJSON(t, provider),
+	}
+
+	//no logo, banner or favicon
+	return append(row, make([]driver.Value, 30)...)
+}
+
+//create the row read by loadService
+func fakeServiceRow(t *testing.T, svc *Service) []driver.Value {
+	row := []driver.Value{
+		svc.Provider.ID.String(),
+		svc.Provider.URLName,
+		svc.Provider.URLNameFriendly,
+		svc.Provider.User.ID.String(),
+		fakeJSON(t, svc.Provider),
+		svc.ID.String(),
+		int64(svc.Type),
+		fakeJSON(t, svc),
+	}
+
+	//no image
+	return append(row, make([]driver.Value, 10)...)
+}
+
+//create the row read by bookingQueryParse
+func fakeBookingRow(t *testing.T, book *Booking) []driver.Value {
+	row := []driver.Value{
+		//provider
+		book.Provider.ID.String(),
+		book.Provider.URLName,
+		book.Provider.URLNameFriendly,
+		nil,
+		fakeBit(false),
+		nil,
+		fakeJSON(t, book.Provider),
+
+		//user
+		book.Provider.User.ID.String(),
+		book.Provider.User.Email,
+		nil,
+		fakeJSON(t, book.Provider.User),
+
+		//service
+		int64(book.Service.Type),
+		book.Service.ID.String(),
+		fakeJSON(t, book.Service),
+
+		//client
+		book.Client.ID.String(),
+		book.Client.Email,
+		fakeBit(false),
+		fakeJSON(t, book.Client),
+
+		//booking
+		book.ID.String(),
+		nil,
+		int64(book.ServiceType),
+		book.TimeFrom,
+		book.TimeTo,
+		book.TimeFrom,
+		book.TimeTo,
+		fakeBit(book.Confirmed),
+		fakeBit(book.ClientCreated),
+		nil,
+		nil,
+		nil,
+		fakeTime(book.PaymentExpiration),
+		nil,
+		fakeBit(false),
+		fakeBit(false),
+		nil,
+		fakeBit(false),
+		fakeBit(false),
+		nil,
+		fakeBit(book.Deleted),
+		book.Created,
+		fakeJSON(t, book),
+	}
+
+	//payment
+	if book.Payment != nil {
+		payment := book.Payment
+		row = append(row, payment.ID.String(), payment.FriendlyID, int64(payment.Type), int64(payment.Amount), fakeTime(payment.Invoiced), fakeTime(payment.Paid), fakeTime(payment.Captured), fakeString(payment.StripeID), fakeString(payment.PayPalID), fakeJSON(t, payment))
+	} else {
+		row = append(row, make([]driver.Value, 10)...)
+	}
+
+	//no deposit, provider user, package or membership
+	return append(row, make([]driver.Value, 15)...)
+}
+
+//create the row read by giftCardQueryParse
+func fakeGiftCardRow(t *testing.T, card *GiftCard) []driver.Value {
+	return []driver.Value{
+		card.ID.String(),
+		card.ProviderID.String(),
+		card.PaymentID.String(),
+		card.Code,
+		int64(card.Amount),
+		card.Created,
+		fakeJSON(t, card),
+		fakeTime(card.Activated),
+		int64(card.Balance),
+	}
+}
@@ -21,6 +21,7 @@ const (
 	EmailSubjectBookingCancelProvider          emailSubjectKey = "bookingCancelProvider"
 	EmailSubjectBookingConfirmClient           emailSubjectKey = "bookingConfirmClient"
 	EmailSubjectBookingEdit                    emailSubjectKey = "bookingEdit"
+	EmailSubjectBookingEditProvider            emailSubjectKey = "bookingEditProvider"
 	EmailSubjectBookingNewFromClientToClient   emailSubjectKey = "bookingFromClientToClient"
 	EmailSubjectBookingNewFromClientToProvider emailSubjectKey = "bookingFromClientToProvider"
 	EmailSubjectBookingNewFromProviderToClient emailSubjectKey = "bookingFromProviderToClient"
@@ -35,9 +36,12 @@ const (
 	EmailSubjectInvoice                        emailSubjectKey = "invoice"
 	EmailSubjectPaymentClient                  emailSubjectKey = "paymentClient"
 	EmailSubjectPaymentProvider                emailSubjectKey = "paymentProvider"
+	EmailSubjectPaymentReminderClient          emailSubjectKey = "paymentReminderClient"
 	EmailSubjectProviderUserInvite             emailSubjectKey = "providerUserInvite"
 	EmailSubjectPwdReset                       emailSubjectKey = "pwdReset"
+	EmailSubjectRefundClient                   emailSubjectKey = "refundClient"
 	EmailSubjectVerify                         emailSubjectKey = "verify"
+	EmailSubjectWaitlistOfferClient            emailSubjectKey = "waitlistOfferClient"
 	EmailSubjectWelcome                        emailSubjectKey = "welcome"
 )
 
@@ -47,6 +51,7 @@ var emailSubjectText = map[emailSubjectKey]string{
 	EmailSubjectBookingCancelProvider:          "An order has been cancelled",
 	EmailSubjectBookingConfirmClient:           "Your order has been confirmed",
 	EmailSubjectBookingEdit:                    "Your order has been updated",
+	EmailSubjectBookingEditProvider:            "An order has been rescheduled",
 	EmailSubjectBookingNewFromClientToClient:   "Your order is pending confirmation",
 	EmailSubjectBookingNewFromClientToProvider: "You have received a new order, please confirm",
 	EmailSubjectBookingNewFromProviderToClient: "Your order has been created",
@@ -62,8 +67,11 @@ var emailSubjectText = map[emailSubjectKey]string{
 	EmailSubjectPaymentClient:                  "Your payment has been received",
 	EmailSubjectProviderUserInvite:             "You have been added to the team",
 	EmailSubjectPaymentProvider:                "You have received payment",
+	EmailSubjectPaymentReminderClient:          "Reminder: your invoice from %s is unpaid",
 	EmailSubjectPwdReset:                       "Reset Your Password",
+	EmailSubjectRefundClient:                   "Your refund has been issued",
 	EmailSubjectVerify:                         "Please Verify Your Email",
+	EmailSubjectWaitlistOfferClient:            "A time has opened up for your service",
 	EmailSubjectWelcome:                        "Welcome!",
 }
 
@@ -239,7 +247,29 @@ func (s *Server) createEmailBookingEditClient(ctx context.Context, book *booking
 	return ctx, subject, body, nil
 }
 
-//create the email to the client for a new booking
+//create the email to the provider for a booking rescheduled by a client
+func (s *Server) createEmailBookingEditProvider(ctx context.Context, book *bookingUI) (context.Context, string, string, error) {
+	var o sync.Once
+	var tpl *template.Template
+	o.Do(func() {
+		tpl = s.loadTemplateEmail(ctx, "svcbookeditprovider.html")
+	})
+	subject := GetEmailSubjectText(EmailSubjectBookingEditProvider)
+	data := s.createTemplateDataEmail()
+	providerUI := s.createProviderUI(book.Provider)
+	data[TplParamProvider] = providerUI
+	data[TplParamSvc] = s.createServiceUI(providerUI, book.Service)
+	data[TplParamBook] = book
+
+	//use the provider timezone
+	ctx = SetCtxTimeZone(ctx, book.Provider.User.TimeZone)
+	body, err := s.renderEmailTemplate(ctx, tpl, data)
+	if err != nil {
+		return ctx, "", "", errors.Wrap(err, "render service book edit provider")
+	}
+	return ctx, subject, body, nil
+}
+
 func (s *Server) createEmailBookingNewClient(ctx context.Context, book *bookingUI, isClient bool) (context.Context, string, string, error) {
 	var o sync.Once
 	var tpl *template.Template
@@ -548,6 +578,24 @@ func (s *Server) createEmailPaymentClient(ctx context.Context, provider *provide
 	data := s.createTemplateDataEmail()
 	data[TplParamPayment] = payment
 	data[TplParamProvider] = provider
+
+	//include the code of a gift card once paid
+	if payment.Type == PaymentTypeGiftCard && payment.IsCaptured() {
+		ctx, card, err := LoadGiftCardByPaymentID(ctx, s.getDB(), payment.ID)
+		if err != nil {
+			return ctx, "", "", errors.Wrap(err, "load gift card")
+		}
+		data[TplParamGiftCard] = card
+	}
+
+	//include the session package once paid
+	if payment.Type == PaymentTypePackage && payment.IsCaptured() {
+		ctx, clientPkg, err := LoadClientPackageByPaymentID(ctx, s.getDB(), payment.ID)
+		if err != nil {
+			return ctx, "", "", errors.Wrap(err, "load client package")
+		}
+		data[TplParamPackage] = clientPkg
+	}
 	body, err := s.renderEmailTemplate(ctx, tpl, data)
 	if err != nil {
 		return ctx, "", "", errors.Wrap(err, "render payment client")
@@ -555,6 +603,42 @@ func (s *Server) createEmailPaymentClient(ctx context.Context, provider *provide
 	return ctx, subject, body, nil
 }
 
+//create the payment reminder email to the client
+func (s *Server) createEmailPaymentReminderClient(ctx context.Context, provider *providerUI, payment *paymentUI) (context.Context, string, string, error) {
+	var o sync.Once
+	var tpl *template.Template
+	o.Do(func() {
+		tpl = s.loadTemplateEmail(ctx, "paymentreminderclient.html")
+	})
+	subject := GetEmailSubjectText(EmailSubjectPaymentReminderClient, provider.Name)
+	data := s.createTemplateDataEmail()
+	data[TplParamPayment] = payment
+	data[TplParamProvider] = provider
+	body, err := s.renderEmailTemplate(ctx, tpl, data)
+	if err != nil {
+		return ctx, "", "", errors.Wrap(err, "render payment reminder client")
+	}
+	return ctx, subject, body, nil
+}
+
+//create the refund email to the client
+func (s *Server) createEmailRefundClient(ctx context.Context, provider *providerUI, payment *paymentUI) (context.Context, string, string, error) {
+	var o sync.Once
+	var tpl *template.Template
+	o.Do(func() {
+		tpl = s.loadTemplateEmail(ctx, "refundclient.html")
+	})
+	subject := GetEmailSubjectText(EmailSubjectRefundClient)
+	data := s.createTemplateDataEmail()
+	data[TplParamPayment] = payment
+	data[TplParamProvider] = provider
+	body, err := s.renderEmailTemplate(ctx, tpl, data)
+	if err != nil {
+		return ctx, "", "", errors.Wrap(err, "render refund client")
+	}
+	return ctx, subject, body, nil
+}
+
 //create the payment email to the provider
 func (s *Server) createEmailPaymentProvider(ctx context.Context, provider *providerUI, payment *paymentUI) (context.Context, string, string, error) {
 	var o sync.Once
@@ -626,6 +710,29 @@ func (s *Server) createEmailVerify(ctx context.Context, user *User, verifyURL st
 	return ctx, subject, body, nil
 }
 
+//create the email to the client for a waitlist offer
+func (s *Server) createEmailWaitlistOfferClient(ctx context.Context, provider *providerUI, svc *serviceUI, waitlist *Waitlist, claimURL string) (context.Context, string, string, error) {
+	var o sync.Once
+	var tpl *template.Template
+	o.Do(func() {
+		tpl = s.loadTemplateEmail(ctx, "waitlistofferclient.html")
+	})
+	subject := GetEmailSubjectText(EmailSubjectWaitlistOfferClient)
+	data := s.createTemplateDataEmail()
+	data[TplParamProvider] = provider
+	data[TplParamSvc] = svc
+	data[TplParamWaitlist] = waitlist
+	data[TplParamURL] = claimURL
+
+	//use the client timezone
+	ctx = SetCtxTimeZone(ctx, waitlist.Client.TimeZone)
+	body, err := s.renderEmailTemplate(ctx, tpl, data)
+	if err != nil {
+		return ctx, "", "", errors.Wrap(err, "render waitlist offer client")
+	}
+	return ctx, subject, body, nil
+}
+
 //create the wlecome email
 func (s *Server) createEmailWelcome(ctx context.Context, provider *providerUI) (context.Context, string, string, error) {
 	var o sync.Once
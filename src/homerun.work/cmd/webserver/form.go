@@ -5,9 +5,13 @@ const (
 	LenCampaignInterests = 100
 	LenCampaignLocations = 100
 	LenCodeCoupon        = 10
+	LenCodeGiftCard      = 12
 	LenDescBook          = 200
 	LenDescCoupon        = 200
+	LenDescMembership    = 200
+	LenDescPackage       = 200
 	LenDescPayment       = 200
+	LenDescPaymentItem   = 100
 	LenDescProvider      = 1000
 	LenDescProviderNote  = 1000
 	LenDescSvc           = 200
@@ -17,6 +21,7 @@ const (
 	LenEmail             = 50
 	LenLocation          = 100
 	LenName              = 50
+	LenPaymentItems      = 50
 	LenTextContact       = 500
 	LenTextCampaign      = 150
 	LenTextFaq           = 500
@@ -126,7 +131,11 @@ type ClientBookingForm struct {
 	ProviderNoteSet bool
 	Freq            string `validate:"omitempty,recFreq"`
 	FreqSet         bool
-	Description     string `validate:"omitempty,max=200"` //LenDescBook
+	FreqInterval    string   `validate:"omitempty,min=1,max=2,numeric,recInterval"`
+	FreqDays        []string `validate:"omitempty,max=7,weekDays"`
+	FreqUntil       string   `validate:"omitempty,date"`
+	FreqCount       string   `validate:"omitempty,min=1,max=3,numeric,recCount,excluded_with=FreqUntil"`
+	Description     string   `validate:"omitempty,max=200"` //LenDescBook
 	DescriptionSet  bool
 	Confirmed       bool
 	ClientCreated   bool
@@ -158,6 +167,17 @@ type CouponForm struct {
 	Description string `validate:"omitempty,min=2,max=200"` //LenDescCoupon
 	ServiceID   string `validate:"omitempty,uuid_rfc4122"`
 	NewClients  bool
+
+	//usage limits
+	UsageLimit       string `validate:"omitempty,max=5,number"`
+	UsageLimitClient string `validate:"omitempty,max=5,number"`
+	MinAmount        string `validate:"omitempty,max=8,numeric,price"`
+	FirstBookings    string `validate:"omitempty,max=3,number"`
+}
+
+//CurrencyForm : form for a currency
+type CurrencyForm struct {
+	Currency string `validate:"required,currency"`
 }
 
 //CredentialsForm : form for credentials
@@ -172,20 +192,100 @@ type FaqForm struct {
 	Answer   string `validate:"required,min=3,max=500"` //LenTextFaq
 }
 
+//GiftCardRedeemForm : form for redeeming a gift card against an invoice
+type GiftCardRedeemForm struct {
+	Code string `validate:"required,min=1,max=12"` //LenCodeGiftCard
+}
+
 //GoogleTrackingIDForm : form for a Google tracking id
 type GoogleTrackingIDForm struct {
 	ID string `validate:"required,min=6,max=16"`
 }
 
-//PaymentForm : form for a payment
+//MembershipForm : form for adding a membership plan, where no sessions indicates unlimited sessions
+type MembershipForm struct {
+	NameForm
+	Description string   `validate:"omitempty,min=2,max=200"` //LenDescMembership
+	Price       string   `validate:"required,min=1,max=8,numeric,price"`
+	Sessions    string   `validate:"omitempty,max=3,number"`
+	ServiceIDs  []string `validate:"required,min=1,max=50,uuids"`
+}
+
+//MembershipJoinForm : form for a client joining a membership
+type MembershipJoinForm struct {
+	EmailForm
+	NameForm
+	ID    string `validate:"required,uuid_rfc4122"`
+	Phone string `validate:"omitempty,phone"`
+}
+
+//PackageForm : form for adding a session package
+type PackageForm struct {
+	NameForm
+	Description string   `validate:"omitempty,min=2,max=200"` //LenDescPackage
+	Price       string   `validate:"required,min=1,max=8,numeric,price"`
+	Sessions    string   `validate:"required,min=1,max=3,number"`
+	Expiration  string   `validate:"omitempty,max=4,number"`
+	ServiceIDs  []string `validate:"required,min=1,max=50,uuids"`
+}
+
+//PackageBuyForm : form for a client buying a session package
+type PackageBuyForm struct {
+	EmailForm
+	NameForm
+	ID    string `validate:"required,uuid_rfc4122"`
+	Phone string `validate:"omitempty,phone"`
+}
+
+//PaymentForm : form for a payment, where the price is computed if there are line items
 type PaymentForm struct {
 	EmailForm
 	NameForm
-	Phone           string `validate:"omitempty,phone"`
-	Price           string `validate:"required,min=1,max=5,numeric,price"`
-	Description     string `validate:"omitempty,min=3,max=200"` //LenDescPayment
+	Phone           string             `validate:"omitempty,phone"`
+	Price           string             `validate:"required_without=Items,omitempty,min=1,max=5,numeric,price"`
+	Description     string             `validate:"omitempty,min=3,max=200"` //LenDescPayment
+	Items           []*PaymentItemForm `validate:"omitempty,max=50,dive"`   //LenPaymentItems
+	TaxRate         string             `validate:"omitempty,numeric,taxRate"`
 	ClientInitiated bool
 	DirectCapture   bool
+	GiftCard        bool
+}
+
+//PaymentReminderForm : form for the days after an invoice when payment reminders are sent, with none disabling the reminders
+type PaymentReminderForm struct {
+	ReminderDays string `validate:"omitempty,max=50,reminderDays"`
+}
+
+//TipPercentForm : form for the tip percentages offered to clients, with none disabling tips
+type TipPercentForm struct {
+	TipPercents string `validate:"omitempty,max=50,tipPercents"`
+}
+
+//TipForm : form for a tip added by a client, either as a percentage or a custom amount
+type TipForm struct {
+	Tip        string `validate:"required_without=TipPercent,omitempty,max=8,numeric,price"`
+	TipPercent string `validate:"required_without=Tip,omitempty,tipPercent"`
+}
+
+//PaymentItemForm : form for a line item on a payment
+type PaymentItemForm struct {
+	ItemType     string `validate:"required,paymentItemType"`
+	ItemDesc     string `validate:"required,min=1,max=100"` //LenDescPaymentItem
+	ItemQuantity string `validate:"required,numeric,quantity"`
+	ItemPrice    string `validate:"required,numeric,price"`
+}
+
+//TaxRateForm : form for adding a sales tax rate
+type TaxRateForm struct {
+	Name    string `validate:"required,min=2,max=50"`  //LenName
+	Region  string `validate:"omitempty,min=2,max=50"` //LenName
+	TaxRate string `validate:"required,numeric,taxRate"`
+}
+
+//RefundForm : form for a refund
+type RefundForm struct {
+	Price       string `validate:"required,min=1,max=5,numeric,price"`
+	Description string `validate:"omitempty,min=3,max=200"` //LenDescPayment
 }
 
 //PasswordForm : form for a password
@@ -231,22 +331,53 @@ type TextLongForm struct {
 //ServiceForm : form for creating a service
 type ServiceForm struct {
 	ApptOnly bool
+	Class    bool
 	NameForm
+	CancelCutoff       string `validate:"required,min=1,max=2,numeric,svcCancelCutoff"`
+	CancelCutoffUnit   string `validate:"required,svcPaddingUnit"`
+	CancelFee          string `validate:"required,min=1,max=5,numeric,svcFee=CancelFeeType"`
+	CancelFeeType      string `validate:"required,svcFeeType"`
+	CancelFeeWindow    string `validate:"required,min=1,max=3,numeric,svcCancelFeeWindow"`
+	Capacity           string `validate:"required,min=1,max=3,numeric,svcCapacity"`
+	Deposit            string `validate:"required,min=1,max=5,numeric,svcFee=DepositType"`
+	DepositType        string `validate:"required,svcFeeType"`
 	Description        string `validate:"required,min=3,max=200"` //LenDescSvc
 	Duration           string `validate:"required,min=1,max=5,numeric,durationSvc"`
 	EnableZoom         bool
+	Horizon            string `validate:"required,min=1,max=3,numeric,svcHorizon"`
 	Interval           string `validate:"required,min=1,max=2,numeric,svcInterval"`
 	Location           string `validate:"svcLoc=LocationType,omitempty,min=2,max=100"` //LenLocation
 	LocationType       string `validate:"required,svcLocType"`
+	NoShowFee          string `validate:"required,min=1,max=5,numeric,svcFee=NoShowFeeType"`
+	NoShowFeeType      string `validate:"required,svcFeeType"`
 	Note               string `validate:"omitempty,min=3,max=200"` //LenNoteSvc
 	Padding            string `validate:"required,min=1,max=3,numeric,svcPadding"`
 	PaddingInitial     string `validate:"required,min=1,max=2,numeric,svcPaddingInitial"`
 	PaddingInitialUnit string `validate:"required,svcPaddingUnit"`
+	PayToBook          bool
 	Price              string `validate:"required,min=1,max=5,numeric,price"`
 	PriceType          string `validate:"required,priceType"`
+	TaxExempt          bool
 	URLVideo           string `validate:"omitempty,min=6,max=100,url,urlVideo"` //LenURL
 }
 
+//ScheduleExceptionForm : form for adding an exception to the schedule
+type ScheduleExceptionForm struct {
+	Annual   bool
+	Closed   bool
+	Date     string `validate:"required,date"`
+	Name     string `validate:"omitempty,min=2,max=50"` //LenName
+	Time     string `validate:"required_without=Closed,omitempty,min=1,time"`
+	Duration string `validate:"required_without=Closed,omitempty,min=1,max=4,numeric,durationScheduleStr"`
+}
+
+//ScheduleSeasonForm : form for adding a seasonal schedule
+type ScheduleSeasonForm struct {
+	Name  string `validate:"required,min=2,max=50"` //LenName
+	Start string `validate:"required,date"`
+	End   string `validate:"required,date"`
+}
+
 //ScheduleForm : form for defining a schedule
 type ScheduleForm struct {
 	Time     string `validate:"required,min=1,time"`
@@ -295,6 +426,14 @@ type UserSignUpForm struct {
 	UserForm
 }
 
+//WaitlistForm : form for joining a waitlist
+type WaitlistForm struct {
+	ClientForm
+	Start  string `validate:"required,date"`
+	End    string `validate:"required,date,dateGTE=Start"`
+	UserID string `validate:"omitempty,uuid_rfc4122"`
+}
+
 //ZelleIDForm : form for a Zelle id
 type ZelleIDForm struct {
 	ZelleID string `validate:"required,phone|email,max=50"` //LenEmail
@@ -0,0 +1,534 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+//gift card db tables
+const (
+	dbTableGiftCard        = "gift_card"
+	dbTableGiftCardAttempt = "gift_card_attempt"
+	dbTableGiftCardEntry   = "gift_card_entry"
+)
+
+//gift card constants
+const (
+	giftCardAttemptsMax    = 5
+	giftCardAttemptsPeriod = time.Hour
+	giftCardCodeLetters    = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" //32 letters, excluding those easily confused, to evenly map a random byte
+	giftCardCodeRetries    = 5
+)
+
+//GiftCard : definition of a gift card purchased from a provider
+type GiftCard struct {
+	ID         *uuid.UUID `json:"-"`
+	ProviderID *uuid.UUID `json:"-"`
+	PaymentID  *uuid.UUID `json:"-"`
+	Code       string     `json:"-"`
+	Amount     int        `json:"-"` //non-decimal, in the minor unit of the currency
+	Balance    int        `json:"-"` //non-decimal, in the minor unit of the currency
+	Activated  *time.Time `json:"-"`
+	Created    time.Time  `json:"-"`
+	Name       string     `json:"Name"`
+	Email      string     `json:"Email"`
+	Currency   Currency   `json:"Currency"`
+}
+
+//GetCurrency : get the currency
+func (g *GiftCard) GetCurrency() Currency {
+	if g.Currency == "" {
+		return CurrencyDefault
+	}
+	return g.Currency
+}
+
+//IsActive : check if the gift card has been paid for
+func (g *GiftCard) IsActive() bool {
+	return g.Activated != nil
+}
+
+//FormatAmount : format the purchased amount
+func (g *GiftCard) FormatAmount() string {
+	return g.GetCurrency().FormatAmountMinorUnits(g.Amount)
+}
+
+//FormatBalance : format the remaining balance
+func (g *GiftCard) FormatBalance() string {
+	return g.GetCurrency().FormatAmountMinorUnits(g.Balance)
+}
+
+//FormatCreated : format the date the gift card was purchased
+func (g *GiftCard) FormatCreated(timeZone string) string {
+	return FormatDateLocal(g.Created, timeZone)
+}
+
+//AllowRefundAmount : check if the amount can be refunded from the purchase, which is limited to the remaining balance
+func (g *GiftCard) AllowRefundAmount(amount int) bool {
+	return amount <= g.Balance
+}
+
+//FormatStatus : format the status of the gift card
+func (g *GiftCard) FormatStatus() string {
+	if !g.IsActive() {
+		return "Unpaid"
+	}
+	if g.Balance <= 0 {
+		return "Used"
+	}
+	return "Active"
+}
+
+//GiftCardEntry : definition of an entry in the balance ledger of a gift card
+type GiftCardEntry struct {
+	ID                *uuid.UUID
+	GiftCardID        *uuid.UUID
+	PaymentID         *uuid.UUID
+	Amount            int //non-decimal, in the minor unit of the currency, negative for redemptions
+	Refund            bool
+	Created           time.Time
+	PaymentType       PaymentType
+	PaymentFriendlyID string
+}
+
+//FormatAmount : format the amount
+func (g *GiftCardEntry) FormatAmount(currency Currency) string {
+	return currency.FormatAmountMinorUnits(g.Amount)
+}
+
+//FormatCreated : format the date of the entry
+func (g *GiftCardEntry) FormatCreated(timeZone string) string {
+	return FormatDateTimeLocal(g.Created, timeZone)
+}
+
+//GenGiftCardCode : generate a random gift card code
+func GenGiftCardCode() (string, error) {
+	bytes, err := CreateRandomBytes(LenCodeGiftCard)
+	if err != nil {
+		return "", errors.Wrap(err, "random bytes")
+	}
+	code := make([]byte, len(bytes))
+	for i, b := range bytes {
+		code[i] = giftCardCodeLetters[int(b)%len(giftCardCodeLetters)]
+	}
+	return string(code), nil
+}
+
+//compute the amount of the balance to apply to an invoice
+func computeGiftCardRedemption(balance int, amount int) int {
+	return Max(Min(balance, amount), 0)
+}
+
+//compute the entries reversing the ledger entries of a refunded payment, where a purchase is reversed by the amount refunded and a redemption is restored once the invoice is fully refunded
+func computeGiftCardReversals(payment *Payment, entries []*GiftCardEntry) []*GiftCardEntry {
+	refunded := payment.ComputeAmountRefunded()
+	reversals := make([]*GiftCardEntry, 0, len(entries))
+	for _, entry := range entries {
+		amount := 0
+		if entry.Amount > 0 {
+			amount = -Min(refunded, entry.Amount)
+		} else if payment.IsRefundedFull() {
+			amount = -entry.Amount
+		}
+		if amount == 0 {
+			continue
+		}
+		reversals = append(reversals, &GiftCardEntry{
+			GiftCardID: entry.GiftCardID,
+			PaymentID:  entry.PaymentID,
+			Amount:     amount,
+			Refund:     true,
+		})
+	}
+	return reversals
+}
+
+//create the statement to load a gift card, computing the balance from the ledger, where a purchase only counts once paid, a redemption only while the invoice exists, and a refund reverses either
+func giftCardQueryCreate(whereStmt string) string {
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(g.id),BIN_TO_UUID(g.provider_id),BIN_TO_UUID(g.payment_id),g.code,g.amount,g.created,g.data,p.captured,(SELECT COALESCE(SUM(e.amount),0) FROM %s e INNER JOIN %s ep ON ep.id=e.payment_id WHERE e.deleted=0 AND e.gift_card_id=g.id AND ep.deleted=0 AND (e.amount<0 OR ep.captured IS NOT NULL)) FROM %s g INNER JOIN %s p ON p.id=g.payment_id WHERE g.deleted=0 AND p.deleted=0 AND %s ORDER BY g.created DESC", dbTableGiftCardEntry, dbTablePayment, dbTableGiftCard, dbTablePayment, whereStmt)
+	return stmt
+}
+
+//parse a gift card
+func giftCardQueryParse(rowFn ScanFn) (*GiftCard, error) {
+	//read the row
+	var idStr string
+	var providerIDStr string
+	var paymentIDStr string
+	var code string
+	var amount int
+	var created time.Time
+	var dataStr string
+	var captured sql.NullTime
+	var balance int
+	err := rowFn(&idStr, &providerIDStr, &paymentIDStr, &code, &amount, &created, &dataStr, &captured, &balance)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "scan gift card")
+	}
+
+	//parse the uuids
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse uuid gift card id")
+	}
+	providerID, err := uuid.FromString(providerIDStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse uuid provider id")
+	}
+	paymentID, err := uuid.FromString(paymentIDStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse uuid payment id")
+	}
+
+	//unmarshal the data
+	var card GiftCard
+	err = json.Unmarshal([]byte(dataStr), &card)
+	if err != nil {
+		return nil, errors.Wrap(err, "unjson gift card")
+	}
+	card.ID = &id
+	card.ProviderID = &providerID
+	card.PaymentID = &paymentID
+	card.Code = code
+	card.Amount = amount
+	card.Balance = balance
+	card.Created = created
+	if captured.Valid {
+		card.Activated = &captured.Time
+	}
+	return &card, nil
+}
+
+//loadGiftCard : load a gift card
+func loadGiftCard(ctx context.Context, db *DB, whereStmt string, args ...interface{}) (context.Context, *GiftCard, error) {
+	stmt := giftCardQueryCreate(whereStmt)
+	ctx, row, err := db.QueryRow(ctx, stmt, args...)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "query row gift card")
+	}
+	card, err := giftCardQueryParse(row.Scan)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "gift card parse")
+	}
+	return ctx, card, nil
+}
+
+//LoadGiftCardByProviderIDAndID : load a gift card by the id
+func LoadGiftCardByProviderIDAndID(ctx context.Context, db *DB, providerID *uuid.UUID, id *uuid.UUID) (context.Context, *GiftCard, error) {
+	whereStmt := "g.provider_id=UUID_TO_BIN(?) AND g.id=UUID_TO_BIN(?)"
+	return loadGiftCard(ctx, db, whereStmt, providerID, id)
+}
+
+//LoadGiftCardByPaymentID : load the gift card purchased with a payment
+func LoadGiftCardByPaymentID(ctx context.Context, db *DB, paymentID *uuid.UUID) (context.Context, *GiftCard, error) {
+	whereStmt := "g.payment_id=UUID_TO_BIN(?)"
+	return loadGiftCard(ctx, db, whereStmt, paymentID)
+}
+
+//SavePaymentGiftCard : save the payment for the purchase of a gift card, issuing the gift card with the payment amount as the opening balance
+func SavePaymentGiftCard(ctx context.Context, db *DB, payment *Payment, card *GiftCard) (context.Context, error) {
+	ctx, err := db.ProcessTx(ctx, "save payment gift card", func(ctx context.Context, db *DB) (context.Context, error) {
+		//save the payment
+		ctx, err := SavePayment(ctx, db, payment, payment.DirectCapture, false)
+		if err != nil {
+			return ctx, errors.Wrap(err, "save payment")
+		}
+
+		//generate an id
+		id, err := uuid.NewV4()
+		if err != nil {
+			return ctx, errors.Wrap(err, "new uuid gift card")
+		}
+		card.ID = &id
+		card.ProviderID = payment.ProviderID
+		card.PaymentID = payment.ID
+		card.Amount = payment.Amount
+		card.Currency = payment.GetCurrency()
+
+		//json encode the data
+		dataJSON, err := json.Marshal(card)
+		if err != nil {
+			return ctx, errors.Wrap(err, "json gift card")
+		}
+
+		//save the gift card, generating a new code if the code is already used by the provider
+		stmt := fmt.Sprintf("INSERT INTO %s(id,provider_id,payment_id,code,amount,data) VALUES (UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),?,?,?)", dbTableGiftCard)
+		for i := 0; ; i++ {
+			card.Code, err = GenGiftCardCode()
+			if err != nil {
+				return ctx, errors.Wrap(err, "gen gift card code")
+			}
+			ctx, _, err = db.Exec(ctx, stmt, card.ID, card.ProviderID, card.PaymentID, card.Code, card.Amount, dataJSON)
+			if err == nil {
+				break
+			}
+			if !IsErrDuplicate(err) || i >= giftCardCodeRetries {
+				return ctx, errors.Wrap(err, "insert gift card")
+			}
+		}
+
+		//record the opening balance
+		ctx, err = saveGiftCardEntry(ctx, db, &GiftCardEntry{GiftCardID: card.ID, PaymentID: payment.ID, Amount: card.Amount})
+		if err != nil {
+			return ctx, errors.Wrap(err, "save gift card entry")
+		}
+		return ctx, nil
+	})
+	if err != nil {
+		return ctx, errors.Wrap(err, "save payment gift card")
+	}
+	return ctx, nil
+}
+
+//save an entry in the balance ledger of a gift card, where the reversal of an entry replaces any previous reversal
+func saveGiftCardEntry(ctx context.Context, db *DB, entry *GiftCardEntry) (context.Context, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return ctx, errors.Wrap(err, "new uuid gift card entry")
+	}
+	stmt := fmt.Sprintf("INSERT INTO %s(id,gift_card_id,payment_id,amount,refund) VALUES (UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),?,?) ON DUPLICATE KEY UPDATE amount=VALUES(amount),deleted=0", dbTableGiftCardEntry)
+	ctx, _, err = db.Exec(ctx, stmt, &id, entry.GiftCardID, entry.PaymentID, entry.Amount, entry.Refund)
+	if err != nil {
+		return ctx, errors.Wrap(err, "insert gift card entry")
+	}
+	return ctx, nil
+}
+
+//reverse the gift card ledger entries for a refunded payment
+func reverseGiftCardEntries(ctx context.Context, db *DB, payment *Payment) (context.Context, error) {
+	ctx, logger := GetLogger(ctx)
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(gift_card_id),amount FROM %s WHERE deleted=0 AND refund=0 AND payment_id=UUID_TO_BIN(?)", dbTableGiftCardEntry)
+	ctx, rows, err := db.Query(ctx, stmt, payment.ID)
+	if err != nil {
+		return ctx, errors.Wrap(err, "select gift card entries")
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Warnw("rows close", "error", err)
+		}
+	}()
+
+	//read the rows
+	entries := make([]*GiftCardEntry, 0, 1)
+	var cardIDStr string
+	var amount int
+	for rows.Next() {
+		err := rows.Scan(&cardIDStr, &amount)
+		if err != nil {
+			return ctx, errors.Wrap(err, "rows scan gift card entries")
+		}
+		cardID, err := uuid.FromString(cardIDStr)
+		if err != nil {
+			return ctx, errors.Wrap(err, "parse uuid gift card id")
+		}
+		entries = append(entries, &GiftCardEntry{
+			GiftCardID: &cardID,
+			PaymentID:  payment.ID,
+			Amount:     amount,
+		})
+	}
+	err = rows.Close()
+	if err != nil {
+		return ctx, errors.Wrap(err, "rows close gift card entries")
+	}
+
+	//save the reversals
+	for _, reversal := range computeGiftCardReversals(payment, entries) {
+		ctx, err = saveGiftCardEntry(ctx, db, reversal)
+		if err != nil {
+			return ctx, errors.Wrap(err, "save gift card entry")
+		}
+	}
+	return ctx, nil
+}
+
+//RedeemGiftCard : apply the balance of a gift card as a credit on an invoice, returning the amount applied or 0 if the gift card cannot be used
+func RedeemGiftCard(ctx context.Context, db *DB, payment *Payment, code string) (context.Context, int, error) {
+	applied := 0
+	ctx, err := db.ProcessTx(ctx, "redeem gift card", func(ctx context.Context, db *DB) (context.Context, error) {
+		//lock the gift card
+		stmt := fmt.Sprintf("SELECT BIN_TO_UUID(id) FROM %s WHERE deleted=0 AND provider_id=UUID_TO_BIN(?) AND code=UPPER(?) FOR UPDATE", dbTableGiftCard)
+		ctx, row, err := db.QueryRow(ctx, stmt, payment.ProviderID, code)
+		if err != nil {
+			return ctx, errors.Wrap(err, "query row gift card lock")
+		}
+		var idStr string
+		err = row.Scan(&idStr)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ctx, nil
+			}
+			return ctx, errors.Wrap(err, "select gift card lock")
+		}
+		id, err := uuid.FromString(idStr)
+		if err != nil {
+			return ctx, errors.Wrap(err, "parse uuid gift card id")
+		}
+
+		//load the balance
+		ctx, card, err := LoadGiftCardByProviderIDAndID(ctx, db, payment.ProviderID, &id)
+		if err != nil {
+			return ctx, errors.Wrap(err, "load gift card")
+		}
+		if card == nil || !card.IsActive() || card.Balance <= 0 || card.GetCurrency() != payment.GetCurrency() {
+			return ctx, nil
+		}
+
+		//check if the gift card has already been applied to the invoice
+		stmt = fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE gift_card_id=UUID_TO_BIN(?) AND payment_id=UUID_TO_BIN(?)", dbTableGiftCardEntry)
+		ctx, row, err = db.QueryRow(ctx, stmt, card.ID, payment.ID)
+		if err != nil {
+			return ctx, errors.Wrap(err, "query row gift card entry")
+		}
+		var count int
+		err = row.Scan(&count)
+		if err != nil {
+			return ctx, errors.Wrap(err, "select gift card entry")
+		}
+		if count > 0 {
+			return ctx, nil
+		}
+
+		//apply the balance up to the amount owed
+		amount := computeGiftCardRedemption(card.Balance, payment.Amount)
+		if amount == 0 {
+			return ctx, nil
+		}
+		ctx, err = saveGiftCardEntry(ctx, db, &GiftCardEntry{GiftCardID: card.ID, PaymentID: payment.ID, Amount: -amount})
+		if err != nil {
+			return ctx, errors.Wrap(err, "save gift card entry")
+		}
+		payment.ApplyCredit(fmt.Sprintf("Gift Card %s", card.Code), amount)
+		ctx, err = UpdatePaymentAmount(ctx, db, payment)
+		if err != nil {
+			return ctx, errors.Wrap(err, "update payment amount")
+		}
+		applied = amount
+		return ctx, nil
+	})
+	if err != nil {
+		return ctx, 0, errors.Wrap(err, "redeem gift card")
+	}
+	return ctx, applied, nil
+}
+
+//ListGiftCardsByProviderID : list the gift cards issued by a provider
+func ListGiftCardsByProviderID(ctx context.Context, db *DB, providerID *uuid.UUID) (context.Context, []*GiftCard, error) {
+	ctx, logger := GetLogger(ctx)
+	stmt := giftCardQueryCreate("g.provider_id=UUID_TO_BIN(?)")
+	ctx, rows, err := db.Query(ctx, stmt, providerID)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "select gift cards")
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Warnw("rows close", "error", err)
+		}
+	}()
+
+	//read the gift cards
+	cards := make([]*GiftCard, 0, 2)
+	for rows.Next() {
+		card, err := giftCardQueryParse(rows.Scan)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "gift card parse")
+		}
+		cards = append(cards, card)
+	}
+	return ctx, cards, nil
+}
+
+//ListGiftCardEntriesByGiftCardID : list the balance ledger of a gift card, excluding redemptions on deleted invoices
+func ListGiftCardEntriesByGiftCardID(ctx context.Context, db *DB, cardID *uuid.UUID) (context.Context, []*GiftCardEntry, error) {
+	ctx, logger := GetLogger(ctx)
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(e.id),BIN_TO_UUID(e.payment_id),e.amount,e.refund,e.created,p.type,p.friendly_id FROM %s e INNER JOIN %s p ON p.id=e.payment_id WHERE e.deleted=0 AND p.deleted=0 AND e.gift_card_id=UUID_TO_BIN(?) ORDER BY e.created", dbTableGiftCardEntry, dbTablePayment)
+	ctx, rows, err := db.Query(ctx, stmt, cardID)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "select gift card entries")
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Warnw("rows close", "error", err)
+		}
+	}()
+
+	//read the rows
+	entries := make([]*GiftCardEntry, 0, 2)
+	var idStr string
+	var paymentIDStr string
+	var amount int
+	var refundBit string
+	var created time.Time
+	var paymentType int
+	var paymentFriendlyID string
+	for rows.Next() {
+		err := rows.Scan(&idStr, &paymentIDStr, &amount, &refundBit, &created, &paymentType, &paymentFriendlyID)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "rows scan gift card entries")
+		}
+
+		//parse the uuids
+		id, err := uuid.FromString(idStr)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "parse uuid")
+		}
+		paymentID, err := uuid.FromString(paymentIDStr)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "parse uuid payment id")
+		}
+		entries = append(entries, &GiftCardEntry{
+			ID:                &id,
+			GiftCardID:        cardID,
+			PaymentID:         &paymentID,
+			Amount:            amount,
+			Refund:            refundBit == "\x01",
+			Created:           created,
+			PaymentType:       PaymentType(paymentType),
+			PaymentFriendlyID: paymentFriendlyID,
+		})
+	}
+	return ctx, entries, nil
+}
+
+//CountGiftCardAttempts : count the invalid gift card codes recently entered for the provider by the email or from the ip address
+func CountGiftCardAttempts(ctx context.Context, db *DB, providerID *uuid.UUID, email string, ip string, now time.Time) (context.Context, int, error) {
+	stmt := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE created>? AND ((provider_id=UUID_TO_BIN(?) AND email=? AND email<>'') OR ip=?)", dbTableGiftCardAttempt)
+	ctx, row, err := db.QueryRow(ctx, stmt, now.Add(-giftCardAttemptsPeriod), providerID, email, ip)
+	if err != nil {
+		return ctx, 0, errors.Wrap(err, "query row gift card attempts")
+	}
+
+	//read the row
+	var count int
+	err = row.Scan(&count)
+	if err != nil {
+		return ctx, 0, errors.Wrap(err, "select gift card attempts")
+	}
+	return ctx, count, nil
+}
+
+//SaveGiftCardAttempt : record an invalid gift card code entered for the provider
+func SaveGiftCardAttempt(ctx context.Context, db *DB, providerID *uuid.UUID, email string, ip string, now time.Time) (context.Context, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return ctx, errors.Wrap(err, "new uuid gift card attempt")
+	}
+	stmt := fmt.Sprintf("INSERT INTO %s(id,provider_id,email,ip,created) VALUES (UUID_TO_BIN(?),UUID_TO_BIN(?),?,?,?)", dbTableGiftCardAttempt)
+	ctx, _, err = db.Exec(ctx, stmt, &id, providerID, email, ip, now)
+	if err != nil {
+		return ctx, errors.Wrap(err, "insert gift card attempt")
+	}
+	return ctx, nil
+}
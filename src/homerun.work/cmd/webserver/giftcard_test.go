@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+func TestGenGiftCardCode(t *testing.T) {
+	codes := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		code, err := GenGiftCardCode()
+		if err != nil {
+			t.Fatalf("gen gift card code: %v", err)
+		}
+		if len(code) != LenCodeGiftCard {
+			t.Errorf("code %s: length %d, want %d", code, len(code), LenCodeGiftCard)
+		}
+		for _, c := range code {
+			if !strings.ContainsRune(giftCardCodeLetters, c) {
+				t.Errorf("code %s: invalid letter %c", code, c)
+			}
+		}
+		if codes[code] {
+			t.Errorf("code %s: duplicate", code)
+		}
+		codes[code] = true
+	}
+}
+
+func TestComputeGiftCardRedemption(t *testing.T) {
+	tests := []struct {
+		name    string
+		balance int
+		amount  int
+		want    int
+	}{
+		{"balance covers the invoice", 5000, 3000, 3000},
+		{"balance covers part of the invoice", 2000, 3000, 2000},
+		{"balance equals the invoice", 3000, 3000, 3000},
+		{"no balance", 0, 3000, 0},
+		{"negative balance after a refund", -500, 3000, 0},
+		{"nothing owed", 5000, 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := computeGiftCardRedemption(test.balance, test.amount)
+			if got != test.want {
+				t.Errorf("got %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestComputeGiftCardReversals(t *testing.T) {
+	cardID := uuid.Must(uuid.NewV4())
+	paymentID := uuid.Must(uuid.NewV4())
+	purchase := &GiftCardEntry{GiftCardID: &cardID, PaymentID: &paymentID, Amount: 5000}
+	redemption := &GiftCardEntry{GiftCardID: &cardID, PaymentID: &paymentID, Amount: -2000}
+	tests := []struct {
+		name    string
+		amount  int
+		refunds []int
+		entries []*GiftCardEntry
+		want    []int
+	}{
+		{"purchase not refunded", 5000, nil, []*GiftCardEntry{purchase}, []int{}},
+		{"purchase partially refunded", 5000, []int{1500}, []*GiftCardEntry{purchase}, []int{-1500}},
+		{"purchase refunded twice", 5000, []int{1500, 1000}, []*GiftCardEntry{purchase}, []int{-2500}},
+		{"purchase fully refunded", 5000, []int{5000}, []*GiftCardEntry{purchase}, []int{-5000}},
+		{"invoice partially refunded", 3000, []int{1000}, []*GiftCardEntry{redemption}, []int{}},
+		{"invoice fully refunded", 3000, []int{3000}, []*GiftCardEntry{redemption}, []int{2000}},
+		{"invoice fully refunded in parts", 3000, []int{1000, 2000}, []*GiftCardEntry{redemption}, []int{2000}},
+		{"invoice without a gift card", 3000, []int{3000}, []*GiftCardEntry{}, []int{}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payment := &Payment{
+				ID:     &paymentID,
+				Amount: test.amount,
+			}
+			for _, amount := range test.refunds {
+				payment.Refunds = append(payment.Refunds, &PaymentRefund{Amount: amount})
+			}
+			got := computeGiftCardReversals(payment, test.entries)
+			if len(got) != len(test.want) {
+				t.Fatalf("got %d reversals, want %d", len(got), len(test.want))
+			}
+			for i, reversal := range got {
+				if reversal.Amount != test.want[i] {
+					t.Errorf("reversal %d: got %d, want %d", i, reversal.Amount, test.want[i])
+				}
+				if !reversal.Refund {
+					t.Errorf("reversal %d: not marked as a refund", i)
+				}
+				if reversal.GiftCardID != test.entries[i].GiftCardID || reversal.PaymentID != test.entries[i].PaymentID {
+					t.Errorf("reversal %d: wrong gift card or payment", i)
+				}
+			}
+		})
+	}
+}
+
+func TestGiftCardAllowRefundAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		balance int
+		amount  int
+		want    bool
+	}{
+		{"unused", 5000, 5000, true},
+		{"partially used", 3000, 3000, true},
+		{"more than the balance", 3000, 3001, false},
+		{"fully used", 0, 100, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			card := &GiftCard{Amount: 5000, Balance: test.balance}
+			got := card.AllowRefundAmount(test.amount)
+			if got != test.want {
+				t.Errorf("got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRedeemGiftCard(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	tests := []struct {
+		name      string
+		found     bool
+		activated bool
+		currency  Currency
+		balance   int
+		applied   int64
+		want      int
+	}{
+		{"balance covers the invoice", true, true, CurrencyUSD, 5000, 0, 3000},
+		{"balance covers part of the invoice", true, true, CurrencyUSD, 2000, 0, 2000},
+		{"balance used", true, true, CurrencyUSD, 0, 0, 0},
+		{"already applied to the invoice", true, true, CurrencyUSD, 5000, 1, 0},
+		{"not paid for", true, false, CurrencyUSD, 5000, 0, 0},
+		{"another currency", true, true, CurrencyEUR, 5000, 0, 0},
+		{"unknown code", false, true, CurrencyUSD, 5000, 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, s := newFakeDB(t)
+			providerID := newFakeID(t)
+			payment := &Payment{
+				ID:          newFakeID(t),
+				ProviderID:  providerID,
+				SecondaryID: newFakeID(t),
+				Type:        PaymentTypeBooking,
+				Amount:      3000,
+				Currency:    CurrencyUSD,
+			}
+			card := &GiftCard{
+				ID:         newFakeID(t),
+				ProviderID: providerID,
+				PaymentID:  newFakeID(t),
+				Code:       "ABCDEFGH",
+				Amount:     5000,
+				Balance:    test.balance,
+				Created:    now,
+				Currency:   test.currency,
+			}
+			if test.activated {
+				card.Activated = &now
+			}
+			if test.found {
+				db.onRows("code=UPPER(?) FOR UPDATE", []driver.Value{card.ID.String()})
+			} else {
+				db.onRows("code=UPPER(?) FOR UPDATE")
+			}
+			db.onRows("g.provider_id=UUID_TO_BIN(?) AND g.id=UUID_TO_BIN(?)", fakeGiftCardRow(t, card))
+			db.onRows(fmt.Sprintf("SELECT COUNT(*) FROM %s", dbTableGiftCardEntry), []driver.Value{test.applied})
+			db.onExec(fmt.Sprintf("INSERT INTO %s", dbTableGiftCardEntry), 1)
+			db.onExec(fmt.Sprintf("UPDATE %s SET amount=?,data=?", dbTablePayment), 1)
+
+			_, got, err := RedeemGiftCard(context.Background(), s.getDB(), payment, strings.ToLower(card.Code))
+			if err != nil {
+				t.Fatalf("redeem gift card: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("got %d, want %d", got, test.want)
+			}
+
+			//the balance is only debited once, reducing the amount owed on the invoice
+			entries := db.executed(fmt.Sprintf("INSERT INTO %s", dbTableGiftCardEntry))
+			updates := db.executed(fmt.Sprintf("UPDATE %s SET amount=?,data=?", dbTablePayment))
+			if test.want == 0 {
+				if len(entries) != 0 || len(updates) != 0 || payment.Amount != 3000 {
+					t.Errorf("got %d entries and %d updates for an amount of %d, want none", len(entries), len(updates), payment.Amount)
+				}
+				return
+			}
+			if len(entries) != 1 || len(updates) != 1 {
+				t.Fatalf("got %d entries and %d updates, want 1", len(entries), len(updates))
+			}
+			if amount := entries[0].args[3].Value; amount != -test.want {
+				t.Errorf("entry amount %v, want %d", amount, -test.want)
+			}
+			if payment.Amount != 3000-test.want || updates[0].args[0].Value != payment.Amount {
+				t.Errorf("payment amount %d, want %d", payment.Amount, 3000-test.want)
+			}
+			if len(db.executed("COMMIT")) != 1 {
+				t.Errorf("redemption not committed")
+			}
+		})
+	}
+}
@@ -653,14 +653,9 @@ func TerminateEventRecurringGoogle(ctx context.Context, calendarID *string, even
 	}
 
 	//update the recurrence rules and add the "until" to the rules
-	rules := event.Recurrence
-	for i, rule := range rules {
-		parsedRule, err := ParseRecurrenceRule(rule)
-		if err != nil {
-			return nil, errors.Wrap(err, fmt.Sprintf("parse recurrence rule: %s", rule))
-		}
-		parsedRule.Until = endDate
-		rules[i] = parsedRule.RecurString()
+	rules, err := TerminateRecurrenceRules(event.Recurrence, endDate)
+	if err != nil {
+		return nil, errors.Wrap(err, "terminate recurrence rules")
 	}
 	event.Recurrence = rules
 
@@ -222,6 +222,8 @@ func (s *Server) handleAPIEmail() http.HandlerFunc {
 			ctx, subject, body, err = s.createEmailBookingConfirmClient(ctx, bookUI)
 		case MsgTypeBookingEditClient:
 			ctx, subject, body, err = s.createEmailBookingEditClient(ctx, bookUI)
+		case MsgTypeBookingEditProvider:
+			ctx, subject, body, err = s.createEmailBookingEditProvider(ctx, bookUI)
 		case MsgTypeBookingNewClient:
 			ctx, subject, body, err = s.createEmailBookingNewClient(ctx, bookUI, isClient)
 		case MsgTypeBookingNewProvider:
@@ -254,10 +256,14 @@ func (s *Server) handleAPIEmail() http.HandlerFunc {
 			ctx, subject, body, err = s.createEmailPaymentClient(ctx, providerUI, paymentUI)
 		case MsgTypePaymentProvider:
 			ctx, subject, body, err = s.createEmailPaymentProvider(ctx, providerUI, paymentUI)
+		case MsgTypePaymentReminderClient:
+			ctx, subject, body, err = s.createEmailPaymentReminderClient(ctx, providerUI, paymentUI)
 		case MsgTypePwdReset:
 			ctx, subject, body, err = s.createEmailPwdReset(ctx, "url")
 		case MsgTypeProviderUserInvite:
 			ctx, subject, body, err = s.createEmailProviderUserInvite(ctx, providerUI, "email")
+		case MsgTypeRefundClient:
+			ctx, subject, body, err = s.createEmailRefundClient(ctx, providerUI, paymentUI)
 		case MsgTypeWelcome:
 			ctx, subject, body, err = s.createEmailWelcome(ctx, providerUI)
 		default:
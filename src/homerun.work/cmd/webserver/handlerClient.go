@@ -57,6 +57,21 @@ func (s *Server) loadServiceClient(w http.ResponseWriter, r *http.Request, tpl *
 	return svcUI, true
 }
 
+//load the service from the url for a booking, rejecting a service not found for the provider or other than that of the booking
+func (s *Server) loadServiceClientBook(w http.ResponseWriter, r *http.Request, tpl *template.Template, data templateData, provider *providerUI, book *bookingUI) (*serviceUI, bool) {
+	ctx, logger := GetLogger(s.getCtx(r))
+	svc, ok := s.loadServiceClient(w, r.WithContext(ctx), tpl, data, provider)
+	if !ok {
+		return nil, false
+	}
+	if svc.ID == nil || book.Provider == nil || book.Service == nil || book.Provider.ID.String() != provider.ID.String() || book.Service.ID.String() != svc.ID.String() {
+		logger.Warnw("booking service mismatch", "id", book.ID, "providerId", provider.ID, "serviceId", svc.ID)
+		s.redirectError(w, r.WithContext(ctx), Err)
+		return nil, false
+	}
+	return svc, true
+}
+
 //load a client web template
 func (s *Server) loadWebTemplateClient(ctx context.Context, templateFile string) *template.Template {
 	files := []string{path.Join(BaseWebTemplatePathClient, "base.html"), path.Join(BaseWebTemplatePathClient, templateFile)}
@@ -93,6 +108,26 @@ func (s *Server) createTemplateDataClient(w http.ResponseWriter, r *http.Request
 	}
 	data[TplParamFaqCount] = count
 
+	//load the package count
+	ctx, count, err = CountSessionPackagesByProviderID(ctx, s.getDB(), provider.ID)
+	if err != nil {
+		logger.Errorw("count session packages", "error", err, "id", provider.ID)
+		data[TplParamErr] = GetErrText(Err)
+		s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+		return nil, nil, nil, false
+	}
+	data[TplParamPackageCount] = count
+
+	//load the membership count
+	ctx, count, err = CountMembershipPlansByProviderID(ctx, s.getDB(), provider.ID)
+	if err != nil {
+		logger.Errorw("count membership plans", "error", err, "id", provider.ID)
+		data[TplParamErr] = GetErrText(Err)
+		s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+		return nil, nil, nil, false
+	}
+	data[TplParamMembershipCount] = count
+
 	//add the errors
 	errs := make(map[string]string)
 	data[TplParamErrs] = errs
@@ -361,6 +396,52 @@ func (s *Server) handleClientBookingSubmit() http.HandlerFunc {
 		}
 		data[TplParamURLPrev] = url
 
+		//load the user if set
+		var providerUser *ProviderUser
+		if userIDStr != "" {
+			userID := uuid.FromStringOrNil(userIDStr)
+			if userID == uuid.Nil {
+				logger.Errorw("invalid user id", "id", userIDStr)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			ctx, providerUser, err = LoadProviderUserForServiceByProviderIDAndServiceIDAndUserID(ctx, s.getDB(), provider.ID, svc.ID, &userID)
+			if err != nil {
+				logger.Errorw("load provider user", "error", err, "id", userID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+		}
+
+		//hold the time while the client completes the booking
+		now := data[TplParamCurrentTime].(time.Time)
+		if svc.IsApptOnly() && !svc.IsClass() {
+			user := provider.User
+			if providerUser != nil {
+				user = providerUser.User
+			}
+			holdID, err := s.GetCookieBookingHold(r.WithContext(ctx))
+			if err != nil {
+				logger.Warnw("get cookie booking hold", "error", err)
+			}
+			hold := CreateBookingHold(holdID, provider.Provider, user, svc.Service, timeFrom, now)
+			ctx, ok, err = SaveBookingHold(ctx, s.getDB(), hold, now)
+			if err != nil {
+				logger.Errorw("save booking hold", "error", err, "id", svc.ID, "from", timeFrom)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			if !ok {
+				data[TplParamErr] = GetErrText(ErrBookingTime)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			s.SetCookieBookingHold(w, hold.ID)
+		}
+
 		//check the method
 		if r.Method == http.MethodGet {
 			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
@@ -411,31 +492,28 @@ func (s *Server) handleClientBookingSubmit() http.HandlerFunc {
 			return
 		}
 
-		//load the user if set
-		var providerUser *ProviderUser
-		if userIDStr != "" {
-			userID := uuid.FromStringOrNil(userIDStr)
-			if userID == uuid.Nil {
-				logger.Errorw("invalid user id", "id", userIDStr)
-				data[TplParamErr] = GetErrText(Err)
-				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
-				return
-			}
-			ctx, providerUser, err = LoadProviderUserForServiceByProviderIDAndServiceIDAndUserID(ctx, s.getDB(), provider.ID, svc.ID, &userID)
+		//create the booking
+		book, ok := s.saveBooking(w, r.WithContext(ctx), tpl, data, errs, provider, providerUser, svc, nil, now, RecurrenceScopeOnce, form, true)
+		if !ok {
+			return
+		}
+
+		//collect the payment required to book
+		if book.IsPaymentPending() {
+			http.Redirect(w, r.WithContext(ctx), book.GetURLPaymentClient(), http.StatusSeeOther)
+			return
+		}
+
+		//request the deposit required to confirm the booking
+		if book.HasDeposit() && book.ComputeDeposit() > 0 && provider.SupportsPaymentOnline() {
+			ctx, _, err = s.savePaymentDeposit(ctx, provider, book, now)
 			if err != nil {
-				logger.Errorw("load provider user", "error", err, "id", userID)
+				logger.Errorw("save payment deposit", "error", err, "id", book.ID)
 				data[TplParamErr] = GetErrText(Err)
 				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
 				return
 			}
 		}
-
-		//create the booking
-		now := data[TplParamCurrentTime].(time.Time)
-		book, ok := s.saveBooking(w, r.WithContext(ctx), tpl, data, errs, provider, providerUser, svc, nil, now, false, form, true)
-		if !ok {
-			return
-		}
 		http.Redirect(w, r.WithContext(ctx), book.GetURLConfirmClient(), http.StatusSeeOther)
 	}
 }
@@ -512,12 +590,19 @@ func (s *Server) handleClientBookingCancel() http.HandlerFunc {
 			return
 		}
 
-		//load the service
-		svc, ok := s.loadServiceClient(w, r.WithContext(ctx), tpl, data, provider)
+		//load the service of the booking
+		svc, ok := s.loadServiceClientBook(w, r.WithContext(ctx), tpl, data, provider, book)
 		if !ok {
 			return
 		}
 
+		//compute any late cancellation fee using the policy agreed to when booking
+		now := data[TplParamCurrentTime].(time.Time)
+		cancelFee := book.ComputeCancelFee(now)
+		if cancelFee > 0 {
+			data[TplParamCancelFee] = book.GetCurrency().FormatAmount(cancelFee)
+		}
+
 		//check the method
 		if r.Method == http.MethodGet {
 			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
@@ -531,31 +616,58 @@ func (s *Server) handleClientBookingCancel() http.HandlerFunc {
 			return
 		}
 
+		//check the cancellation cutoff
+		if !svc.CheckCancelTime(now, book.TimeFrom) {
+			logger.Warnw("booking cancel cutoff", "id", book.ID, "time", book.TimeFrom)
+			data[TplParamErr] = GetErrText(ErrBookingCutoff)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
 		//cancel the booking
-		now := data[TplParamCurrentTime].(time.Time)
-		ok = s.cancelServiceBooking(w, r.WithContext(ctx), tpl, data, errs, provider, svc, book, now, false)
+		ok = s.cancelServiceBooking(w, r.WithContext(ctx), tpl, data, errs, provider, svc, book, now, RecurrenceScopeOnce)
 		if !ok {
 			return
 		}
+
+		//charge the late cancellation fee
+		if cancelFee > 0 {
+			ctx, _, err := s.savePaymentFee(ctx, provider, book, "late cancellation fee", cancelFee, now)
+			if err != nil {
+				logger.Errorw("save payment fee", "error", err, "id", book.ID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+		}
 		ctx = s.setCtxMsg(ctx, MsgBookingCancel)
 		s.invokeHdlrGet(s.handleClientIndex(), w, r.WithContext(ctx))
 	}
 }
 
-//handle the client view booking page
-func (s *Server) handleClientBookingView() http.HandlerFunc {
+//handle the client booking reschedule page
+func (s *Server) handleClientBookingReschedule() http.HandlerFunc {
 	var o sync.Once
 	var tpl *template.Template
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, logger := GetLogger(s.getCtx(r))
 		o.Do(func() {
-			tpl = s.loadWebTemplateClient(ctx, "order-detail.html")
+			tpl = s.loadWebTemplateClient(ctx, "order-reschedule.html")
 		})
 		provider, data, errs, ok := s.createTemplateDataClient(w, r.WithContext(ctx), tpl)
 		if !ok {
 			return
 		}
-		data[TplParamClientView] = r.FormValue(URLParams.Client)
+
+		//read the form
+		dateStr := r.FormValue(URLParams.Date)
+		timeStr := r.FormValue(URLParams.Time)
+		timeZone := r.FormValue(URLParams.TimeZone)
+
+		//default the timezone if not set
+		if timeZone == "" {
+			timeZone = GetCtxTimeZone(ctx)
+		}
 
 		//check for the booking id
 		bookIDStr := GetCtxBookID(ctx)
@@ -567,68 +679,86 @@ func (s *Server) handleClientBookingView() http.HandlerFunc {
 		}
 
 		//load the booking
-		ctx, book, ok := s.loadTemplateBook(w, r.WithContext(ctx), tpl, data, errs, bookIDStr, false, true)
+		ctx, book, ok := s.loadTemplateBook(w, r.WithContext(ctx), tpl, data, errs, bookIDStr, false, false)
 		if !ok {
 			data[TplParamErr] = GetErrText(Err)
 			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
 			return
 		}
-		data[TplParamFormAction] = book.GetURLViewClient()
+		data[TplParamFormAction] = book.GetURLRescheduleClient()
 
-		//load the service
-		_, ok = s.loadServiceClient(w, r.WithContext(ctx), tpl, data, provider)
+		//load the service of the booking
+		svc, ok := s.loadServiceClientBook(w, r.WithContext(ctx), tpl, data, provider, book)
 		if !ok {
 			return
 		}
 
-		//check the method
-		if r.Method == http.MethodGet {
-			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
-			return
+		//default the date to that of the selected time, otherwise that of the booking
+		if dateStr == "" {
+			date := book.TimeFrom
+			if timeStr != "" {
+				date = ParseTimeUnixLocal(timeStr, timeZone)
+			}
+			dateStr = FormatDateLocal(date, timeZone)
 		}
 
-		//update the booking
-		book.EnableClientPhone = r.FormValue(URLParams.EnablePhone) == "on"
-		_, err := UpdateBookingData(ctx, s.getDB(), book.Booking)
-		if err != nil {
-			data[TplParamErr] = GetErrText(Err)
-			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+		//prepare the data
+		data[TplParamDate] = dateStr
+		data[TplParamTime] = timeStr
+		data[TplParamTimeZone] = timeZone
+
+		//only upcoming bookings within the cutoff can be rescheduled
+		now := data[TplParamCurrentTime].(time.Time)
+		if book.IsCancelled() || !now.Before(book.TimeFrom) {
+			logger.Warnw("booking reschedule past", "id", book.ID, "time", book.TimeFrom)
+			s.SetCookieErr(w, Err)
+			http.Redirect(w, r.WithContext(ctx), book.GetURLViewClient(), http.StatusSeeOther)
+			return
+		}
+		if !svc.CheckCancelTime(now, book.TimeFrom) {
+			logger.Warnw("booking reschedule cutoff", "id", book.ID, "time", book.TimeFrom)
+			s.SetCookieErr(w, ErrBookingCutoff)
+			http.Redirect(w, r.WithContext(ctx), book.GetURLViewClient(), http.StatusSeeOther)
 			return
 		}
 
-		//success
-		s.SetCookieMsg(w, MsgUpdateSuccess)
-		http.Redirect(w, r.WithContext(ctx), book.GetURLViewClient(), http.StatusSeeOther)
-	}
-}
+		//use the availability of the team member assigned to the booking
+		provider.ProviderUser = book.ProviderUser
 
-//handle the client contact page
-func (s *Server) handleClientContact() http.HandlerFunc {
-	var o sync.Once
-	var tpl *template.Template
-	return func(w http.ResponseWriter, r *http.Request) {
-		ctx, logger := GetLogger(s.getCtx(r))
-		o.Do(func() {
-			tpl = s.loadWebTemplateClient(ctx, "contact.html")
-		})
-		provider, data, errs, ok := s.createTemplateDataClient(w, r.WithContext(ctx), tpl)
+		//load the service times
+		ctx, svcStartDate, _, svcTimes, ok := s.loadTemplateServiceTimes(w, r.WithContext(ctx), tpl, data, errs, provider, svc, dateStr, now, true)
 		if !ok {
 			return
 		}
+		data[TplParamSvcStartDate] = FormatDateLocal(svcStartDate, timeZone)
 
-		//read the form
-		name := r.FormValue(URLParams.Name)
-		email := r.FormValue(URLParams.Email)
-		phone := r.FormValue(URLParams.Phone)
-		text := r.FormValue(URLParams.Text)
-		timeZone := r.FormValue(URLParams.TimeZone)
-
-		//prepare the data
-		data[TplParamFormAction] = provider.GetURLContactClient()
-		data[TplParamName] = name
-		data[TplParamEmail] = email
-		data[TplParamPhone] = phone
-		data[TplParamText] = text
+		//create the structure used for the client ui
+		type svcTimeSlot struct {
+			Value    int64  `json:"value"`
+			Label    string `json:"label"`
+			Disabled bool   `json:"disabled"`
+			Selected bool   `json:"selected"`
+		}
+		time := ParseTimeUnixUTC(timeStr)
+		svcTimeSlots := make([]*svcTimeSlot, 0, len(svcTimes))
+		for _, svcTime := range svcTimes {
+			if !svcTime.Hidden {
+				svcTimeSlots = append(svcTimeSlots, &svcTimeSlot{
+					Value:    svcTime.Start.Unix(),
+					Label:    svcTime.FormatPeriodLocal(svc.IsApptOnly(), timeZone),
+					Disabled: svcTime.Unavailable,
+					Selected: svcTime.Start.Unix() == time.Unix(),
+				})
+			}
+		}
+		svcTimesJSON, err := json.Marshal(svcTimeSlots)
+		if err != nil {
+			logger.Errorw("json service times", "error", err, "id", svc.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamSvcBusyTimes] = string(svcTimesJSON)
 
 		//check the method
 		if r.Method == http.MethodGet {
@@ -636,90 +766,618 @@ func (s *Server) handleClientContact() http.HandlerFunc {
 			return
 		}
 
-		//validate the form
-		form := ContactForm{
-			ClientForm: ClientForm{
-				ClientDataForm: ClientDataForm{
-					EmailForm: EmailForm{
-						Email: strings.TrimSpace(email),
-					},
-					NameForm: NameForm{
-						Name: name,
-					},
-					Phone: FormatPhone(phone),
+		//validate the booking, retaining everything but the time
+		form := &ClientBookingForm{
+			ServiceID:     svc.ID.String(),
+			ClientID:      book.Client.ID.String(),
+			Location:      book.Location,
+			Confirmed:     book.Confirmed,
+			ClientCreated: book.ClientCreated,
+			ClientBookingDateTimeForm: ClientBookingDateTimeForm{
+				TimeUnixForm: TimeUnixForm{
+					Time: timeStr,
 				},
 				TimeZoneForm: TimeZoneForm{
 					TimeZone: timeZone,
 				},
 			},
-			Text: text,
+			Code: book.CouponCode,
 		}
 		ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
 		if !ok {
 			return
 		}
 
-		//populate from the form
-		client := &Client{
-			ProviderID: provider.ID,
-			Email:      form.Email,
-			Name:       form.Name,
-			Phone:      form.Phone,
-			TimeZone:   form.TimeZone,
-		}
-
-		//save the client
-		ctx, err := SaveClient(ctx, s.getDB(), client)
-		if err != nil {
-			logger.Errorw("save client", "error", err, "client", client, "id", provider.ID)
-			data[TplParamErr] = GetErrText(Err)
-			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
-			return
-		}
-
-		//queue the email
-		ctx, err = s.queueEmailContact(ctx, provider, client, text)
-		if err != nil {
-			logger.Errorw("queue email contact", "error", err, "id", client.ID)
-			ctx = s.setCtxErr(ctx, ErrClientInvite)
-			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+		//update the booking
+		book, ok = s.saveBooking(w, r.WithContext(ctx), tpl, data, errs, provider, book.ProviderUser, svc, book, now, RecurrenceScopeOnce, form, true)
+		if !ok {
 			return
 		}
-
-		//success
-		s.SetCookieMsg(w, MsgContact)
-		http.Redirect(w, r.WithContext(ctx), provider.GetURLProvider(), http.StatusSeeOther)
+		s.SetCookieMsg(w, MsgBookingReschedule)
+		http.Redirect(w, r.WithContext(ctx), book.GetURLViewClient(), http.StatusSeeOther)
 	}
 }
 
-//handle the client direct payment page
-func (s *Server) handleClientPaymentDirect() http.HandlerFunc {
+//handle the client booking page to save a card
+func (s *Server) handleClientBookingCard() http.HandlerFunc {
 	var o sync.Once
 	var tpl *template.Template
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, logger := GetLogger(s.getCtx(r))
 		o.Do(func() {
-			tpl = s.loadWebTemplateClient(ctx, "payment-direct.html")
+			tpl = s.loadWebTemplateClient(ctx, "order-card.html")
 		})
 		provider, data, errs, ok := s.createTemplateDataClient(w, r.WithContext(ctx), tpl)
 		if !ok {
 			return
 		}
 
-		//read the form
-		desc := r.FormValue(URLParams.Desc)
-		email := r.FormValue(URLParams.Email)
-		name := r.FormValue(URLParams.Name)
-		phone := r.FormValue(URLParams.Phone)
-		priceStr := r.FormValue(URLParams.Price)
-		svcIDStr := r.FormValue(URLParams.SvcID)
-		timeZone := r.FormValue(URLParams.TimeZone)
-
-		//load the service if specified
-		var err error
-		var svc *Service
-		if svcIDStr != "" {
-			svcID := uuid.FromStringOrNil(svcIDStr)
+		//check for the booking id
+		bookIDStr := GetCtxBookID(ctx)
+		if bookIDStr == "" {
+			logger.Errorw("no booking id")
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//load the booking
+		ctx, book, ok := s.loadTemplateBook(w, r.WithContext(ctx), tpl, data, errs, bookIDStr, false, false)
+		if !ok {
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//cards can only be saved with stripe
+		if provider.StripeToken == nil {
+			http.Redirect(w, r.WithContext(ctx), book.GetURLViewClient(), http.StatusSeeOther)
+			return
+		}
+
+		//load the client
+		ctx, client, err := LoadClientByID(ctx, s.getDB(), book.Client.ID)
+		if err != nil {
+			logger.Errorw("load client", "error", err, "id", book.Client.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamClient] = client
+
+		//check for a saved card
+		stripeID := r.FormValue(URLParams.StripeID)
+		if stripeID != "" {
+			paymentMethod, err := RetrievePaymentMethodStripe(ctx, provider.StripeToken, stripeID)
+			if err != nil {
+				logger.Errorw("retrieve stripe payment method", "error", err, "id", stripeID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			ctx, err = UpdateClientStripe(ctx, s.getDB(), client.ID, client.StripeCustomerID, paymentMethod)
+			if err != nil {
+				logger.Errorw("update client stripe", "error", err, "id", client.ID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			s.SetCookieMsg(w, MsgCardSaved)
+			http.Redirect(w, r.WithContext(ctx), book.GetURLViewClient(), http.StatusSeeOther)
+			return
+		}
+
+		//create a stripe customer if necessary
+		if client.StripeCustomerID == nil {
+			customerID, err := CreateCustomerStripe(ctx, provider.StripeToken, client.Email, client.Name, client.ID.String())
+			if err != nil {
+				logger.Errorw("create stripe customer", "error", err, "id", client.ID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			client.StripeCustomerID = &customerID
+			ctx, err = UpdateClientStripe(ctx, s.getDB(), client.ID, client.StripeCustomerID, client.PaymentMethodStripe)
+			if err != nil {
+				logger.Errorw("update client stripe", "error", err, "id", client.ID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+		}
+
+		//create a stripe session to save the card
+		session, err := CreateSessionSetupStripe(ctx, provider.StripeToken, *client.StripeCustomerID, client.ID.String(), book.GetURLCardClient())
+		if err != nil {
+			logger.Errorw("create stripe setup session", "error", err, "id", client.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		stripeAccountID, err := provider.StripeToken.GetStripeUserID()
+		if err != nil {
+			logger.Errorw("get stripe account id", "error", err)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamStripeAccountID] = stripeAccountID
+		data[TplParamStripeSessionID] = session.ID
+		s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+	}
+}
+
+//handle the client view booking page
+func (s *Server) handleClientBookingView() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateClient(ctx, "order-detail.html")
+		})
+		provider, data, errs, ok := s.createTemplateDataClient(w, r.WithContext(ctx), tpl)
+		if !ok {
+			return
+		}
+		data[TplParamClientView] = r.FormValue(URLParams.Client)
+
+		//check for the booking id
+		bookIDStr := GetCtxBookID(ctx)
+		if bookIDStr == "" {
+			logger.Errorw("no booking id")
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//load the booking
+		ctx, book, ok := s.loadTemplateBook(w, r.WithContext(ctx), tpl, data, errs, bookIDStr, false, true)
+		if !ok {
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamFormAction] = book.GetURLViewClient()
+
+		//load the service
+		_, ok = s.loadServiceClient(w, r.WithContext(ctx), tpl, data, provider)
+		if !ok {
+			return
+		}
+
+		//check the method
+		if r.Method == http.MethodGet {
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//update the booking
+		book.EnableClientPhone = r.FormValue(URLParams.EnablePhone) == "on"
+		_, err := UpdateBookingData(ctx, s.getDB(), book.Booking)
+		if err != nil {
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//success
+		s.SetCookieMsg(w, MsgUpdateSuccess)
+		http.Redirect(w, r.WithContext(ctx), book.GetURLViewClient(), http.StatusSeeOther)
+	}
+}
+
+//handle the client contact page
+func (s *Server) handleClientContact() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateClient(ctx, "contact.html")
+		})
+		provider, data, errs, ok := s.createTemplateDataClient(w, r.WithContext(ctx), tpl)
+		if !ok {
+			return
+		}
+
+		//read the form
+		name := r.FormValue(URLParams.Name)
+		email := r.FormValue(URLParams.Email)
+		phone := r.FormValue(URLParams.Phone)
+		text := r.FormValue(URLParams.Text)
+		timeZone := r.FormValue(URLParams.TimeZone)
+
+		//prepare the data
+		data[TplParamFormAction] = provider.GetURLContactClient()
+		data[TplParamName] = name
+		data[TplParamEmail] = email
+		data[TplParamPhone] = phone
+		data[TplParamText] = text
+
+		//check the method
+		if r.Method == http.MethodGet {
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//validate the form
+		form := ContactForm{
+			ClientForm: ClientForm{
+				ClientDataForm: ClientDataForm{
+					EmailForm: EmailForm{
+						Email: strings.TrimSpace(email),
+					},
+					NameForm: NameForm{
+						Name: name,
+					},
+					Phone: FormatPhone(phone),
+				},
+				TimeZoneForm: TimeZoneForm{
+					TimeZone: timeZone,
+				},
+			},
+			Text: text,
+		}
+		ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
+		if !ok {
+			return
+		}
+
+		//populate from the form
+		client := &Client{
+			ProviderID: provider.ID,
+			Email:      form.Email,
+			Name:       form.Name,
+			Phone:      form.Phone,
+			TimeZone:   form.TimeZone,
+		}
+
+		//save the client
+		ctx, err := SaveClient(ctx, s.getDB(), client)
+		if err != nil {
+			logger.Errorw("save client", "error", err, "client", client, "id", provider.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//queue the email
+		ctx, err = s.queueEmailContact(ctx, provider, client, text)
+		if err != nil {
+			logger.Errorw("queue email contact", "error", err, "id", client.ID)
+			ctx = s.setCtxErr(ctx, ErrClientInvite)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//success
+		s.SetCookieMsg(w, MsgContact)
+		http.Redirect(w, r.WithContext(ctx), provider.GetURLProvider(), http.StatusSeeOther)
+	}
+}
+
+//handle the client membership page
+func (s *Server) handleClientMembership() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateClient(ctx, "membership.html")
+		})
+		provider, data, _, ok := s.createTemplateDataClient(w, r.WithContext(ctx), tpl)
+		if !ok {
+			return
+		}
+
+		//validate the id
+		idStr := r.FormValue(URLParams.ID)
+		membershipID := uuid.FromStringOrNil(idStr)
+		if membershipID == uuid.Nil {
+			logger.Warnw("invalid uuid", "id", idStr)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//load the membership
+		ctx, membership, err := LoadClientMembershipByProviderIDAndID(ctx, s.getDB(), provider.ID, &membershipID)
+		if err != nil || membership == nil {
+			logger.Errorw("load client membership", "error", err, "id", membershipID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamMembership] = membership
+		url := provider.GetURLMembershipClient(membership.ID)
+
+		//check for a completed checkout, where the membership is activated once stripe confirms the first payment
+		if r.FormValue(URLParams.StripeID) != "" {
+			s.SetCookieMsg(w, MsgMembershipJoin)
+			http.Redirect(w, r.WithContext(ctx), url, http.StatusSeeOther)
+			return
+		}
+
+		//subscriptions can only be created with stripe
+		if !membership.IsPending() || provider.StripeToken == nil {
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//load the client
+		ctx, client, err := LoadClientByID(ctx, s.getDB(), membership.ClientID)
+		if err != nil {
+			logger.Errorw("load client", "error", err, "id", membership.ClientID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//create a stripe customer if necessary
+		if client.StripeCustomerID == nil {
+			customerID, err := CreateCustomerStripe(ctx, provider.StripeToken, client.Email, client.Name, client.ID.String())
+			if err != nil {
+				logger.Errorw("create stripe customer", "error", err, "id", client.ID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			client.StripeCustomerID = &customerID
+			ctx, err = UpdateClientStripe(ctx, s.getDB(), client.ID, client.StripeCustomerID, client.PaymentMethodStripe)
+			if err != nil {
+				logger.Errorw("update client stripe", "error", err, "id", client.ID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+		}
+
+		//create a stripe session to subscribe
+		session, err := CreateSessionSubscriptionStripe(ctx, provider.StripeToken, *client.StripeCustomerID, membership.StripePlanID, membership.ID.String(), url)
+		if err != nil {
+			logger.Errorw("create stripe subscription session", "error", err, "id", membership.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		stripeAccountID, err := provider.StripeToken.GetStripeUserID()
+		if err != nil {
+			logger.Errorw("get stripe account id", "error", err)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamStripeAccountID] = stripeAccountID
+		data[TplParamStripeSessionID] = session.ID
+		s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+	}
+}
+
+//handle the client join membership page
+func (s *Server) handleClientMembershipJoin() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateClient(ctx, "membership-join.html")
+		})
+		provider, data, errs, ok := s.createTemplateDataClient(w, r.WithContext(ctx), tpl)
+		if !ok {
+			return
+		}
+
+		//read the form
+		idStr := r.FormValue(URLParams.ID)
+		email := r.FormValue(URLParams.Email)
+		name := r.FormValue(URLParams.Name)
+		phone := r.FormValue(URLParams.Phone)
+		timeZone := r.FormValue(URLParams.TimeZone)
+
+		//prepare the data
+		data[TplParamFormAction] = provider.GetURLMembershipJoinClient()
+		data[TplParamID] = idStr
+		data[TplParamEmail] = email
+		data[TplParamName] = name
+		data[TplParamPhone] = phone
+
+		//load the membership plans
+		ctx, plans, err := ListMembershipPlansByProviderID(ctx, s.getDB(), provider.ID)
+		if err != nil {
+			logger.Errorw("load membership plans", "error", err, "id", provider.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamMembershipPlans] = plans
+
+		//check the method
+		if r.Method == http.MethodGet {
+			data[TplParamEmail] = ""
+			data[TplParamName] = ""
+			data[TplParamPhone] = ""
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//memberships are billed through stripe
+		if provider.StripeToken == nil {
+			logger.Warnw("membership join without stripe", "id", provider.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//validate the form
+		form := &MembershipJoinForm{
+			EmailForm: EmailForm{
+				Email: strings.TrimSpace(email),
+			},
+			NameForm: NameForm{
+				Name: name,
+			},
+			ID:    idStr,
+			Phone: FormatPhone(phone),
+		}
+		ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
+		if !ok {
+			return
+		}
+
+		//find the selected membership plan
+		var matchedPlan *MembershipPlan
+		for _, plan := range plans {
+			if plan.ID.String() == form.ID {
+				matchedPlan = plan
+				break
+			}
+		}
+		if matchedPlan == nil {
+			errs[string(FieldErrID)] = GetFieldErrText(string(FieldErrID))
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//save the membership
+		ctx, membership, err := s.saveClientMembership(ctx, provider, matchedPlan, form, timeZone)
+		if err != nil {
+			logger.Errorw("save client membership", "error", err)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		http.Redirect(w, r.WithContext(ctx), provider.GetURLMembershipClient(membership.ID), http.StatusSeeOther)
+	}
+}
+
+//handle the client buy package page
+func (s *Server) handleClientPackageBuy() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateClient(ctx, "package-buy.html")
+		})
+		provider, data, errs, ok := s.createTemplateDataClient(w, r.WithContext(ctx), tpl)
+		if !ok {
+			return
+		}
+
+		//read the form
+		idStr := r.FormValue(URLParams.ID)
+		email := r.FormValue(URLParams.Email)
+		name := r.FormValue(URLParams.Name)
+		phone := r.FormValue(URLParams.Phone)
+		timeZone := r.FormValue(URLParams.TimeZone)
+
+		//prepare the data
+		data[TplParamFormAction] = provider.GetURLPackageBuyClient()
+		data[TplParamID] = idStr
+		data[TplParamEmail] = email
+		data[TplParamName] = name
+		data[TplParamPhone] = phone
+
+		//load the packages
+		ctx, pkgs, err := ListSessionPackagesByProviderID(ctx, s.getDB(), provider.ID)
+		if err != nil {
+			logger.Errorw("load session packages", "error", err, "id", provider.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamPackages] = pkgs
+
+		//check the method
+		if r.Method == http.MethodGet {
+			data[TplParamEmail] = ""
+			data[TplParamName] = ""
+			data[TplParamPhone] = ""
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//packages must be paid online
+		if !provider.SupportsPaymentOnline() {
+			logger.Warnw("package buy without online payments", "id", provider.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//validate the form
+		form := &PackageBuyForm{
+			EmailForm: EmailForm{
+				Email: strings.TrimSpace(email),
+			},
+			NameForm: NameForm{
+				Name: name,
+			},
+			ID:    idStr,
+			Phone: FormatPhone(phone),
+		}
+		ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
+		if !ok {
+			return
+		}
+
+		//find the selected package
+		var matchedPkg *SessionPackage
+		for _, pkg := range pkgs {
+			if pkg.ID.String() == form.ID {
+				matchedPkg = pkg
+				break
+			}
+		}
+		if matchedPkg == nil {
+			errs[string(FieldErrID)] = GetFieldErrText(string(FieldErrID))
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//save the payment
+		now := data[TplParamCurrentTime].(time.Time)
+		ctx, payment, err := s.savePaymentPackage(ctx, provider, matchedPkg, form, now, timeZone)
+		if err != nil {
+			logger.Errorw("save payment package", "error", err)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		http.Redirect(w, r.WithContext(ctx), payment.URL, http.StatusSeeOther)
+	}
+}
+
+//handle the client direct payment page
+func (s *Server) handleClientPaymentDirect() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateClient(ctx, "payment-direct.html")
+		})
+		provider, data, errs, ok := s.createTemplateDataClient(w, r.WithContext(ctx), tpl)
+		if !ok {
+			return
+		}
+
+		//read the form
+		desc := r.FormValue(URLParams.Desc)
+		email := r.FormValue(URLParams.Email)
+		giftCard := r.FormValue(URLParams.GiftCard) == "on"
+		name := r.FormValue(URLParams.Name)
+		phone := r.FormValue(URLParams.Phone)
+		priceStr := r.FormValue(URLParams.Price)
+		svcIDStr := r.FormValue(URLParams.SvcID)
+		timeZone := r.FormValue(URLParams.TimeZone)
+
+		//load the service if specified, ignored for a gift card
+		var err error
+		var svc *Service
+		if svcIDStr != "" && !giftCard {
+			svcID := uuid.FromStringOrNil(svcIDStr)
 			if svcID == uuid.Nil {
 				logger.Errorw("invalid uuid", "id", svcIDStr)
 				data[TplParamErr] = GetErrText(Err)
@@ -740,9 +1398,14 @@ func (s *Server) handleClientPaymentDirect() http.HandlerFunc {
 		data[TplParamFormAction] = provider.GetURLPaymentDirectClient()
 		data[TplParamDesc] = desc
 		data[TplParamEmail] = email
+		data[TplParamGiftCard] = giftCard
 		data[TplParamName] = name
 		data[TplParamPhone] = phone
 		data[TplParamPrice] = priceStr
+		taxRate := findTaxRateDirect(provider, svc)
+		if taxRate != nil {
+			data[TplParamTaxRate] = taxRate.FormatRate()
+		}
 
 		//check the method
 		if r.Method == http.MethodGet {
@@ -771,6 +1434,7 @@ func (s *Server) handleClientPaymentDirect() http.HandlerFunc {
 			Description:     desc,
 			ClientInitiated: true,
 			DirectCapture:   false,
+			GiftCard:        giftCard,
 		}
 		ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
 		if !ok {
@@ -874,6 +1538,7 @@ func (s *Server) handleClientPayment() http.HandlerFunc {
 		//check for a booking id
 		var err error
 		var payment *Payment
+		var tipUser *ProviderUser
 		bookIDStr := GetCtxBookID(ctx)
 		if bookIDStr != "" {
 			//load the booking
@@ -890,6 +1555,7 @@ func (s *Server) handleClientPayment() http.HandlerFunc {
 				return
 			}
 			data[TplParamFormAction] = book.GetURLPaymentClient()
+			tipUser = book.ProviderUser
 
 			//load the service
 			_, ok = s.loadServiceClient(w, r.WithContext(ctx), tpl, data, provider)
@@ -906,17 +1572,7 @@ func (s *Server) handleClientPayment() http.HandlerFunc {
 			}
 			if payment == nil {
 				//create a payment
-				form := &PaymentForm{
-					EmailForm: EmailForm{
-						Email: book.Client.Email,
-					},
-					NameForm: NameForm{
-						Name: book.Client.Name,
-					},
-					Price:           strconv.FormatFloat(float64(book.ComputeServicePrice()), 'f', 2, 32),
-					ClientInitiated: true,
-					DirectCapture:   false,
-				}
+				form := createPaymentFormBooking(book, true, false)
 				now := data[TplParamCurrentTime].(time.Time)
 				ctx, payment, err = s.savePaymentBooking(ctx, provider, book, form, now)
 				if err != nil {
@@ -973,6 +1629,30 @@ func (s *Server) handleClientPayment() http.HandlerFunc {
 		paymentUI := s.createPaymentUI(payment)
 		data[TplParamPayment] = paymentUI
 
+		//load the gift card purchased with the payment
+		if payment.Type == PaymentTypeGiftCard {
+			ctx, card, err := LoadGiftCardByPaymentID(ctx, s.getDB(), payment.ID)
+			if err != nil {
+				logger.Errorw("load gift card", "error", err, "id", payment.ID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			data[TplParamGiftCard] = card
+		}
+
+		//load the session package purchased with the payment
+		if payment.Type == PaymentTypePackage {
+			ctx, clientPkg, err := LoadClientPackageByPaymentID(ctx, s.getDB(), payment.ID)
+			if err != nil {
+				logger.Errorw("load client package", "error", err, "id", payment.ID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			data[TplParamPackage] = clientPkg
+		}
+
 		//check for the a payment confirmation
 		now := data[TplParamCurrentTime].(time.Time)
 		paypalID := r.FormValue(URLParams.PayPalID)
@@ -1006,6 +1686,17 @@ func (s *Server) handleClientPayment() http.HandlerFunc {
 					return
 				}
 
+				//confirm a booking waiting on the payment if already captured
+				if payment.IsCaptured() {
+					ctx, err = s.confirmBookingPaymentCaptured(ctx, payment, now)
+					if err != nil {
+						logger.Errorw("confirm booking payment", "error", err, "id", payment.ID)
+						data[TplParamErr] = GetErrText(Err)
+						s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+						return
+					}
+				}
+
 				//queue the emails
 				ctx, err = s.queueEmailsPayment(ctx, provider, paymentUI)
 				if err != nil {
@@ -1020,6 +1711,149 @@ func (s *Server) handleClientPayment() http.HandlerFunc {
 			return
 		}
 
+		//add a tip to an unpaid invoice
+		tip := r.FormValue(URLParams.Tip)
+		tipPercent := r.FormValue(URLParams.TipPercent)
+		isTip := tip != "" || tipPercent != ""
+		if provider.HasTips() && payment.SupportsTip() {
+			data[TplParamTip] = tip
+			data[TplParamTipPercents] = provider.TipPercents
+		}
+		if r.Method == http.MethodPost && isTip && provider.HasTips() && payment.SupportsTip() {
+			form := &TipForm{
+				Tip:        tip,
+				TipPercent: tipPercent,
+			}
+			if s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, false) {
+				//compute the tip
+				var amount int
+				if form.TipPercent != "" {
+					percent, _ := strconv.Atoi(form.TipPercent)
+					amount = payment.ComputeTip(percent)
+				} else {
+					price, _ := strconv.ParseFloat(form.Tip, 32)
+					amount = payment.GetCurrency().ToMinorUnits(float32(price))
+				}
+				payment.SetTip(amount)
+
+				//attribute the tip to the team member providing the service
+				payment.TipUserID = nil
+				payment.TipUserName = ""
+				if tipUser != nil && tipUser.User != nil {
+					payment.TipUserID = tipUser.ID
+					payment.TipUserName = tipUser.User.FormatName()
+				}
+
+				//update the checkout created for the previous amount, which fails if the client has already paid
+				ctx, err = s.updatePaymentCheckout(ctx, provider.StripeToken, payment)
+				if err != nil {
+					logger.Errorw("update payment checkout", "error", err, "id", payment.ID)
+					data[TplParamErr] = GetErrText(Err)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return
+				}
+
+				//save the payment
+				ctx, err = UpdatePaymentAmount(ctx, s.getDB(), payment)
+				if err != nil {
+					logger.Errorw("update payment amount", "error", err, "id", payment.ID)
+					data[TplParamErr] = GetErrText(Err)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return
+				}
+				if amount > 0 {
+					s.SetCookieMsg(w, MsgTipAdd)
+				} else {
+					s.SetCookieMsg(w, MsgTipDel)
+				}
+				http.Redirect(w, r.WithContext(ctx), payment.URL, http.StatusSeeOther)
+				return
+			}
+		}
+
+		//apply a gift card to an unpaid invoice
+		code := strings.ToUpper(strings.TrimSpace(r.FormValue(URLParams.Code)))
+		if r.Method == http.MethodPost && !isTip && payment.Type == PaymentTypeBooking && !payment.IsPaid() {
+			data[TplParamCode] = code
+			form := &GiftCardRedeemForm{
+				Code: code,
+			}
+			if s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, false) {
+				//limit the invalid codes entered by the client
+				ip := GetRemoteIP(r)
+				ctx, count, err := CountGiftCardAttempts(ctx, s.getDB(), provider.ID, payment.Email, ip, now)
+				if err != nil {
+					logger.Errorw("count gift card attempts", "error", err, "id", payment.ID)
+					data[TplParamErr] = GetErrText(Err)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return
+				}
+				if count >= giftCardAttemptsMax {
+					logger.Warnw("gift card attempts", "id", payment.ID, "email", payment.Email, "ip", ip)
+					errs[string(FieldErrCode)] = GetErrText(ErrGiftCardAttempts)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return
+				}
+
+				//redeem the gift card
+				ctx, applied, err := RedeemGiftCard(ctx, s.getDB(), payment, code)
+				if err != nil {
+					logger.Errorw("redeem gift card", "error", err, "id", payment.ID)
+					data[TplParamErr] = GetErrText(Err)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return
+				}
+				if applied > 0 {
+					//update the checkout created for the previous amount
+					ctx, err = s.updatePaymentCheckout(ctx, provider.StripeToken, payment)
+					if err != nil {
+						logger.Errorw("update payment checkout", "error", err, "id", payment.ID)
+						data[TplParamErr] = GetErrText(Err)
+						s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+						return
+					}
+
+					//the invoice is paid in full by the gift card
+					if payment.Amount == 0 {
+						payment.Paid = &now
+						payment.Captured = &now
+						ctx, err = UpdatePaymentPaid(ctx, s.getDB(), payment.ID, payment.Paid, payment.Captured)
+						if err != nil {
+							logger.Errorw("mark payment paid", "error", err, "id", payment.ID)
+							data[TplParamErr] = GetErrText(Err)
+							s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+							return
+						}
+						ctx, err = s.confirmBookingPaymentCaptured(ctx, payment, now)
+						if err != nil {
+							logger.Errorw("confirm booking payment", "error", err, "id", payment.ID)
+							data[TplParamErr] = GetErrText(Err)
+							s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+							return
+						}
+						ctx, err = s.queueEmailsPayment(ctx, provider, paymentUI)
+						if err != nil {
+							logger.Errorw("queue email payment", "error", err)
+							data[TplParamErr] = GetErrText(Err)
+							s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+							return
+						}
+					}
+					s.SetCookieMsg(w, MsgGiftCardRedeem)
+					http.Redirect(w, r.WithContext(ctx), payment.URL, http.StatusSeeOther)
+					return
+				}
+				ctx, err = SaveGiftCardAttempt(ctx, s.getDB(), provider.ID, payment.Email, ip, now)
+				if err != nil {
+					logger.Errorw("save gift card attempt", "error", err, "id", payment.ID)
+					data[TplParamErr] = GetErrText(Err)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return
+				}
+				errs[string(FieldErrCode)] = GetErrText(ErrGiftCardInvalid)
+			}
+		}
+
 		//set-up payment
 		if provider.PayPalEmail != nil {
 			//create a paypal order if necessary
@@ -1109,3 +1943,255 @@ func (s *Server) handleClientServiceIndex() http.HandlerFunc {
 		s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
 	}
 }
+
+//handle the client waitlist page
+func (s *Server) handleClientWaitlist() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateClient(ctx, "waitlist.html")
+		})
+		provider, data, errs, ok := s.createTemplateDataClient(w, r.WithContext(ctx), tpl)
+		if !ok {
+			return
+		}
+
+		//read the form
+		email := r.FormValue(URLParams.Email)
+		enablePhone := r.FormValue(URLParams.EnablePhone) == "on"
+		end := r.FormValue(URLParams.End)
+		name := r.FormValue(URLParams.Name)
+		phone := r.FormValue(URLParams.Phone)
+		start := r.FormValue(URLParams.Start)
+		timeZone := r.FormValue(URLParams.TimeZone)
+		userIDStr := r.FormValue(URLParams.UserID)
+
+		//prepare the data
+		data[TplParamEmail] = email
+		data[TplParamEnablePhone] = enablePhone
+		data[TplParamEnd] = end
+		data[TplParamName] = name
+		data[TplParamPhone] = phone
+		data[TplParamStart] = start
+		data[TplParamUserID] = userIDStr
+
+		//load the service
+		svc, ok := s.loadServiceClient(w, r.WithContext(ctx), tpl, data, provider)
+		if !ok {
+			return
+		}
+		if !svc.IsApptOnly() {
+			logger.Warnw("waitlist service not appointment-only", "id", svc.ID)
+			s.redirectError(w, r.WithContext(ctx), Err)
+			return
+		}
+		data[TplParamFormAction] = svc.GetURLWaitlist()
+
+		//load the users
+		ctx, svcUsers, err := ListProviderUsersForService(ctx, s.getDB(), provider.ID, svc.ID)
+		if err != nil {
+			logger.Errorw("list users", "error", err, "id", provider.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//process to create a list of users, sorted by the login
+		users := make([]*ProviderUser, 0, 2)
+		for _, svcUser := range svcUsers {
+			users = append(users, svcUser.User)
+		}
+		sort.Slice(users, func(i, j int) bool {
+			return users[i].Login < users[j].Login
+		})
+		data[TplParamUsers] = users
+
+		//check the method
+		if r.Method == http.MethodGet {
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//validate the form
+		form := WaitlistForm{
+			ClientForm: ClientForm{
+				ClientDataForm: ClientDataForm{
+					EmailForm: EmailForm{
+						Email: strings.TrimSpace(email),
+					},
+					NameForm: NameForm{
+						Name: name,
+					},
+					Phone: FormatPhone(phone),
+				},
+				TimeZoneForm: TimeZoneForm{
+					TimeZone: timeZone,
+				},
+			},
+			Start:  start,
+			End:    end,
+			UserID: userIDStr,
+		}
+		ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
+		if !ok {
+			return
+		}
+
+		//check for a selected user
+		var providerUserID *uuid.UUID
+		if form.UserID != "" {
+			for _, user := range users {
+				if user.ID.String() == form.UserID {
+					providerUserID = user.ID
+					break
+				}
+			}
+			if providerUserID == nil {
+				errs[string(FieldErrUserID)] = GetFieldErrText(string(FieldErrUserID))
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+		}
+
+		//populate from the form, including the entire end date
+		waitlist := &Waitlist{
+			ProviderID:     provider.ID,
+			ServiceID:      svc.ID,
+			ProviderUserID: providerUserID,
+			Client: &Client{
+				Email:    form.Email,
+				Name:     form.Name,
+				Phone:    form.Phone,
+				TimeZone: form.TimeZone,
+			},
+			EnableClientPhone: enablePhone && form.Phone != "",
+			TimeStart:         ParseDateLocal(form.Start, form.TimeZone),
+			TimeEnd:           ParseDateLocal(form.End, form.TimeZone).AddDate(0, 0, 1),
+		}
+
+		//save the waitlist
+		ctx, err = SaveWaitlist(ctx, s.getDB(), waitlist)
+		if err != nil {
+			logger.Errorw("save waitlist", "error", err, "id", svc.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//success
+		s.SetCookieMsg(w, MsgWaitlistAdd)
+		http.Redirect(w, r.WithContext(ctx), svc.GetURLService(), http.StatusSeeOther)
+	}
+}
+
+//handle the client waitlist claim page
+func (s *Server) handleClientWaitlistClaim() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateClient(ctx, "waitlist-claim.html")
+		})
+		provider, data, errs, ok := s.createTemplateDataClient(w, r.WithContext(ctx), tpl)
+		if !ok {
+			return
+		}
+
+		//read the form
+		token := r.FormValue(URLParams.Token)
+
+		//load the service
+		svc, ok := s.loadServiceClient(w, r.WithContext(ctx), tpl, data, provider)
+		if !ok {
+			return
+		}
+		url, err := CreateURLRelParams(svc.GetURLWaitlistClaim(), URLParams.Token, token)
+		if err != nil {
+			logger.Errorw("create url", "error", err, "url", svc.GetURLWaitlistClaim())
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamFormAction] = url
+
+		//load the waitlist
+		ctx, waitlist, err := LoadWaitlistByProviderIDAndToken(ctx, s.getDB(), provider.ID, token)
+		if err != nil {
+			logger.Errorw("load waitlist", "error", err, "id", provider.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//check if the offer is still valid
+		now := data[TplParamCurrentTime].(time.Time)
+		if waitlist == nil || waitlist.Claimed || waitlist.IsOfferExpired(now) || *waitlist.ServiceID != *svc.ID {
+			data[TplParamErr] = GetErrText(ErrWaitlistOffer)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamWaitlist] = waitlist
+		data[TplParamSvcTime] = svc.FormatTime(*waitlist.OfferTime, waitlist.Client.TimeZone)
+
+		//check the method
+		if r.Method == http.MethodGet {
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//load the user that was offered
+		var providerUser *ProviderUser
+		if waitlist.OfferProviderUserID != nil {
+			ctx, providerUser, err = LoadProviderUserForServiceByProviderIDAndServiceIDAndUserID(ctx, s.getDB(), provider.ID, svc.ID, waitlist.OfferProviderUserID)
+			if err != nil {
+				logger.Errorw("load provider user", "error", err, "id", waitlist.OfferProviderUserID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+		}
+
+		//use the client location if necessary
+		location := waitlist.Client.Location
+		if svc.LocationType.IsLocationProvider() {
+			location = svc.Location
+		}
+		location = svc.ProcessBookingLocationInput(location)
+
+		//create the booking for the client
+		form := &ClientBookingForm{
+			ServiceID:     svc.ID.String(),
+			ClientID:      waitlist.Client.ID.String(),
+			EnablePhone:   waitlist.EnableClientPhone,
+			Location:      location,
+			ClientCreated: true,
+			ClientBookingDateTimeForm: ClientBookingDateTimeForm{
+				TimeUnixForm: TimeUnixForm{
+					Time: strconv.FormatInt(waitlist.OfferTime.Unix(), 10),
+				},
+				TimeZoneForm: TimeZoneForm{
+					TimeZone: waitlist.Client.TimeZone,
+				},
+			},
+			ProviderNote:    svc.Note,
+			ProviderNoteSet: true,
+		}
+		book, ok := s.saveBooking(w, r.WithContext(ctx), tpl, data, errs, provider, providerUser, svc, nil, now, RecurrenceScopeOnce, form, true)
+		if !ok {
+			return
+		}
+
+		//mark the offer as claimed
+		ctx, err = MarkWaitlistClaimed(ctx, s.getDB(), waitlist.ID)
+		if err != nil {
+			logger.Errorw("mark waitlist claimed", "error", err, "id", waitlist.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		http.Redirect(w, r.WithContext(ctx), book.GetURLConfirmClient(), http.StatusSeeOther)
+	}
+}
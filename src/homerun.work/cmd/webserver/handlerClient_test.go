@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLoadServiceClientBook(t *testing.T) {
+	tests := []struct {
+		name          string
+		otherProvider bool
+		otherService  bool
+		found         bool
+		want          bool
+	}{
+		{"service of the booking", false, false, true, true},
+		{"another service of the provider", false, true, true, false},
+		{"service of another provider", false, false, false, false},
+		{"booking of another provider", true, false, true, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, s := newFakeDB(t)
+			user := &User{ID: newFakeID(t), Email: "provider@example.com"}
+			provider := &Provider{ID: newFakeID(t), User: user, Name: "Provider", URLName: "provider"}
+			svc := &Service{ID: newFakeID(t), Type: ServiceTypeAppt, Provider: provider}
+			book := &Booking{ID: newFakeID(t), Provider: provider, Service: svc}
+
+			//the service in the url
+			svcURL := svc
+			if test.otherService {
+				svcURL = &Service{ID: newFakeID(t), Type: ServiceTypeAppt, Provider: provider}
+			}
+			if test.otherProvider {
+				book.Provider = &Provider{ID: newFakeID(t), User: user, Name: "Other", URLName: "other"}
+			}
+			if test.found {
+				db.onRows("s.deleted=0 AND p.id=UUID_TO_BIN(?) and s.id=UUID_TO_BIN(?)", fakeServiceRow(t, svcURL))
+			} else {
+				db.onRows("s.deleted=0 AND p.id=UUID_TO_BIN(?) and s.id=UUID_TO_BIN(?)")
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			ctx := SetCtxProviderURLName(r.Context(), provider.URLName)
+			ctx = SetCtxServiceID(ctx, svcURL.ID.String())
+			w := httptest.NewRecorder()
+			data := make(templateData)
+			got, ok := s.loadServiceClientBook(w, r.WithContext(ctx), nil, data, &providerUI{provider}, s.createBookingUI(book))
+			if ok != test.want {
+				t.Fatalf("got %t, want %t", ok, test.want)
+			}
+
+			//the booking is either loaded with its own service or the client is sent to the error page
+			if ok {
+				if got.ID.String() != svc.ID.String() || data[TplParamSvc] != got {
+					t.Errorf("got service %s, want %s", got.ID, svc.ID)
+				}
+				return
+			}
+			if w.Code != http.StatusSeeOther || w.Header().Get("Location") != URIErr {
+				t.Errorf("got status %d to %s, want %d to %s", w.Code, w.Header().Get("Location"), http.StatusSeeOther, URIErr)
+			}
+		})
+	}
+}
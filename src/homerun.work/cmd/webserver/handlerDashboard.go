@@ -246,9 +246,8 @@ func (s *Server) handleDashboardAccount() http.HandlerFunc {
 				return
 			}
 
-			//update the hours to use the new timezone if necessary
+			//keep the same local hours in the new timezone if necessary
 			if provider.User.TimeZone != form.TimeZone {
-				now := data[TplParamCurrentTime].(time.Time)
 				schedule := provider.GetSchedule()
 				if schedule == nil {
 					logger.Errorw("no schedule", "id", provider.ID)
@@ -256,7 +255,7 @@ func (s *Server) handleDashboardAccount() http.HandlerFunc {
 					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
 					return
 				}
-				schedule.Adjust(now, provider.User.TimeZone, form.TimeZone)
+				schedule.TimeZone = form.TimeZone
 				ctx, err := SaveProvider(ctx, s.getDB(), provider.Provider)
 				if err != nil {
 					logger.Errorw("save provider", "error", err, "id", provider.ID)
@@ -326,6 +325,10 @@ func (s *Server) handleDashboardBookingAdd() http.HandlerFunc {
 		desc := r.FormValue(URLParams.Desc)
 		email := r.FormValue(URLParams.Email)
 		freqStr := r.FormValue(URLParams.Freq)
+		freqCountStr := r.FormValue(URLParams.FreqCount)
+		freqDayStrs := r.Form[URLParams.FreqDays]
+		freqIntervalStr := r.FormValue(URLParams.FreqInterval)
+		freqUntilStr := r.FormValue(URLParams.FreqUntil)
 		location := r.FormValue(URLParams.Location)
 		name := r.FormValue(URLParams.Name)
 		phone := r.FormValue(URLParams.Phone)
@@ -339,6 +342,10 @@ func (s *Server) handleDashboardBookingAdd() http.HandlerFunc {
 		data[TplParamDesc] = desc
 		data[TplParamEmail] = email
 		data[TplParamFreq] = freqStr
+		data[TplParamFreqCount] = freqCountStr
+		data[TplParamFreqDays] = freqDayStrs
+		data[TplParamFreqInterval] = freqIntervalStr
+		data[TplParamFreqUntil] = freqUntilStr
 		data[TplParamLocation] = location
 		data[TplParamName] = name
 		data[TplParamPhone] = phone
@@ -461,6 +468,10 @@ func (s *Server) handleDashboardBookingAdd() http.HandlerFunc {
 			ProviderNoteSet: true,
 			Freq:            freqStr,
 			FreqSet:         true,
+			FreqInterval:    freqIntervalStr,
+			FreqDays:        freqDayStrs,
+			FreqUntil:       freqUntilStr,
+			FreqCount:       freqCountStr,
 			ClientCreated:   false,
 			Confirmed:       true,
 			Location:        location,
@@ -498,7 +509,7 @@ func (s *Server) handleDashboardBookingAdd() http.HandlerFunc {
 		}
 
 		//create the booking, prioritizing the entered client information
-		book, ok := s.saveBooking(w, r.WithContext(ctx), tpl, data, errs, provider, providerUser, svc, nil, now, false, form, false)
+		book, ok := s.saveBooking(w, r.WithContext(ctx), tpl, data, errs, provider, providerUser, svc, nil, now, RecurrenceScopeOnce, form, false)
 		if !ok {
 			return
 		}
@@ -681,13 +692,15 @@ func (s *Server) handleDashboardBookingEdit() http.HandlerFunc {
 
 	//steps on the page
 	steps := struct {
-		StepConfirm string
-		StepUpd     string
-		StepUpdAll  string
+		StepConfirm      string
+		StepUpd          string
+		StepUpdAll       string
+		StepUpdFollowing string
 	}{
-		StepConfirm: "stepConfirm",
-		StepUpd:     "stepUpd",
-		StepUpdAll:  "stepUpdAll",
+		StepConfirm:      "stepConfirm",
+		StepUpd:          "stepUpd",
+		StepUpdAll:       "stepUpdAll",
+		StepUpdFollowing: "stepUpdFollowing",
 	}
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, logger := GetLogger(s.getCtx(r))
@@ -744,6 +757,7 @@ func (s *Server) handleDashboardBookingEdit() http.HandlerFunc {
 		data[TplParamDesc] = desc
 		data[TplParamLocation] = location
 		data[TplParamRecurrenceFreq] = book.FormatRecurrenceFreq()
+		data[TplParamRecurrenceOptions] = book.FormatRecurrenceOptions(timeZone)
 		data[TplParamSvcID] = svcIDStr
 		data[TplParamTime] = timeStr
 
@@ -800,10 +814,13 @@ func (s *Server) handleDashboardBookingEdit() http.HandlerFunc {
 		}
 
 		//execute the correct operation
-		changeAllFollowing := false
+		scope := RecurrenceScopeOnce
 		switch step {
-		case steps.StepUpdAll:
-			changeAllFollowing = true
+		case steps.StepUpdAll, steps.StepUpdFollowing:
+			scope = RecurrenceScopeFollowing
+			if step == steps.StepUpdAll {
+				scope = RecurrenceScopeAll
+			}
 			fallthrough
 		case steps.StepUpd:
 			//no edits allowed for captured bookings
@@ -822,7 +839,7 @@ func (s *Server) handleDashboardBookingEdit() http.HandlerFunc {
 				ProviderNote:    desc,
 				ProviderNoteSet: true,
 				ClientCreated:   book.ClientCreated,
-				Confirmed:       true,
+				Confirmed:       book.Confirmed || !book.IsDepositDue(),
 				Location:        location,
 				ClientBookingDateTimeForm: ClientBookingDateTimeForm{
 					TimeUnixForm: TimeUnixForm{
@@ -839,8 +856,23 @@ func (s *Server) handleDashboardBookingEdit() http.HandlerFunc {
 				return
 			}
 
+			//apply a change to all upcoming occurrences to the first upcoming occurrence, shifting the time by the same amount
+			if scope == RecurrenceScopeAll {
+				ctx, seriesBook, err := s.loadBookingSeriesUpcoming(ctx, book, now)
+				if err != nil {
+					logger.Errorw("load booking series", "error", err, "id", book.ID)
+					data[TplParamErr] = GetErrText(Err)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return
+				}
+				timeUnix, _ := strconv.ParseInt(form.Time, 10, 64)
+				timeShift := time.Unix(timeUnix, 0).Sub(book.TimeFrom)
+				form.Time = strconv.FormatInt(seriesBook.TimeFrom.Add(timeShift).Unix(), 10)
+				book = seriesBook
+			}
+
 			//update the booking
-			book, ok = s.saveBooking(w, r.WithContext(ctx), tpl, data, errs, provider, book.ProviderUser, svc, book, now, changeAllFollowing, form, false)
+			book, ok = s.saveBooking(w, r.WithContext(ctx), tpl, data, errs, provider, book.ProviderUser, svc, book, now, scope, form, false)
 			if !ok {
 				return
 			}
@@ -848,6 +880,13 @@ func (s *Server) handleDashboardBookingEdit() http.HandlerFunc {
 			return
 		case steps.StepConfirm:
 			if !book.Confirmed {
+				//the deposit must be paid before confirming
+				if book.IsDepositDue() {
+					data[TplParamErr] = GetErrText(ErrDepositUnpaid)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return
+				}
+
 				//validate the note
 				form := &ClientConfirmForm{
 					Text: desc,
@@ -963,15 +1002,21 @@ func (s *Server) handleDashboardBookingView() http.HandlerFunc {
 
 	//steps on the page
 	steps := struct {
-		StepDel        string
-		StepDelAll     string
-		StepMarkPaid   string
-		StepMarkUnPaid string
+		StepChargeCard   string
+		StepDel          string
+		StepDelAll       string
+		StepDelFollowing string
+		StepMarkPaid     string
+		StepMarkUnPaid   string
+		StepNoShow       string
 	}{
-		StepDel:        "stepDel",
-		StepDelAll:     "stepDelAll",
-		StepMarkPaid:   "stepMarkPaid",
-		StepMarkUnPaid: "stepMarkUnPaid",
+		StepChargeCard:   "stepChargeCard",
+		StepDel:          "stepDel",
+		StepDelAll:       "stepDelAll",
+		StepDelFollowing: "stepDelFollowing",
+		StepMarkPaid:     "stepMarkPaid",
+		StepMarkUnPaid:   "stepMarkUnPaid",
+		StepNoShow:       "stepNoShow",
 	}
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, logger := GetLogger(s.getCtx(r))
@@ -1015,6 +1060,38 @@ func (s *Server) handleDashboardBookingView() http.HandlerFunc {
 		}
 		data[TplParamBreadcrumbs] = breadcrumbs
 
+		//load the other clients attending the class
+		if book.IsClass() {
+			ctx, attendees, err := ListBookingsByServiceIDAndTime(ctx, s.getDB(), book.Service.ID, book.ProviderUserID, book.TimeFrom)
+			if err != nil {
+				logger.Errorw("load attendees", "error", err, "id", book.ID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			data[TplParamAttendees] = s.createBookingUIs(attendees)
+		}
+
+		//load the payments made against the booking
+		ctx, ok = s.loadTemplateBookPayments(w, r.WithContext(ctx), tpl, data, provider, book)
+		if !ok {
+			return
+		}
+
+		//load the client to check for a card on file
+		var client *Client
+		if provider.StripeToken != nil && book.SupportsPayment() {
+			var err error
+			ctx, client, err = LoadClientByID(ctx, s.getDB(), book.Client.ID)
+			if err != nil {
+				logger.Errorw("load client", "error", err, "id", book.Client.ID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			data[TplParamClient] = client
+		}
+
 		//prepare the confirmation modal
 		if book.AllowUnPay() {
 			data[TplParamConfirmMsg] = GetMsgText(MsgPaymentMarkUnPaid)
@@ -1033,22 +1110,42 @@ func (s *Server) handleDashboardBookingView() http.HandlerFunc {
 		}
 
 		//execute the correct operation
-		changeAllFollowing := false
+		scope := RecurrenceScopeOnce
 		step := r.FormValue(URLParams.Step)
 		switch step {
-		case steps.StepMarkPaid:
+		case steps.StepChargeCard:
+			//sanity check the operation
+			if client == nil || !client.HasCard() || book.IsPaid() {
+				logger.Errorw("invalid charge card", "id", book.ID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+
 			//save the payment
-			form := &PaymentForm{
-				EmailForm: EmailForm{
-					Email: book.Client.Email,
-				},
-				NameForm: NameForm{
-					Name: book.Client.Name,
-				},
-				Price:           strconv.FormatFloat(float64(book.ComputeServicePrice()), 'f', 2, 32),
-				ClientInitiated: false,
-				DirectCapture:   true,
+			form := createPaymentFormBooking(book, false, false)
+			ctx, payment, err := s.savePaymentBooking(ctx, provider, book, form, now)
+			if err != nil {
+				logger.Errorw("save payment", "error", err)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+
+			//charge the card
+			ctx, ok, err := s.chargePaymentCard(ctx, provider, client, payment, now)
+			if err != nil || !ok {
+				logger.Errorw("charge card", "error", err, "id", book.ID)
+				data[TplParamErr] = GetErrText(ErrCardCharge)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
 			}
+			s.SetCookieMsg(w, MsgCardCharged)
+			http.Redirect(w, r.WithContext(ctx), provider.GetURLBookings(), http.StatusSeeOther)
+			return
+		case steps.StepMarkPaid:
+			//save the payment
+			form := createPaymentFormBooking(book, false, true)
 			ctx, _, err := s.savePaymentBooking(ctx, provider, book, form, now)
 			if err != nil {
 				logger.Errorw("save payment", "error", err)
@@ -1073,13 +1170,58 @@ func (s *Server) handleDashboardBookingView() http.HandlerFunc {
 				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
 				return
 			}
-		case steps.StepDelAll:
-			changeAllFollowing = true
+		case steps.StepNoShow:
+			//sanity check the operation
+			if !book.AllowNoShow(now) {
+				logger.Errorw("invalid mark no-show", "id", book.ID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+
+			//mark the booking as a no-show
+			book.NoShow = true
+			ctx, err := UpdateBookingData(ctx, s.getDB(), book.Booking)
+			if err != nil {
+				logger.Errorw("update booking no-show", "error", err, "id", book.ID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+
+			//charge the no-show fee using the policy agreed to when booking
+			noShowFee := book.ComputeNoShowFee()
+			if noShowFee > 0 {
+				ctx, _, err = s.savePaymentFee(ctx, provider, book, "no-show fee", noShowFee, now)
+				if err != nil {
+					logger.Errorw("save payment fee", "error", err, "id", book.ID)
+					data[TplParamErr] = GetErrText(Err)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return
+				}
+			}
+		case steps.StepDelAll, steps.StepDelFollowing:
+			scope = RecurrenceScopeFollowing
+			if step == steps.StepDelAll {
+				scope = RecurrenceScopeAll
+			}
 			fallthrough
 		case steps.StepDel:
+			//cancel all upcoming occurrences starting with the first upcoming occurrence
+			if scope == RecurrenceScopeAll {
+				ctx, seriesBook, err := s.loadBookingSeriesUpcoming(ctx, book, now)
+				if err != nil {
+					logger.Errorw("load booking series", "error", err, "id", book.ID)
+					data[TplParamErr] = GetErrText(Err)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return
+				}
+				book = seriesBook
+			}
+
 			//delete the booking
 			now := data[TplParamCurrentTime].(time.Time)
-			ok = s.cancelServiceBooking(w, r.WithContext(ctx), tpl, data, errs, provider, svc, book, now, changeAllFollowing)
+			ok = s.cancelServiceBooking(w, r.WithContext(ctx), tpl, data, errs, provider, svc, book, now, scope)
 			if !ok {
 				return
 			}
@@ -1837,6 +1979,7 @@ func (s *Server) handleDashboardClientEdit() http.HandlerFunc {
 		data[TplParamSteps] = steps
 
 		//handle the input
+		disableReminders := r.FormValue(URLParams.DisableReminders) == "on"
 		email := r.FormValue(URLParams.Email)
 		location := r.FormValue(URLParams.Location)
 		name := r.FormValue(URLParams.Name)
@@ -1844,6 +1987,7 @@ func (s *Server) handleDashboardClientEdit() http.HandlerFunc {
 		step := r.FormValue(URLParams.Step)
 
 		//prepare the data
+		data[TplParamDisableReminders] = disableReminders
 		data[TplParamEmail] = email
 		data[TplParamLocation] = location
 		data[TplParamName] = name
@@ -1855,6 +1999,36 @@ func (s *Server) handleDashboardClientEdit() http.HandlerFunc {
 			return
 		}
 
+		//load the packages purchased by the client
+		ctx, clientPkgs, err := ListClientPackagesByClientID(ctx, s.getDB(), provider.ID, client.ID)
+		if err != nil {
+			logger.Errorw("load client packages", "error", err, "id", client.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamClientPackages] = clientPkgs
+
+		//load the package usage history
+		ctx, pkgUses, err := ListClientPackageUsesByClientID(ctx, s.getDB(), provider.ID, client.ID)
+		if err != nil {
+			logger.Errorw("load client package uses", "error", err, "id", client.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamPackageUses] = pkgUses
+
+		//load the memberships of the client
+		ctx, clientMemberships, err := ListClientMembershipsByClientID(ctx, s.getDB(), provider.ID, client.ID)
+		if err != nil {
+			logger.Errorw("load client memberships", "error", err, "id", client.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamClientMemberships] = clientMemberships
+
 		//prepare the confirmation modal
 		data[TplParamConfirmMsg] = GetMsgText(MsgClientDelConfirm)
 		data[TplParamConfirmSubmitName] = URLParams.Step
@@ -1862,6 +2036,7 @@ func (s *Server) handleDashboardClientEdit() http.HandlerFunc {
 
 		//check the method
 		if r.Method == http.MethodGet {
+			data[TplParamDisableReminders] = client.DisablePaymentReminders
 			data[TplParamEmail] = client.Email
 			data[TplParamLocation] = client.Location
 			data[TplParamName] = client.Name
@@ -1928,6 +2103,7 @@ func (s *Server) handleDashboardClientEdit() http.HandlerFunc {
 			client.Name = form.Name
 			client.Location = form.Location
 			client.Phone = form.Phone
+			client.DisablePaymentReminders = disableReminders
 
 			//update the client
 			ctx, err := SaveClient(ctx, s.getDB(), client)
@@ -2053,6 +2229,10 @@ func (s *Server) handleDashboardCouponAdd() http.HandlerFunc {
 		desc := r.FormValue(URLParams.Desc)
 		svcIDStr := r.FormValue(URLParams.SvcID)
 		newClients := r.FormValue(URLParams.Flag) == "on"
+		usageLimitStr := r.FormValue(URLParams.UsageLimit)
+		usageLimitClientStr := r.FormValue(URLParams.UsageLimitClient)
+		minAmountStr := r.FormValue(URLParams.MinAmount)
+		firstBookingsStr := r.FormValue(URLParams.FirstBookings)
 		timeZone := r.FormValue(URLParams.TimeZone)
 
 		//prepare the data
@@ -2064,6 +2244,10 @@ func (s *Server) handleDashboardCouponAdd() http.HandlerFunc {
 		data[TplParamDesc] = desc
 		data[TplParamSvcID] = svcIDStr
 		data[TplParamFlag] = newClients
+		data[TplParamUsageLimit] = usageLimitStr
+		data[TplParamUsageLimitClient] = usageLimitClientStr
+		data[TplParamMinAmount] = minAmountStr
+		data[TplParamFirstBookings] = firstBookingsStr
 
 		//load the services
 		ctx, svcs, ok := s.loadTemplateServices(w, r.WithContext(ctx), tpl, data, provider)
@@ -2090,14 +2274,18 @@ func (s *Server) handleDashboardCouponAdd() http.HandlerFunc {
 
 		//validate the data
 		form := CouponForm{
-			Type:        couponTypeStr,
-			Code:        code,
-			Value:       valStr,
-			Start:       startStr,
-			End:         endStr,
-			Description: desc,
-			ServiceID:   svcIDStr,
-			NewClients:  newClients,
+			Type:             couponTypeStr,
+			Code:             code,
+			Value:            valStr,
+			Start:            startStr,
+			End:              endStr,
+			Description:      desc,
+			ServiceID:        svcIDStr,
+			NewClients:       newClients,
+			UsageLimit:       usageLimitStr,
+			UsageLimitClient: usageLimitClientStr,
+			MinAmount:        minAmountStr,
+			FirstBookings:    firstBookingsStr,
 		}
 		ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
 		if !ok {
@@ -2123,17 +2311,25 @@ func (s *Server) handleDashboardCouponAdd() http.HandlerFunc {
 		val, _ := strconv.ParseFloat(valStr, 32)
 		start := ParseDateLocal(startStr, timeZone)
 		end := ParseDateLocal(endStr, timeZone)
+		usageLimit, _ := strconv.Atoi(form.UsageLimit)
+		usageLimitClient, _ := strconv.Atoi(form.UsageLimitClient)
+		minAmount, _ := strconv.ParseFloat(form.MinAmount, 32)
+		firstBookings, _ := strconv.Atoi(form.FirstBookings)
 
 		//populate from the form
 		coupon := &Coupon{
-			ProviderID:  provider.ID,
-			Type:        *couponType,
-			Code:        strings.ToUpper(form.Code),
-			Value:       float32(val),
-			Start:       start,
-			End:         end,
-			Description: desc,
-			NewClients:  form.NewClients,
+			ProviderID:       provider.ID,
+			Type:             *couponType,
+			Code:             strings.ToUpper(form.Code),
+			Value:            float32(val),
+			Start:            start,
+			End:              end,
+			Description:      desc,
+			NewClients:       form.NewClients,
+			UsageLimit:       usageLimit,
+			UsageLimitClient: usageLimitClient,
+			MinAmount:        float32(minAmount),
+			FirstBookings:    firstBookings,
 		}
 		if matchedSvc != nil {
 			coupon.SetService(matchedSvc.Service)
@@ -2196,6 +2392,10 @@ func (s *Server) handleDashboardCouponEdit() http.HandlerFunc {
 		desc := r.FormValue(URLParams.Desc)
 		svcIDStr := r.FormValue(URLParams.SvcID)
 		newClients := r.FormValue(URLParams.Flag) == "on"
+		usageLimitStr := r.FormValue(URLParams.UsageLimit)
+		usageLimitClientStr := r.FormValue(URLParams.UsageLimitClient)
+		minAmountStr := r.FormValue(URLParams.MinAmount)
+		firstBookingsStr := r.FormValue(URLParams.FirstBookings)
 		timeZone := r.FormValue(URLParams.TimeZone)
 		step := r.FormValue(URLParams.Step)
 
@@ -2208,6 +2408,10 @@ func (s *Server) handleDashboardCouponEdit() http.HandlerFunc {
 		data[TplParamDesc] = desc
 		data[TplParamSvcID] = svcIDStr
 		data[TplParamFlag] = newClients
+		data[TplParamUsageLimit] = usageLimitStr
+		data[TplParamUsageLimitClient] = usageLimitClientStr
+		data[TplParamMinAmount] = minAmountStr
+		data[TplParamFirstBookings] = firstBookingsStr
 
 		//validate the id
 		idStr := r.FormValue(URLParams.ID)
@@ -2229,6 +2433,16 @@ func (s *Server) handleDashboardCouponEdit() http.HandlerFunc {
 		}
 		data[TplParamCoupon] = coupon
 
+		//load the redemptions
+		ctx, redemptions, err := ListCouponRedemptionsByCouponID(ctx, s.getDB(), provider.ID, coupon.ID)
+		if err != nil {
+			logger.Errorw("load coupon redemptions", "error", err, "id", couponID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamRedemptions] = redemptions
+
 		//load the services
 		ctx, svcs, ok := s.loadTemplateServices(w, r.WithContext(ctx), tpl, data, provider)
 		if !ok {
@@ -2260,6 +2474,10 @@ func (s *Server) handleDashboardCouponEdit() http.HandlerFunc {
 			data[TplParamEnd] = coupon.FormatEnd(timeZone)
 			data[TplParamDesc] = coupon.Description
 			data[TplParamFlag] = coupon.NewClients
+			data[TplParamUsageLimit] = coupon.FormatUsageLimit()
+			data[TplParamUsageLimitClient] = coupon.FormatUsageLimitClient()
+			data[TplParamMinAmount] = coupon.FormatMinAmount()
+			data[TplParamFirstBookings] = coupon.FormatFirstBookings()
 			if coupon.ServiceID != nil {
 				data[TplParamSvcID] = coupon.ServiceID.String()
 			} else {
@@ -2285,14 +2503,18 @@ func (s *Server) handleDashboardCouponEdit() http.HandlerFunc {
 		case steps.StepUpd:
 			//validate the data
 			form := CouponForm{
-				Type:        couponTypeStr,
-				Code:        code,
-				Value:       valStr,
-				Start:       startStr,
-				End:         endStr,
-				Description: desc,
-				ServiceID:   svcIDStr,
-				NewClients:  newClients,
+				Type:             couponTypeStr,
+				Code:             code,
+				Value:            valStr,
+				Start:            startStr,
+				End:              endStr,
+				Description:      desc,
+				ServiceID:        svcIDStr,
+				NewClients:       newClients,
+				UsageLimit:       usageLimitStr,
+				UsageLimitClient: usageLimitClientStr,
+				MinAmount:        minAmountStr,
+				FirstBookings:    firstBookingsStr,
 			}
 			ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
 			if !ok {
@@ -2320,6 +2542,10 @@ func (s *Server) handleDashboardCouponEdit() http.HandlerFunc {
 			val, _ := strconv.ParseFloat(valStr, 32)
 			start := ParseDateLocal(startStr, timeZone)
 			end := ParseDateLocal(endStr, timeZone)
+			usageLimit, _ := strconv.Atoi(form.UsageLimit)
+			usageLimitClient, _ := strconv.Atoi(form.UsageLimitClient)
+			minAmount, _ := strconv.ParseFloat(form.MinAmount, 32)
+			firstBookings, _ := strconv.Atoi(form.FirstBookings)
 
 			//populate from the form
 			coupon.Type = *couponType
@@ -2329,6 +2555,10 @@ func (s *Server) handleDashboardCouponEdit() http.HandlerFunc {
 			coupon.End = end
 			coupon.Description = desc
 			coupon.NewClients = newClients
+			coupon.UsageLimit = usageLimit
+			coupon.UsageLimitClient = usageLimitClient
+			coupon.MinAmount = float32(minAmount)
+			coupon.FirstBookings = firstBookings
 			if matchedSvc != nil {
 				coupon.SetService(matchedSvc.Service)
 			}
@@ -2709,184 +2939,1078 @@ func (s *Server) handleDashboardFaqs() http.HandlerFunc {
 	}
 }
 
-//handle the hours page
-func (s *Server) handleDashboardHours() http.HandlerFunc {
+//handle the gift card view page
+func (s *Server) handleDashboardGiftCardView() http.HandlerFunc {
 	var o sync.Once
 	var tpl *template.Template
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, logger := GetLogger(s.getCtx(r))
 		o.Do(func() {
-			tpl = s.loadWebTemplateDashboard(ctx, "service-hours.html")
+			tpl = s.loadWebTemplateDashboard(ctx, "gift-card-view.html")
 		})
-		ctx, provider, data, _, ok := s.createTemplateDataDashboard(w, r.WithContext(ctx), tpl, false)
+		ctx, provider, data, _, ok := s.createTemplateDataDashboard(w, r.WithContext(ctx), tpl, true)
 		if !ok {
 			return
 		}
 
 		//setup the breadcrumbs
 		breadcrumbs := []breadcrumb{
-			{"Schedule", ""},
+			{"Gift Cards", provider.GetURLGiftCards()},
+			{"View Gift Card", ""},
 		}
 		data[TplParamBreadcrumbs] = breadcrumbs
-		data[TplParamActiveNav] = provider.GetURLHours()
-		data[TplParamFormAction] = provider.GetURLHours()
-		data[TplParamClientView] = r.FormValue(URLParams.Client)
+		data[TplParamActiveNav] = provider.GetURLGiftCards()
 
-		//check the method
-		if r.Method == http.MethodGet {
-			ok = s.loadTemplateProviderSchedule(w, r.WithContext(ctx), tpl, data, provider)
-			if !ok {
-				return
-			}
-			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+		//validate the id
+		idStr := r.FormValue(URLParams.ID)
+		cardID := uuid.FromStringOrNil(idStr)
+		if cardID == uuid.Nil {
+			logger.Warnw("invalid uuid", "id", idStr)
+			s.SetCookieErr(w, Err)
+			http.Redirect(w, r.WithContext(ctx), provider.GetURLGiftCards(), http.StatusSeeOther)
 			return
 		}
 
-		//handle the input
-		schedule := r.FormValue(URLParams.Schedule)
-
-		//prepare the data
-		data[TplParamSchedule] = schedule
-
-		//validate the data
-		now := data[TplParamCurrentTime].(time.Time)
-		errDays, err := s.setSchedule(ctx, provider, schedule, now, provider.User.TimeZone)
+		//load the gift card
+		ctx, card, err := LoadGiftCardByProviderIDAndID(ctx, s.getDB(), provider.ID, &cardID)
 		if err != nil {
-			logger.Warnw("set schedule", "error", errDays, "schedule", schedule)
+			logger.Errorw("load gift card", "error", err, "id", cardID)
 			data[TplParamErr] = GetErrText(Err)
 			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
 			return
 		}
-		if len(errDays) > 0 {
-			logger.Warnw("invalid provider schedule", "error", errDays, "schedule", schedule)
-			errDaysJSON, err := json.Marshal(errDays)
-			if err != nil {
-				logger.Warnw("error json", "error", errDays)
-				data[TplParamErr] = GetErrText(Err)
-				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
-				return
-			}
-			data[TplParamDaysOfWeek] = string(errDaysJSON)
-			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+		if card == nil {
+			logger.Warnw("no gift card", "id", cardID)
+			s.SetCookieErr(w, Err)
+			http.Redirect(w, r.WithContext(ctx), provider.GetURLGiftCards(), http.StatusSeeOther)
 			return
 		}
+		data[TplParamGiftCard] = card
 
-		//save the provider
-		ctx, err = SaveProvider(ctx, s.getDB(), provider.Provider)
+		//load the ledger
+		ctx, entries, err := ListGiftCardEntriesByGiftCardID(ctx, s.getDB(), card.ID)
 		if err != nil {
-			logger.Errorw("save provider", "error", err, "provider", provider)
+			logger.Errorw("load gift card entries", "error", err, "id", cardID)
 			data[TplParamErr] = GetErrText(Err)
 			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
 			return
 		}
-
-		//success
-		s.SetCookieMsg(w, MsgUpdateSuccess)
-		url := s.checkClientView(data, provider, provider.GetURLHours())
-		http.Redirect(w, r.WithContext(ctx), url, http.StatusSeeOther)
+		data[TplParamGiftCardEntries] = entries
+		s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
 	}
 }
 
-//handle the dashboard page
-func (s *Server) handleDashboardIndex() http.HandlerFunc {
+//handle the gift cards page
+func (s *Server) handleDashboardGiftCards() http.HandlerFunc {
 	var o sync.Once
 	var tpl *template.Template
 	return func(w http.ResponseWriter, r *http.Request) {
-		ctx, _ := GetLogger(s.getCtx(r))
+		ctx, logger := GetLogger(s.getCtx(r))
 		o.Do(func() {
-			tpl = s.loadWebTemplateDashboard(ctx, "index.html")
+			tpl = s.loadWebTemplateDashboard(ctx, "gift-cards.html")
 		})
-		ctx, _, data, _, ok := s.createTemplateDataDashboard(w, r.WithContext(ctx), tpl, false)
+		ctx, provider, data, _, ok := s.createTemplateDataDashboard(w, r.WithContext(ctx), tpl, true)
 		if !ok {
 			return
 		}
-		s.SetCookieSignUp(w)
+
+		//setup the breadcrumbs
+		breadcrumbs := []breadcrumb{
+			{"Gift Cards", ""},
+		}
+		data[TplParamBreadcrumbs] = breadcrumbs
+		data[TplParamActiveNav] = provider.GetURLGiftCards()
+
+		//load the gift cards
+		ctx, cards, err := ListGiftCardsByProviderID(ctx, s.getDB(), provider.ID)
+		if err != nil {
+			logger.Errorw("load gift cards", "error", err)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamGiftCards] = cards
+		data[TplParamURL] = ForceURLAbs(ctx, provider.GetURLPaymentDirectClient())
 		s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
 	}
 }
 
-//handle the links page
-func (s *Server) handleDashboardLinks() http.HandlerFunc {
+//handle the hours page
+func (s *Server) handleDashboardHours() http.HandlerFunc {
 	var o sync.Once
 	var tpl *template.Template
+
+	//steps on the page
+	steps := struct {
+		StepAdd string
+		StepDel string
+		StepUpd string
+	}{
+		StepAdd: "stepAdd",
+		StepDel: "stepDel",
+		StepUpd: "stepUpd",
+	}
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, logger := GetLogger(s.getCtx(r))
 		o.Do(func() {
-			tpl = s.loadWebTemplateDashboard(ctx, "links.html")
+			tpl = s.loadWebTemplateDashboard(ctx, "service-hours.html")
 		})
-		ctx, provider, data, errs, ok := s.createTemplateDataDashboard(w, r.WithContext(ctx), tpl, true)
+		ctx, provider, data, errs, ok := s.createTemplateDataDashboard(w, r.WithContext(ctx), tpl, false)
 		if !ok {
 			return
 		}
 
 		//setup the breadcrumbs
 		breadcrumbs := []breadcrumb{
-			{"Links", ""},
+			{"Schedule", ""},
 		}
 		data[TplParamBreadcrumbs] = breadcrumbs
-		data[TplParamActiveNav] = provider.GetURLLinks()
-		data[TplParamFormAction] = provider.GetURLLinks()
+		data[TplParamActiveNav] = provider.GetURLHours()
+		data[TplParamFormAction] = provider.GetURLHours()
 		data[TplParamClientView] = r.FormValue(URLParams.Client)
+		data[TplParamSteps] = steps
 
-		//check the method
-		if r.Method == http.MethodGet {
+		//load the schedule
+		providerSchedule := provider.GetSchedule()
+		if providerSchedule == nil {
+			logger.Errorw("no schedule", "id", provider.ID)
+			data[TplParamErr] = GetErrText(Err)
 			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
 			return
 		}
+		data[TplParamScheduleSeasons] = providerSchedule.Seasons
 
-		//handle the input
-		urlFacebook := r.FormValue(URLParams.URLFacebook)
-		urlInstagram := r.FormValue(URLParams.URLInstagram)
-		urlLinkedIn := r.FormValue(URLParams.URLLinkedIn)
-		urlTwitter := r.FormValue(URLParams.URLTwitter)
-		urlWeb := r.FormValue(URLParams.URLWeb)
+		//prepare the confirmation modal
+		data[TplParamConfirmMsg] = GetMsgText(MsgSeasonDelConfirm)
+		data[TplParamConfirmSubmitName] = URLParams.Step
+		data[TplParamConfirmSubmitValue] = steps.StepDel
 
-		//prepare the data
-		data[TplParamURLFacebookProvider] = urlFacebook
-		data[TplParamURLInstagramProvider] = urlInstagram
-		data[TplParamURLLinkedInProvider] = urlLinkedIn
-		data[TplParamURLTwitterProvider] = urlTwitter
-		data[TplParamURLWebProvider] = urlWeb
+		//check for a seasonal schedule
+		step := r.FormValue(URLParams.Step)
+		var season *ProviderSchedule
+		idStr := r.FormValue(URLParams.ID)
+		if idStr != "" {
+			id := uuid.FromStringOrNil(idStr)
+			season = providerSchedule.GetSeason(&id)
+			if id == uuid.Nil || season == nil {
+				logger.Warnw("invalid schedule season id", "id", idStr)
+				s.SetCookieErr(w, Err)
+				http.Redirect(w, r.WithContext(ctx), provider.GetURLHours(), http.StatusSeeOther)
+				return
+			}
+			if step != steps.StepDel {
+				breadcrumbs = []breadcrumb{
+					{"Schedule", provider.GetURLHours()},
+					{season.Name, ""},
+				}
+				data[TplParamBreadcrumbs] = breadcrumbs
+				data[TplParamID] = idStr
+				data[TplParamScheduleSeason] = season
+			}
+		}
 
-		//validate the data
-		form := ProviderLinksForm{
-			URLFacebook:  urlFacebook,
-			URLInstagram: urlInstagram,
-			URLLinkedIn:  urlLinkedIn,
-			URLTwitter:   urlTwitter,
-			URLWeb:       urlWeb,
+		//load the hours being edited
+		editSchedule := providerSchedule
+		if season != nil {
+			editSchedule = season
 		}
-		ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
+		ok = s.loadTemplateProviderSchedule(w, r.WithContext(ctx), tpl, data, editSchedule)
 		if !ok {
 			return
 		}
 
-		//populate from the form
-		provider.URLFacebook = form.URLFacebook
-		provider.URLInstagram = form.URLInstagram
-		provider.URLLinkedIn = form.URLLinkedIn
-		provider.URLTwitter = form.URLTwitter
-		provider.URLWeb = form.URLWeb
-
-		//save the provider
-		ctx, err := SaveProvider(ctx, s.getDB(), provider.Provider)
-		if err != nil {
-			logger.Errorw("save provider", "error", err, "provider", provider)
-			data[TplParamErr] = GetErrText(Err)
+		//check the method
+		if r.Method == http.MethodGet {
+			now := data[TplParamCurrentTime].(time.Time)
+			data[TplParamStart] = FormatDateLocal(now, provider.User.TimeZone)
+			data[TplParamEnd] = FormatDateLocal(now.AddDate(0, 3, 0), provider.User.TimeZone)
 			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
 			return
 		}
 
-		//success
-		s.SetCookieMsg(w, MsgUpdateSuccess)
-		url := s.checkClientView(data, provider, provider.GetURLLinks())
-		http.Redirect(w, r.WithContext(ctx), url, http.StatusSeeOther)
-	}
-}
+		//execute the correct operation
+		var msgKey MsgKey
+		url := provider.GetURLHours()
+		switch step {
+		case steps.StepAdd:
+			//handle the input
+			name := r.FormValue(URLParams.Name)
+			start := r.FormValue(URLParams.Start)
+			end := r.FormValue(URLParams.End)
 
-//handle the payment page
-func (s *Server) handleDashboardPayment() http.HandlerFunc {
-	var o sync.Once
+			//prepare the data
+			data[TplParamName] = name
+			data[TplParamStart] = start
+			data[TplParamEnd] = end
+
+			//validate the data
+			form := ScheduleSeasonForm{
+				Name:  name,
+				Start: start,
+				End:   end,
+			}
+			ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
+			if !ok {
+				return
+			}
+			if ParseDateUTC(form.End).Before(ParseDateUTC(form.Start)) {
+				errs[string(FieldErrEnd)] = GetFieldErrText(string(FieldErrEnd))
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+
+			//create the seasonal schedule, starting with the regular hours
+			season, err := s.createScheduleSeason(&form, providerSchedule)
+			if err != nil {
+				logger.Errorw("create schedule season", "error", err, "form", form)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			if !providerSchedule.AddSeason(season) {
+				data[TplParamErr] = GetErrText(ErrSeasonOverlap)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			msgKey = MsgSeasonAdd
+			url = provider.GetURLHoursSeason(season.ID)
+		case steps.StepDel:
+			if season == nil || !providerSchedule.DeleteSeason(season.ID) {
+				logger.Warnw("invalid schedule season id", "id", idStr)
+				s.SetCookieErr(w, Err)
+				http.Redirect(w, r.WithContext(ctx), provider.GetURLHours(), http.StatusSeeOther)
+				return
+			}
+			msgKey = MsgSeasonDel
+		case steps.StepUpd:
+			//handle the input
+			schedule := r.FormValue(URLParams.Schedule)
+
+			//prepare the data
+			data[TplParamSchedule] = schedule
+
+			//validate the data
+			errDays, err := s.setSchedule(ctx, provider, schedule, provider.User.TimeZone, season)
+			if err != nil {
+				logger.Warnw("set schedule", "error", errDays, "schedule", schedule)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			if len(errDays) > 0 {
+				logger.Warnw("invalid provider schedule", "error", errDays, "schedule", schedule)
+				errDaysJSON, err := json.Marshal(errDays)
+				if err != nil {
+					logger.Warnw("error json", "error", errDays)
+					data[TplParamErr] = GetErrText(Err)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return
+				}
+				data[TplParamDaysOfWeek] = string(errDaysJSON)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			msgKey = MsgUpdateSuccess
+			if season != nil {
+				url = provider.GetURLHoursSeason(season.ID)
+			} else {
+				url = s.checkClientView(data, provider, url)
+			}
+		default:
+			logger.Errorw("invalid step", "step", step)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//save the provider
+		ctx, err := SaveProvider(ctx, s.getDB(), provider.Provider)
+		if err != nil {
+			logger.Errorw("save provider", "error", err, "provider", provider)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//success
+		s.SetCookieMsg(w, msgKey)
+		http.Redirect(w, r.WithContext(ctx), url, http.StatusSeeOther)
+	}
+}
+
+//handle the dashboard page
+func (s *Server) handleDashboardIndex() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, _ := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateDashboard(ctx, "index.html")
+		})
+		ctx, _, data, _, ok := s.createTemplateDataDashboard(w, r.WithContext(ctx), tpl, false)
+		if !ok {
+			return
+		}
+		s.SetCookieSignUp(w)
+		s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+	}
+}
+
+//handle the links page
+func (s *Server) handleDashboardLinks() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateDashboard(ctx, "links.html")
+		})
+		ctx, provider, data, errs, ok := s.createTemplateDataDashboard(w, r.WithContext(ctx), tpl, true)
+		if !ok {
+			return
+		}
+
+		//setup the breadcrumbs
+		breadcrumbs := []breadcrumb{
+			{"Links", ""},
+		}
+		data[TplParamBreadcrumbs] = breadcrumbs
+		data[TplParamActiveNav] = provider.GetURLLinks()
+		data[TplParamFormAction] = provider.GetURLLinks()
+		data[TplParamClientView] = r.FormValue(URLParams.Client)
+
+		//check the method
+		if r.Method == http.MethodGet {
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//handle the input
+		urlFacebook := r.FormValue(URLParams.URLFacebook)
+		urlInstagram := r.FormValue(URLParams.URLInstagram)
+		urlLinkedIn := r.FormValue(URLParams.URLLinkedIn)
+		urlTwitter := r.FormValue(URLParams.URLTwitter)
+		urlWeb := r.FormValue(URLParams.URLWeb)
+
+		//prepare the data
+		data[TplParamURLFacebookProvider] = urlFacebook
+		data[TplParamURLInstagramProvider] = urlInstagram
+		data[TplParamURLLinkedInProvider] = urlLinkedIn
+		data[TplParamURLTwitterProvider] = urlTwitter
+		data[TplParamURLWebProvider] = urlWeb
+
+		//validate the data
+		form := ProviderLinksForm{
+			URLFacebook:  urlFacebook,
+			URLInstagram: urlInstagram,
+			URLLinkedIn:  urlLinkedIn,
+			URLTwitter:   urlTwitter,
+			URLWeb:       urlWeb,
+		}
+		ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
+		if !ok {
+			return
+		}
+
+		//populate from the form
+		provider.URLFacebook = form.URLFacebook
+		provider.URLInstagram = form.URLInstagram
+		provider.URLLinkedIn = form.URLLinkedIn
+		provider.URLTwitter = form.URLTwitter
+		provider.URLWeb = form.URLWeb
+
+		//save the provider
+		ctx, err := SaveProvider(ctx, s.getDB(), provider.Provider)
+		if err != nil {
+			logger.Errorw("save provider", "error", err, "provider", provider)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//success
+		s.SetCookieMsg(w, MsgUpdateSuccess)
+		url := s.checkClientView(data, provider, provider.GetURLLinks())
+		http.Redirect(w, r.WithContext(ctx), url, http.StatusSeeOther)
+	}
+}
+
+//handle the membership add page
+func (s *Server) handleDashboardMembershipAdd() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateDashboard(ctx, "membership-add.html")
+		})
+		ctx, provider, data, errs, ok := s.createTemplateDataDashboard(w, r.WithContext(ctx), tpl, true)
+		if !ok {
+			return
+		}
+
+		//setup the breadcrumbs
+		breadcrumbs := []breadcrumb{
+			{"Memberships", provider.GetURLMemberships()},
+			{"Add Membership", ""},
+		}
+		data[TplParamBreadcrumbs] = breadcrumbs
+		data[TplParamActiveNav] = provider.GetURLMemberships()
+
+		//handle the input
+		name := r.FormValue(URLParams.Name)
+		desc := r.FormValue(URLParams.Desc)
+		priceStr := r.FormValue(URLParams.Price)
+		sessionsStr := r.FormValue(URLParams.Sessions)
+		svcIDStrs := r.Form[URLParams.SvcIDs]
+
+		//prepare the data
+		data[TplParamName] = name
+		data[TplParamDesc] = desc
+		data[TplParamPrice] = priceStr
+		data[TplParamSessions] = sessionsStr
+		data[TplParamSvcIDs] = svcIDStrs
+
+		//load the services
+		ctx, svcs, ok := s.loadTemplateServices(w, r.WithContext(ctx), tpl, data, provider)
+		if !ok {
+			return
+		}
+
+		//check the method
+		if r.Method == http.MethodGet {
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//memberships are billed through stripe
+		if provider.StripeToken == nil {
+			data[TplParamErr] = GetErrText(ErrMembershipStripe)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//validate the data
+		form := MembershipForm{
+			NameForm: NameForm{
+				Name: name,
+			},
+			Description: desc,
+			Price:       priceStr,
+			Sessions:    sessionsStr,
+			ServiceIDs:  svcIDStrs,
+		}
+		ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
+		if !ok {
+			return
+		}
+
+		//parse the data
+		price, _ := strconv.ParseFloat(form.Price, 32)
+		sessions, _ := strconv.Atoi(form.Sessions)
+		if price <= 0 {
+			errs[string(FieldErrPrice)] = GetFieldErrText(string(FieldErrPrice))
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//find the matching services
+		matchedSvcs := findServicesByIDs(svcs, form.ServiceIDs)
+		if len(matchedSvcs) == 0 {
+			errs[string(FieldErrSvcIDs)] = GetFieldErrText(string(FieldErrSvcIDs))
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//populate from the form
+		plan := &MembershipPlan{
+			ProviderID:  provider.ID,
+			Name:        form.Name,
+			Description: form.Description,
+			Price:       float32(price),
+			Sessions:    sessions,
+		}
+		plan.SetServices(matchedSvcs)
+
+		//save the membership plan
+		ctx, err := s.saveMembershipPlan(ctx, provider, plan)
+		if err != nil {
+			logger.Errorw("save membership plan", "error", err, "plan", plan, "id", provider.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//success
+		s.SetCookieMsg(w, MsgMembershipAdd)
+		http.Redirect(w, r.WithContext(ctx), provider.GetURLMemberships(), http.StatusSeeOther)
+	}
+}
+
+//handle the membership edit page
+func (s *Server) handleDashboardMembershipEdit() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+
+	//steps on the page
+	steps := struct {
+		StepCancel string
+		StepDel    string
+		StepUpd    string
+	}{
+		StepCancel: "stepCancel",
+		StepDel:    "stepDel",
+		StepUpd:    "stepUpd",
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateDashboard(ctx, "membership-edit.html")
+		})
+		ctx, provider, data, errs, ok := s.createTemplateDataDashboard(w, r.WithContext(ctx), tpl, true)
+		if !ok {
+			return
+		}
+
+		//setup the breadcrumbs
+		breadcrumbs := []breadcrumb{
+			{"Memberships", provider.GetURLMemberships()},
+			{"Edit Membership", ""},
+		}
+		data[TplParamBreadcrumbs] = breadcrumbs
+		data[TplParamActiveNav] = provider.GetURLMemberships()
+		data[TplParamFormAction] = provider.GetURLMembershipEdit(nil)
+		data[TplParamSteps] = steps
+
+		//handle the input
+		name := r.FormValue(URLParams.Name)
+		desc := r.FormValue(URLParams.Desc)
+		priceStr := r.FormValue(URLParams.Price)
+		sessionsStr := r.FormValue(URLParams.Sessions)
+		svcIDStrs := r.Form[URLParams.SvcIDs]
+		step := r.FormValue(URLParams.Step)
+
+		//prepare the data
+		data[TplParamName] = name
+		data[TplParamDesc] = desc
+		data[TplParamPrice] = priceStr
+		data[TplParamSessions] = sessionsStr
+		data[TplParamSvcIDs] = svcIDStrs
+
+		//validate the id
+		idStr := r.FormValue(URLParams.ID)
+		planID := uuid.FromStringOrNil(idStr)
+		if planID == uuid.Nil {
+			logger.Warnw("invalid uuid", "id", idStr)
+			s.SetCookieErr(w, Err)
+			http.Redirect(w, r.WithContext(ctx), provider.GetURLMemberships(), http.StatusSeeOther)
+			return
+		}
+
+		//load the membership plan
+		ctx, plan, err := LoadMembershipPlanByProviderIDAndID(ctx, s.getDB(), provider.ID, &planID)
+		if err != nil {
+			logger.Errorw("load membership plan", "error", err, "id", planID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamMembership] = plan
+
+		//load the members
+		ctx, members, err := ListClientMembershipsByMembershipPlanID(ctx, s.getDB(), provider.ID, plan.ID)
+		if err != nil {
+			logger.Errorw("load client memberships", "error", err, "id", plan.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamMembers] = members
+
+		//load the services
+		ctx, svcs, ok := s.loadTemplateServices(w, r.WithContext(ctx), tpl, data, provider)
+		if !ok {
+			return
+		}
+
+		//prepare the confirmation modal
+		data[TplParamConfirmMsg] = GetMsgText(MsgMembershipDelConfirm)
+		data[TplParamConfirmSubmitName] = URLParams.Step
+		data[TplParamConfirmSubmitValue] = steps.StepDel
+
+		//check the method
+		if r.Method == http.MethodGet {
+			data[TplParamName] = plan.Name
+			data[TplParamDesc] = plan.Description
+			data[TplParamPrice] = plan.Price
+			data[TplParamSessions] = plan.FormatSessionsInput()
+			data[TplParamSvcIDs] = plan.FormatServiceIDs()
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//execute the correct operation
+		var msgKey MsgKey
+		switch step {
+		case steps.StepCancel:
+			//find the member
+			memberIDStr := r.FormValue(URLParams.MemberID)
+			var matchedMember *ClientMembership
+			for _, member := range members {
+				if member.ID.String() == memberIDStr {
+					matchedMember = member
+					break
+				}
+			}
+			if matchedMember == nil || !matchedMember.IsCancellable() || provider.StripeToken == nil {
+				logger.Warnw("invalid member", "id", memberIDStr)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+
+			//cancel the membership
+			ctx, err := s.cancelClientMembership(ctx, provider, matchedMember)
+			if err != nil {
+				logger.Errorw("cancel client membership", "error", err, "id", matchedMember.ID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			s.SetCookieMsg(w, MsgMembershipCancel)
+			http.Redirect(w, r.WithContext(ctx), provider.GetURLMembershipEdit(plan.ID), http.StatusSeeOther)
+			return
+		case steps.StepDel:
+			//delete the membership plan
+			ctx, err := DeleteMembershipPlan(ctx, s.getDB(), provider.ID, plan.ID)
+			if err != nil {
+				logger.Errorw("delete membership plan", "error", err, "id", plan.ID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			msgKey = MsgMembershipDel
+		case steps.StepUpd:
+			//memberships are billed through stripe
+			if provider.StripeToken == nil {
+				data[TplParamErr] = GetErrText(ErrMembershipStripe)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+
+			//validate the data
+			form := MembershipForm{
+				NameForm: NameForm{
+					Name: name,
+				},
+				Description: desc,
+				Price:       priceStr,
+				Sessions:    sessionsStr,
+				ServiceIDs:  svcIDStrs,
+			}
+			ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
+			if !ok {
+				return
+			}
+
+			//parse the data
+			price, _ := strconv.ParseFloat(form.Price, 32)
+			sessions, _ := strconv.Atoi(form.Sessions)
+			if price <= 0 {
+				errs[string(FieldErrPrice)] = GetFieldErrText(string(FieldErrPrice))
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+
+			//find the matching services
+			matchedSvcs := findServicesByIDs(svcs, form.ServiceIDs)
+			if len(matchedSvcs) == 0 {
+				errs[string(FieldErrSvcIDs)] = GetFieldErrText(string(FieldErrSvcIDs))
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+
+			//populate from the form, where existing members keep the terms they subscribed to
+			plan.Name = form.Name
+			plan.Description = form.Description
+			plan.Price = float32(price)
+			plan.Sessions = sessions
+			plan.SetServices(matchedSvcs)
+
+			//update the membership plan
+			ctx, err := s.saveMembershipPlan(ctx, provider, plan)
+			if err != nil {
+				logger.Errorw("save membership plan", "error", err, "plan", plan)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			msgKey = MsgMembershipEdit
+		default:
+			logger.Errorw("invalid step", "id", plan.ID, "step", step)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//success
+		s.SetCookieMsg(w, msgKey)
+		http.Redirect(w, r.WithContext(ctx), provider.GetURLMemberships(), http.StatusSeeOther)
+	}
+}
+
+//handle the memberships page
+func (s *Server) handleDashboardMemberships() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateDashboard(ctx, "memberships.html")
+		})
+		ctx, provider, data, _, ok := s.createTemplateDataDashboard(w, r.WithContext(ctx), tpl, true)
+		if !ok {
+			return
+		}
+
+		//setup the breadcrumbs
+		breadcrumbs := []breadcrumb{
+			{"Memberships", ""},
+		}
+		data[TplParamBreadcrumbs] = breadcrumbs
+		data[TplParamActiveNav] = provider.GetURLMemberships()
+
+		//load the membership plans
+		ctx, plans, err := ListMembershipPlansByProviderID(ctx, s.getDB(), provider.ID)
+		if err != nil {
+			logger.Errorw("load membership plans", "error", err)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamMembershipPlans] = plans
+		s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+	}
+}
+
+//handle the package add page
+func (s *Server) handleDashboardPackageAdd() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateDashboard(ctx, "package-add.html")
+		})
+		ctx, provider, data, errs, ok := s.createTemplateDataDashboard(w, r.WithContext(ctx), tpl, true)
+		if !ok {
+			return
+		}
+
+		//setup the breadcrumbs
+		breadcrumbs := []breadcrumb{
+			{"Packages", provider.GetURLPackages()},
+			{"Add Package", ""},
+		}
+		data[TplParamBreadcrumbs] = breadcrumbs
+		data[TplParamActiveNav] = provider.GetURLPackages()
+
+		//handle the input
+		name := r.FormValue(URLParams.Name)
+		desc := r.FormValue(URLParams.Desc)
+		priceStr := r.FormValue(URLParams.Price)
+		sessionsStr := r.FormValue(URLParams.Sessions)
+		expirationStr := r.FormValue(URLParams.Expiration)
+		svcIDStrs := r.Form[URLParams.SvcIDs]
+
+		//prepare the data
+		data[TplParamName] = name
+		data[TplParamDesc] = desc
+		data[TplParamPrice] = priceStr
+		data[TplParamSessions] = sessionsStr
+		data[TplParamExpiration] = expirationStr
+		data[TplParamSvcIDs] = svcIDStrs
+
+		//load the services
+		ctx, svcs, ok := s.loadTemplateServices(w, r.WithContext(ctx), tpl, data, provider)
+		if !ok {
+			return
+		}
+
+		//check the method
+		if r.Method == http.MethodGet {
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//validate the data
+		form := PackageForm{
+			NameForm: NameForm{
+				Name: name,
+			},
+			Description: desc,
+			Price:       priceStr,
+			Sessions:    sessionsStr,
+			Expiration:  expirationStr,
+			ServiceIDs:  svcIDStrs,
+		}
+		ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
+		if !ok {
+			return
+		}
+
+		//parse the data
+		price, _ := strconv.ParseFloat(form.Price, 32)
+		sessions, _ := strconv.Atoi(form.Sessions)
+		expirationDays, _ := strconv.Atoi(form.Expiration)
+		if sessions <= 0 {
+			errs[string(FieldErrSessions)] = GetFieldErrText(string(FieldErrSessions))
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//find the matching services
+		matchedSvcs := findServicesByIDs(svcs, form.ServiceIDs)
+		if len(matchedSvcs) == 0 {
+			errs[string(FieldErrSvcIDs)] = GetFieldErrText(string(FieldErrSvcIDs))
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//populate from the form
+		pkg := &SessionPackage{
+			ProviderID:     provider.ID,
+			Name:           form.Name,
+			Description:    form.Description,
+			Price:          float32(price),
+			Sessions:       sessions,
+			ExpirationDays: expirationDays,
+		}
+		pkg.SetServices(matchedSvcs)
+
+		//save the package
+		ctx, err := SaveSessionPackage(ctx, s.getDB(), pkg)
+		if err != nil {
+			logger.Errorw("save session package", "error", err, "package", pkg, "id", provider.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//success
+		s.SetCookieMsg(w, MsgPackageAdd)
+		http.Redirect(w, r.WithContext(ctx), provider.GetURLPackages(), http.StatusSeeOther)
+	}
+}
+
+//handle the package edit page
+func (s *Server) handleDashboardPackageEdit() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+
+	//steps on the page
+	steps := struct {
+		StepDel string
+		StepUpd string
+	}{
+		StepDel: "stepDel",
+		StepUpd: "stepUpd",
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateDashboard(ctx, "package-edit.html")
+		})
+		ctx, provider, data, errs, ok := s.createTemplateDataDashboard(w, r.WithContext(ctx), tpl, true)
+		if !ok {
+			return
+		}
+
+		//setup the breadcrumbs
+		breadcrumbs := []breadcrumb{
+			{"Packages", provider.GetURLPackages()},
+			{"Edit Package", ""},
+		}
+		data[TplParamBreadcrumbs] = breadcrumbs
+		data[TplParamActiveNav] = provider.GetURLPackages()
+		data[TplParamFormAction] = provider.GetURLPackageEdit(nil)
+		data[TplParamSteps] = steps
+
+		//handle the input
+		name := r.FormValue(URLParams.Name)
+		desc := r.FormValue(URLParams.Desc)
+		priceStr := r.FormValue(URLParams.Price)
+		sessionsStr := r.FormValue(URLParams.Sessions)
+		expirationStr := r.FormValue(URLParams.Expiration)
+		svcIDStrs := r.Form[URLParams.SvcIDs]
+		step := r.FormValue(URLParams.Step)
+
+		//prepare the data
+		data[TplParamName] = name
+		data[TplParamDesc] = desc
+		data[TplParamPrice] = priceStr
+		data[TplParamSessions] = sessionsStr
+		data[TplParamExpiration] = expirationStr
+		data[TplParamSvcIDs] = svcIDStrs
+
+		//validate the id
+		idStr := r.FormValue(URLParams.ID)
+		pkgID := uuid.FromStringOrNil(idStr)
+		if pkgID == uuid.Nil {
+			logger.Warnw("invalid uuid", "id", idStr)
+			s.SetCookieErr(w, Err)
+			http.Redirect(w, r.WithContext(ctx), provider.GetURLPackages(), http.StatusSeeOther)
+			return
+		}
+
+		//load the package
+		ctx, pkg, err := LoadSessionPackageByProviderIDAndID(ctx, s.getDB(), provider.ID, &pkgID)
+		if err != nil {
+			logger.Errorw("load session package", "error", err, "id", pkgID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamPackage] = pkg
+
+		//load the services
+		ctx, svcs, ok := s.loadTemplateServices(w, r.WithContext(ctx), tpl, data, provider)
+		if !ok {
+			return
+		}
+
+		//prepare the confirmation modal
+		data[TplParamConfirmMsg] = GetMsgText(MsgPackageDelConfirm)
+		data[TplParamConfirmSubmitName] = URLParams.Step
+		data[TplParamConfirmSubmitValue] = steps.StepDel
+
+		//check the method
+		if r.Method == http.MethodGet {
+			data[TplParamName] = pkg.Name
+			data[TplParamDesc] = pkg.Description
+			data[TplParamPrice] = pkg.Price
+			data[TplParamSessions] = pkg.Sessions
+			data[TplParamExpiration] = pkg.FormatExpirationDays()
+			data[TplParamSvcIDs] = pkg.FormatServiceIDs()
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//execute the correct operation
+		var msgKey MsgKey
+		switch step {
+		case steps.StepDel:
+			//delete the package
+			ctx, err := DeleteSessionPackage(ctx, s.getDB(), provider.ID, pkg.ID)
+			if err != nil {
+				logger.Errorw("delete session package", "error", err, "id", pkg.ID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			msgKey = MsgPackageDel
+		case steps.StepUpd:
+			//validate the data
+			form := PackageForm{
+				NameForm: NameForm{
+					Name: name,
+				},
+				Description: desc,
+				Price:       priceStr,
+				Sessions:    sessionsStr,
+				Expiration:  expirationStr,
+				ServiceIDs:  svcIDStrs,
+			}
+			ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
+			if !ok {
+				return
+			}
+
+			//parse the data
+			price, _ := strconv.ParseFloat(form.Price, 32)
+			sessions, _ := strconv.Atoi(form.Sessions)
+			expirationDays, _ := strconv.Atoi(form.Expiration)
+			if sessions <= 0 {
+				errs[string(FieldErrSessions)] = GetFieldErrText(string(FieldErrSessions))
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+
+			//find the matching services
+			matchedSvcs := findServicesByIDs(svcs, form.ServiceIDs)
+			if len(matchedSvcs) == 0 {
+				errs[string(FieldErrSvcIDs)] = GetFieldErrText(string(FieldErrSvcIDs))
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+
+			//populate from the form, where packages already purchased are unaffected
+			pkg.Name = form.Name
+			pkg.Description = form.Description
+			pkg.Price = float32(price)
+			pkg.Sessions = sessions
+			pkg.ExpirationDays = expirationDays
+			pkg.SetServices(matchedSvcs)
+
+			//update the package
+			ctx, err := SaveSessionPackage(ctx, s.getDB(), pkg)
+			if err != nil {
+				logger.Errorw("save session package", "error", err, "package", pkg)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			msgKey = MsgPackageEdit
+		default:
+			logger.Errorw("invalid step", "id", pkg.ID, "step", step)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//success
+		s.SetCookieMsg(w, msgKey)
+		http.Redirect(w, r.WithContext(ctx), provider.GetURLPackages(), http.StatusSeeOther)
+	}
+}
+
+//handle the packages page
+func (s *Server) handleDashboardPackages() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateDashboard(ctx, "packages.html")
+		})
+		ctx, provider, data, _, ok := s.createTemplateDataDashboard(w, r.WithContext(ctx), tpl, true)
+		if !ok {
+			return
+		}
+
+		//setup the breadcrumbs
+		breadcrumbs := []breadcrumb{
+			{"Packages", ""},
+		}
+		data[TplParamBreadcrumbs] = breadcrumbs
+		data[TplParamActiveNav] = provider.GetURLPackages()
+
+		//load the packages
+		ctx, pkgs, err := ListSessionPackagesByProviderID(ctx, s.getDB(), provider.ID)
+		if err != nil {
+			logger.Errorw("load session packages", "error", err)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		data[TplParamPackages] = pkgs
+		s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+	}
+}
+
+//handle the payment page
+func (s *Server) handleDashboardPayment() http.HandlerFunc {
+	var o sync.Once
 	var tpl *template.Template
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, logger := GetLogger(s.getCtx(r))
@@ -2930,11 +4054,17 @@ func (s *Server) handleDashboardPayment() http.HandlerFunc {
 
 		//check the method
 		if r.Method == http.MethodGet {
+			//default the line items for the booking
 			data[TplParamDesc] = ""
 			data[TplParamEmail] = book.Client.Email
+			data[TplParamItems] = createPaymentItemForms(book.CreatePaymentItems(), book.GetCurrency())
 			data[TplParamName] = book.Client.Name
 			data[TplParamPhone] = book.Client.Phone
-			data[TplParamPrice] = book.ComputeServicePrice()
+			data[TplParamPrice] = book.ComputeServicePriceBalance()
+			data[TplParamTaxRate] = ""
+			if book.HasTax() {
+				data[TplParamTaxRate] = FormatRate(book.ServiceTaxRate)
+			}
 			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
 			return
 		}
@@ -2945,13 +4075,44 @@ func (s *Server) handleDashboardPayment() http.HandlerFunc {
 		name := r.FormValue(URLParams.Name)
 		phone := r.FormValue(URLParams.Phone)
 		priceStr := r.FormValue(URLParams.Price)
+		taxRateStr := r.FormValue(URLParams.TaxRate)
+		itemTypes := r.Form[URLParams.ItemType]
+		itemDescs := r.Form[URLParams.ItemDesc]
+		itemQuantities := r.Form[URLParams.ItemQuantity]
+		itemPrices := r.Form[URLParams.ItemPrice]
+
+		//read the line items, ignoring empty rows
+		var itemForms []*PaymentItemForm
+		for i, itemType := range itemTypes {
+			itemForm := &PaymentItemForm{
+				ItemType: itemType,
+			}
+			if i < len(itemDescs) {
+				itemForm.ItemDesc = strings.TrimSpace(itemDescs[i])
+			}
+			if i < len(itemQuantities) {
+				itemForm.ItemQuantity = itemQuantities[i]
+			}
+			if i < len(itemPrices) {
+				itemForm.ItemPrice = itemPrices[i]
+			}
+			if itemForm.ItemDesc == "" && itemForm.ItemPrice == "" {
+				continue
+			}
+			itemForms = append(itemForms, itemForm)
+		}
+		if len(itemForms) > 0 {
+			priceStr = ""
+		}
 
 		//prepare the data
 		data[TplParamDesc] = desc
 		data[TplParamEmail] = email
+		data[TplParamItems] = itemForms
 		data[TplParamName] = name
 		data[TplParamPhone] = phone
 		data[TplParamPrice] = priceStr
+		data[TplParamTaxRate] = taxRateStr
 
 		//validate the form
 		form := &PaymentForm{
@@ -2964,6 +4125,8 @@ func (s *Server) handleDashboardPayment() http.HandlerFunc {
 			Phone:           FormatPhone(phone),
 			Price:           priceStr,
 			Description:     desc,
+			Items:           itemForms,
+			TaxRate:         taxRateStr,
 			ClientInitiated: false,
 			DirectCapture:   false,
 		}
@@ -2972,6 +4135,18 @@ func (s *Server) handleDashboardPayment() http.HandlerFunc {
 			return
 		}
 
+		//validate the total of the line items
+		if len(form.Items) > 0 {
+			currency := provider.GetCurrency()
+			_, amount := ComputePaymentItems(createPaymentItems(form, currency))
+			data[TplParamPrice] = FormatFloat(currency.FromMinorUnits(amount))
+			if amount <= 0 || amount > currency.ToMinorUnits(priceMax) {
+				errs[string(FieldErrPrice)] = GetFieldErrText(string(FieldErrPrice))
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+		}
+
 		//save the payment
 		ctx, payment, err := s.savePaymentBooking(ctx, provider, book, form, now)
 		if err != nil {
@@ -2997,6 +4172,57 @@ func (s *Server) handleDashboardPayment() http.HandlerFunc {
 	}
 }
 
+//handle downloading the pdf invoice or receipt for a payment
+func (s *Server) handleDashboardPaymentPDF() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		ctx, provider, _, _, ok := s.createTemplateDataDashboard(w, r.WithContext(ctx), nil, true)
+		if !ok {
+			return
+		}
+
+		//load the payment, which must belong to the provider
+		idStr := r.FormValue(URLParams.PaymentID)
+		id := uuid.FromStringOrNil(idStr)
+		if id == uuid.Nil {
+			logger.Errorw("invalid uuid", "id", idStr)
+			s.SetCookieErr(w, Err)
+			http.Redirect(w, r.WithContext(ctx), provider.GetURLPayments(), http.StatusSeeOther)
+			return
+		}
+		ctx, payment, err := LoadPaymentByID(ctx, s.getDB(), &id)
+		if err != nil {
+			logger.Errorw("load payment", "error", err, "id", id)
+			s.SetCookieErr(w, Err)
+			http.Redirect(w, r.WithContext(ctx), provider.GetURLPayments(), http.StatusSeeOther)
+			return
+		}
+		if payment.ProviderID.String() != provider.ID.String() {
+			logger.Errorw("invalid payment provider", "id", id, "providerId", provider.ID)
+			s.SetCookieErr(w, Err)
+			http.Redirect(w, r.WithContext(ctx), provider.GetURLPayments(), http.StatusSeeOther)
+			return
+		}
+
+		//generate the pdf
+		paymentUI := s.createPaymentUI(payment)
+		ctx, paymentPDF, err := s.createPaymentPDF(ctx, provider, paymentUI)
+		if err != nil {
+			logger.Errorw("create payment pdf", "error", err, "id", id)
+			s.SetCookieErr(w, Err)
+			http.Redirect(w, r.WithContext(ctx), paymentUI.GetURLView(), http.StatusSeeOther)
+			return
+		}
+		w.Header().Set(HeaderCacheControl, "no-store")
+		w.Header().Set(HeaderContentType, pdfContentType)
+		w.Header().Set(HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s\"", paymentPDF.FileName))
+		_, err = w.Write(paymentPDF.Data)
+		if err != nil {
+			logger.Warnw("write payment pdf", "error", err, "id", id)
+		}
+	}
+}
+
 //handle the payment view page
 func (s *Server) handleDashboardPaymentView() http.HandlerFunc {
 	var o sync.Once
@@ -3006,9 +4232,11 @@ func (s *Server) handleDashboardPaymentView() http.HandlerFunc {
 	steps := struct {
 		StepDel      string
 		StepMarkPaid string
+		StepRefund   string
 	}{
 		StepDel:      "stepDel",
 		StepMarkPaid: "stepMarkPaid",
+		StepRefund:   "stepRefund",
 	}
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, logger := GetLogger(s.getCtx(r))
@@ -3055,6 +4283,12 @@ func (s *Server) handleDashboardPaymentView() http.HandlerFunc {
 				return
 			}
 			paymentUI = s.createPaymentUI(payment)
+
+			//load all payments made against the booking
+			ctx, ok = s.loadTemplateBookPayments(w, r.WithContext(ctx), tpl, data, provider, book)
+			if !ok {
+				return
+			}
 		} else {
 			//load the payment directly
 			idStr := r.FormValue(URLParams.PaymentID)
@@ -3084,6 +4318,10 @@ func (s *Server) handleDashboardPaymentView() http.HandlerFunc {
 			ctx, book, ok := s.loadTemplateBook(w, r.WithContext(ctx), tpl, data, errs, payment.SecondaryID.String(), false, false)
 			if ok {
 				ctx, _, _ = s.loadTemplateService(w, r.WithContext(ctx), tpl, data, provider, book.Service.ID, now)
+				ctx, ok = s.loadTemplateBookPayments(w, r.WithContext(ctx), tpl, data, provider, book)
+				if !ok {
+					return
+				}
 			} else if paymentUI.ServiceID != "" {
 				svcID := uuid.FromStringOrNil(paymentUI.ServiceID)
 				if svcID == uuid.Nil {
@@ -3104,6 +4342,8 @@ func (s *Server) handleDashboardPaymentView() http.HandlerFunc {
 
 		//check the method
 		if r.Method == http.MethodGet {
+			data[TplParamDesc] = ""
+			data[TplParamPrice] = paymentUI.GetAmountRefundable()
 			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
 			return
 		}
@@ -3111,6 +4351,66 @@ func (s *Server) handleDashboardPaymentView() http.HandlerFunc {
 		//process the step
 		step := r.FormValue(URLParams.Step)
 		switch step {
+		case steps.StepRefund:
+			//sanity check the operation
+			if !paymentUI.AllowRefund() {
+				logger.Errorw("invalid refund", "id", paymentUI.ID)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+
+			//read the form
+			desc := r.FormValue(URLParams.Desc)
+			priceStr := r.FormValue(URLParams.Price)
+
+			//prepare the data
+			data[TplParamDesc] = desc
+			data[TplParamPrice] = priceStr
+
+			//validate the form
+			form := &RefundForm{
+				Price:       priceStr,
+				Description: desc,
+			}
+			ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
+			if !ok {
+				return
+			}
+			price, _ := strconv.ParseFloat(form.Price, 32)
+			if !paymentUI.AllowRefundAmount(float32(price)) {
+				data[TplParamErr] = GetErrText(ErrRefundAmount)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+
+			//a gift card purchase can only be refunded up to the remaining balance
+			if paymentUI.Type == PaymentTypeGiftCard {
+				ctx, card, err := LoadGiftCardByPaymentID(ctx, s.getDB(), paymentUI.ID)
+				if err != nil {
+					logger.Errorw("load gift card", "error", err, "id", paymentUI.ID)
+					data[TplParamErr] = GetErrText(Err)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return
+				}
+				if card != nil && !card.AllowRefundAmount(card.GetCurrency().ToMinorUnits(float32(price))) {
+					data[TplParamErr] = GetErrText(ErrGiftCardRefund, card.FormatBalance())
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return
+				}
+			}
+
+			//refund the payment
+			ctx, err := s.refundPayment(ctx, provider, paymentUI.Payment, float32(price), form.Description, now)
+			if err != nil {
+				logger.Errorw("refund payment", "error", err, "id", paymentUI.ID)
+				data[TplParamErr] = GetErrText(ErrRefund)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			s.SetCookieMsg(w, MsgPaymentRefund)
+			http.Redirect(w, r.WithContext(ctx), paymentUI.GetURLView(), http.StatusSeeOther)
+			return
 		case steps.StepDel:
 			ctx, err := DeletePayment(ctx, s.getDB(), paymentUI.ID)
 			if err != nil {
@@ -3145,11 +4445,17 @@ func (s *Server) handleDashboardPaymentSettings() http.HandlerFunc {
 
 	//steps on the page
 	steps := struct {
-		StepDel string
-		StepUpd string
+		StepCurrency  string
+		StepDel       string
+		StepReminders string
+		StepTips      string
+		StepUpd       string
 	}{
-		StepDel: "stepDel",
-		StepUpd: "stepUpd",
+		StepCurrency:  "stepCurrency",
+		StepDel:       "stepDel",
+		StepReminders: "stepReminders",
+		StepTips:      "stepTips",
+		StepUpd:       "stepUpd",
 	}
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, logger := GetLogger(s.getCtx(r))
@@ -3170,16 +4476,23 @@ func (s *Server) handleDashboardPaymentSettings() http.HandlerFunc {
 		data[TplParamFormAction] = provider.GetURLPaymentSettings()
 		data[TplParamSteps] = steps
 		data[TplParamTypes] = PaymentTypes
+		data[TplParamCurrencies] = Currencies
 
 		//handle the input
+		currency := r.FormValue(URLParams.Currency)
 		email := r.FormValue(URLParams.Email)
 		id := r.FormValue(URLParams.ID)
+		reminderDays := r.FormValue(URLParams.ReminderDays)
 		step := r.FormValue(URLParams.Step)
+		tipPercents := r.FormValue(URLParams.TipPercents)
 		paymentType := r.FormValue(URLParams.Type)
 
 		//prepare the data
+		data[TplParamCurrency] = currency
 		data[TplParamEmail] = email
 		data[TplParamID] = id
+		data[TplParamReminderDays] = reminderDays
+		data[TplParamTipPercents] = tipPercents
 		data[TplParamType] = paymentType
 
 		//prepare the confirmation modal
@@ -3192,42 +4505,152 @@ func (s *Server) handleDashboardPaymentSettings() http.HandlerFunc {
 				data[TplParamConfirmMsg] = GetMsgText(MsgPayPalActivate)
 				data[TplParamConfirmSubmitValue] = steps.StepUpd
 			}
-			data[TplParamConfirmSubmitName] = URLParams.Step
-		case PaymentTypes.TypeStripe:
-			if provider.StripeToken != nil {
-				data[TplParamConfirmMsg] = GetMsgText(MsgStripeRemove)
-				data[TplParamConfirmSubmitValue] = steps.StepDel
-			} else {
-				data[TplParamConfirmMsg] = GetMsgText(MsgStripeActivate)
-				data[TplParamConfirmSubmitValue] = steps.StepUpd
+			data[TplParamConfirmSubmitName] = URLParams.Step
+		case PaymentTypes.TypeStripe:
+			if provider.StripeToken != nil {
+				data[TplParamConfirmMsg] = GetMsgText(MsgStripeRemove)
+				data[TplParamConfirmSubmitValue] = steps.StepDel
+			} else {
+				data[TplParamConfirmMsg] = GetMsgText(MsgStripeActivate)
+				data[TplParamConfirmSubmitValue] = steps.StepUpd
+			}
+			data[TplParamConfirmSubmitName] = URLParams.Step
+		case PaymentTypes.TypeZelle:
+			if provider.ZelleID != nil {
+				data[TplParamConfirmMsg] = GetMsgText(MsgZelleRemove)
+				data[TplParamConfirmSubmitValue] = steps.StepDel
+			} else {
+				data[TplParamConfirmMsg] = GetMsgText(MsgZelleActivate)
+				data[TplParamConfirmSubmitValue] = steps.StepUpd
+			}
+			data[TplParamConfirmSubmitName] = URLParams.Step
+		}
+
+		//check the method
+		if r.Method == http.MethodGet {
+			//default the data
+			data[TplParamCurrency] = string(provider.GetCurrency())
+			data[TplParamReminderDays] = provider.FormatPaymentReminderDays()
+			data[TplParamTipPercents] = provider.FormatTipPercents()
+			if provider.PayPalEmail != nil {
+				data[TplParamEmail] = *provider.PayPalEmail
+			}
+			if provider.ZelleID != nil {
+				data[TplParamID] = *provider.ZelleID
+			}
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//execute the correct operation
+		switch step {
+		case steps.StepCurrency:
+			//validate the data
+			form := CurrencyForm{
+				Currency: currency,
+			}
+			ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
+			if !ok {
+				return
+			}
+
+			//check if existing amounts are affected by the change
+			currencyNew := ParseCurrency(form.Currency)
+			if currencyNew != provider.GetCurrency() {
+				ctx, count, err := CountPaymentsByProviderIDAndFilter(ctx, s.getDB(), provider.ID, PaymentFilterUnPaid)
+				if err != nil {
+					logger.Errorw("count payments unpaid", "error", err, "id", provider.ID)
+					data[TplParamErr] = GetErrText(Err)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return
+				}
+				if count > 0 {
+					errs[string(FieldErrCurrency)] = GetErrText(ErrCurrencyUnPaid, count)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return
+				}
+				ctx, count, err = CountPricedItemsByProviderID(ctx, s.getDB(), provider.ID)
+				if err != nil {
+					logger.Errorw("count priced items", "error", err, "id", provider.ID)
+					data[TplParamErr] = GetErrText(Err)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return
+				}
+				if count > 0 && r.FormValue(URLParams.CurrencyConfirm) == "" {
+					data[TplParamCurrencyConfirm] = true
+					errs[string(FieldErrCurrency)] = GetErrText(ErrCurrencyConfirm, count)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return
+				}
+			}
+
+			//populate from the form
+			provider.Currency = currencyNew
+
+			//save the provider
+			ctx, err := SaveProvider(ctx, s.getDB(), provider.Provider)
+			if err != nil {
+				logger.Errorw("save provider", "error", err, "provider", provider)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+		case steps.StepReminders:
+			//validate the data
+			form := PaymentReminderForm{
+				ReminderDays: reminderDays,
 			}
-			data[TplParamConfirmSubmitName] = URLParams.Step
-		case PaymentTypes.TypeZelle:
-			if provider.ZelleID != nil {
-				data[TplParamConfirmMsg] = GetMsgText(MsgZelleRemove)
-				data[TplParamConfirmSubmitValue] = steps.StepDel
-			} else {
-				data[TplParamConfirmMsg] = GetMsgText(MsgZelleActivate)
-				data[TplParamConfirmSubmitValue] = steps.StepUpd
+			ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
+			if !ok {
+				return
 			}
-			data[TplParamConfirmSubmitName] = URLParams.Step
-		}
 
-		//check the method
-		if r.Method == http.MethodGet {
-			//default the data
-			if provider.PayPalEmail != nil {
-				data[TplParamEmail] = *provider.PayPalEmail
+			//populate from the form
+			days, err := ParsePaymentReminderDays(form.ReminderDays)
+			if err != nil {
+				logger.Errorw("parse reminder days", "error", err, "days", form.ReminderDays)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
 			}
-			if provider.ZelleID != nil {
-				data[TplParamID] = *provider.ZelleID
+			provider.PaymentReminderDays = days
+
+			//save the provider
+			ctx, err = SaveProvider(ctx, s.getDB(), provider.Provider)
+			if err != nil {
+				logger.Errorw("save provider", "error", err, "provider", provider)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+		case steps.StepTips:
+			//validate the data
+			form := TipPercentForm{
+				TipPercents: tipPercents,
+			}
+			ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
+			if !ok {
+				return
 			}
-			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
-			return
-		}
 
-		//execute the correct operation
-		switch step {
+			//populate from the form
+			percents, err := ParseTipPercents(form.TipPercents)
+			if err != nil {
+				logger.Errorw("parse tip percents", "error", err, "percents", form.TipPercents)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			provider.TipPercents = percents
+
+			//save the provider
+			ctx, err = SaveProvider(ctx, s.getDB(), provider.Provider)
+			if err != nil {
+				logger.Errorw("save provider", "error", err, "provider", provider)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
 		case steps.StepDel:
 			switch paymentType {
 			case PaymentTypes.TypePayPal:
@@ -3697,17 +5120,31 @@ func (s *Server) handleDashboardServiceAdd() http.HandlerFunc {
 		//check the method
 		if r.Method == http.MethodGet {
 			data[TplParamApptOnly] = true
+			data[TplParamCancelCutoff] = 0
+			data[TplParamCancelCutoffUnit] = PaddingUnitHours
+			data[TplParamCancelFee] = 0
+			data[TplParamCancelFeeType] = FeeTypePercentage
+			data[TplParamCancelFeeWindow] = 0
+			data[TplParamCapacity] = strconv.Itoa(ServiceCapacityDefault)
+			data[TplParamClass] = false
+			data[TplParamDeposit] = 0
+			data[TplParamDepositType] = FeeTypePercentage
 			data[TplParamDesc] = ""
 			data[TplParamDuration] = ""
 			data[TplParamEnableZoom] = false
+			data[TplParamHorizon] = 0
 			data[TplParamInterval] = strconv.Itoa(ServiceIntervalDefault)
 			data[TplParamLocation] = provider.Location
 			data[TplParamLocationType] = ServiceLocationTypeRemote
 			data[TplParamName] = ""
+			data[TplParamNoShowFee] = 0
+			data[TplParamNoShowFeeType] = FeeTypePercentage
 			data[TplParamNote] = ""
 			data[TplParamPadding] = 0
 			data[TplParamPaddingInitial] = 1
 			data[TplParamPaddingInitialUnit] = PaddingUnitHours
+			data[TplParamPayToBook] = false
+			data[TplParamTaxExempt] = false
 			data[TplParamPrice] = ""
 			data[TplParamPriceType] = ""
 			data[TplParamURLVideo] = ""
@@ -3716,58 +5153,105 @@ func (s *Server) handleDashboardServiceAdd() http.HandlerFunc {
 		}
 
 		//handle the input
-		apptOnly := r.FormValue(URLParams.ApptOnly) == "on"
+		apptOnlyStr := r.FormValue(URLParams.ApptOnly)
+		apptOnly := apptOnlyStr == "on" || apptOnlyStr == "class"
+		class := apptOnlyStr == "class"
+		cancelCutoffStr := r.FormValue(URLParams.CancelCutoff)
+		cancelCutoffUnitStr := r.FormValue(URLParams.CancelCutoffUnit)
+		cancelFeeStr := r.FormValue(URLParams.CancelFee)
+		cancelFeeTypeStr := r.FormValue(URLParams.CancelFeeType)
+		cancelFeeWindowStr := r.FormValue(URLParams.CancelFeeWindow)
+		capacityStr := r.FormValue(URLParams.Capacity)
+		depositStr := r.FormValue(URLParams.Deposit)
+		depositTypeStr := r.FormValue(URLParams.DepositType)
 		desc := r.FormValue(URLParams.Desc)
 		durationStr := r.FormValue(URLParams.Duration)
 		enableZoom := r.FormValue(URLParams.EnableZoom) == "on"
+		horizonStr := r.FormValue(URLParams.Horizon)
 		intervalStr := r.FormValue(URLParams.Interval)
 		location := r.FormValue(URLParams.Location)
 		locationTypeStr := r.FormValue(URLParams.LocationType)
 		name := r.FormValue(URLParams.Name)
+		noShowFeeStr := r.FormValue(URLParams.NoShowFee)
+		noShowFeeTypeStr := r.FormValue(URLParams.NoShowFeeType)
 		note := r.FormValue(URLParams.Note)
 		paddingStr := r.FormValue(URLParams.Padding)
 		paddingInitialStr := r.FormValue(URLParams.PaddingInitial)
 		paddingInitialUnitStr := r.FormValue(URLParams.PaddingInitialUnit)
+		payToBook := r.FormValue(URLParams.PayToBook) == "on"
 		priceStr := r.FormValue(URLParams.Price)
 		priceTypeStr := r.FormValue(URLParams.PriceType)
+		taxExempt := r.FormValue(URLParams.TaxExempt) == "on"
 		urlVideo := r.FormValue(URLParams.URLVideo)
 
 		//prepare the data
 		data[TplParamApptOnly] = apptOnly
+		data[TplParamCancelCutoff] = cancelCutoffStr
+		data[TplParamCancelCutoffUnit] = cancelCutoffUnitStr
+		data[TplParamCancelFee] = cancelFeeStr
+		data[TplParamCancelFeeType] = cancelFeeTypeStr
+		data[TplParamCancelFeeWindow] = cancelFeeWindowStr
+		data[TplParamCapacity] = capacityStr
+		data[TplParamClass] = class
+		data[TplParamDeposit] = depositStr
+		data[TplParamDepositType] = depositTypeStr
 		data[TplParamDesc] = desc
 		data[TplParamDuration] = durationStr
 		data[TplParamEnableZoom] = enableZoom
+		data[TplParamHorizon] = horizonStr
 		data[TplParamInterval] = intervalStr
 		data[TplParamLocation] = location
 		data[TplParamLocationType] = locationTypeStr
 		data[TplParamName] = name
+		data[TplParamNoShowFee] = noShowFeeStr
+		data[TplParamNoShowFeeType] = noShowFeeTypeStr
 		data[TplParamNote] = note
 		data[TplParamPadding] = paddingStr
 		data[TplParamPaddingInitial] = paddingInitialStr
 		data[TplParamPaddingInitialUnit] = paddingInitialUnitStr
+		data[TplParamPayToBook] = payToBook
 		data[TplParamPrice] = priceStr
 		data[TplParamPriceType] = priceTypeStr
+		data[TplParamTaxExempt] = taxExempt
 		data[TplParamURLVideo] = urlVideo
 
 		//validate the data
 		user := provider.GetUser()
+		//only a class allows multiple clients per time
+		if !class {
+			capacityStr = strconv.Itoa(ServiceCapacityDefault)
+		}
 		form := ServiceForm{
-			ApptOnly:     apptOnly,
-			Description:  desc,
-			Duration:     durationStr,
-			EnableZoom:   user.ZoomToken != nil && enableZoom,
-			Interval:     intervalStr,
-			Location:     location,
-			LocationType: locationTypeStr,
+			ApptOnly:         apptOnly,
+			Class:            class,
+			CancelCutoff:     cancelCutoffStr,
+			CancelCutoffUnit: cancelCutoffUnitStr,
+			CancelFee:        cancelFeeStr,
+			CancelFeeType:    cancelFeeTypeStr,
+			CancelFeeWindow:  cancelFeeWindowStr,
+			Capacity:         capacityStr,
+			Deposit:          depositStr,
+			DepositType:      depositTypeStr,
+			Description:      desc,
+			Duration:         durationStr,
+			EnableZoom:       user.ZoomToken != nil && enableZoom,
+			Horizon:          horizonStr,
+			Interval:         intervalStr,
+			Location:         location,
+			LocationType:     locationTypeStr,
 			NameForm: NameForm{
 				Name: name,
 			},
+			NoShowFee:          noShowFeeStr,
+			NoShowFeeType:      noShowFeeTypeStr,
 			Note:               note,
 			Padding:            paddingStr,
 			PaddingInitial:     paddingInitialStr,
 			PaddingInitialUnit: paddingInitialUnitStr,
+			PayToBook:          payToBook,
 			Price:              priceStr,
 			PriceType:          priceTypeStr,
+			TaxExempt:          taxExempt,
 			URLVideo:           urlVideo,
 		}
 		ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
@@ -3885,15 +5369,29 @@ func (s *Server) handleDashboardServiceEdit() http.HandlerFunc {
 		//check the method
 		if r.Method == http.MethodGet {
 			data[TplParamApptOnly] = svc.IsApptOnly()
+			data[TplParamCancelCutoff] = strconv.Itoa(svc.CancelCutoff)
+			data[TplParamCancelCutoffUnit] = svc.GetCancelCutoffUnit()
+			data[TplParamCancelFee] = svc.CancelFee
+			data[TplParamCancelFeeType] = svc.GetCancelFeeType()
+			data[TplParamCancelFeeWindow] = strconv.Itoa(svc.CancelFeeWindow)
+			data[TplParamCapacity] = strconv.Itoa(svc.GetCapacity())
+			data[TplParamClass] = svc.IsClass()
+			data[TplParamDeposit] = svc.Deposit
+			data[TplParamDepositType] = svc.GetDepositType()
 			data[TplParamDesc] = svc.Description
 			data[TplParamDuration] = strconv.Itoa(svc.Duration)
 			data[TplParamEnableZoom] = svc.EnableZoom
+			data[TplParamHorizon] = strconv.Itoa(svc.Horizon)
 			data[TplParamInterval] = strconv.Itoa(svc.Interval)
 			data[TplParamName] = svc.Name
+			data[TplParamNoShowFee] = svc.NoShowFee
+			data[TplParamNoShowFeeType] = svc.GetNoShowFeeType()
 			data[TplParamNote] = svc.Note
 			data[TplParamPadding] = strconv.Itoa(svc.Padding)
 			data[TplParamPaddingInitial] = strconv.Itoa(svc.PaddingInitial)
 			data[TplParamPaddingInitialUnit] = svc.PaddingInitialUnit
+			data[TplParamPayToBook] = svc.PayToBook
+			data[TplParamTaxExempt] = svc.TaxExempt
 			data[TplParamPrice] = svc.Price
 			data[TplParamPriceType] = svc.PriceType
 			data[TplParamURLVideo] = svc.URLVideo
@@ -3910,62 +5408,109 @@ func (s *Server) handleDashboardServiceEdit() http.HandlerFunc {
 		}
 
 		//handle the input
-		apptOnly := r.FormValue(URLParams.ApptOnly) == "on"
+		apptOnlyStr := r.FormValue(URLParams.ApptOnly)
+		apptOnly := apptOnlyStr == "on" || apptOnlyStr == "class"
+		class := apptOnlyStr == "class"
+		cancelCutoffStr := r.FormValue(URLParams.CancelCutoff)
+		cancelCutoffUnitStr := r.FormValue(URLParams.CancelCutoffUnit)
+		cancelFeeStr := r.FormValue(URLParams.CancelFee)
+		cancelFeeTypeStr := r.FormValue(URLParams.CancelFeeType)
+		cancelFeeWindowStr := r.FormValue(URLParams.CancelFeeWindow)
+		capacityStr := r.FormValue(URLParams.Capacity)
+		depositStr := r.FormValue(URLParams.Deposit)
+		depositTypeStr := r.FormValue(URLParams.DepositType)
 		desc := r.FormValue(URLParams.Desc)
 		durationStr := r.FormValue(URLParams.Duration)
 		enableZoom := r.FormValue(URLParams.EnableZoom) == "on"
+		horizonStr := r.FormValue(URLParams.Horizon)
 		imgIdxs := r.Form[URLParams.ImgIdx]
 		intervalStr := r.FormValue(URLParams.Interval)
 		location := r.FormValue(URLParams.Location)
 		locationTypeStr := r.FormValue(URLParams.LocationType)
 		name := r.FormValue(URLParams.Name)
+		noShowFeeStr := r.FormValue(URLParams.NoShowFee)
+		noShowFeeTypeStr := r.FormValue(URLParams.NoShowFeeType)
 		note := r.FormValue(URLParams.Note)
 		paddingStr := r.FormValue(URLParams.Padding)
 		paddingInitialStr := r.FormValue(URLParams.PaddingInitial)
 		paddingInitialUnitStr := r.FormValue(URLParams.PaddingInitialUnit)
+		payToBook := r.FormValue(URLParams.PayToBook) == "on"
 		priceStr := r.FormValue(URLParams.Price)
 		priceTypeStr := r.FormValue(URLParams.PriceType)
+		taxExempt := r.FormValue(URLParams.TaxExempt) == "on"
 		urlVideo := r.FormValue(URLParams.URLVideo)
 		step := r.FormValue(URLParams.Step)
 		viewType := r.FormValue(URLParams.Type)
 
 		//prepare the data
 		data[TplParamApptOnly] = apptOnly
+		data[TplParamCancelCutoff] = cancelCutoffStr
+		data[TplParamCancelCutoffUnit] = cancelCutoffUnitStr
+		data[TplParamCancelFee] = cancelFeeStr
+		data[TplParamCancelFeeType] = cancelFeeTypeStr
+		data[TplParamCancelFeeWindow] = cancelFeeWindowStr
+		data[TplParamCapacity] = capacityStr
+		data[TplParamClass] = class
+		data[TplParamDeposit] = depositStr
+		data[TplParamDepositType] = depositTypeStr
 		data[TplParamDesc] = desc
 		data[TplParamDuration] = durationStr
 		data[TplParamEnableZoom] = enableZoom
+		data[TplParamHorizon] = horizonStr
 		data[TplParamInterval] = intervalStr
 		data[TplParamLocation] = location
 		data[TplParamLocationType] = locationTypeStr
 		data[TplParamName] = name
+		data[TplParamNoShowFee] = noShowFeeStr
+		data[TplParamNoShowFeeType] = noShowFeeTypeStr
 		data[TplParamNote] = note
 		data[TplParamPadding] = paddingStr
 		data[TplParamPaddingInitial] = paddingInitialStr
 		data[TplParamPaddingInitialUnit] = paddingInitialUnitStr
+		data[TplParamPayToBook] = payToBook
 		data[TplParamPrice] = priceStr
 		data[TplParamPriceType] = priceTypeStr
+		data[TplParamTaxExempt] = taxExempt
 		data[TplParamURLVideo] = urlVideo
 		data[TplParamType] = viewType
 
 		//validate the data
 		user := provider.GetUser()
+		//only a class allows multiple clients per time
+		if !class {
+			capacityStr = strconv.Itoa(ServiceCapacityDefault)
+		}
 		form := ServiceForm{
-			ApptOnly:     apptOnly,
-			Description:  desc,
-			Duration:     durationStr,
-			EnableZoom:   user.ZoomToken != nil && enableZoom,
-			Interval:     intervalStr,
-			Location:     location,
-			LocationType: locationTypeStr,
+			ApptOnly:         apptOnly,
+			Class:            class,
+			CancelCutoff:     cancelCutoffStr,
+			CancelCutoffUnit: cancelCutoffUnitStr,
+			CancelFee:        cancelFeeStr,
+			CancelFeeType:    cancelFeeTypeStr,
+			CancelFeeWindow:  cancelFeeWindowStr,
+			Capacity:         capacityStr,
+			Deposit:          depositStr,
+			DepositType:      depositTypeStr,
+			Description:      desc,
+			Duration:         durationStr,
+			EnableZoom:       user.ZoomToken != nil && enableZoom,
+			Horizon:          horizonStr,
+			Interval:         intervalStr,
+			Location:         location,
+			LocationType:     locationTypeStr,
 			NameForm: NameForm{
 				Name: name,
 			},
+			NoShowFee:          noShowFeeStr,
+			NoShowFeeType:      noShowFeeTypeStr,
 			Note:               note,
 			Padding:            paddingStr,
 			PaddingInitial:     paddingInitialStr,
 			PaddingInitialUnit: paddingInitialUnitStr,
+			PayToBook:          payToBook,
 			Price:              priceStr,
 			PriceType:          priceTypeStr,
+			TaxExempt:          taxExempt,
 			URLVideo:           urlVideo,
 		}
 		ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
@@ -3993,7 +5538,11 @@ func (s *Server) handleDashboardServiceEdit() http.HandlerFunc {
 			}
 		case steps.StepUpd:
 			//populate from the form
-			svc.SetFields(apptOnly, form.Name, form.Description, form.Note, form.Price, form.PriceType, form.Duration, form.LocationType, form.Location, form.Padding, form.PaddingInitial, form.PaddingInitialUnit, form.Interval, form.EnableZoom, form.URLVideo)
+			svc.SetFields(apptOnly, class, form.Capacity, form.Name, form.Description, form.Note, form.Price, form.PriceType, form.Duration, form.LocationType, form.Location, form.Padding, form.PaddingInitial, form.PaddingInitialUnit, form.Horizon, form.CancelCutoff, form.CancelCutoffUnit, form.Interval, form.EnableZoom, form.URLVideo)
+			svc.SetCancelPolicy(form.CancelFeeWindow, form.CancelFee, form.CancelFeeType, form.NoShowFee, form.NoShowFeeType)
+			svc.SetDeposit(form.Deposit, form.DepositType)
+			svc.PayToBook = form.PayToBook
+			svc.TaxExempt = form.TaxExempt
 
 			//handle the delete and re-ordering of any images
 			svc.ProcessImgIndices(imgIdxs)
@@ -4300,6 +5849,153 @@ func (s *Server) handleDashboardServices() http.HandlerFunc {
 	}
 }
 
+//handle the sales tax page
+func (s *Server) handleDashboardTaxes() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+
+	//steps on the page
+	steps := struct {
+		StepAdd string
+		StepDel string
+	}{
+		StepAdd: "stepAdd",
+		StepDel: "stepDel",
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateDashboard(ctx, "taxes.html")
+		})
+		ctx, provider, data, errs, ok := s.createTemplateDataDashboard(w, r.WithContext(ctx), tpl, true)
+		if !ok {
+			return
+		}
+
+		//setup the breadcrumbs
+		breadcrumbs := []breadcrumb{
+			{"Sales Tax", ""},
+		}
+		data[TplParamBreadcrumbs] = breadcrumbs
+		data[TplParamActiveNav] = provider.GetURLTaxes()
+		data[TplParamFormAction] = provider.GetURLTaxes()
+		data[TplParamSteps] = steps
+		data[TplParamTaxRates] = provider.TaxRates
+
+		//prepare the confirmation modal
+		data[TplParamConfirmMsg] = GetMsgText(MsgTaxRateDelConfirm)
+		data[TplParamConfirmSubmitName] = URLParams.Step
+		data[TplParamConfirmSubmitValue] = steps.StepDel
+
+		//determine the report dates, defaulting to the year to date
+		now := data[TplParamCurrentTime].(time.Time)
+		timeZone := provider.User.TimeZone
+		today := ParseDateLocal(FormatDateLocal(now, timeZone), timeZone)
+		periodType := ParseTaxPeriodType(r.FormValue(URLParams.Period))
+		start := ParseDateLocal(r.FormValue(URLParams.Start), timeZone)
+		if start.IsZero() {
+			start = TaxPeriodTypeAnnually.GetStart(today)
+		}
+		end := ParseDateLocal(r.FormValue(URLParams.End), timeZone)
+		if end.IsZero() || end.Before(start) {
+			end = today
+		}
+		data[TplParamEnd] = FormatDateLocal(end, timeZone)
+		data[TplParamPeriod] = periodType
+		data[TplParamPeriodTypes] = TaxPeriodTypes
+		data[TplParamStart] = FormatDateLocal(start, timeZone)
+
+		//load the payments captured during the report dates
+		ctx, payments, err := ListPaymentsByProviderIDAndCaptured(ctx, s.getDB(), provider.ID, start, end.AddDate(0, 0, 1))
+		if err != nil {
+			logger.Errorw("load payments", "error", err, "id", provider.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		report, total := CreateTaxReport(payments, periodType, provider.GetCurrency(), timeZone)
+		data[TplParamReport] = report
+		data[TplParamReportTips] = CreateTipReport(payments, provider.GetCurrency(), provider.Name)
+		data[TplParamReportTotal] = total
+
+		//check the method
+		if r.Method == http.MethodGet {
+			data[TplParamName] = ""
+			data[TplParamRegion] = ""
+			data[TplParamTaxRate] = ""
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//execute the correct operation
+		var msgKey MsgKey
+		step := r.FormValue(URLParams.Step)
+		switch step {
+		case steps.StepAdd:
+			//handle the input
+			name := r.FormValue(URLParams.Name)
+			region := strings.TrimSpace(r.FormValue(URLParams.Region))
+			taxRateStr := r.FormValue(URLParams.TaxRate)
+
+			//prepare the data
+			data[TplParamName] = name
+			data[TplParamRegion] = region
+			data[TplParamTaxRate] = taxRateStr
+
+			//validate the data
+			form := TaxRateForm{
+				Name:    name,
+				Region:  region,
+				TaxRate: taxRateStr,
+			}
+			ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
+			if !ok {
+				return
+			}
+
+			//create the tax rate
+			taxRate, err := s.createTaxRate(&form)
+			if err != nil {
+				logger.Errorw("create tax rate", "error", err, "form", form)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			provider.AddTaxRate(taxRate)
+			msgKey = MsgTaxRateAdd
+		case steps.StepDel:
+			//validate the id
+			idStr := r.FormValue(URLParams.ID)
+			id := uuid.FromStringOrNil(idStr)
+			if id == uuid.Nil || !provider.DeleteTaxRate(&id) {
+				logger.Warnw("invalid tax rate id", "id", idStr)
+				s.SetCookieErr(w, Err)
+				http.Redirect(w, r.WithContext(ctx), provider.GetURLTaxes(), http.StatusSeeOther)
+				return
+			}
+			msgKey = MsgTaxRateDel
+		default:
+			logger.Errorw("invalid step", "step", step)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//save the provider
+		ctx, err = SaveProvider(ctx, s.getDB(), provider.Provider)
+		if err != nil {
+			logger.Errorw("save provider", "error", err, "provider", provider)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//success
+		s.SetCookieMsg(w, msgKey)
+		http.Redirect(w, r.WithContext(ctx), provider.GetURLTaxes(), http.StatusSeeOther)
+	}
+}
+
 //handle the testimonial add page
 func (s *Server) handleDashboardTestimonialAdd() http.HandlerFunc {
 	var o sync.Once
@@ -4581,6 +6277,152 @@ func (s *Server) handleDashboardTestimonials() http.HandlerFunc {
 	}
 }
 
+//handle the time off page
+func (s *Server) handleDashboardTimeOff() http.HandlerFunc {
+	var o sync.Once
+	var tpl *template.Template
+
+	//steps on the page
+	steps := struct {
+		StepAdd string
+		StepDel string
+	}{
+		StepAdd: "stepAdd",
+		StepDel: "stepDel",
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, logger := GetLogger(s.getCtx(r))
+		o.Do(func() {
+			tpl = s.loadWebTemplateDashboard(ctx, "time-off.html")
+		})
+		ctx, provider, data, errs, ok := s.createTemplateDataDashboard(w, r.WithContext(ctx), tpl, false)
+		if !ok {
+			return
+		}
+
+		//setup the breadcrumbs
+		breadcrumbs := []breadcrumb{
+			{"Schedule", provider.GetURLHours()},
+			{"Time Off", ""},
+		}
+		data[TplParamBreadcrumbs] = breadcrumbs
+		data[TplParamActiveNav] = provider.GetURLTimeOff()
+		data[TplParamFormAction] = provider.GetURLTimeOff()
+		data[TplParamSteps] = steps
+
+		//load the schedule exceptions
+		schedule := provider.GetSchedule()
+		if schedule == nil {
+			logger.Errorw("no schedule", "id", provider.ID)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		now := data[TplParamCurrentTime].(time.Time)
+		timeZone := provider.User.TimeZone
+		data[TplParamScheduleExceptions] = schedule.ListExceptionsUpcoming(now.In(GetLocation(timeZone)))
+
+		//prepare the confirmation modal
+		data[TplParamConfirmMsg] = GetMsgText(MsgTimeOffDelConfirm)
+		data[TplParamConfirmSubmitName] = URLParams.Step
+		data[TplParamConfirmSubmitValue] = steps.StepDel
+
+		//check the method
+		if r.Method == http.MethodGet {
+			data[TplParamClosed] = true
+			data[TplParamDate] = FormatDateLocal(now, timeZone)
+			data[TplParamDuration] = strconv.Itoa(ProviderDefaultScheduleDuration)
+			data[TplParamTime] = ProviderDefaultScheduleStart
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//execute the correct operation
+		var msgKey MsgKey
+		step := r.FormValue(URLParams.Step)
+		switch step {
+		case steps.StepAdd:
+			//handle the input
+			annual := r.FormValue(URLParams.Annual) == "on"
+			closed := r.FormValue(URLParams.Closed) == "on"
+			date := r.FormValue(URLParams.Date)
+			duration := r.FormValue(URLParams.Duration)
+			name := r.FormValue(URLParams.Name)
+			timeStr := r.FormValue(URLParams.Time)
+
+			//prepare the data
+			data[TplParamAnnual] = annual
+			data[TplParamClosed] = closed
+			data[TplParamDate] = date
+			data[TplParamDuration] = duration
+			data[TplParamName] = name
+			data[TplParamTime] = timeStr
+
+			//validate the data
+			form := ScheduleExceptionForm{
+				Annual:   annual,
+				Closed:   closed,
+				Date:     date,
+				Duration: duration,
+				Name:     name,
+				Time:     timeStr,
+			}
+			ok = s.validateForm(w, r.WithContext(ctx), tpl, data, errs, form, true)
+			if !ok {
+				return
+			}
+
+			//create the exception
+			exception, err := s.createScheduleException(&form)
+			if err != nil {
+				logger.Errorw("create schedule exception", "error", err, "form", form)
+				data[TplParamErr] = GetErrText(Err)
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+
+			//the working hours cannot cross into the next day
+			if !exception.Closed && exception.TimeDurations[0].GetEnd() > minutesDay {
+				errs[string(FieldErrDuration)] = GetFieldErrText(string(FieldErrDuration))
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return
+			}
+			schedule.AddException(exception)
+			msgKey = MsgTimeOffAdd
+		case steps.StepDel:
+			//validate the id
+			idStr := r.FormValue(URLParams.ID)
+			id := uuid.FromStringOrNil(idStr)
+			if id == uuid.Nil || !schedule.DeleteException(&id) {
+				logger.Warnw("invalid schedule exception id", "id", idStr)
+				s.SetCookieErr(w, Err)
+				http.Redirect(w, r.WithContext(ctx), provider.GetURLTimeOff(), http.StatusSeeOther)
+				return
+			}
+			msgKey = MsgTimeOffDel
+		default:
+			logger.Errorw("invalid step", "step", step)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+		provider.SetSchedule(schedule)
+
+		//save the provider
+		ctx, err := SaveProvider(ctx, s.getDB(), provider.Provider)
+		if err != nil {
+			logger.Errorw("save provider", "error", err, "provider", provider)
+			data[TplParamErr] = GetErrText(Err)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return
+		}
+
+		//success
+		s.SetCookieMsg(w, msgKey)
+		http.Redirect(w, r.WithContext(ctx), provider.GetURLTimeOff(), http.StatusSeeOther)
+	}
+}
+
 //handle the user add page
 func (s *Server) handleDashboardUserAdd() http.HandlerFunc {
 	var o sync.Once
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"io/ioutil"
 	"net/http"
+
+	"github.com/gofrs/uuid"
 )
 
 //handle the paypal webhook callback
@@ -81,6 +83,14 @@ func (s *Server) handlePayPalWebHookCallback() http.HandlerFunc {
 					return
 				}
 
+				//ignore captures for invoices not created by a payment
+				paymentID, err := uuid.FromString(resource.InvoiceID)
+				if err != nil {
+					logger.Warnw("capture for unknown invoice", "error", err, "id", resource.InvoiceID)
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+
 				//store the response
 				now := GetTimeNow("")
 				ctx, err = UpdatePaymentCaptured(ctx, s.getDB(), &resource.InvoiceID, &bodyStr, &now)
@@ -89,6 +99,73 @@ func (s *Server) handlePayPalWebHookCallback() http.HandlerFunc {
 					w.WriteHeader(http.StatusInternalServerError)
 					return
 				}
+
+				//confirm a booking waiting on the payment
+				ctx, payment, err := LoadPaymentByID(ctx, s.getDB(), &paymentID)
+				if err != nil {
+					logger.Errorw("load payment", "error", err, "id", paymentID)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				if payment == nil || payment.Type != PaymentTypeBooking {
+					logger.Debugw("capture not for a booking", "id", paymentID)
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				ctx, err = s.confirmBookingPaymentCaptured(ctx, payment, now)
+				if err != nil {
+					logger.Errorw("confirm booking payment", "error", err, "id", paymentID)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+			}
+		} else if event.ResourceType == PayPalResourceTypeRefund {
+			if event.EventType == PayPalEventTypePaymentCaptureRefunded {
+				resource, err := ParseResourceRefundPayPal([]byte(event.Resource))
+				if err != nil {
+					logger.Errorw("parse refund resource paypal", "error", err, "body", event.Resource)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				//load the refunded payment, ignoring refunds for invoices not created by a payment
+				paymentID, err := uuid.FromString(resource.InvoiceID)
+				if err != nil {
+					logger.Warnw("refund for unknown invoice", "error", err, "id", resource.InvoiceID)
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				ctx, payment, err := LoadPaymentByID(ctx, s.getDB(), &paymentID)
+				if err != nil {
+					logger.Errorw("load payment", "error", err, "id", paymentID)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				if payment == nil {
+					logger.Warnw("refund for unknown payment", "id", paymentID)
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+
+				//record the refund
+				amount, err := resource.Amount.GetAmount()
+				if err != nil {
+					logger.Errorw("parse refund amount", "error", err, "body", event.Resource)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				refund := &PaymentRefund{
+					ExternalID: resource.ID,
+					Amount:     amount,
+					Reason:     resource.NoteToPayer,
+					Created:    GetTimeNow(""),
+				}
+				ctx, err = s.savePaymentRefundExternal(ctx, payment, refund)
+				if err != nil {
+					logger.Errorw("save payment refund", "error", err, "id", paymentID)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
 			}
 		}
 		w.WriteHeader(http.StatusOK)
@@ -1190,7 +1190,7 @@ func (s *Server) handleProviderSignUpMain() http.HandlerFunc {
 			}
 
 			//default the schedule
-			start := ParseTimeLocal(ProviderDefaultScheduleStart, now, provider.User.TimeZone)
+			start, _ := ParseTimeOfDay(ProviderDefaultScheduleStart)
 			duration := ProviderDefaultScheduleDuration
 			schedule := provider.GetSchedule()
 			if schedule == nil {
@@ -1221,7 +1221,7 @@ func (s *Server) handleProviderSignUpMain() http.HandlerFunc {
 					}
 				}
 			}
-			data[TplParamTime] = FormatTimeLocal(start, provider.User.TimeZone)
+			data[TplParamTime] = start.Format()
 			data[TplParamScheduleDuration] = duration
 			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
 			return
@@ -1299,15 +1299,26 @@ func (s *Server) handleProviderSignUpMain() http.HandlerFunc {
 			Experience:   experience,
 			ServiceArea:  svcArea,
 			Service: ServiceForm{
-				ApptOnly:     true,
-				Description:  desc,
-				Duration:     duration,
-				Interval:     ServiceIntervals[0].ValueStr,
-				Location:     provider.Location,
-				LocationType: string(ServiceLocationTypeRemote),
+				ApptOnly:         true,
+				CancelCutoff:     "0",
+				CancelCutoffUnit: string(PaddingUnitHours),
+				CancelFee:        "0",
+				CancelFeeType:    string(FeeTypePercentage),
+				CancelFeeWindow:  "0",
+				Capacity:         strconv.Itoa(ServiceCapacityDefault),
+				Deposit:          "0",
+				DepositType:      string(FeeTypePercentage),
+				Description:      desc,
+				Duration:         duration,
+				Horizon:          "0",
+				Interval:         ServiceIntervals[0].ValueStr,
+				Location:         provider.Location,
+				LocationType:     string(ServiceLocationTypeRemote),
 				NameForm: NameForm{
 					Name: subject,
 				},
+				NoShowFee:          "0",
+				NoShowFeeType:      string(FeeTypePercentage),
 				Padding:            "0",
 				PaddingInitial:     "0",
 				PaddingInitialUnit: string(PaddingUnitHours),
@@ -1355,7 +1366,7 @@ func (s *Server) handleProviderSignUpMain() http.HandlerFunc {
 
 		//initalize the schedule
 		scheduleDuration, _ := strconv.ParseInt(form.ScheduleDuration, 10, 32)
-		err = s.createSchedule(provider, now, !checkedMon, !checkedTue, !checkedWed, !checkedThu, !checkedFri, !checkedSat, !checkedSun, form.Time, int(scheduleDuration))
+		err = s.createSchedule(provider, !checkedMon, !checkedTue, !checkedWed, !checkedThu, !checkedFri, !checkedSat, !checkedSun, form.Time, int(scheduleDuration))
 		if err != nil {
 			logger.Errorw("create schedule", "id", userID)
 			data[TplParamErr] = GetErrText(Err)
@@ -2,6 +2,9 @@ package main
 
 import (
 	"net/http"
+	"time"
+
+	"github.com/gofrs/uuid"
 )
 
 //handle the stripe login
@@ -179,6 +182,27 @@ func (s *Server) handleStripeWebHookCallback() http.HandlerFunc {
 					return
 				}
 
+				//ignore sessions saving a card, which have no payment
+				if string(session.Mode) == StripeModeSetup {
+					break
+				}
+
+				//link the subscription for a membership, which is paid through the invoice
+				if string(session.Mode) == StripeModeSubscription {
+					membershipID := uuid.FromStringOrNil(session.ClientReferenceID)
+					if membershipID == uuid.Nil || session.Subscription == nil {
+						logger.Warnw("subscription session for unknown membership", "id", session.ID)
+						break
+					}
+					ctx, err = UpdateClientMembershipSubscription(ctx, s.getDB(), &membershipID, session.Subscription.ID)
+					if err != nil {
+						logger.Errorw("update client membership subscription", "error", err, "id", membershipID)
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					break
+				}
+
 				//store the response
 				ctx, err = UpdatePaymentCapturedByExternalID(ctx, s.getDB(), &session.PaymentIntent.ID, &body, &now)
 				if err != nil {
@@ -186,6 +210,24 @@ func (s *Server) handleStripeWebHookCallback() http.HandlerFunc {
 					w.WriteHeader(http.StatusInternalServerError)
 					return
 				}
+
+				//confirm a booking waiting on the payment
+				ctx, payment, err := LoadPaymentByExternalID(ctx, s.getDB(), &session.PaymentIntent.ID)
+				if err != nil {
+					logger.Errorw("load payment", "error", err, "id", session.PaymentIntent.ID)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				if payment == nil || payment.Type != PaymentTypeBooking {
+					logger.Debugw("capture not for a booking", "id", session.PaymentIntent.ID)
+					break
+				}
+				ctx, err = s.confirmBookingPaymentCaptured(ctx, payment, now)
+				if err != nil {
+					logger.Errorw("confirm booking payment", "error", err, "id", session.PaymentIntent.ID)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
 			case StripeEventTypePaymentIntentSucceeded:
 				intent, err := ParsePaymentIntentStripe(event.Data.Raw)
 				if err != nil {
@@ -201,6 +243,114 @@ func (s *Server) handleStripeWebHookCallback() http.HandlerFunc {
 					w.WriteHeader(http.StatusInternalServerError)
 					return
 				}
+
+				//confirm a booking waiting on the payment
+				ctx, payment, err := LoadPaymentByExternalID(ctx, s.getDB(), &intent.ID)
+				if err != nil {
+					logger.Errorw("load payment", "error", err, "id", intent.ID)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				if payment == nil || payment.Type != PaymentTypeBooking {
+					logger.Debugw("capture not for a booking", "id", intent.ID)
+					break
+				}
+				ctx, err = s.confirmBookingPaymentCaptured(ctx, payment, now)
+				if err != nil {
+					logger.Errorw("confirm booking payment", "error", err, "id", intent.ID)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+			case StripeEventTypeInvoicePaid:
+				invoice, err := ParseInvoiceStripe(event.Data.Raw)
+				if err != nil {
+					logger.Errorw("parse invoice stripe", "error", err)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				//activate the membership for the paid period
+				ctx, err = s.saveClientMembershipInvoiceStripe(ctx, invoice, event.Account, body, now)
+				if err != nil {
+					logger.Errorw("save client membership invoice", "error", err, "id", invoice.ID)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+			case StripeEventTypeInvoicePaymentFailed:
+				invoice, err := ParseInvoiceStripe(event.Data.Raw)
+				if err != nil {
+					logger.Errorw("parse invoice stripe", "error", err)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				subscriptionID := invoice.GetSubscriptionID()
+				if subscriptionID == "" {
+					break
+				}
+
+				//the membership lapses until stripe retries the payment successfully
+				ctx, err = UpdateClientMembershipStatusBySubscriptionID(ctx, s.getDB(), subscriptionID, MembershipStatusPastDue)
+				if err != nil {
+					logger.Errorw("update client membership status", "error", err, "id", subscriptionID)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+			case StripeEventTypeSubscriptionDeleted:
+				subscription, err := ParseSubscriptionStripe(event.Data.Raw)
+				if err != nil {
+					logger.Errorw("parse subscription stripe", "error", err)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				ctx, err = UpdateClientMembershipStatusBySubscriptionID(ctx, s.getDB(), subscription.ID, MembershipStatusCancelled)
+				if err != nil {
+					logger.Errorw("update client membership status", "error", err, "id", subscription.ID)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+			case StripeEventTypeChargeRefunded:
+				charge, err := ParseChargeStripe(event.Data.Raw)
+				if err != nil {
+					logger.Errorw("parse charge stripe", "error", err)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				//payments store the payment intent, if any, otherwise the charge
+				id := charge.PaymentIntent
+				if id == "" {
+					id = charge.ID
+				}
+				ctx, payment, err := LoadPaymentByExternalID(ctx, s.getDB(), &id)
+				if err != nil {
+					logger.Errorw("load payment", "error", err, "id", id)
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				if payment == nil || charge.Refunds == nil {
+					logger.Warnw("refund for unknown payment", "id", id)
+					break
+				}
+
+				//record the refunds
+				for _, result := range charge.Refunds.Data {
+					refundStripe := &RefundStripe{result}
+					if refundStripe.IsFailed() {
+						continue
+					}
+					refund := &PaymentRefund{
+						ExternalID: refundStripe.ID,
+						Amount:     int(refundStripe.Amount),
+						Reason:     refundStripe.GetReason(),
+						Created:    time.Unix(refundStripe.Created, 0),
+					}
+					ctx, err = s.savePaymentRefundExternal(ctx, payment, refund)
+					if err != nil {
+						logger.Errorw("save payment refund", "error", err, "id", id)
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+				}
 			}
 		}
 		w.WriteHeader(http.StatusOK)
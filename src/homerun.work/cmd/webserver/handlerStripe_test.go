@@ -0,0 +1,147 @@
+package main
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stripe/stripe-go/webhook"
+)
+
+//create a signed stripe webhook request for the event
+func createStripeWebHookRequest(eventType string, obj string) *http.Request {
+	secret := "whsec_test"
+	viper.Set(cfgKeyStripeWebHookSecretCheckout, secret)
+	viper.Set(cfgKeyStripeLive, false)
+	body := fmt.Sprintf(`{"id":"evt_test","object":"event","type":"%s","livemode":false,"data":{"object":%s}}`, eventType, obj)
+	now := time.Now()
+	signature := webhook.ComputeSignature(now, []byte(body), secret)
+	r := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/?%s=checkout", URLParams.State), strings.NewReader(body))
+	r.Header.Set(StripeHeaderSignature, fmt.Sprintf("t=%d,v1=%x", now.Unix(), signature))
+	return r
+}
+
+func TestStripeWebHookCaptureStatus(t *testing.T) {
+	events := []struct {
+		name      string
+		eventType string
+		obj       string
+	}{
+		{"checkout session", StripeEventTypeCheckoutSessionCompleted, `{"id":"cs_test","object":"checkout.session","mode":"payment","payment_intent":"pi_test"}`},
+		{"payment intent", StripeEventTypePaymentIntentSucceeded, `{"id":"pi_test","object":"payment_intent"}`},
+	}
+	tests := []struct {
+		name    string
+		payment *Payment
+		loadErr error
+		want    int
+	}{
+		{"payment fails to load", nil, errors.New("connection lost"), http.StatusInternalServerError},
+		{"unknown payment", nil, nil, http.StatusOK},
+		{"payment not for a booking", &Payment{Type: PaymentTypeDirect}, nil, http.StatusOK},
+	}
+	for _, event := range events {
+		for _, test := range tests {
+			t.Run(fmt.Sprintf("%s: %s", event.name, test.name), func(t *testing.T) {
+				db, s := newFakeDB(t)
+				db.onExec(fmt.Sprintf("INSERT INTO %s", dbTableEventStripe), 1)
+				db.onExec("captured=?,deleted=0 WHERE stripe_id=?", 1)
+				loadStmt := "p.deleted=0 AND (p.stripe_id=?"
+				if test.loadErr != nil {
+					db.onErr(loadStmt, test.loadErr)
+				} else if test.payment != nil {
+					test.payment.ID = newFakeID(t)
+					test.payment.ProviderID = newFakeID(t)
+					test.payment.SecondaryID = newFakeID(t)
+					db.onRows(loadStmt, fakePaymentRow(t, test.payment))
+				} else {
+					db.onRows(loadStmt)
+				}
+
+				w := httptest.NewRecorder()
+				s.handleStripeWebHookCallback()(w, createStripeWebHookRequest(event.eventType, event.obj))
+				if w.Code != test.want {
+					t.Errorf("got status %d, want %d", w.Code, test.want)
+				}
+				if len(db.executed("captured=?,deleted=0 WHERE stripe_id=?")) != 1 {
+					t.Errorf("capture not stored")
+				}
+			})
+		}
+	}
+}
+
+func TestStripeWebHookChargeRefunded(t *testing.T) {
+	charge := `{"id":"ch_test","object":"charge","payment_intent":"pi_test","refunds":{"object":"list","data":[{"id":"re_test","object":"refund","amount":1000,"status":"succeeded","created":1600000000}]}}`
+	tests := []struct {
+		name     string
+		refunds  []*PaymentRefund
+		loadErr  error
+		want     int
+		recorded bool
+	}{
+		{"payment fails to load", nil, errors.New("connection lost"), http.StatusInternalServerError, false},
+		{"new refund", nil, nil, http.StatusOK, true},
+		{"refund made from the dashboard", []*PaymentRefund{{ExternalID: "re_test", Amount: 1000}}, nil, http.StatusOK, false},
+		{"refund for another charge", []*PaymentRefund{{ExternalID: "re_other", Amount: 500}}, nil, http.StatusOK, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, s := newFakeDB(t)
+			user := &User{ID: newFakeID(t), Email: "provider@example.com"}
+			provider := &Provider{ID: newFakeID(t), User: user, Name: "Provider", URLName: "provider"}
+			stripeID := "pi_test"
+			payment := &Payment{
+				ID:          newFakeID(t),
+				ProviderID:  provider.ID,
+				SecondaryID: newFakeID(t),
+				Type:        PaymentTypeBooking,
+				Amount:      5000,
+				StripeID:    &stripeID,
+				Email:       "client@example.com",
+				Refunds:     test.refunds,
+			}
+			db.onExec(fmt.Sprintf("INSERT INTO %s", dbTableEventStripe), 1)
+			loadStmt := "p.deleted=0 AND (p.stripe_id=?"
+			if test.loadErr != nil {
+				db.onErr(loadStmt, test.loadErr)
+			} else {
+				db.onRows(loadStmt, fakePaymentRow(t, payment))
+			}
+			db.onRows(fmt.Sprintf("SELECT amount,data FROM %s WHERE id=UUID_TO_BIN(?) FOR UPDATE", dbTablePayment), []driver.Value{int64(payment.Amount), fakeJSON(t, payment)})
+			db.onExec(fmt.Sprintf("UPDATE %s SET data=?", dbTablePayment), 1)
+			db.onRows(fmt.Sprintf("FROM %s WHERE deleted=0 AND refund=0", dbTableGiftCardEntry))
+			db.onRows("p.deleted=0 AND p.id=UUID_TO_BIN(?)", fakeProviderRow(t, provider))
+			db.onExec(fmt.Sprintf("INSERT INTO %s", dbTableMessage), 1)
+
+			w := httptest.NewRecorder()
+			s.handleStripeWebHookCallback()(w, createStripeWebHookRequest(StripeEventTypeChargeRefunded, charge))
+			if w.Code != test.want {
+				t.Errorf("got status %d, want %d", w.Code, test.want)
+			}
+
+			//a refund is recorded and emailed once
+			updates := db.executed(fmt.Sprintf("UPDATE %s SET data=?", dbTablePayment))
+			emails := db.executed(fmt.Sprintf("INSERT INTO %s", dbTableMessage))
+			if !test.recorded {
+				if len(updates) != 0 || len(emails) != 0 {
+					t.Errorf("got %d updates and %d emails, want none", len(updates), len(emails))
+				}
+				return
+			}
+			if len(updates) != 1 || len(emails) != 1 {
+				t.Fatalf("got %d updates and %d emails, want 1", len(updates), len(emails))
+			}
+			data, ok := updates[0].args[0].Value.([]byte)
+			if !ok || !strings.Contains(string(data), `"ExternalID":"re_test","Amount":1000`) {
+				t.Errorf("refund not recorded: %s", data)
+			}
+		})
+	}
+}
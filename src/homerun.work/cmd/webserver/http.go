@@ -8,6 +8,7 @@ import (
 	"io"
 	"io/ioutil"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -30,11 +31,13 @@ const (
 
 //http constants
 const (
-	HeaderAPIToken      = "X-HR-Token"
-	HeaderCacheControl  = "Cache-Control"
-	HeaderContentType   = "Content-Type"
-	HeaderForwardedHost = "X-Forwarded-Host"
-	HeaderRequestID     = "X-Request-Id"
+	HeaderAPIToken           = "X-HR-Token"
+	HeaderCacheControl       = "Cache-Control"
+	HeaderContentDisposition = "Content-Disposition"
+	HeaderContentType        = "Content-Type"
+	HeaderForwardedFor       = "X-Forwarded-For"
+	HeaderForwardedHost      = "X-Forwarded-Host"
+	HeaderRequestID          = "X-Request-Id"
 )
 
 //default images
@@ -62,10 +65,12 @@ const (
 	URIBookingAddSuccess    = "/add-order-success.html"
 	URIBookingCancel        = "/cancel"
 	URIBookingCancelSuccess = "/cancel-order-success.html"
+	URIBookingCard          = "/card.html"
 	URIBookingSubmit        = "/order3.html"
 	URIBookingConfirm       = "/order4.html"
 	URIBookingEdit          = "/edit-order.html"
 	URIBookingEditSuccess   = "/edit-order-success.html"
+	URIBookingReschedule    = "/reschedule.html"
 	URIBookingView          = "/view-order.html"
 	URIBookings             = "/orders.html"
 	URICalendars            = "/calendar.html"
@@ -95,6 +100,8 @@ const (
 	URIFaqs                 = "/faqs.html"
 	URIErr                  = "/error.html"
 	URIForgotPwd            = "/forgotpwd.html"
+	URIGiftCardView         = "/view-gift-card.html"
+	URIGiftCards            = "/gift-cards.html"
 	URIGoogle               = "/google"
 	URIHours                = "/service-hours.html"
 	URIHowItWorks           = "/how-it-works.html"
@@ -104,10 +111,20 @@ const (
 	URILogin                = "/login.html"
 	URILogout               = "/logout.html"
 	URIMaintenance          = "/maintenance"
+	URIMembership           = "/membership.html"
+	URIMembershipAdd        = "/add-membership.html"
+	URIMembershipEdit       = "/edit-membership.html"
+	URIMembershipJoin       = "/join-membership.html"
+	URIMemberships          = "/memberships.html"
 	URIOAuthLogin           = "/login"
 	URIOrdersCalendar       = "/orders/calendar"
+	URIPackageAdd           = "/add-package.html"
+	URIPackageBuy           = "/buy-package.html"
+	URIPackageEdit          = "/edit-package.html"
+	URIPackages             = "/packages.html"
 	URIPayment              = "/payment.html"
 	URIPaymentView          = "/view-payment.html"
+	URIPaymentPDF           = "/payment.pdf"
 	URIPayPal               = "/paypal"
 	URIPaymentDirect        = "/payment-direct.html"
 	URIPaymentSettings      = "/payment-settings.html"
@@ -133,14 +150,18 @@ const (
 	URISvcEdit              = "/edit-service.html"
 	URISvcUsers             = "/service-members.html"
 	URISvcs                 = "/services.html"
+	URITaxes                = "/taxes.html"
 	URITestimonialAdd       = "/add-testimonial.html"
 	URITestimonialEdit      = "/edit-testimonial.html"
 	URITestimonials         = "/testimonials.html"
 	URITerms                = "/terms.html"
+	URITimeOff              = "/time-off.html"
 	URITutors               = "/tutors"
 	URIUserAdd              = "/add-member.html"
 	URIUserEdit             = "/edit-member.html"
 	URIUsers                = "/members.html"
+	URIWaitlist             = "/waitlist.html"
+	URIWaitlistClaim        = "/waitlist-claim.html"
 	URIZoom                 = "/zoom"
 	URIZoomSupport          = "/zoom-support.html"
 )
@@ -149,12 +170,19 @@ const (
 type urlParams struct {
 	AgeMin                  string
 	AgeMax                  string
+	Annual                  string
 	ApptOnly                string
 	AuthToken               string
 	Bio                     string
 	BookID                  string
 	Budget                  string
 	CampaignID              string
+	CancelCutoff            string
+	CancelCutoffUnit        string
+	CancelFee               string
+	CancelFeeType           string
+	CancelFeeWindow         string
+	Capacity                string
 	CheckedMon              string
 	CheckedTue              string
 	CheckedWed              string
@@ -165,11 +193,17 @@ type urlParams struct {
 	City                    string
 	Client                  string
 	ClientID                string
+	Closed                  string
 	Code                    string
+	Currency                string
+	CurrencyConfirm         string
 	Data                    string
 	Date                    string
+	Deposit                 string
+	DepositType             string
 	Desc                    string
 	DisablePhone            string
+	DisableReminders        string
 	Domain                  string
 	Duration                string
 	Education               string
@@ -180,16 +214,24 @@ type urlParams struct {
 	Error                   string
 	ErrorDesc               string
 	Experience              string
+	Expiration              string
 	ExternalID              string
 	Filter                  string
 	FilterSub               string
+	FirstBookings           string
 	FirstName               string
 	Flag                    string
 	Freq                    string
+	FreqCount               string
+	FreqDays                string
+	FreqInterval            string
+	FreqUntil               string
 	Gender                  string
+	GiftCard                string
 	GoogleRecaptchaResponse string
 	HasFacebookAdAccount    string
 	HasFacebookPage         string
+	Horizon                 string
 	ID                      string
 	Img                     string
 	ImgBanner               string
@@ -200,13 +242,21 @@ type urlParams struct {
 	ImgLogo                 string
 	Interests               string
 	Interval                string
+	ItemDesc                string
+	ItemPrice               string
+	ItemQuantity            string
+	ItemType                string
 	LastName                string
 	Location                string
 	LocationType            string
 	Locations               string
+	MemberID                string
+	MinAmount               string
 	MsgKey                  string
 	Name                    string
 	Next                    string
+	NoShowFee               string
+	NoShowFeeType           string
 	Note                    string
 	OAuth                   string
 	Padding                 string
@@ -214,7 +264,9 @@ type urlParams struct {
 	PaddingInitialUnit      string
 	Password                string
 	PayPalID                string
+	PayToBook               string
 	PaymentID               string
+	Period                  string
 	Phone                   string
 	Prev                    string
 	Price                   string
@@ -222,8 +274,11 @@ type urlParams struct {
 	ProviderID              string
 	ProviderName            string
 	ProviderURLName         string
+	Region                  string
+	ReminderDays            string
 	Schedule                string
 	ScheduleDuration        string
+	Sessions                string
 	Start                   string
 	State                   string
 	Status                  string
@@ -232,10 +287,16 @@ type urlParams struct {
 	SvcArea                 string
 	SvcDesc                 string
 	SvcID                   string
+	SvcIDs                  string
 	SvcName                 string
+	TaxExempt               string
+	TaxRate                 string
 	Text                    string
 	Time                    string
 	TimeZone                string
+	Tip                     string
+	TipPercent              string
+	TipPercents             string
 	Token                   string
 	Type                    string
 	URLFacebook             string
@@ -246,6 +307,8 @@ type urlParams struct {
 	URLTwitter              string
 	URLVideo                string
 	URLWeb                  string
+	UsageLimit              string
+	UsageLimitClient        string
 	UserID                  string
 	Value                   string
 	Version                 string
@@ -255,12 +318,19 @@ type urlParams struct {
 var URLParams urlParams = urlParams{
 	AgeMin:                  "ageMin",
 	AgeMax:                  "ageMax",
+	Annual:                  "annual",
 	ApptOnly:                "apptOnly",
 	AuthToken:               "authToken",
 	Bio:                     "bio",
 	BookID:                  "bookId",
 	Budget:                  "budget",
 	CampaignID:              "campaignId",
+	CancelCutoff:            "cancelCutoff",
+	CancelCutoffUnit:        "cancelCutoffUnit",
+	CancelFee:               "cancelFee",
+	CancelFeeType:           "cancelFeeType",
+	CancelFeeWindow:         "cancelFeeWindow",
+	Capacity:                "capacity",
 	CheckedMon:              "checkedMon",
 	CheckedTue:              "checkedTue",
 	CheckedWed:              "checkedWed",
@@ -271,11 +341,17 @@ var URLParams urlParams = urlParams{
 	City:                    "city",
 	Client:                  "client",
 	ClientID:                "clientId",
+	Closed:                  "closed",
 	Code:                    "code",
+	Currency:                "currency",
+	CurrencyConfirm:         "currencyConfirm",
 	Data:                    "data",
 	Date:                    "date",
+	Deposit:                 "deposit",
+	DepositType:             "depositType",
 	Desc:                    "desc",
 	DisablePhone:            "disablePhone",
+	DisableReminders:        "disableReminders",
 	Domain:                  "domain",
 	Duration:                "duration",
 	Education:               "education",
@@ -286,16 +362,24 @@ var URLParams urlParams = urlParams{
 	Error:                   "error",
 	ErrorDesc:               "error_description",
 	Experience:              "experience",
+	Expiration:              "expiration",
 	ExternalID:              "externalId",
 	Filter:                  "filter",
 	FilterSub:               "filterSub",
+	FirstBookings:           "firstBookings",
 	FirstName:               "firstName",
 	Flag:                    "flag",
 	Freq:                    "freq",
+	FreqCount:               "freqCount",
+	FreqDays:                "freqDays",
+	FreqInterval:            "freqInterval",
+	FreqUntil:               "freqUntil",
 	Gender:                  "gender",
+	GiftCard:                "giftCard",
 	GoogleRecaptchaResponse: "g-recaptcha-response",
 	HasFacebookAdAccount:    "hasFacebookAdAccount",
 	HasFacebookPage:         "hasFacebookPage",
+	Horizon:                 "horizon",
 	ID:                      "id",
 	Img:                     "img",
 	ImgBanner:               "imgBanner",
@@ -306,13 +390,21 @@ var URLParams urlParams = urlParams{
 	ImgLogo:                 "imgLogo",
 	Interests:               "interests",
 	Interval:                "interval",
+	ItemDesc:                "itemDesc",
+	ItemPrice:               "itemPrice",
+	ItemQuantity:            "itemQuantity",
+	ItemType:                "itemType",
 	LastName:                "lastName",
 	Location:                "location",
 	LocationType:            "locationType",
 	Locations:               "locations",
+	MemberID:                "memberId",
+	MinAmount:               "minAmount",
 	MsgKey:                  "msgKey",
 	Name:                    "name",
 	Next:                    "next",
+	NoShowFee:               "noShowFee",
+	NoShowFeeType:           "noShowFeeType",
 	Note:                    "note",
 	OAuth:                   "oauth",
 	Padding:                 "padding",
@@ -320,7 +412,9 @@ var URLParams urlParams = urlParams{
 	PaddingInitialUnit:      "paddingInitialUnit",
 	Password:                "password",
 	PayPalID:                "paypalId",
+	PayToBook:               "payToBook",
 	PaymentID:               "paymentId",
+	Period:                  "period",
 	Phone:                   "phone",
 	Prev:                    "prev",
 	Price:                   "price",
@@ -328,8 +422,11 @@ var URLParams urlParams = urlParams{
 	ProviderID:              "providerId",
 	ProviderName:            "providerName",
 	ProviderURLName:         "providerUrlName",
+	Region:                  "region",
+	ReminderDays:            "reminderDays",
 	Schedule:                "schedule",
 	ScheduleDuration:        "scheduleDuration",
+	Sessions:                "sessions",
 	State:                   "state",
 	Status:                  "status",
 	Start:                   "start",
@@ -338,10 +435,16 @@ var URLParams urlParams = urlParams{
 	SvcArea:                 "svcArea",
 	SvcDesc:                 "svcDesc",
 	SvcID:                   "svcId",
+	SvcIDs:                  "svcIds",
 	SvcName:                 "svcName",
+	TaxExempt:               "taxExempt",
+	TaxRate:                 "taxRate",
 	Text:                    "text",
 	Time:                    "time",
 	TimeZone:                "timeZone",
+	Tip:                     "tip",
+	TipPercent:              "tipPercent",
+	TipPercents:             "tipPercents",
 	Token:                   "token",
 	Type:                    "type",
 	URLFacebook:             "urlFacebook",
@@ -352,6 +455,8 @@ var URLParams urlParams = urlParams{
 	URLTwitter:              "urlTwitter",
 	URLVideo:                "urlVideo",
 	URLWeb:                  "urlWeb",
+	UsageLimit:              "usageLimit",
+	UsageLimitClient:        "usageLimitClient",
 	UserID:                  "userId",
 	Value:                   "value",
 	Version:                 "v",
@@ -378,15 +483,24 @@ const (
 	TplParamActiveNav              templateDataKey = "ActiveNav"
 	TplParamAgeMin                 templateDataKey = "AgeMin"
 	TplParamAgeMax                 templateDataKey = "AgeMax"
+	TplParamAnnual                 templateDataKey = "Annual"
 	TplParamAlert                  templateDataKey = "Alert"
 	TplParamApptOnly               templateDataKey = "ApptOnly"
+	TplParamAttendees              templateDataKey = "Attendees"
 	TplParamBio                    templateDataKey = "Bio"
 	TplParamBook                   templateDataKey = "Book"
+	TplParamBookPayments           templateDataKey = "BookPayments"
 	TplParamBooks                  templateDataKey = "Books"
 	TplParamBreadcrumbs            templateDataKey = "Breadcrumbs"
 	TplParamBudget                 templateDataKey = "Budget"
 	TplParamCampaign               templateDataKey = "Campaign"
 	TplParamCampaigns              templateDataKey = "Campaigns"
+	TplParamCancelCutoff           templateDataKey = "CancelCutoff"
+	TplParamCancelCutoffUnit       templateDataKey = "CancelCutoffUnit"
+	TplParamCancelFee              templateDataKey = "CancelFee"
+	TplParamCancelFeeType          templateDataKey = "CancelFeeType"
+	TplParamCancelFeeWindow        templateDataKey = "CancelFeeWindow"
+	TplParamCapacity               templateDataKey = "Capacity"
 	TplParamCheckedMon             templateDataKey = "CheckedMon"
 	TplParamCheckedTue             templateDataKey = "CheckedTue"
 	TplParamCheckedWed             templateDataKey = "CheckedWed"
@@ -395,10 +509,14 @@ const (
 	TplParamCheckedSat             templateDataKey = "CheckedSat"
 	TplParamCheckedSun             templateDataKey = "CheckedSun"
 	TplParamCity                   templateDataKey = "City"
+	TplParamClass                  templateDataKey = "Class"
 	TplParamClientID               templateDataKey = "ClientId"
 	TplParamClient                 templateDataKey = "Client"
+	TplParamClientMemberships      templateDataKey = "ClientMemberships"
+	TplParamClientPackages         templateDataKey = "ClientPackages"
 	TplParamClientView             templateDataKey = "ClientView"
 	TplParamClients                templateDataKey = "Clients"
+	TplParamClosed                 templateDataKey = "Closed"
 	TplParamCode                   templateDataKey = "Code"
 	TplParamConfirm                templateDataKey = "Confirm"
 	TplParamConfirmMsg             templateDataKey = "ConfirmMsg"
@@ -414,15 +532,22 @@ const (
 	TplParamCouponTypes            templateDataKey = "CouponTypes"
 	TplParamCoupon                 templateDataKey = "Coupon"
 	TplParamCoupons                templateDataKey = "Coupons"
+	TplParamCurrencies             templateDataKey = "Currencies"
+	TplParamCurrency               templateDataKey = "Currency"
+	TplParamCurrencyConfirm        templateDataKey = "CurrencyConfirm"
 	TplParamCurrentTime            templateDataKey = "CurrentTime"
 	TplParamCurrentYear            templateDataKey = "CurrentYear"
 	TplParamDate                   templateDataKey = "Date"
+	TplParamDatesUnavailable       templateDataKey = "DatesUnavailable"
 	TplParamDaysOfWeek             templateDataKey = "DaysOfWeek"
+	TplParamDeposit                templateDataKey = "Deposit"
+	TplParamDepositType            templateDataKey = "DepositType"
 	TplParamDesc                   templateDataKey = "Desc"
 	TplParamDevModeEnable          templateDataKey = "DevModeEnable"
 	TplParamDisableAuth            templateDataKey = "DisableAuth"
 	TplParamDisableNav             templateDataKey = "DisableNav"
 	TplParamDisablePhone           templateDataKey = "DisablePhone"
+	TplParamDisableReminders       templateDataKey = "DisableReminders"
 	TplParamDomain                 templateDataKey = "Domain"
 	TplParamDomainPublic           templateDataKey = "DomainPublic"
 	TplParamDuration               templateDataKey = "Duration"
@@ -437,6 +562,7 @@ const (
 	TplParamErr                    templateDataKey = "Err"
 	TplParamErrs                   templateDataKey = "Errs"
 	TplParamExperience             templateDataKey = "Experience"
+	TplParamExpiration             templateDataKey = "Expiration"
 	TplParamFacebookAPIVersion     templateDataKey = "FacebookAPIVersion"
 	TplParamFacebookAppID          templateDataKey = "FacebookAppId"
 	TplParamFacebookConversionCost templateDataKey = "FacebookConversionCost"
@@ -444,39 +570,62 @@ const (
 	TplParamFaqCount               templateDataKey = "FaqCount"
 	TplParamFaq                    templateDataKey = "Faq"
 	TplParamFaqs                   templateDataKey = "Faqs"
+	TplParamFeeTypes               templateDataKey = "FeeTypes"
 	TplParamFileCSS                templateDataKey = "FileCss"
 	TplParamFileJS                 templateDataKey = "FileJs"
 	TplParamFilter                 templateDataKey = "Filter"
 	TplParamFilterSub              templateDataKey = "FilterSub"
 	TplParamFlag                   templateDataKey = "Flag"
+	TplParamFirstBookings          templateDataKey = "FirstBookings"
 	TplParamFormAction             templateDataKey = "FormAction"
 	TplParamFormAction2            templateDataKey = "FormAction2"
 	TplParamFreq                   templateDataKey = "Freq"
+	TplParamFreqCount              templateDataKey = "FreqCount"
+	TplParamFreqDays               templateDataKey = "FreqDays"
+	TplParamFreqInterval           templateDataKey = "FreqInterval"
+	TplParamFreqUntil              templateDataKey = "FreqUntil"
 	TplParamGender                 templateDataKey = "Gender"
+	TplParamGiftCard               templateDataKey = "GiftCard"
+	TplParamGiftCardEntries        templateDataKey = "GiftCardEntries"
+	TplParamGiftCards              templateDataKey = "GiftCards"
 	TplParamGoogleRecaptchaSiteKey templateDataKey = "GoogleRecaptchaSiteKey"
 	TplParamGoogleTagManagerID     templateDataKey = "GoogleTagManagerId"
 	TplParamGoogleTrackingID       templateDataKey = "GoogleTrackingId"
 	TplParamHasAccess              templateDataKey = "HasAccess"
 	TplParamHasFacebookAdAccount   templateDataKey = "HasFacebookAdAccount"
 	TplParamHasFacebookPage        templateDataKey = "HasFacebookPage"
+	TplParamHorizon                templateDataKey = "Horizon"
 	TplParamID                     templateDataKey = "Id"
 	TplParamInputs                 templateDataKey = "Inputs"
 	TplParamInterests              templateDataKey = "Interests"
 	TplParamInterval               templateDataKey = "Interval"
+	TplParamItems                  templateDataKey = "Items"
+	TplParamItemTypes              templateDataKey = "ItemTypes"
 	TplParamIPPublic               templateDataKey = "IpPublic"
 	TplParamIsAdmin                templateDataKey = "IsAdmin"
 	TplParamLocation               templateDataKey = "Location"
 	TplParamLocationType           templateDataKey = "LocationType"
 	TplParamLocations              templateDataKey = "Locations"
 	TplParamMarquee                templateDataKey = "Marquee"
+	TplParamMembership             templateDataKey = "Membership"
+	TplParamMembershipCount        templateDataKey = "MembershipCount"
+	TplParamMembershipPlans        templateDataKey = "MembershipPlans"
+	TplParamMembers                templateDataKey = "Members"
 	TplParamMetaDesc               templateDataKey = "MetaDesc"
 	TplParamMetaKeywords           templateDataKey = "MetaKeywords"
+	TplParamMinAmount              templateDataKey = "MinAmount"
 	TplParamMsg                    templateDataKey = "Msg"
 	TplParamName                   templateDataKey = "Name"
 	TplParamNameFirst              templateDataKey = "FirstName"
 	TplParamNameLast               templateDataKey = "LastName"
 	TplParamNavDisable             templateDataKey = "NavDisable"
+	TplParamNoShowFee              templateDataKey = "NoShowFee"
+	TplParamNoShowFeeType          templateDataKey = "NoShowFeeType"
 	TplParamNote                   templateDataKey = "Note"
+	TplParamPackage                templateDataKey = "Package"
+	TplParamPackageCount           templateDataKey = "PackageCount"
+	TplParamPackageUses            templateDataKey = "PackageUses"
+	TplParamPackages               templateDataKey = "Packages"
 	TplParamPadding                templateDataKey = "Padding"
 	TplParamPaddingInitial         templateDataKey = "PaddingInitial"
 	TplParamPaddingInitialUnit     templateDataKey = "PaddingInitialUnit"
@@ -486,6 +635,9 @@ const (
 	TplParamPayments               templateDataKey = "Payments"
 	TplParamPayPalClientID         templateDataKey = "PayPalClientId"
 	TplParamPayPalOrderID          templateDataKey = "PayPalOrderId"
+	TplParamPayToBook              templateDataKey = "PayToBook"
+	TplParamPeriod                 templateDataKey = "Period"
+	TplParamPeriodTypes            templateDataKey = "PeriodTypes"
 	TplParamPhone                  templateDataKey = "Phone"
 	TplParamPlaidToken             templateDataKey = "PlaidToken"
 	TplParamPrice                  templateDataKey = "Price"
@@ -497,14 +649,24 @@ const (
 	TplParamProviderName           templateDataKey = "ProviderName"
 	TplParamRecurrenceFreq         templateDataKey = "RecurrenceFreq"
 	TplParamRecurrenceFreqs        templateDataKey = "RecurrenceFreqs"
+	TplParamRecurrenceOptions      templateDataKey = "RecurrenceOptions"
+	TplParamRedemptions            templateDataKey = "Redemptions"
+	TplParamRegion                 templateDataKey = "Region"
+	TplParamReminderDays           templateDataKey = "ReminderDays"
 	TplParamReport                 templateDataKey = "Report"
+	TplParamReportTips             templateDataKey = "ReportTips"
+	TplParamReportTotal            templateDataKey = "ReportTotal"
 	TplParamSchedule               templateDataKey = "Schedule"
 	TplParamSchedule1              templateDataKey = "Schedule1"
 	TplParamSchedule2              templateDataKey = "Schedule2"
 	TplParamScheduleDuration       templateDataKey = "ScheduleDuration"
+	TplParamScheduleExceptions     templateDataKey = "ScheduleExceptions"
+	TplParamScheduleSeason         templateDataKey = "ScheduleSeason"
+	TplParamScheduleSeasons        templateDataKey = "ScheduleSeasons"
 	TplParamServiceAreas           templateDataKey = "ServiceAreas"
 	TplParamServiceIntervals       templateDataKey = "ServiceIntervals"
 	TplParamServiceLocations       templateDataKey = "ServiceLocations"
+	TplParamSessions               templateDataKey = "Sessions"
 	TplParamShowHomeRun            templateDataKey = "ShowHomeRun"
 	TplParamStart                  templateDataKey = "Start"
 	TplParamStatus                 templateDataKey = "Status"
@@ -519,7 +681,9 @@ const (
 	TplParamSvcAreaStrs            templateDataKey = "SvcAreaStrs"
 	TplParamSvcBusyTimes           templateDataKey = "SvcBusyTimes"
 	TplParamSvcDesc                templateDataKey = "SvcDesc"
+	TplParamSvcEndDate             templateDataKey = "SvcEndDate"
 	TplParamSvcID                  templateDataKey = "SvcId"
+	TplParamSvcIDs                 templateDataKey = "SvcIds"
 	TplParamSvcName                templateDataKey = "SvcName"
 	TplParamSvcStartDate           templateDataKey = "SvcStartDate"
 	TplParamSvcTime                templateDataKey = "SvcTime"
@@ -530,6 +694,11 @@ const (
 	TplParamTime                   templateDataKey = "Time"
 	TplParamTimeZone               templateDataKey = "TimeZone"
 	TplParamTimeZones              templateDataKey = "TimeZones"
+	TplParamTaxExempt              templateDataKey = "TaxExempt"
+	TplParamTaxRate                templateDataKey = "TaxRate"
+	TplParamTaxRates               templateDataKey = "TaxRates"
+	TplParamTip                    templateDataKey = "Tip"
+	TplParamTipPercents            templateDataKey = "TipPercents"
 	TplParamTips                   templateDataKey = "Tips"
 	TplParamTitleAlert             templateDataKey = "TitleAlert"
 	TplParamToken                  templateDataKey = "Token"
@@ -578,10 +747,13 @@ const (
 	TplParamURLView                templateDataKey = "UrlView"
 	TplParamURLWebProvider         templateDataKey = "UrlWebProvider"
 	TplParamURLYouTube             templateDataKey = "UrlYouTube"
+	TplParamUsageLimit             templateDataKey = "UsageLimit"
+	TplParamUsageLimitClient       templateDataKey = "UsageLimitClient"
 	TplParamUserID                 templateDataKey = "UserId"
 	TplParamUser                   templateDataKey = "User"
 	TplParamUsers                  templateDataKey = "Users"
 	TplParamValue                  templateDataKey = "Value"
+	TplParamWaitlist               templateDataKey = "Waitlist"
 	TplParamZelleID                templateDataKey = "ZelleId"
 )
 
@@ -739,6 +911,20 @@ func URLJoin(baseURL string, paths ...string) string {
 	return parsedURL.String()
 }
 
+//GetRemoteIP : get the ip address of the client, using the address appended by the proxy if forwarded
+func GetRemoteIP(r *http.Request) string {
+	forwarded := r.Header.Get(HeaderForwardedFor)
+	if forwarded != "" {
+		tokens := strings.Split(forwarded, ",")
+		return strings.TrimSpace(tokens[len(tokens)-1])
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 //FileUpload : information about the uploaded file
 type FileUpload struct {
 	FullPath    string
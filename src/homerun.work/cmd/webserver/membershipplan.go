@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+//membership plan db tables
+const (
+	dbTableMembershipPlan = "membership_plan"
+)
+
+//MembershipPlan : definition of a monthly membership sold by a provider and billed through a Stripe subscription
+type MembershipPlan struct {
+	ID                 *uuid.UUID   `json:"-"`
+	ProviderID         *uuid.UUID   `json:"-"`
+	Name               string       `json:"Name"`
+	Description        string       `json:"Description"`
+	Price              float32      `json:"Price"`
+	Sessions           int          `json:"Sessions"` //per month, where zero indicates unlimited
+	ServiceIDs         []*uuid.UUID `json:"ServiceIDs"`
+	ServiceNames       []string     `json:"ServiceNames"`
+	StripePlanID       string       `json:"StripePlanID"`
+	StripePlanAmount   int          `json:"StripePlanAmount"`
+	StripePlanCurrency Currency     `json:"StripePlanCurrency"`
+}
+
+//FormatPrice : format the monthly price
+func (m *MembershipPlan) FormatPrice(currency Currency) string {
+	return fmt.Sprintf("%s/month", currency.FormatAmount(m.Price))
+}
+
+//FormatSessions : format the number of sessions per month
+func (m *MembershipPlan) FormatSessions() string {
+	return formatMembershipSessions(m.Sessions)
+}
+
+//FormatSessionsInput : format the number of sessions per month for the form
+func (m *MembershipPlan) FormatSessionsInput() string {
+	if m.Sessions == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", m.Sessions)
+}
+
+//FormatServices : format the services covered by the membership
+func (m *MembershipPlan) FormatServices() string {
+	return strings.Join(m.ServiceNames, ", ")
+}
+
+//FormatServiceIDs : format the ids of the services covered by the membership for the form
+func (m *MembershipPlan) FormatServiceIDs() []string {
+	ids := make([]string, 0, len(m.ServiceIDs))
+	for _, id := range m.ServiceIDs {
+		ids = append(ids, id.String())
+	}
+	return ids
+}
+
+//SetServices : set the services covered by the membership
+func (m *MembershipPlan) SetServices(svcs []*Service) {
+	m.ServiceIDs = make([]*uuid.UUID, 0, len(svcs))
+	m.ServiceNames = make([]string, 0, len(svcs))
+	for _, svc := range svcs {
+		m.ServiceIDs = append(m.ServiceIDs, svc.ID)
+		m.ServiceNames = append(m.ServiceNames, svc.Name)
+	}
+}
+
+//IsStripePlanCurrent : check if the Stripe plan matches the price, since the price of a Stripe plan cannot be changed
+func (m *MembershipPlan) IsStripePlanCurrent(currency Currency) bool {
+	return m.StripePlanID != "" && m.StripePlanCurrency == currency && m.StripePlanAmount == currency.ToMinorUnits(m.Price)
+}
+
+//SetStripePlan : set the Stripe plan created for the price
+func (m *MembershipPlan) SetStripePlan(id string, currency Currency) {
+	m.StripePlanID = id
+	m.StripePlanCurrency = currency
+	m.StripePlanAmount = currency.ToMinorUnits(m.Price)
+}
+
+//format the number of sessions per month
+func formatMembershipSessions(sessions int) string {
+	if sessions == 0 {
+		return "Unlimited sessions per month"
+	}
+	if sessions == 1 {
+		return "1 session per month"
+	}
+	return fmt.Sprintf("%d sessions per month", sessions)
+}
+
+//LoadMembershipPlanByProviderIDAndID : load a membership plan by provider id and id
+func LoadMembershipPlanByProviderIDAndID(ctx context.Context, db *DB, providerID *uuid.UUID, id *uuid.UUID) (context.Context, *MembershipPlan, error) {
+	stmt := fmt.Sprintf("SELECT data FROM %s WHERE deleted=0 AND provider_id=UUID_TO_BIN(?) AND id=UUID_TO_BIN(?)", dbTableMembershipPlan)
+	ctx, row, err := db.QueryRow(ctx, stmt, providerID, id)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "query row membership plan")
+	}
+
+	//read the row
+	var dataStr string
+	err = row.Scan(&dataStr)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx, nil, fmt.Errorf("no membership plan: %s: %s", providerID, id)
+		}
+		return ctx, nil, errors.Wrap(err, "select membership plan")
+	}
+
+	//unmarshal the data
+	var plan MembershipPlan
+	err = json.Unmarshal([]byte(dataStr), &plan)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "unjson membership plan")
+	}
+	plan.ID = id
+	plan.ProviderID = providerID
+	return ctx, &plan, nil
+}
+
+//SaveMembershipPlan : save a membership plan
+func SaveMembershipPlan(ctx context.Context, db *DB, plan *MembershipPlan) (context.Context, error) {
+	//generate an id if necessary
+	if plan.ID == nil {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return ctx, errors.Wrap(err, "new uuid membership plan")
+		}
+		plan.ID = &id
+	}
+
+	//json encode the membership plan data
+	dataJSON, err := json.Marshal(plan)
+	if err != nil {
+		return ctx, errors.Wrap(err, "json membership plan")
+	}
+
+	//save to the db
+	stmt := fmt.Sprintf("INSERT INTO %s(id,provider_id,data) VALUES (UUID_TO_BIN(?),UUID_TO_BIN(?),?) ON DUPLICATE KEY UPDATE data=VALUES(data)", dbTableMembershipPlan)
+	ctx, result, err := db.Exec(ctx, stmt, plan.ID, plan.ProviderID, dataJSON)
+	if err != nil {
+		return ctx, errors.Wrap(err, "insert membership plan")
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return ctx, errors.Wrap(err, "insert membership plan rows affected")
+	}
+
+	//0 indicated no update, 1 an insert, 2 an update
+	if count < 0 || count > 2 {
+		return ctx, fmt.Errorf("unable to insert membership plan: %s", plan.ProviderID)
+	}
+	return ctx, nil
+}
+
+//DeleteMembershipPlan : delete a membership plan, leaving any existing memberships active
+func DeleteMembershipPlan(ctx context.Context, db *DB, providerID *uuid.UUID, id *uuid.UUID) (context.Context, error) {
+	stmt := fmt.Sprintf("UPDATE %s SET deleted=1 WHERE provider_id=UUID_TO_BIN(?) AND id=UUID_TO_BIN(?)", dbTableMembershipPlan)
+	ctx, result, err := db.Exec(ctx, stmt, providerID, id)
+	if err != nil {
+		return ctx, errors.Wrap(err, "delete membership plan")
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return ctx, errors.Wrap(err, "delete membership plan rows affected")
+	}
+	if count == 0 {
+		return ctx, fmt.Errorf("delete membership plan error: %s", id)
+	}
+	return ctx, nil
+}
+
+//CountMembershipPlansByProviderID : count the membership plans for the provider
+func CountMembershipPlansByProviderID(ctx context.Context, db *DB, providerID *uuid.UUID) (context.Context, int, error) {
+	stmt := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE deleted=0 AND provider_id=UUID_TO_BIN(?)", dbTableMembershipPlan)
+	ctx, row, err := db.QueryRow(ctx, stmt, providerID)
+	if err != nil {
+		return ctx, 0, errors.Wrap(err, "count membership plans")
+	}
+
+	//read the rows
+	var count int
+	err = row.Scan(&count)
+	if err != nil {
+		return ctx, 0, errors.Wrap(err, "row scan count membership plans")
+	}
+	return ctx, count, nil
+}
+
+//ListMembershipPlansByProviderID : list all membership plans for the provider
+func ListMembershipPlansByProviderID(ctx context.Context, db *DB, providerID *uuid.UUID) (context.Context, []*MembershipPlan, error) {
+	ctx, logger := GetLogger(ctx)
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(id),data FROM %s WHERE deleted=0 AND provider_id=UUID_TO_BIN(?) ORDER BY created", dbTableMembershipPlan)
+	ctx, rows, err := db.Query(ctx, stmt, providerID)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "select membership plans")
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Warnw("rows close", "error", err)
+		}
+	}()
+
+	//read the rows
+	plans := make([]*MembershipPlan, 0, 2)
+	var idStr string
+	var dataStr string
+	for rows.Next() {
+		err := rows.Scan(&idStr, &dataStr)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "rows scan membership plans")
+		}
+
+		//parse the uuid
+		id, err := uuid.FromString(idStr)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "parse uuid")
+		}
+
+		//unmarshal the data
+		var plan MembershipPlan
+		err = json.Unmarshal([]byte(dataStr), &plan)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "unjson membership plan")
+		}
+		plan.ID = &id
+		plan.ProviderID = providerID
+		plans = append(plans, &plan)
+	}
+	return ctx, plans, nil
+}
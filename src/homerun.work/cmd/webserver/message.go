@@ -25,6 +25,7 @@ const (
 	MsgTypeBookingCancelProvider       MsgType = "bookingCancelProvider"
 	MsgTypeBookingConfirmClient        MsgType = "bookingConfirmClient"
 	MsgTypeBookingEditClient           MsgType = "bookingEditClient"
+	MsgTypeBookingEditProvider         MsgType = "bookingEditProvider"
 	MsgTypeBookingNewClient            MsgType = "bookingNewClient"
 	MsgTypeBookingNewProvider          MsgType = "bookingNewProvider"
 	MsgTypeBookingReminderClient       MsgType = "bookingReminderClient"
@@ -42,8 +43,11 @@ const (
 	MsgTypeMessage                     MsgType = "message"
 	MsgTypePaymentClient               MsgType = "paymentClient"
 	MsgTypePaymentProvider             MsgType = "paymentProvider"
+	MsgTypePaymentReminderClient       MsgType = "paymentReminderClient"
 	MsgTypePwdReset                    MsgType = "pwdReset"
 	MsgTypeProviderUserInvite          MsgType = "providerUserInvite"
+	MsgTypeRefundClient                MsgType = "refundClient"
+	MsgTypeWaitlistOfferClient         MsgType = "waitlistOfferClient"
 	MsgTypeWelcome                     MsgType = "welcome"
 )
 
@@ -65,6 +69,16 @@ type Message struct {
 	BodyText     string     `json:"BodyText"`
 	Text         string     `json:"Text"`
 	TokenURL     string     `json:"TokenUrl"`
+
+	//attachments generated when sending
+	Attachments []*MessageAttachment `json:"-"`
+}
+
+//MessageAttachment : definition of a file attached to a message
+type MessageAttachment struct {
+	FileName    string
+	ContentType string
+	Data        []byte
 }
 
 //LoadMsgByID : load a message
@@ -23,6 +23,8 @@ type NotificationType int
 //notification types
 const (
 	NotificationTypeBookingReminder NotificationType = iota + 1
+	NotificationTypeWaitlistOffer
+	NotificationTypePaymentReminder
 )
 
 //Notification : definition of a notification
@@ -35,6 +37,7 @@ type Notification struct {
 	TimeStart   string           `json:"TimeStart"`
 	TimeEnd     string           `json:"TimeEnd"`
 	Booking     *Booking         `json:"-"`
+	Payment     *Payment         `json:"-"`
 }
 
 //CreateNotification : create a notification
@@ -132,6 +135,72 @@ func CreateBookingNotifications(ctx context.Context, db *DB, now time.Time, noti
 	return ctx, nil
 }
 
+//CreatePaymentNotifications : create the reminder notifications for unpaid invoices that are due based on the days set by the provider
+func CreatePaymentNotifications(ctx context.Context, db *DB, now time.Time, limit int) (context.Context, error) {
+	ctx, err := db.ProcessTx(ctx, "create payment notifications", func(ctx context.Context, db *DB) (context.Context, error) {
+		ctx, logger := GetLogger(ctx)
+
+		//compute the time before which the previous reminder must have been sent
+		duration := time.Duration(GetNotificationPaymentReminderMin()) * time.Minute
+		checkSendTime := now.UTC().Add(-duration)
+
+		//process payments, using the count of previous reminders to find the days for the next reminder
+		stmt := fmt.Sprintf("SELECT BIN_TO_UUID(p.user_id),BIN_TO_UUID(pmt.id) FROM %s pmt INNER JOIN %s p ON p.id=pmt.provider_id LEFT JOIN (SELECT secondary_id,COUNT(*) AS count,MAX(send_date) AS send_date FROM %s WHERE deleted=0 AND type=%d GROUP BY secondary_id) n ON n.secondary_id=pmt.id WHERE pmt.deleted=0 AND pmt.type IN (%d,%d,%d) AND pmt.invoiced IS NOT NULL AND pmt.paid IS NULL AND pmt.captured IS NULL AND JSON_TYPE(p.data->'$.PaymentReminderDays')='ARRAY' AND COALESCE(n.count,0)<JSON_LENGTH(p.data,'$.PaymentReminderDays') AND ?>=DATE_ADD(pmt.invoiced,INTERVAL JSON_EXTRACT(p.data,CONCAT('$.PaymentReminderDays[',COALESCE(n.count,0),']')) DAY) AND (n.send_date IS NULL OR ?>=n.send_date) ORDER BY pmt.invoiced LIMIT %d", dbTablePayment, dbTableProvider, dbTableNotification, NotificationTypePaymentReminder, PaymentTypeBooking, PaymentTypeDeposit, PaymentTypeFee, limit)
+		ctx, rows, err := db.Query(ctx, stmt, now.UTC(), checkSendTime)
+		if err != nil {
+			return ctx, errors.Wrap(err, "select payment notifications")
+		}
+		defer func() {
+			err := rows.Close()
+			if err != nil {
+				logger.Warnw("rows close", "error", err)
+			}
+		}()
+
+		//read the rows
+		notifications := make([]*Notification, 0, 2)
+		var userIDStr string
+		var paymentIDStr string
+		for rows.Next() {
+			err := rows.Scan(&userIDStr, &paymentIDStr)
+			if err != nil {
+				return ctx, errors.Wrap(err, "rows scan payment notifications")
+			}
+
+			//parse the uuid
+			userID, err := uuid.FromString(userIDStr)
+			if err != nil {
+				return ctx, errors.Wrap(err, "parse uuid user id")
+			}
+			paymentID, err := uuid.FromString(paymentIDStr)
+			if err != nil {
+				return ctx, errors.Wrap(err, "parse uuid payment id")
+			}
+
+			//prepare to store the notification
+			notification := &Notification{
+				UserID:      &userID,
+				SecondaryID: &paymentID,
+				Type:        NotificationTypePaymentReminder,
+			}
+			notifications = append(notifications, notification)
+		}
+
+		//save the notifications
+		for _, notification := range notifications {
+			ctx, err = CreateNotification(ctx, db, notification, now)
+			if err != nil {
+				return ctx, errors.Wrap(err, fmt.Sprintf("create notification: %s: %s", notification.UserID, notification.SecondaryID))
+			}
+		}
+		return ctx, nil
+	})
+	if err != nil {
+		return ctx, errors.Wrap(err, "create payment notifications")
+	}
+	return ctx, nil
+}
+
 //state tracking the booking
 type bookingState struct {
 	ID        *uuid.UUID
@@ -228,6 +297,44 @@ func ListNotificationsToProcess(ctx context.Context, db *DB, now time.Time, limi
 				}
 				book.TimeFrom = timeFrom
 				book.TimeTo = timeTo
+			case NotificationTypeWaitlistOffer:
+				//load the cancelled booking
+				ctx, book, err := LoadBookingByID(ctx, db, notification.SecondaryID, false, true)
+				if err != nil {
+					return ctx, errors.Wrap(err, fmt.Sprintf("load booking: %s", notification.SecondaryID))
+				}
+				notification.Booking = book
+
+				//use the notification times
+				timeFrom, err := time.Parse(time.RFC3339, notification.TimeStart)
+				if err != nil {
+					return ctx, errors.Wrap(err, fmt.Sprintf("parse time: %s", notification.TimeStart))
+				}
+				timeTo, err := time.Parse(time.RFC3339, notification.TimeEnd)
+				if err != nil {
+					return ctx, errors.Wrap(err, fmt.Sprintf("parse time: %s", notification.TimeEnd))
+				}
+				book.TimeFrom = timeFrom
+				book.TimeTo = timeTo
+			case NotificationTypePaymentReminder:
+				//load the payment, which is not found if deleted
+				ctx, payment, err := LoadPaymentByID(ctx, db, notification.SecondaryID)
+				if err != nil {
+					return ctx, errors.Wrap(err, fmt.Sprintf("load payment: %s", notification.SecondaryID))
+				}
+				notification.Payment = payment
+				if payment == nil {
+					continue
+				}
+
+				//load the client for payments not made for a booking
+				if payment.Client == nil {
+					ctx, client, err := LoadClientByProviderIDAndEmail(ctx, db, payment.ProviderID, payment.Email)
+					if err != nil {
+						return ctx, errors.Wrap(err, fmt.Sprintf("load client: %s", payment.Email))
+					}
+					payment.Client = client
+				}
 			default:
 				return ctx, fmt.Errorf("invalid notification type: %d", notification.Type)
 			}
@@ -30,48 +30,497 @@ const (
 	PaymentTypeBooking PaymentType = iota + 1
 	PaymentTypeCampaign
 	PaymentTypeDirect
+	PaymentTypeFee
+	PaymentTypeDeposit
+	PaymentTypeGiftCard
+	PaymentTypePackage
+	PaymentTypeMembership
 )
 
+//Label : label for the payment type
+func (p PaymentType) Label() string {
+	switch p {
+	case PaymentTypeBooking:
+		return "Invoice"
+	case PaymentTypeCampaign:
+		return "Campaign"
+	case PaymentTypeDirect:
+		return "Direct Payment"
+	case PaymentTypeFee:
+		return "Fee"
+	case PaymentTypeDeposit:
+		return "Deposit"
+	case PaymentTypeGiftCard:
+		return "Gift Card"
+	case PaymentTypePackage:
+		return "Package"
+	case PaymentTypeMembership:
+		return "Membership"
+	}
+	return ""
+}
+
+//PaymentRefund : definition of a refund made against a payment
+type PaymentRefund struct {
+	ExternalID string    `json:"ExternalID"`
+	Amount     int       `json:"Amount"` //non-decimal, in the minor unit of the payment currency
+	Reason     string    `json:"Reason"`
+	Created    time.Time `json:"Created"`
+}
+
+//GetAmount : get the refund amount
+func (p *PaymentRefund) GetAmount(currency Currency) float32 {
+	return currency.FromMinorUnits(p.Amount)
+}
+
+//FormatAmount : format the refund amount
+func (p *PaymentRefund) FormatAmount(currency Currency) string {
+	return currency.FormatAmountMinorUnits(p.Amount)
+}
+
+//FormatCreated : format the refund date
+func (p *PaymentRefund) FormatCreated(timeZone string) string {
+	return FormatDateTimeLocal(p.Created, timeZone)
+}
+
+//PaymentItemType : type of a line item on a payment
+type PaymentItemType string
+
+//payment item types
+const (
+	PaymentItemTypeService  PaymentItemType = "Service"
+	PaymentItemTypeAddOn    PaymentItemType = "Add-On"
+	PaymentItemTypeLabor    PaymentItemType = "Labor"
+	PaymentItemTypeMaterial PaymentItemType = "Materials"
+	PaymentItemTypeMileage  PaymentItemType = "Mileage"
+	PaymentItemTypeOther    PaymentItemType = "Other"
+	PaymentItemTypeDiscount PaymentItemType = "Discount"
+	PaymentItemTypeCredit   PaymentItemType = "Credit"
+	PaymentItemTypeTax      PaymentItemType = "Tax"
+	PaymentItemTypeTip      PaymentItemType = "Tip"
+)
+
+//PaymentItemTypes : payment item types that can be entered on an invoice, tax is computed separately and tips are added by the client
+var PaymentItemTypes []PaymentItemType = []PaymentItemType{
+	PaymentItemTypeService,
+	PaymentItemTypeAddOn,
+	PaymentItemTypeLabor,
+	PaymentItemTypeMaterial,
+	PaymentItemTypeMileage,
+	PaymentItemTypeOther,
+	PaymentItemTypeDiscount,
+	PaymentItemTypeCredit,
+}
+
+//ParsePaymentItemType : parse a payment item type
+func ParsePaymentItemType(in string) PaymentItemType {
+	switch in {
+	case string(PaymentItemTypeService):
+		return PaymentItemTypeService
+	case string(PaymentItemTypeAddOn):
+		return PaymentItemTypeAddOn
+	case string(PaymentItemTypeLabor):
+		return PaymentItemTypeLabor
+	case string(PaymentItemTypeMaterial):
+		return PaymentItemTypeMaterial
+	case string(PaymentItemTypeMileage):
+		return PaymentItemTypeMileage
+	case string(PaymentItemTypeOther):
+		return PaymentItemTypeOther
+	case string(PaymentItemTypeDiscount):
+		return PaymentItemTypeDiscount
+	case string(PaymentItemTypeCredit):
+		return PaymentItemTypeCredit
+	case string(PaymentItemTypeTax):
+		return PaymentItemTypeTax
+	case string(PaymentItemTypeTip):
+		return PaymentItemTypeTip
+	}
+	return ""
+}
+
+//IsDeduction : check if the item type reduces the amount owed
+func (p PaymentItemType) IsDeduction() bool {
+	return p == PaymentItemTypeDiscount || p == PaymentItemTypeCredit
+}
+
+//IsTaxable : check if the item type is subject to tax
+func (p PaymentItemType) IsTaxable() bool {
+	return p != PaymentItemTypeCredit && p != PaymentItemTypeTax && p != PaymentItemTypeTip
+}
+
+//IsSubTotal : check if the item type is included in the subtotal, which excludes the tax and tip
+func (p PaymentItemType) IsSubTotal() bool {
+	return p != PaymentItemTypeTax && p != PaymentItemTypeTip
+}
+
+//PaymentItem : definition of a line item on a payment
+type PaymentItem struct {
+	Type        PaymentItemType `json:"Type"`
+	Description string          `json:"Description"`
+	Quantity    float32         `json:"Quantity"`
+	Price       int             `json:"Price"`  //non-decimal unit price, in the minor unit of the payment currency
+	Amount      int             `json:"Amount"` //non-decimal, in the minor unit of the payment currency, negative for deductions
+}
+
+//NewPaymentItem : create a line item, computing the amount
+func NewPaymentItem(currency Currency, itemType PaymentItemType, desc string, quantity float32, price float32) *PaymentItem {
+	item := &PaymentItem{
+		Type:        itemType,
+		Description: desc,
+		Quantity:    quantity,
+		Price:       currency.ToMinorUnits(price),
+	}
+	item.Amount = int(math.Round(float64(item.Quantity) * float64(item.Price)))
+	if itemType.IsDeduction() {
+		item.Amount = -item.Amount
+	}
+	return item
+}
+
+//GetPrice : get the unit price
+func (p *PaymentItem) GetPrice(currency Currency) float32 {
+	return currency.FromMinorUnits(p.Price)
+}
+
+//FormatPrice : format the unit price
+func (p *PaymentItem) FormatPrice(currency Currency) string {
+	return currency.FormatAmountMinorUnits(p.Price)
+}
+
+//FormatQuantity : format the quantity
+func (p *PaymentItem) FormatQuantity() string {
+	return FormatFloat(p.Quantity)
+}
+
+//GetAmount : get the amount
+func (p *PaymentItem) GetAmount(currency Currency) float32 {
+	return currency.FromMinorUnits(p.Amount)
+}
+
+//FormatAmount : format the amount, showing deductions as negative
+func (p *PaymentItem) FormatAmount(currency Currency) string {
+	return currency.FormatAmountMinorUnits(p.Amount)
+}
+
+//ComputePaymentItems : compute the line items, adding a tax line if necessary, and the total amount
+func ComputePaymentItems(items []*PaymentItem, taxRate float32) ([]*PaymentItem, int) {
+	//sum the items, tracking the taxable amount
+	amount := 0
+	amountTaxable := 0
+	computed := make([]*PaymentItem, 0, len(items)+1)
+	for _, item := range items {
+		if item.Type == PaymentItemTypeTax {
+			continue
+		}
+		computed = append(computed, item)
+		amount += item.Amount
+		if item.Type.IsTaxable() {
+			amountTaxable += item.Amount
+		}
+	}
+
+	//add the tax
+	if taxRate > 0 && amountTaxable > 0 {
+		tax := &PaymentItem{
+			Type:        PaymentItemTypeTax,
+			Description: fmt.Sprintf("Tax (%s%%)", FormatRate(taxRate)),
+			Quantity:    1,
+		}
+		tax.Price = int(math.Round(float64(amountTaxable) * float64(taxRate) / 100))
+		tax.Amount = tax.Price
+		computed = append(computed, tax)
+		amount += tax.Amount
+	}
+	if amount < 0 {
+		amount = 0
+	}
+	return computed, amount
+}
+
 //Payment : definition of a Payment
 type Payment struct {
-	ID              *uuid.UUID  `json:"-"`
-	ProviderID      *uuid.UUID  `json:"-"`
-	SecondaryID     *uuid.UUID  `json:"-"`
-	FriendlyID      string      `json:"-"`
-	Type            PaymentType `json:"-"`
-	Amount          int         `json:"-"` //non-decimal, pennies in USD
-	PayPalID        *string     `json:"-"`
-	StripeAccountID *string     `json:"-"`
-	StripeSessionID *string     `json:"-"`
-	StripeID        *string     `json:"-"`
-	ExternalData    *string     `json:"-"`
-	Invoiced        *time.Time  `json:"-"`
-	Paid            *time.Time  `json:"-"`
-	Captured        *time.Time  `json:"-"`
-	Client          *Client     `json:"-"`
-	Name            string      `json:"Name"`
-	Email           string      `json:"Email"`
-	Phone           string      `json:"Phone"`
-	ProviderName    string      `json:"ProviderName"`
-	Description     string      `json:"Description"`
-	Note            string      `json:"Note"`
-	URL             string      `json:"Url"`
-	ClientInitiated bool        `json:"ClientInitiated"`
-	DirectCapture   bool        `json:"DirectCapture"`
-	Internal        bool        `json:"Internal"`
-	ServiceID       string      `json:"ServiceID"`
+	ID              *uuid.UUID       `json:"-"`
+	ProviderID      *uuid.UUID       `json:"-"`
+	SecondaryID     *uuid.UUID       `json:"-"`
+	FriendlyID      string           `json:"-"`
+	Type            PaymentType      `json:"-"`
+	Amount          int              `json:"-"` //non-decimal, in the minor unit of the currency
+	PayPalID        *string          `json:"-"`
+	StripeAccountID *string          `json:"-"`
+	StripeSessionID *string          `json:"-"`
+	StripeID        *string          `json:"-"`
+	ExternalData    *string          `json:"-"`
+	Invoiced        *time.Time       `json:"-"`
+	Paid            *time.Time       `json:"-"`
+	Captured        *time.Time       `json:"-"`
+	Client          *Client          `json:"-"`
+	Name            string           `json:"Name"`
+	Email           string           `json:"Email"`
+	Phone           string           `json:"Phone"`
+	ProviderName    string           `json:"ProviderName"`
+	Currency        Currency         `json:"Currency"`
+	Description     string           `json:"Description"`
+	Note            string           `json:"Note"`
+	URL             string           `json:"Url"`
+	ClientInitiated bool             `json:"ClientInitiated"`
+	DirectCapture   bool             `json:"DirectCapture"`
+	Internal        bool             `json:"Internal"`
+	ServiceID       string           `json:"ServiceID"`
+	Refunds         []*PaymentRefund `json:"Refunds"`
+	Items           []*PaymentItem   `json:"Items"`
+	Reminders       []time.Time      `json:"Reminders"`
+	TipUserID       *uuid.UUID       `json:"TipUserID"`
+	TipUserName     string           `json:"TipUserName"`
+}
+
+//GetCurrency : get the currency, defaulting for payments made before the currency was recorded
+func (p *Payment) GetCurrency() Currency {
+	if p.Currency == "" {
+		return CurrencyDefault
+	}
+	return p.Currency
 }
 
 //SetAmount : set the payment amount as a fractionless number
 func (p *Payment) SetAmount(amount float32) {
-	//convert to the lowest unit
-	amount = amount * 100
-	p.Amount = int(math.Ceil(float64(amount)))
+	p.Amount = p.GetCurrency().ToMinorUnits(amount)
 }
 
 //GetAmount : get the payment amount
 func (p *Payment) GetAmount() float32 {
-	return float32(p.Amount) / 100
+	return p.GetCurrency().FromMinorUnits(p.Amount)
+}
+
+//FormatAmount : format the payment amount
+func (p *Payment) FormatAmount() string {
+	return p.GetCurrency().FormatAmountMinorUnits(p.Amount)
+}
+
+//SetItems : set the line items, adding the tax, and set the payment amount to the total
+func (p *Payment) SetItems(items []*PaymentItem, taxRate float32) {
+	p.Items, p.Amount = ComputePaymentItems(items, taxRate)
+}
+
+//ApplyCredit : apply a credit against the amount owed, itemizing the payment if necessary
+func (p *Payment) ApplyCredit(desc string, amount int) {
+	p.itemize()
+
+	//add the credit before the tax and tip, which are unaffected since credits are not taxable
+	credit := &PaymentItem{
+		Type:        PaymentItemTypeCredit,
+		Description: desc,
+		Quantity:    1,
+		Price:       amount,
+		Amount:      -amount,
+	}
+	items := make([]*PaymentItem, 0, len(p.Items)+1)
+	for _, item := range p.Items {
+		if !item.Type.IsSubTotal() && credit != nil {
+			items = append(items, credit)
+			credit = nil
+		}
+		items = append(items, item)
+	}
+	if credit != nil {
+		items = append(items, credit)
+	}
+	p.Items = items
+	p.Amount = Max(p.Amount-amount, 0)
+}
+
+//SetTip : set the tip added by the client, replacing any existing tip
+func (p *Payment) SetTip(amount int) {
+	tipPrev := p.ComputeAmountTip()
+	p.itemize()
+
+	//remove the existing tip and add the new one after the tax
+	items := make([]*PaymentItem, 0, len(p.Items)+1)
+	for _, item := range p.Items {
+		if item.Type == PaymentItemTypeTip {
+			continue
+		}
+		items = append(items, item)
+	}
+	if amount > 0 {
+		items = append(items, &PaymentItem{
+			Type:        PaymentItemTypeTip,
+			Description: "Tip",
+			Quantity:    1,
+			Price:       amount,
+			Amount:      amount,
+		})
+	}
+	p.Items = items
+	p.Amount = Max(p.Amount-tipPrev+amount, 0)
+}
+
+//itemize the original amount if the payment has no line items
+func (p *Payment) itemize() {
+	if len(p.Items) > 0 {
+		return
+	}
+	p.Items = []*PaymentItem{
+		{
+			Type:        PaymentItemTypeService,
+			Description: p.Description,
+			Quantity:    1,
+			Price:       p.Amount,
+			Amount:      p.Amount,
+		},
+	}
+}
+
+//HasItems : check if the payment is itemized
+func (p *Payment) HasItems() bool {
+	return len(p.Items) > 0
+}
+
+//ComputeAmountTax : compute the tax from the line items, as a fractionless number
+func (p *Payment) ComputeAmountTax() int {
+	amount := 0
+	for _, item := range p.Items {
+		if item.Type == PaymentItemTypeTax {
+			amount += item.Amount
+		}
+	}
+	return amount
+}
+
+//ComputeAmountTip : compute the tip from the line items, as a fractionless number
+func (p *Payment) ComputeAmountTip() int {
+	amount := 0
+	for _, item := range p.Items {
+		if item.Type == PaymentItemTypeTip {
+			amount += item.Amount
+		}
+	}
+	return amount
+}
+
+//ComputeAmountBeforeTip : compute the amount owed excluding the tip, as a fractionless number
+func (p *Payment) ComputeAmountBeforeTip() int {
+	return Max(p.Amount-p.ComputeAmountTip(), 0)
+}
+
+//ComputeTip : compute the tip for a percentage of the amount owed excluding the tip, as a fractionless number
+func (p *Payment) ComputeTip(percent int) int {
+	return int(math.Round(float64(p.ComputeAmountBeforeTip()) * float64(percent) / 100))
+}
+
+//FormatTip : format the tip for a percentage of the amount owed excluding the tip
+func (p *Payment) FormatTip(percent int) string {
+	return p.GetCurrency().FormatAmountMinorUnits(p.ComputeTip(percent))
+}
+
+//HasTip : check if the client added a tip
+func (p *Payment) HasTip() bool {
+	return p.ComputeAmountTip() > 0
+}
+
+//SupportsTip : check if the client can add a tip, which is limited to unpaid booking invoices
+func (p *Payment) SupportsTip() bool {
+	return p.Type == PaymentTypeBooking && !p.IsPaid()
+}
+
+//ComputeAmountSubTotal : compute the sum of the line items before tax and tip, as a fractionless number
+func (p *Payment) ComputeAmountSubTotal() int {
+	amount := 0
+	for _, item := range p.Items {
+		if item.Type.IsSubTotal() {
+			amount += item.Amount
+		}
+	}
+	return amount
+}
+
+//FormatAmountSubTotal : format the sum of the line items before tax
+func (p *Payment) FormatAmountSubTotal() string {
+	return p.GetCurrency().FormatAmountMinorUnits(p.ComputeAmountSubTotal())
+}
+
+//FormatAmountTip : format the tip
+func (p *Payment) FormatAmountTip() string {
+	return p.GetCurrency().FormatAmountMinorUnits(p.ComputeAmountTip())
+}
+
+//FormatAmountTax : format the tax
+func (p *Payment) FormatAmountTax() string {
+	return p.GetCurrency().FormatAmountMinorUnits(p.ComputeAmountTax())
+}
+
+//ComputeAmountRefunded : compute the total amount refunded, as a fractionless number
+func (p *Payment) ComputeAmountRefunded() int {
+	amount := 0
+	for _, refund := range p.Refunds {
+		amount += refund.Amount
+	}
+	return amount
+}
+
+//GetAmountRefunded : get the total amount refunded
+func (p *Payment) GetAmountRefunded() float32 {
+	return p.GetCurrency().FromMinorUnits(p.ComputeAmountRefunded())
+}
+
+//GetAmountRefundable : get the amount remaining that can be refunded
+func (p *Payment) GetAmountRefundable() float32 {
+	return p.GetCurrency().FromMinorUnits(p.Amount - p.ComputeAmountRefunded())
+}
+
+//GetAmountNet : get the payment amount less any refunds
+func (p *Payment) GetAmountNet() float32 {
+	return p.GetAmount() - p.GetAmountRefunded()
+}
+
+//FormatAmountRefunded : format the total amount refunded
+func (p *Payment) FormatAmountRefunded() string {
+	return p.GetCurrency().FormatAmountMinorUnits(p.ComputeAmountRefunded())
+}
+
+//FormatAmountNet : format the payment amount less any refunds
+func (p *Payment) FormatAmountNet() string {
+	return p.GetCurrency().FormatAmountMinorUnits(p.Amount - p.ComputeAmountRefunded())
+}
+
+//GetRefundLatest : get the most recent refund
+func (p *Payment) GetRefundLatest() *PaymentRefund {
+	if len(p.Refunds) == 0 {
+		return nil
+	}
+	return p.Refunds[len(p.Refunds)-1]
+}
+
+//IsRefunded : check if a payment has been at least partially refunded
+func (p *Payment) IsRefunded() bool {
+	return len(p.Refunds) > 0
+}
+
+//IsRefundedFull : check if a payment has been fully refunded
+func (p *Payment) IsRefundedFull() bool {
+	return p.IsRefunded() && p.ComputeAmountRefunded() >= p.Amount
+}
+
+//AddRefund : add a refund, returning false if a refund with the same external id was already recorded
+func (p *Payment) AddRefund(refund *PaymentRefund) bool {
+	for _, paymentRefund := range p.Refunds {
+		if refund.ExternalID != "" && paymentRefund.ExternalID == refund.ExternalID {
+			return false
+		}
+	}
+	p.Refunds = append(p.Refunds, refund)
+	return true
+}
+
+//AllowRefund : check if a payment can be refunded
+func (p *Payment) AllowRefund() bool {
+	return p.IsCaptured() && !p.IsRefundedFull()
+}
+
+//AllowRefundAmount : check if the amount can be refunded
+func (p *Payment) AllowRefundAmount(amount float32) bool {
+	refund := p.GetCurrency().ToMinorUnits(amount)
+	return refund > 0 && refund <= p.Amount-p.ComputeAmountRefunded()
 }
 
 //IsCaptured : check if a payment has been captured
@@ -126,6 +575,28 @@ func (p *Payment) FormatPaid(timeZone string) string {
 	return FormatDateTimeLocal(*p.Paid, timeZone)
 }
 
+//HasReminders : check if payment reminders have been sent
+func (p *Payment) HasReminders() bool {
+	return len(p.Reminders) > 0
+}
+
+//FormatReminders : format the count of payment reminders sent
+func (p *Payment) FormatReminders() string {
+	count := len(p.Reminders)
+	if count == 1 {
+		return "1 Reminder Sent"
+	}
+	return fmt.Sprintf("%d Reminders Sent", count)
+}
+
+//FormatReminderLatest : format the date of the latest payment reminder
+func (p *Payment) FormatReminderLatest(timeZone string) string {
+	if len(p.Reminders) == 0 {
+		return ""
+	}
+	return FormatDateTimeLocal(p.Reminders[len(p.Reminders)-1], timeZone)
+}
+
 //create the statement to load a payment
 func paymentQueryCreate(whereStmt string) string {
 	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(p.id),BIN_TO_UUID(p.provider_id),BIN_TO_UUID(p.secondary_id),p.friendly_id,p.type,p.amount,p.invoiced,p.paid,p.captured,p.stripe_id,p.stripe_session_id,p.stripe_account_id,p.paypal_id,p.data,c.email,c.data FROM %s p LEFT JOIN %s b ON b.id=p.secondary_id LEFT JOIN %s c ON c.id=b.client_id WHERE %s ORDER BY p.invoiced DESC", dbTablePayment, dbTableBooking, dbTableClient, whereStmt)
@@ -256,13 +727,19 @@ func LoadPaymentByProviderIDAndSecondaryIDAndType(ctx context.Context, db *DB, p
 	return loadPayment(ctx, db, whereStmt, providerID, id, paymentType)
 }
 
+//LoadPaymentByExternalID : load a payment by the external id
+func LoadPaymentByExternalID(ctx context.Context, db *DB, id *string) (context.Context, *Payment, error) {
+	whereStmt := "p.deleted=0 AND (p.stripe_id=? OR p.paypal_id=?)"
+	return loadPayment(ctx, db, whereStmt, id, id)
+}
+
 //SavePayment : save a payment
 func SavePayment(ctx context.Context, db *DB, payment *Payment, isDirectCapture bool, deletePrevious bool) (context.Context, error) {
 	ctx, err := db.ProcessTx(ctx, "save payment", func(ctx context.Context, db *DB) (context.Context, error) {
 		//delete any previous payments
 		if deletePrevious {
-			stmt := fmt.Sprintf("UPDATE %s SET deleted=1 WHERE deleted=0 AND provider_id=UUID_TO_BIN(?) AND secondary_id=UUID_TO_BIN(?)", dbTablePayment)
-			ctx, _, err := db.Exec(ctx, stmt, payment.ProviderID, payment.SecondaryID)
+			stmt := fmt.Sprintf("UPDATE %s SET deleted=1 WHERE deleted=0 AND provider_id=UUID_TO_BIN(?) AND secondary_id=UUID_TO_BIN(?) AND type=?", dbTablePayment)
+			ctx, _, err := db.Exec(ctx, stmt, payment.ProviderID, payment.SecondaryID, payment.Type)
 			if err != nil {
 				return ctx, errors.Wrap(err, "update payment")
 			}
@@ -313,6 +790,14 @@ func SavePayment(ctx context.Context, db *DB, payment *Payment, isDirectCapture
 		if count == 0 {
 			return ctx, fmt.Errorf("unable to insert payment: %s: %s", payment.ProviderID, payment.SecondaryID)
 		}
+
+		//link the payment to any coupon redeemed for the booking
+		if payment.Type == PaymentTypeBooking {
+			ctx, err = UpdateCouponRedemptionPayment(ctx, db, payment.SecondaryID, payment.ID)
+			if err != nil {
+				return ctx, errors.Wrap(err, "update coupon redemption payment")
+			}
+		}
 		return ctx, nil
 	})
 	if err != nil {
@@ -379,6 +864,23 @@ func UpdatePaymentPayPalID(ctx context.Context, db *DB, id *uuid.UUID, paypalID
 	return ctx, nil
 }
 
+//UpdatePaymentAmount : update the amount and data of a payment
+func UpdatePaymentAmount(ctx context.Context, db *DB, payment *Payment) (context.Context, error) {
+	//json encode the data
+	dataJSON, err := json.Marshal(payment)
+	if err != nil {
+		return ctx, errors.Wrap(err, "json payment")
+	}
+
+	//update
+	stmt := fmt.Sprintf("UPDATE %s SET amount=?,data=? WHERE id=UUID_TO_BIN(?)", dbTablePayment)
+	ctx, _, err = db.Exec(ctx, stmt, payment.Amount, dataJSON, payment.ID)
+	if err != nil {
+		return ctx, errors.Wrap(err, "update payment amount")
+	}
+	return ctx, nil
+}
+
 //UpdatePaymentCaptured : update the captured state of the payment
 func UpdatePaymentCaptured(ctx context.Context, db *DB, id *string, externalData *string, now *time.Time) (context.Context, error) {
 	stmt := fmt.Sprintf("UPDATE %s SET external_data=?,captured=?,deleted=0 WHERE id=UUID_TO_BIN(?)", dbTablePayment)
@@ -416,6 +918,126 @@ func UpdatePaymentData(ctx context.Context, db *DB, payment *Payment) (context.C
 	return ctx, nil
 }
 
+//SavePaymentRefund : record a refund against a payment, returning false if the refund has already been recorded
+func SavePaymentRefund(ctx context.Context, db *DB, id *uuid.UUID, refund *PaymentRefund) (context.Context, bool, error) {
+	added := false
+	ctx, err := db.ProcessTx(ctx, "save payment refund", func(ctx context.Context, db *DB) (context.Context, error) {
+		//lock the payment
+		stmt := fmt.Sprintf("SELECT amount,data FROM %s WHERE id=UUID_TO_BIN(?) FOR UPDATE", dbTablePayment)
+		ctx, row, err := db.QueryRow(ctx, stmt, id)
+		if err != nil {
+			return ctx, errors.Wrap(err, "query row payment data")
+		}
+		var amount int
+		var dataStr string
+		err = row.Scan(&amount, &dataStr)
+		if err != nil {
+			return ctx, errors.Wrap(err, "select payment data")
+		}
+		var payment Payment
+		err = json.Unmarshal([]byte(dataStr), &payment)
+		if err != nil {
+			return ctx, errors.Wrap(err, "unjson payment")
+		}
+		payment.ID = id
+		payment.Amount = amount
+
+		//ignore a refund already recorded
+		if !payment.AddRefund(refund) {
+			return ctx, nil
+		}
+
+		//json encode the data
+		dataJSON, err := json.Marshal(payment)
+		if err != nil {
+			return ctx, errors.Wrap(err, "json payment")
+		}
+
+		//update
+		stmt = fmt.Sprintf("UPDATE %s SET data=? WHERE id=UUID_TO_BIN(?)", dbTablePayment)
+		ctx, _, err = db.Exec(ctx, stmt, dataJSON, id)
+		if err != nil {
+			return ctx, errors.Wrap(err, "update payment")
+		}
+
+		//reverse any gift card purchased or redeemed with the payment
+		ctx, err = reverseGiftCardEntries(ctx, db, &payment)
+		if err != nil {
+			return ctx, errors.Wrap(err, "reverse gift card entries")
+		}
+		added = true
+		return ctx, nil
+	})
+	if err != nil {
+		return ctx, false, errors.Wrap(err, "save payment refund")
+	}
+	return ctx, added, nil
+}
+
+//SavePaymentReminder : record a payment reminder sent for a payment
+func SavePaymentReminder(ctx context.Context, db *DB, id *uuid.UUID, sent time.Time) (context.Context, error) {
+	ctx, err := db.ProcessTx(ctx, "save payment reminder", func(ctx context.Context, db *DB) (context.Context, error) {
+		//lock the payment
+		stmt := fmt.Sprintf("SELECT data FROM %s WHERE id=UUID_TO_BIN(?) FOR UPDATE", dbTablePayment)
+		ctx, row, err := db.QueryRow(ctx, stmt, id)
+		if err != nil {
+			return ctx, errors.Wrap(err, "query row payment data")
+		}
+		var dataStr string
+		err = row.Scan(&dataStr)
+		if err != nil {
+			return ctx, errors.Wrap(err, "select payment data")
+		}
+		var payment Payment
+		err = json.Unmarshal([]byte(dataStr), &payment)
+		if err != nil {
+			return ctx, errors.Wrap(err, "unjson payment")
+		}
+		payment.Reminders = append(payment.Reminders, sent.UTC())
+
+		//json encode the data
+		dataJSON, err := json.Marshal(payment)
+		if err != nil {
+			return ctx, errors.Wrap(err, "json payment")
+		}
+
+		//update
+		stmt = fmt.Sprintf("UPDATE %s SET data=? WHERE id=UUID_TO_BIN(?)", dbTablePayment)
+		ctx, _, err = db.Exec(ctx, stmt, dataJSON, id)
+		if err != nil {
+			return ctx, errors.Wrap(err, "update payment")
+		}
+		return ctx, nil
+	})
+	if err != nil {
+		return ctx, errors.Wrap(err, "save payment reminder")
+	}
+	return ctx, nil
+}
+
+//LoadPaymentExternalDataByID : load the external data stored when a payment was captured
+func LoadPaymentExternalDataByID(ctx context.Context, db *DB, id *uuid.UUID) (context.Context, *string, error) {
+	stmt := fmt.Sprintf("SELECT external_data FROM %s WHERE id=UUID_TO_BIN(?)", dbTablePayment)
+	ctx, row, err := db.QueryRow(ctx, stmt, id)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "query row payment external data")
+	}
+
+	//read the row
+	var externalData sql.NullString
+	err = row.Scan(&externalData)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx, nil, nil
+		}
+		return ctx, nil, errors.Wrap(err, "select payment external data")
+	}
+	if !externalData.Valid {
+		return ctx, nil, nil
+	}
+	return ctx, &externalData.String, nil
+}
+
 //DeletePayment : delete a payment
 func DeletePayment(ctx context.Context, db *DB, id *uuid.UUID) (context.Context, error) {
 	stmt := fmt.Sprintf("UPDATE %s SET deleted=1 WHERE deleted=0 AND captured IS NULL AND id=UUID_TO_BIN(?)", dbTablePayment)
@@ -431,7 +1053,7 @@ func ListPaymentsByProviderIDAndFilter(ctx context.Context, db *DB, providerID *
 	ctx, logger := GetLogger(ctx)
 
 	//load the payments based on the filter
-	whereStmt := "p.deleted=0 AND p.provider_id=UUID_TO_BIN(?) AND (p.type=? OR (p.type=? AND p.paid IS NOT NULL))"
+	whereStmt := "p.deleted=0 AND p.provider_id=UUID_TO_BIN(?) AND (p.type IN (?,?,?,?) OR (p.type IN (?,?,?) AND p.paid IS NOT NULL))"
 	switch filter {
 	case PaymentFilterAll:
 	case PaymentFilterUnPaid:
@@ -440,7 +1062,7 @@ func ListPaymentsByProviderIDAndFilter(ctx context.Context, db *DB, providerID *
 		return ctx, nil, fmt.Errorf("invalid filter: %s", filter)
 	}
 	stmt := paymentQueryCreate(whereStmt)
-	ctx, rows, err := db.Query(ctx, stmt, providerID, PaymentTypeBooking, PaymentTypeDirect)
+	ctx, rows, err := db.Query(ctx, stmt, providerID, PaymentTypeBooking, PaymentTypeFee, PaymentTypeDeposit, PaymentTypeMembership, PaymentTypeDirect, PaymentTypeGiftCard, PaymentTypePackage)
 	if err != nil {
 		return ctx, nil, errors.Wrap(err, "select payments")
 	}
@@ -463,9 +1085,65 @@ func ListPaymentsByProviderIDAndFilter(ctx context.Context, db *DB, providerID *
 	return ctx, payments, nil
 }
 
+//ListPaymentsByProviderIDAndSecondaryID : list the payments made against a booking
+func ListPaymentsByProviderIDAndSecondaryID(ctx context.Context, db *DB, providerID *uuid.UUID, secondaryID *uuid.UUID) (context.Context, []*Payment, error) {
+	ctx, logger := GetLogger(ctx)
+	whereStmt := "p.deleted=0 AND p.provider_id=UUID_TO_BIN(?) AND p.secondary_id=UUID_TO_BIN(?)"
+	stmt := paymentQueryCreate(whereStmt)
+	ctx, rows, err := db.Query(ctx, stmt, providerID, secondaryID)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "select payments")
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Warnw("rows close select payments", "error", err)
+		}
+	}()
+
+	//read the payments
+	payments := make([]*Payment, 0, 2)
+	for rows.Next() {
+		payment, err := paymentQueryParse(rows.Scan)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "payment parse")
+		}
+		payments = append(payments, payment)
+	}
+	return ctx, payments, nil
+}
+
+//ListPaymentsByProviderIDAndCaptured : list the payments for a provider captured within the time range
+func ListPaymentsByProviderIDAndCaptured(ctx context.Context, db *DB, providerID *uuid.UUID, start time.Time, end time.Time) (context.Context, []*Payment, error) {
+	ctx, logger := GetLogger(ctx)
+	whereStmt := "p.deleted=0 AND p.provider_id=UUID_TO_BIN(?) AND p.type IN (?,?,?,?,?,?,?) AND p.captured>=? AND p.captured<?"
+	stmt := paymentQueryCreate(whereStmt)
+	ctx, rows, err := db.Query(ctx, stmt, providerID, PaymentTypeBooking, PaymentTypeFee, PaymentTypeDeposit, PaymentTypeMembership, PaymentTypeDirect, PaymentTypeGiftCard, PaymentTypePackage, start, end)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "select payments")
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Warnw("rows close select payments", "error", err)
+		}
+	}()
+
+	//read the payments
+	payments := make([]*Payment, 0, 2)
+	for rows.Next() {
+		payment, err := paymentQueryParse(rows.Scan)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "payment parse")
+		}
+		payments = append(payments, payment)
+	}
+	return ctx, payments, nil
+}
+
 //CountPaymentsByProviderIDAndFilter : count the payments for a provider based on the filter
 func CountPaymentsByProviderIDAndFilter(ctx context.Context, db *DB, providerID *uuid.UUID, filter PaymentFilter) (context.Context, int, error) {
-	whereStmt := "deleted=0 AND provider_id=UUID_TO_BIN(?) AND (type=? OR (type=? AND paid IS NOT NULL))"
+	whereStmt := "deleted=0 AND provider_id=UUID_TO_BIN(?) AND (type IN (?,?,?,?) OR (type IN (?,?,?) AND paid IS NOT NULL))"
 	switch filter {
 	case PaymentFilterAll:
 	case PaymentFilterUnPaid:
@@ -476,7 +1154,7 @@ func CountPaymentsByProviderIDAndFilter(ctx context.Context, db *DB, providerID
 
 	//count the payments
 	stmt := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", dbTablePayment, whereStmt)
-	ctx, row, err := db.QueryRow(ctx, stmt, providerID, PaymentTypeBooking, PaymentTypeDirect)
+	ctx, row, err := db.QueryRow(ctx, stmt, providerID, PaymentTypeBooking, PaymentTypeFee, PaymentTypeDeposit, PaymentTypeMembership, PaymentTypeDirect, PaymentTypeGiftCard, PaymentTypePackage)
 	if err != nil {
 		return ctx, 0, errors.Wrap(err, "query row payments count")
 	}
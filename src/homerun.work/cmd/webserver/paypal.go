@@ -20,8 +20,10 @@ const (
 //paypal constants
 const (
 	PayPalResourceTypeCapture              = "capture"
+	PayPalResourceTypeRefund               = "refund"
 	PayPalResourceVersion                  = "2.0"
 	PayPalEventTypePaymentCaptureCompleted = "PAYMENT.CAPTURE.COMPLETED"
+	PayPalEventTypePaymentCaptureRefunded  = "PAYMENT.CAPTURE.REFUNDED"
 	PayPalEventVersion                     = "1.0"
 	PayPalVerificationStatusSuccess        = "SUCCESS"
 )
@@ -71,6 +73,28 @@ type ResourcePaymentPayPal struct {
 	SellerBreakdown  SellerBreakdownPayPal  `json:"seller_receivable_breakdown"`
 }
 
+//GetAmount : get the amount as a fractionless number
+func (m *MoneyPayPal) GetAmount() (int, error) {
+	amount, err := strconv.ParseFloat(m.Value, 32)
+	if err != nil {
+		return 0, errors.Wrap(err, fmt.Sprintf("parse paypal amount: %s", m.Value))
+	}
+	return ParseCurrency(m.CurrencyCode).ToMinorUnits(float32(amount)), nil
+}
+
+//ResourceRefundPayPal : PayPal refund resource
+type ResourceRefundPayPal struct {
+	ID          string       `json:"id"`
+	InvoiceID   string       `json:"invoice_id"`
+	CustomID    string       `json:"custom_id"`
+	Status      string       `json:"status"`
+	NoteToPayer string       `json:"note_to_payer"`
+	CreateTime  string       `json:"create_time"`
+	UpdateTime  string       `json:"update_time"`
+	Links       []LinkPayPal `json:"links"`
+	Amount      MoneyPayPal  `json:"amount"`
+}
+
 //EventPayPal : PayPal event
 type EventPayPal struct {
 	ID              string          `json:"id"`
@@ -104,6 +128,32 @@ func ParseResourcePaymentPayPal(in []byte) (*ResourcePaymentPayPal, error) {
 	return &resource, nil
 }
 
+//ParseResourceRefundPayPal : parse a PayPal refund resource
+func ParseResourceRefundPayPal(in []byte) (*ResourceRefundPayPal, error) {
+	var resource ResourceRefundPayPal
+	err := json.Unmarshal(in, &resource)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse paypal refund resource")
+	}
+	return &resource, nil
+}
+
+//ParseCaptureIDPayPal : parse the capture id from the PayPal capture event stored for a payment
+func ParseCaptureIDPayPal(in []byte) (string, error) {
+	event, err := ParseEventPayPal(in)
+	if err != nil {
+		return "", errors.Wrap(err, "parse paypal event")
+	}
+	if event.ResourceType != PayPalResourceTypeCapture {
+		return "", fmt.Errorf("invalid paypal resource type: %s", event.ResourceType)
+	}
+	resource, err := ParseResourcePaymentPayPal([]byte(event.Resource))
+	if err != nil {
+		return "", errors.Wrap(err, "parse paypal payment resource")
+	}
+	return resource.ID, nil
+}
+
 //logger wrapper
 type loggerPayPal struct {
 	logger *Logger
@@ -134,7 +184,7 @@ func createClientPayPal() (*paypal.Client, error) {
 }
 
 //CreateOrderPayPal : create a PayPal order
-func CreateOrderPayPal(ctx context.Context, payeeEmail *string, providerName string, desc string, paymentID string, customID string, amount float32) (*OrderPayPal, error) {
+func CreateOrderPayPal(ctx context.Context, payeeEmail *string, providerName string, desc string, paymentID string, customID string, currency Currency, amount float32) (*OrderPayPal, error) {
 	ctx, logger := GetLogger(ctx)
 	start := time.Now()
 	defer func() {
@@ -148,8 +198,8 @@ func CreateOrderPayPal(ctx context.Context, payeeEmail *string, providerName str
 		InvoiceID:   paymentID,
 		CustomID:    customID,
 		Amount: &paypal.PurchaseUnitAmount{
-			Value:    strconv.FormatFloat(float64(amount), 'f', 2, 32),
-			Currency: "USD",
+			Value:    currency.FormatDecimal(amount),
+			Currency: currency.Code(),
 		},
 	}
 	if payeeEmail != nil {
@@ -176,6 +226,70 @@ func CreateOrderPayPal(ctx context.Context, payeeEmail *string, providerName str
 	return order, nil
 }
 
+//UpdateOrderAmountPayPal : update the amount of a PayPal order that has not been captured
+func UpdateOrderAmountPayPal(ctx context.Context, orderID string, currency Currency, amount float32) error {
+	ctx, logger := GetLogger(ctx)
+	start := time.Now()
+	defer func() {
+		logger.Debugw("paypal update order", "elapsedMS", FormatElapsedMS(start))
+		AddCtxStatsAPI(ctx, ServerStatAPIPayPal, "paypal update order", time.Since(start))
+	}()
+
+	//set-up the patch, since the client library sends the purchase units instead
+	patch := []paypal.PaymentPatch{
+		{
+			Operation: "replace",
+			Path:      "/purchase_units/@reference_id=='default'/amount",
+			Value: &paypal.PurchaseUnitAmount{
+				Value:    currency.FormatDecimal(amount),
+				Currency: currency.Code(),
+			},
+		},
+	}
+	client, err := createClientPayPal()
+	if err != nil {
+		return errors.Wrap(err, "paypal create client")
+	}
+	req, err := client.NewRequest(http.MethodPatch, fmt.Sprintf("%s/v2/checkout/orders/%s", client.APIBase, orderID), patch)
+	if err != nil {
+		return errors.Wrap(err, "paypal update order request")
+	}
+	err = client.SendWithAuth(req, nil)
+	if err != nil {
+		return errors.Wrap(err, "paypal update order")
+	}
+	return nil
+}
+
+//RefundCapturePayPal : refund all or part of a PayPal capture
+func RefundCapturePayPal(ctx context.Context, captureID string, paymentID string, currency Currency, amount float32, note string) (*paypal.RefundResponse, error) {
+	ctx, logger := GetLogger(ctx)
+	start := time.Now()
+	defer func() {
+		logger.Debugw("paypal refund capture", "elapsedMS", FormatElapsedMS(start))
+		AddCtxStatsAPI(ctx, ServerStatAPIPayPal, "paypal refund capture", time.Since(start))
+	}()
+
+	//set-up the refund parameters
+	request := paypal.RefundCaptureRequest{
+		Amount: &paypal.Money{
+			Value:    currency.FormatDecimal(amount),
+			Currency: currency.Code(),
+		},
+		InvoiceID:   paymentID,
+		NoteToPayer: note,
+	}
+	client, err := createClientPayPal()
+	if err != nil {
+		return nil, errors.Wrap(err, "paypal create client")
+	}
+	result, err := client.RefundCapture(captureID, request)
+	if err != nil {
+		return nil, errors.Wrap(err, "paypal refund capture")
+	}
+	return result, nil
+}
+
 //VerifyWebHookSignaturePayPal : verify the payload signature for a PayPal webhook event
 func VerifyWebHookSignaturePayPal(r *http.Request, webhookID string) (bool, error) {
 	ctx, logger := GetLogger(r.Context())
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/pkg/errors"
+)
+
+//pdf constants
+const (
+	pdfContentType  = "application/pdf"
+	pdfFontFamily   = "Helvetica"
+	pdfLineHeight   = 6
+	pdfLogoName     = "logo"
+	pdfLogoSize     = 20
+	pdfMargin       = 15
+	pdfPageSize     = "Letter"
+	pdfPageWidth    = 215.9 //letter, in mm
+	pdfContentWidth = pdfPageWidth - (2 * pdfMargin)
+)
+
+//pdf image types by content type
+var pdfImgTypes = map[string]string{
+	"image/gif":  "GIF",
+	"image/jpeg": "JPG",
+	"image/png":  "PNG",
+}
+
+//PaymentPDF : generated pdf for a payment
+type PaymentPDF struct {
+	FileName string
+	Data     []byte
+}
+
+//CreatePaymentPDF : create a pdf invoice or receipt for a payment, optionally with the provider logo
+func CreatePaymentPDF(payment *Payment, providerName string, logo []byte, timeZone string) (*PaymentPDF, error) {
+	pdf := gofpdf.New("P", "mm", pdfPageSize, "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.SetTitle(fmt.Sprintf("%s #%s", payment.Type.Label(), payment.FriendlyID), true)
+	pdf.SetAuthor(providerName, true)
+	pdf.AddPage()
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	//add the logo
+	headerX := float64(pdfMargin)
+	if len(logo) > 0 {
+		imgType, ok := pdfImgTypes[http.DetectContentType(logo)]
+		if ok {
+			pdf.RegisterImageOptionsReader(pdfLogoName, gofpdf.ImageOptions{ImageType: imgType}, bytes.NewReader(logo))
+			pdf.ImageOptions(pdfLogoName, pdfMargin, pdfMargin, pdfLogoSize, pdfLogoSize, false, gofpdf.ImageOptions{ImageType: imgType}, 0, "")
+			headerX += pdfLogoSize + 5
+		}
+	}
+
+	//add the header, using a receipt once paid
+	title := "INVOICE"
+	if payment.IsCaptured() || payment.IsPaid() {
+		title = "RECEIPT"
+	}
+	pdf.SetXY(headerX, pdfMargin)
+	pdf.SetFont(pdfFontFamily, "B", 16)
+	pdf.CellFormat(pdfContentWidth-(headerX-pdfMargin), 8, tr(providerName), "", 0, "L", false, 0, "")
+	pdf.SetXY(pdfMargin, pdfMargin)
+	pdf.SetFont(pdfFontFamily, "B", 20)
+	pdf.CellFormat(pdfContentWidth, 8, title, "", 1, "R", false, 0, "")
+	pdf.SetFont(pdfFontFamily, "", 10)
+	pdf.CellFormat(pdfContentWidth, pdfLineHeight, fmt.Sprintf("%s #: %s", payment.Type.Label(), payment.FriendlyID), "", 1, "R", false, 0, "")
+	if payment.IsInvoiced() {
+		pdf.CellFormat(pdfContentWidth, pdfLineHeight, tr(fmt.Sprintf("Invoiced: %s", payment.FormatInvoiced(timeZone))), "", 1, "R", false, 0, "")
+	}
+	if payment.IsCaptured() {
+		pdf.CellFormat(pdfContentWidth, pdfLineHeight, tr(fmt.Sprintf("Paid: %s", payment.FormatCaptured(timeZone))), "", 1, "R", false, 0, "")
+	} else if payment.IsPaid() {
+		pdf.CellFormat(pdfContentWidth, pdfLineHeight, tr(fmt.Sprintf("Received: %s", payment.FormatPaid(timeZone))), "", 1, "R", false, 0, "")
+	}
+	pdf.SetY(pdf.GetY() + pdfLineHeight)
+
+	//add the recipient
+	pdf.SetFont(pdfFontFamily, "B", 11)
+	pdf.CellFormat(pdfContentWidth, pdfLineHeight, "Bill To", "B", 1, "L", false, 0, "")
+	pdf.SetFont(pdfFontFamily, "", 10)
+	for _, line := range []string{payment.Name, payment.Email, payment.Phone} {
+		if line != "" {
+			pdf.CellFormat(pdfContentWidth, pdfLineHeight, tr(line), "", 1, "L", false, 0, "")
+		}
+	}
+	pdf.SetY(pdf.GetY() + pdfLineHeight)
+
+	//add the items, translating the amounts for currency symbols outside of ascii
+	currency := payment.GetCurrency()
+	colWidths := []float64{pdfContentWidth - 90, 20, 35, 35}
+	pdf.SetFont(pdfFontFamily, "B", 10)
+	pdf.SetFillColor(238, 238, 237)
+	pdf.CellFormat(colWidths[0], pdfLineHeight+1, "Item", "", 0, "L", true, 0, "")
+	pdf.CellFormat(colWidths[1], pdfLineHeight+1, "Qty", "", 0, "R", true, 0, "")
+	pdf.CellFormat(colWidths[2], pdfLineHeight+1, "Price", "", 0, "R", true, 0, "")
+	pdf.CellFormat(colWidths[3], pdfLineHeight+1, "Amount", "", 1, "R", true, 0, "")
+	pdf.SetFont(pdfFontFamily, "", 10)
+	if payment.HasItems() {
+		for _, item := range payment.Items {
+			if !item.Type.IsSubTotal() {
+				continue
+			}
+			pdf.CellFormat(colWidths[0], pdfLineHeight, tr(fmt.Sprintf("%s (%s)", item.Description, item.Type)), "B", 0, "L", false, 0, "")
+			pdf.CellFormat(colWidths[1], pdfLineHeight, item.FormatQuantity(), "B", 0, "R", false, 0, "")
+			pdf.CellFormat(colWidths[2], pdfLineHeight, tr(item.FormatPrice(currency)), "B", 0, "R", false, 0, "")
+			pdf.CellFormat(colWidths[3], pdfLineHeight, tr(item.FormatAmount(currency)), "B", 1, "R", false, 0, "")
+		}
+		pdf.CellFormat(pdfContentWidth-colWidths[3], pdfLineHeight, "Subtotal", "", 0, "R", false, 0, "")
+		pdf.CellFormat(colWidths[3], pdfLineHeight, tr(payment.FormatAmountSubTotal()), "", 1, "R", false, 0, "")
+		for _, item := range payment.Items {
+			if item.Type.IsSubTotal() {
+				continue
+			}
+			pdf.CellFormat(pdfContentWidth-colWidths[3], pdfLineHeight, tr(item.Description), "", 0, "R", false, 0, "")
+			pdf.CellFormat(colWidths[3], pdfLineHeight, tr(item.FormatAmount(currency)), "", 1, "R", false, 0, "")
+		}
+	} else {
+		pdf.CellFormat(colWidths[0], pdfLineHeight, tr(payment.Description), "B", 0, "L", false, 0, "")
+		pdf.CellFormat(colWidths[1], pdfLineHeight, "1", "B", 0, "R", false, 0, "")
+		pdf.CellFormat(colWidths[2], pdfLineHeight, tr(payment.FormatAmount()), "B", 0, "R", false, 0, "")
+		pdf.CellFormat(colWidths[3], pdfLineHeight, tr(payment.FormatAmount()), "B", 1, "R", false, 0, "")
+	}
+	pdf.SetFont(pdfFontFamily, "B", 11)
+	pdf.CellFormat(pdfContentWidth-colWidths[3], pdfLineHeight+1, "Total", "", 0, "R", false, 0, "")
+	pdf.CellFormat(colWidths[3], pdfLineHeight+1, tr(payment.FormatAmount()), "", 1, "R", false, 0, "")
+
+	//add any refunds
+	if payment.IsRefunded() {
+		pdf.SetFont(pdfFontFamily, "", 10)
+		for _, refund := range payment.Refunds {
+			pdf.CellFormat(pdfContentWidth-colWidths[3], pdfLineHeight, tr(fmt.Sprintf("Refunded %s", refund.FormatCreated(timeZone))), "", 0, "R", false, 0, "")
+			pdf.CellFormat(colWidths[3], pdfLineHeight, tr(fmt.Sprintf("-%s", refund.FormatAmount(currency))), "", 1, "R", false, 0, "")
+		}
+		pdf.SetFont(pdfFontFamily, "B", 11)
+		pdf.CellFormat(pdfContentWidth-colWidths[3], pdfLineHeight+1, "Net Paid", "", 0, "R", false, 0, "")
+		pdf.CellFormat(colWidths[3], pdfLineHeight+1, tr(payment.FormatAmountNet()), "", 1, "R", false, 0, "")
+	}
+	pdf.SetY(pdf.GetY() + pdfLineHeight)
+
+	//add the description and note
+	pdf.SetFont(pdfFontFamily, "", 10)
+	if payment.HasItems() && payment.Description != "" {
+		pdf.MultiCell(pdfContentWidth, pdfLineHeight, tr(payment.Description), "", "L", false)
+	}
+	if payment.Note != "" {
+		pdf.SetFont(pdfFontFamily, "B", 11)
+		pdf.CellFormat(pdfContentWidth, pdfLineHeight, "Note", "B", 1, "L", false, 0, "")
+		pdf.SetFont(pdfFontFamily, "", 10)
+		pdf.MultiCell(pdfContentWidth, pdfLineHeight, tr(payment.Note), "", "L", false)
+	}
+
+	//add the status
+	pdf.SetY(pdf.GetY() + pdfLineHeight)
+	pdf.SetFont(pdfFontFamily, "B", 12)
+	status := "Due on Receipt"
+	if payment.IsCaptured() {
+		status = fmt.Sprintf("Paid on %s", payment.FormatCaptured(timeZone))
+	} else if payment.IsPaid() {
+		status = fmt.Sprintf("Pending, paid on %s", payment.FormatPaid(timeZone))
+	}
+	pdf.CellFormat(pdfContentWidth, pdfLineHeight, tr(status), "", 1, "C", false, 0, "")
+
+	//generate the pdf
+	var buffer bytes.Buffer
+	err := pdf.Output(&buffer)
+	if err != nil {
+		return nil, errors.Wrap(err, "pdf output")
+	}
+	fileName := fmt.Sprintf("%s-%s.pdf", strings.ToLower(title), payment.FriendlyID)
+	return &PaymentPDF{
+		FileName: fileName,
+		Data:     buffer.Bytes(),
+	}, nil
+}
+
+//load the data for an uploaded image
+func (s *Server) loadImgData(ctx context.Context, img *Img) (context.Context, []byte, error) {
+	//download from s3
+	if GetAWSS3Enable() {
+		file := img.FileResized
+		if file == "" {
+			file = img.FileSrc
+		}
+		ctx, reader, err := s.awsSession.DownloadS3(ctx, path.Join(URLAssetUpload, img.Path), file)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, fmt.Sprintf("download image: %s", img.GetFile()))
+		}
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, fmt.Sprintf("read image: %s", img.GetFile()))
+		}
+		return ctx, data, nil
+	}
+
+	//load the file from the local system
+	localFile := path.Join(UploadAssetPathLocal, img.GetFile())
+	data, err := ioutil.ReadFile(localFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ctx, nil, nil
+		}
+		return ctx, nil, errors.Wrap(err, fmt.Sprintf("read image: %s", localFile))
+	}
+	return ctx, data, nil
+}
+
+//create a pdf invoice or receipt for a payment, using the provider branding
+func (s *Server) createPaymentPDF(ctx context.Context, provider *providerUI, payment *paymentUI) (context.Context, *PaymentPDF, error) {
+	ctx, logger := GetLogger(ctx)
+
+	//load the logo, ignoring any errors to still generate the pdf
+	var logo []byte
+	if provider.ImgLogo != nil {
+		var err error
+		ctx, logo, err = s.loadImgData(ctx, provider.ImgLogo)
+		if err != nil {
+			logger.Warnw("load logo", "error", err, "id", provider.ID)
+		}
+	}
+	paymentPDF, err := CreatePaymentPDF(payment.Payment, provider.Name, logo, provider.User.TimeZone)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "create payment pdf")
+	}
+	return ctx, paymentPDF, nil
+}
@@ -7,6 +7,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,149 +21,339 @@ const (
 	ProviderDefaultScheduleDuration = 480
 	ProviderDefaultScheduleStart    = "9:00 AM"
 	ProviderLengthRandomURLName     = 6
+	ProviderScheduleExceptionDays   = 365
 )
 
-//ProviderSchedule : working schedule for a provider
+//ScheduleException : an exception to the working schedule for a date, such as time off or a holiday
+type ScheduleException struct {
+	ID            *uuid.UUID      `json:"ID"`
+	Date          time.Time       `json:"Date"`
+	Annual        bool            `json:"Annual"`
+	Closed        bool            `json:"Closed"`
+	Name          string          `json:"Name"`
+	TimeDurations []*TimeDuration `json:"TimeDurations"`
+}
+
+//IsMatch : check if the exception applies to the date of the given time
+func (s *ScheduleException) IsMatch(t time.Time) bool {
+	y, m, d := t.Date()
+	if s.Annual {
+		return m == s.Date.Month() && d == s.Date.Day()
+	}
+	return y == s.Date.Year() && m == s.Date.Month() && d == s.Date.Day()
+}
+
+//FormatDate : format the date of the exception
+func (s *ScheduleException) FormatDate() string {
+	if s.Annual {
+		return s.Date.Format(layoutDayMonth)
+	}
+	return FormatDateUTC(s.Date)
+}
+
+//FormatHours : format the working hours of the exception
+func (s *ScheduleException) FormatHours() string {
+	if s.Closed {
+		return "Closed"
+	}
+	hours := make([]string, len(s.TimeDurations))
+	for idx, timeDuration := range s.TimeDurations {
+		hours[idx] = timeDuration.FormatTimePeriod()
+	}
+	return strings.Join(hours, ", ")
+}
+
+//ProviderSchedule : working schedule for a provider, defined as local wall-clock times in the timezone
 type ProviderSchedule struct {
+	ID           *uuid.UUID                    `json:"ID,omitempty"`
+	Name         string                        `json:"Name,omitempty"`
+	DateStart    time.Time                     `json:"DateStart,omitempty"`
+	DateEnd      time.Time                     `json:"DateEnd,omitempty"`
+	TimeZone     string                        `json:"TimeZone"`
 	DaySchedules map[time.Weekday]*DaySchedule `json:"DaySchedules"`
+	Exceptions   []*ScheduleException          `json:"Exceptions"`
+	Seasons      []*ProviderSchedule           `json:"Seasons,omitempty"`
 }
 
-//IsUnavailable : check if a day of the week is unavailable
-func (p *ProviderSchedule) IsUnavailable(dayOfWeek time.Weekday) bool {
-	schedule, ok := p.DaySchedules[dayOfWeek]
-	if !ok {
-		return true
+//IsEffective : check if a seasonal schedule is effective on the date of the given time
+func (p *ProviderSchedule) IsEffective(t time.Time) bool {
+	y, m, d := t.Date()
+	date := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return !date.Before(p.DateStart) && !date.After(p.DateEnd)
+}
+
+//IsOverlap : check if the date range of a seasonal schedule overlaps the date range
+func (p *ProviderSchedule) IsOverlap(dateStart time.Time, dateEnd time.Time) bool {
+	return !p.DateStart.After(dateEnd) && !p.DateEnd.Before(dateStart)
+}
+
+//FormatDates : format the date range of a seasonal schedule
+func (p *ProviderSchedule) FormatDates() string {
+	return fmt.Sprintf("%s - %s", FormatDateUTC(p.DateStart), FormatDateUTC(p.DateEnd))
+}
+
+//ListDaysFormatted : list the working hours for each day of the week, starting on monday
+func (p *ProviderSchedule) ListDaysFormatted() []string {
+	days := make([]string, 0, 7)
+	for i := 1; i <= 7; i++ {
+		dayOfWeek := time.Weekday(i % 7)
+		hours := "Unavailable"
+		daySchedule, ok := p.DaySchedules[dayOfWeek]
+		if ok && !daySchedule.Unavailable && len(daySchedule.TimeDurations) > 0 {
+			periods := make([]string, len(daySchedule.TimeDurations))
+			for idx, timeDuration := range daySchedule.TimeDurations {
+				periods[idx] = timeDuration.FormatTimePeriod()
+			}
+			hours = strings.Join(periods, ", ")
+		}
+		days = append(days, fmt.Sprintf("%s: %s", dayOfWeek.String()[0:3], hours))
+	}
+	return days
+}
+
+//FindSeason : find the seasonal schedule that is effective on the date of the given time
+func (p *ProviderSchedule) FindSeason(t time.Time) *ProviderSchedule {
+	for _, season := range p.Seasons {
+		if season.IsEffective(t) {
+			return season
+		}
+	}
+	return nil
+}
+
+//GetSeason : get a seasonal schedule
+func (p *ProviderSchedule) GetSeason(id *uuid.UUID) *ProviderSchedule {
+	for _, season := range p.Seasons {
+		if season.ID.String() == id.String() {
+			return season
+		}
 	}
-	return len(schedule.TimePeriods) == 0
+	return nil
 }
 
-//process a day's schedule, returning any time period that overflows into the next day
-func (p *ProviderSchedule) processDaySchedule(now time.Time, timeZone string, dayOfWeek time.Weekday, overflowPeriodIn *TimePeriod) (*TimePeriod, bool) {
-	daySchedule := p.DaySchedules[dayOfWeek]
+//AddSeason : add a seasonal schedule, returning false if the date range overlaps an existing seasonal schedule
+func (p *ProviderSchedule) AddSeason(season *ProviderSchedule) bool {
+	for _, existing := range p.Seasons {
+		if existing.IsOverlap(season.DateStart, season.DateEnd) {
+			return false
+		}
+	}
+	seasons := append(p.Seasons, season)
 
-	//generate the time periods in the given day
-	timePeriods := make([]*TimePeriod, 0, len(daySchedule.TimeDurations))
+	//sort by the start date
+	sort.SliceStable(seasons, func(i int, j int) bool {
+		return seasons[i].DateStart.Before(seasons[j].DateStart)
+	})
+	p.Seasons = seasons
+	return true
+}
 
-	//add the existing overflow period
-	if overflowPeriodIn != nil {
-		start, end := AdjTimes(now, overflowPeriodIn.Start, overflowPeriodIn.End)
-		overflowPeriodIn = &TimePeriod{
-			Start: start.UTC(),
-			End:   end.UTC(),
+//DeleteSeason : delete a seasonal schedule, returning if the seasonal schedule was found
+func (p *ProviderSchedule) DeleteSeason(id *uuid.UUID) bool {
+	for idx, season := range p.Seasons {
+		if season.ID.String() == id.String() {
+			p.Seasons = append(p.Seasons[:idx], p.Seasons[idx+1:]...)
+			return true
 		}
-		timePeriods = append(timePeriods, overflowPeriodIn)
 	}
+	return false
+}
 
-	//process the periods
-	var overflowPeriod *TimePeriod
-	if !daySchedule.Unavailable {
-		prevPeriod := overflowPeriodIn
+//Migrate : migrate a legacy schedule stored as utc times to local times in the timezone
+func (p *ProviderSchedule) Migrate(timeZone string) {
+	if p.TimeZone != "" {
+		return
+	}
+	loc := GetLocation(timeZone)
+	for _, daySchedule := range p.DaySchedules {
 		for _, timeDuration := range daySchedule.TimeDurations {
-			start := timeDuration.Start
-			end := timeDuration.GetEnd()
-			start, end = AdjTimes(now, start, end)
+			timeDuration.migrate(loc)
+		}
+	}
+	for _, exception := range p.Exceptions {
+		for _, timeDuration := range exception.TimeDurations {
+			timeDuration.migrate(loc)
+		}
+	}
+	p.TimeZone = timeZone
+}
 
-			//sanity check overlaps
-			if prevPeriod != nil && prevPeriod.IsOverlap(start, end) {
-				return nil, false
-			}
+//FindException : find the exception for the date of the given time, favoring a specific date over an annual one
+func (p *ProviderSchedule) FindException(t time.Time) *ScheduleException {
+	var annual *ScheduleException
+	for _, exception := range p.Exceptions {
+		if !exception.IsMatch(t) {
+			continue
+		}
+		if !exception.Annual {
+			return exception
+		}
+		annual = exception
+	}
+	return annual
+}
 
-			//add the time period, splitting if the period crosses into the next day
-			var timePeriod *TimePeriod
-			if end.Weekday() != now.Weekday() {
-				timePeriod = &TimePeriod{
-					Start: start.UTC(),
-					End:   GetEndOfDay(start).UTC(),
-				}
+//AddException : add an exception, replacing any existing exception for the same date
+func (p *ProviderSchedule) AddException(exception *ScheduleException) {
+	exceptions := make([]*ScheduleException, 0, len(p.Exceptions)+1)
+	for _, existing := range p.Exceptions {
+		if existing.Annual == exception.Annual && existing.IsMatch(exception.Date) {
+			continue
+		}
+		exceptions = append(exceptions, existing)
+	}
+	exceptions = append(exceptions, exception)
 
-				//sanity check that only one overflow exists
-				if overflowPeriod != nil {
-					return nil, false
-				}
-				overflowPeriod = &TimePeriod{
-					Start: GetBeginningOfDay(end).UTC(),
-					End:   end.UTC(),
-				}
+	//sort by the date, listing the annual exceptions first
+	sort.SliceStable(exceptions, func(i int, j int) bool {
+		if exceptions[i].Annual != exceptions[j].Annual {
+			return exceptions[i].Annual
+		}
+		if exceptions[i].Annual {
+			return exceptions[i].Date.YearDay() < exceptions[j].Date.YearDay()
+		}
+		return exceptions[i].Date.Before(exceptions[j].Date)
+	})
+	p.Exceptions = exceptions
+}
 
-				//ignore any period that has no duration, which can happen if a period end on midnight
-				if overflowPeriod.Start.Equal(overflowPeriod.End) {
-					overflowPeriod = nil
-				}
-			} else {
-				timePeriod = &TimePeriod{
-					Start: start.UTC(),
-					End:   end.UTC(),
-				}
-			}
-			timePeriods = append(timePeriods, timePeriod)
-			prevPeriod = timePeriod
+//DeleteException : delete an exception, returning if the exception was found
+func (p *ProviderSchedule) DeleteException(id *uuid.UUID) bool {
+	for idx, exception := range p.Exceptions {
+		if exception.ID.String() == id.String() {
+			p.Exceptions = append(p.Exceptions[:idx], p.Exceptions[idx+1:]...)
+			return true
 		}
 	}
-	daySchedule.TimePeriods = timePeriods
-	return overflowPeriod, true
+	return false
 }
 
-//Process : process the schedule and generate the time periods across the various dates
-func (p *ProviderSchedule) Process(now time.Time, timeZone string) []string {
-	daysOfWeek := make([]string, 0, 7)
-	overflowPeriod, ok := p.processDaySchedule(now, timeZone, time.Monday, nil)
-	if !ok {
-		daysOfWeek = append(daysOfWeek, time.Monday.String())
+//ListExceptionsUpcoming : list the exceptions that are annual or on or after the given date
+func (p *ProviderSchedule) ListExceptionsUpcoming(t time.Time) []*ScheduleException {
+	y, m, d := t.Date()
+	date := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	exceptions := make([]*ScheduleException, 0, len(p.Exceptions))
+	for _, exception := range p.Exceptions {
+		if exception.Annual || !exception.Date.Before(date) {
+			exceptions = append(exceptions, exception)
+		}
 	}
-	overflowPeriod, ok = p.processDaySchedule(now, timeZone, time.Tuesday, overflowPeriod)
-	if !ok {
-		daysOfWeek = append(daysOfWeek, time.Tuesday.String())
+	return exceptions
+}
+
+//IsUnavailable : check if a day of the week is unavailable
+func (p *ProviderSchedule) IsUnavailable(dayOfWeek time.Weekday) bool {
+	schedule, ok := p.DaySchedules[dayOfWeek]
+	if ok && !schedule.Unavailable && len(schedule.TimeDurations) > 0 {
+		return false
 	}
-	overflowPeriod, ok = p.processDaySchedule(now, timeZone, time.Wednesday, overflowPeriod)
-	if !ok {
-		daysOfWeek = append(daysOfWeek, time.Wednesday.String())
+
+	//check for a period from the previous day that extends into the day
+	schedule, ok = p.DaySchedules[(dayOfWeek+6)%7]
+	if ok && !schedule.Unavailable {
+		for _, timeDuration := range schedule.TimeDurations {
+			if timeDuration.GetEnd() > minutesDay {
+				return false
+			}
+		}
 	}
-	overflowPeriod, ok = p.processDaySchedule(now, timeZone, time.Thursday, overflowPeriod)
-	if !ok {
-		daysOfWeek = append(daysOfWeek, time.Thursday.String())
+	return true
+}
+
+//get the time durations for a local date, accounting for any exception or seasonal schedule for the date
+func (p *ProviderSchedule) getTimeDurations(date time.Time) []*TimeDuration {
+	exception := p.FindException(date)
+	if exception != nil {
+		if exception.Closed {
+			return nil
+		}
+		return exception.TimeDurations
 	}
-	overflowPeriod, ok = p.processDaySchedule(now, timeZone, time.Friday, overflowPeriod)
-	if !ok {
-		daysOfWeek = append(daysOfWeek, time.Friday.String())
+	daySchedules := p.DaySchedules
+	season := p.FindSeason(date)
+	if season != nil {
+		daySchedules = season.DaySchedules
 	}
-	overflowPeriod, ok = p.processDaySchedule(now, timeZone, time.Saturday, overflowPeriod)
-	if !ok {
-		daysOfWeek = append(daysOfWeek, time.Saturday.String())
+	daySchedule, ok := daySchedules[date.Weekday()]
+	if !ok || daySchedule.Unavailable {
+		return nil
 	}
-	overflowPeriod, ok = p.processDaySchedule(now, timeZone, time.Sunday, overflowPeriod)
-	if !ok {
-		daysOfWeek = append(daysOfWeek, time.Sunday.String())
+	return daySchedule.TimeDurations
+}
+
+//IsDateUnavailable : check if a local date is unavailable, accounting for any exception or seasonal schedule for the date
+func (p *ProviderSchedule) IsDateUnavailable(date time.Time) bool {
+	if len(p.getTimeDurations(date)) > 0 {
+		return false
 	}
 
-	//add the overflow to monday if necessary
-	if overflowPeriod != nil {
-		_, ok := p.processDaySchedule(now, timeZone, time.Monday, overflowPeriod)
-		if !ok {
-			daysOfWeek = append(daysOfWeek, time.Monday.String())
+	//check for a period from the previous date that extends into the date
+	for _, timeDuration := range p.getTimeDurations(date.AddDate(0, 0, -1)) {
+		if timeDuration.GetEnd() > minutesDay {
+			return false
 		}
 	}
-	return daysOfWeek
+	return true
+}
+
+//ListTimePeriods : list the working periods that touch the span, resolving the local times on each date
+func (p *ProviderSchedule) ListTimePeriods(start time.Time, end time.Time) []*TimePeriod {
+	//start on the previous date to include any period that crosses midnight
+	loc := GetLocation(p.TimeZone)
+	date := GetBeginningOfDay(start.In(loc)).AddDate(0, 0, -1)
+	timePeriods := make([]*TimePeriod, 0, 2)
+	for !date.After(end) {
+		for _, timeDuration := range p.getTimeDurations(date) {
+			timePeriod := timeDuration.Resolve(date)
+			if timePeriod.Start.After(end) || timePeriod.End.Before(start) {
+				continue
+			}
+			timePeriods = append(timePeriods, timePeriod)
+		}
+		date = date.AddDate(0, 0, 1)
+	}
+	return timePeriods
 }
 
-//Adjust : adjust the schedule based on a change of timezones
-func (p *ProviderSchedule) Adjust(now time.Time, timeZoneSrc string, timeZoneDst string) {
-	//compute the adjustment for the times based on the timezones
-	locSrc := GetLocation(timeZoneSrc)
-	_, offsetSrc := now.In(locSrc).Zone()
-	locDst := GetLocation(timeZoneDst)
-	_, offsetDst := now.In(locDst).Zone()
-	offset := time.Duration(offsetSrc-offsetDst) * time.Second
+//Validate : validate the schedule, returning the days of the week with overlapping periods
+func (p *ProviderSchedule) Validate() []string {
+	daysOfWeek := make([]string, 0, 7)
+	for _, dayOfWeek := range WeekDays {
+		daySchedule, ok := p.DaySchedules[dayOfWeek]
+		if !ok || daySchedule.Unavailable {
+			continue
+		}
 
-	//process the schedule
-	for _, daySchedule := range p.DaySchedules {
+		//collect the periods in minutes, including any period from the previous day that extends into the day
+		type period struct {
+			start TimeOfDay
+			end   TimeOfDay
+		}
+		periods := make([]period, 0, len(daySchedule.TimeDurations)+1)
+		prevSchedule, ok := p.DaySchedules[(dayOfWeek+6)%7]
+		if ok && !prevSchedule.Unavailable {
+			for _, timeDuration := range prevSchedule.TimeDurations {
+				if timeDuration.GetEnd() > minutesDay {
+					periods = append(periods, period{0, timeDuration.GetEnd() - minutesDay})
+				}
+			}
+		}
 		for _, timeDuration := range daySchedule.TimeDurations {
-			timeDuration.Start = timeDuration.Start.Add(offset)
+			periods = append(periods, period{timeDuration.Start, timeDuration.GetEnd()})
 		}
-		for _, timePeriod := range daySchedule.TimePeriods {
-			timePeriod.Start = timePeriod.Start.Add(offset)
-			timePeriod.End = timePeriod.End.Add(offset)
+
+		//check for overlaps
+		sort.SliceStable(periods, func(i int, j int) bool {
+			return periods[i].start < periods[j].start
+		})
+		for idx := 1; idx < len(periods); idx++ {
+			if periods[idx].start < periods[idx-1].end {
+				daysOfWeek = append(daysOfWeek, dayOfWeek.String())
+				break
+			}
 		}
 	}
+	return daysOfWeek
 }
 
 //Provider : provider definition
@@ -199,10 +391,20 @@ type Provider struct {
 	ProviderUser *ProviderUser `json:"-"`
 
 	//payment methods
+	Currency    Currency     `json:"Currency"`
 	PayPalEmail *string      `json:"PayPalEmail"`
 	StripeToken *TokenStripe `json:"StripeToken"`
 	ZelleID     *string      `json:"ZelleID"`
 
+	//sales tax
+	TaxRates []*TaxRate `json:"TaxRates"`
+
+	//days after an invoice when unpaid payment reminders are sent
+	PaymentReminderDays []int `json:"PaymentReminderDays"`
+
+	//tip percentages offered to clients when paying an invoice
+	TipPercents []int `json:"TipPercents"`
+
 	//google
 	GoogleTrackingID     *string         `json:"GoogleTrackingId"`
 	GoogleCalendarID     *string         `json:"-"`
@@ -274,14 +476,28 @@ func (p *Provider) GetZoomToken() *TokenZoom {
 	return nil
 }
 
-//GetSchedule : get the provider schedule
+//GetSchedule : get the provider schedule, migrating a legacy schedule based on the timezone of the provider
 func (p *Provider) GetSchedule() *ProviderSchedule {
-	if p.IsAdmin() {
-		return p.Schedule
+	schedule := p.Schedule
+	if !p.IsAdmin() {
+		//use the provider user schedule
+		schedule = p.ProviderUser.Schedule
 	}
+	p.migrateSchedules()
+	return schedule
+}
 
-	//use the provider user schedule
-	return p.ProviderUser.Schedule
+//migrate legacy schedules stored as utc times to local times in the timezone of the provider
+func (p *Provider) migrateSchedules() {
+	if p.User == nil {
+		return
+	}
+	if p.Schedule != nil {
+		p.Schedule.Migrate(p.User.TimeZone)
+	}
+	if p.ProviderUser != nil && p.ProviderUser.Schedule != nil {
+		p.ProviderUser.Schedule.Migrate(p.User.TimeZone)
+	}
 }
 
 //SetSchedule : set the provider schedule
@@ -295,11 +511,151 @@ func (p *Provider) SetSchedule(schedule *ProviderSchedule) {
 	p.ProviderUser.Schedule = schedule
 }
 
+//GetCurrency : get the currency used for prices and payments, defaulting for providers created before the currency was set
+func (p *Provider) GetCurrency() Currency {
+	if p.Currency == "" {
+		return CurrencyDefault
+	}
+	return p.Currency
+}
+
 //SupportsPayment : check if payments are supported
 func (p *Provider) SupportsPayment() bool {
 	return p.StripeToken != nil || p.PayPalEmail != nil || p.ZelleID != nil
 }
 
+//SupportsPaymentOnline : check if online payments are supported
+func (p *Provider) SupportsPaymentOnline() bool {
+	return p.StripeToken != nil || p.PayPalEmail != nil
+}
+
+//HasPaymentReminders : check if payment reminders are sent for unpaid invoices
+func (p *Provider) HasPaymentReminders() bool {
+	return len(p.PaymentReminderDays) > 0
+}
+
+//FormatPaymentReminderDays : format the days when payment reminders are sent
+func (p *Provider) FormatPaymentReminderDays() string {
+	days := make([]string, len(p.PaymentReminderDays))
+	for i, day := range p.PaymentReminderDays {
+		days[i] = strconv.Itoa(day)
+	}
+	return strings.Join(days, ", ")
+}
+
+//ParsePaymentReminderDays : parse a comma-separated list of days, returning them sorted without duplicates
+func ParsePaymentReminderDays(in string) ([]int, error) {
+	days, err := parseIntList(in)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse days")
+	}
+	return days, nil
+}
+
+//HasTips : check if clients are offered a tip when paying an invoice
+func (p *Provider) HasTips() bool {
+	return len(p.TipPercents) > 0
+}
+
+//FormatTipPercents : format the tip percentages offered to clients
+func (p *Provider) FormatTipPercents() string {
+	percents := make([]string, len(p.TipPercents))
+	for i, percent := range p.TipPercents {
+		percents[i] = strconv.Itoa(percent)
+	}
+	return strings.Join(percents, ", ")
+}
+
+//ParseTipPercents : parse a comma-separated list of tip percentages, returning them sorted without duplicates
+func ParseTipPercents(in string) ([]int, error) {
+	percents, err := parseIntList(in)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse percents")
+	}
+	return percents, nil
+}
+
+//parse a comma-separated list of numbers, returning them sorted without duplicates
+func parseIntList(in string) ([]int, error) {
+	nums := make([]int, 0, 3)
+	for _, numStr := range strings.Split(in, ",") {
+		numStr = strings.TrimSpace(numStr)
+		if numStr == "" {
+			continue
+		}
+		num, err := strconv.Atoi(numStr)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("parse number: %s", numStr))
+		}
+		exists := false
+		for _, existing := range nums {
+			if existing == num {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			nums = append(nums, num)
+		}
+	}
+	sort.Ints(nums)
+	return nums, nil
+}
+
+//AddTaxRate : add a tax rate, replacing any existing rate for the same region
+func (p *Provider) AddTaxRate(taxRate *TaxRate) {
+	taxRates := make([]*TaxRate, 0, len(p.TaxRates)+1)
+	for _, existing := range p.TaxRates {
+		if strings.EqualFold(existing.Region, taxRate.Region) {
+			continue
+		}
+		taxRates = append(taxRates, existing)
+	}
+	taxRates = append(taxRates, taxRate)
+
+	//sort by the region, listing the default rate last
+	sort.SliceStable(taxRates, func(i int, j int) bool {
+		if taxRates[i].IsDefault() != taxRates[j].IsDefault() {
+			return !taxRates[i].IsDefault()
+		}
+		return strings.ToUpper(taxRates[i].Region) < strings.ToUpper(taxRates[j].Region)
+	})
+	p.TaxRates = taxRates
+}
+
+//DeleteTaxRate : delete a tax rate, returning if the rate was found
+func (p *Provider) DeleteTaxRate(id *uuid.UUID) bool {
+	for idx, taxRate := range p.TaxRates {
+		if taxRate.ID.String() == id.String() {
+			p.TaxRates = append(p.TaxRates[:idx], p.TaxRates[idx+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+//FindTaxRate : find the tax rate for a location, preferring a zip code match, then a region match, then the default rate
+func (p *Provider) FindTaxRate(location string) *TaxRate {
+	var match *TaxRate
+	var defaultRate *TaxRate
+	for _, taxRate := range p.TaxRates {
+		if taxRate.IsDefault() {
+			defaultRate = taxRate
+			continue
+		}
+		if !taxRate.IsMatch(location) {
+			continue
+		}
+		if match == nil || (taxRate.IsZipCode() && !match.IsZipCode()) {
+			match = taxRate
+		}
+	}
+	if match != nil {
+		return match
+	}
+	return defaultRate
+}
+
 //IsMappable : check if the location is mappable
 func (p *Provider) IsMappable() bool {
 	if p.Location == "" {
@@ -388,73 +744,75 @@ func (p *Provider) DeleteImgLogo() {
 	p.ImgLogo = nil
 }
 
-//GetBoundaryTimes : the earliest and latest work times for a day of the week
+//GetBoundaryTimes : the earliest and latest work times on the date of the given time, accounting for any exception for the date
 func (p *Provider) GetBoundaryTimes(t time.Time) (time.Time, time.Time) {
 	schedule := p.GetSchedule()
 	if schedule == nil {
 		return time.Time{}, time.Time{}
 	}
-	daySchedule := schedule.DaySchedules[t.Weekday()]
+	dayStart := GetBeginningOfDay(t)
+	dayEnd := dayStart.AddDate(0, 0, 1)
 
-	//walk the durations and find the start and end times
+	//walk the periods and find the start and end times, limited to the day
 	var startFirst time.Time
 	var endLast time.Time
-	for idx, timePeriod := range daySchedule.TimePeriods {
+	for _, timePeriod := range schedule.ListTimePeriods(dayStart, dayEnd) {
+		if !timePeriod.IsOverlap(dayStart, dayEnd) {
+			continue
+		}
 		start := timePeriod.Start
+		if start.Before(dayStart) {
+			start = dayStart
+		}
 		end := timePeriod.End
-		if idx == 0 {
+		if end.After(dayEnd) {
+			end = dayEnd
+		}
+		if startFirst.IsZero() || start.Before(startFirst) {
 			startFirst = start
 		}
-		endLast = end
-	}
-
-	//adjust the times to reflect the current time
-	if !startFirst.IsZero() && !endLast.IsZero() {
-		startFirst, endLast = AdjTimes(t, startFirst, endLast)
+		if end.After(endLast) {
+			endLast = end
+		}
 	}
 	return startFirst, endLast
 }
 
 //IsValidWorkPeriod : check if the time period is valid giving the schedule
-func (p *Provider) IsValidWorkPeriod(ref time.Time, period *TimePeriod) bool {
+func (p *Provider) IsValidWorkPeriod(period *TimePeriod) bool {
 	schedule := p.GetSchedule()
 	if schedule == nil {
 		return true
 	}
-	daySchedule := schedule.DaySchedules[ref.Weekday()]
 
-	//check if the period falls within a valid time duration
-	for _, timeDuration := range daySchedule.TimeDurations {
-		//adjust the times to reflect the current time
-		scheduleStart, scheduleEnd := AdjTimes(ref, timeDuration.Start, timeDuration.GetEnd())
-		if CheckTimeIn(period.Start, scheduleStart, scheduleEnd) && CheckTimeIn(period.End, scheduleStart, scheduleEnd) {
-			return true
-		}
-	}
-
-	//check if the period falls within a valid time period due to an overflow
-	for _, timePeriod := range daySchedule.TimePeriods {
-		//adjust the times to reflect the current time
-		scheduleStart, scheduleEnd := AdjTimes(ref, timePeriod.Start, timePeriod.End)
-		if CheckTimeIn(period.Start, scheduleStart, scheduleEnd) && CheckTimeIn(period.End, scheduleStart, scheduleEnd) {
+	//check if the period falls within a working period
+	for _, timePeriod := range schedule.ListTimePeriods(period.Start, period.End) {
+		if CheckTimeIn(period.Start, timePeriod.Start, timePeriod.End) && CheckTimeIn(period.End, timePeriod.Start, timePeriod.End) {
 			return true
 		}
 	}
 	return false
 }
 
-//GetWorkingMinutes : get number of working minutes available on the given work day
+//GetWorkingMinutes : get number of working minutes available on the date of the given time
 func (p *Provider) GetWorkingMinutes(t time.Time) time.Duration {
-	schedule := p.GetSchedule()
-	if schedule == nil {
+	start, end := p.GetBoundaryTimes(t)
+	if start.IsZero() {
 		return 0
 	}
-	daySchedule := schedule.DaySchedules[t.Weekday()]
 
-	//check if the period falls within a valid time duration
+	//sum the periods, limited to the day
 	totalDuration := time.Duration(0)
-	for _, timePeriod := range daySchedule.TimePeriods {
-		totalDuration += timePeriod.End.Sub(timePeriod.Start)
+	for _, timePeriod := range p.GetSchedule().ListTimePeriods(start, end) {
+		if timePeriod.Start.Before(start) {
+			timePeriod.Start = start
+		}
+		if timePeriod.End.After(end) {
+			timePeriod.End = end
+		}
+		if timePeriod.End.After(timePeriod.Start) {
+			totalDuration += timePeriod.End.Sub(timePeriod.Start)
+		}
 	}
 	return totalDuration
 }
@@ -471,7 +829,7 @@ func (p *Provider) CheckValidTime(now time.Time, start time.Time, end time.Time)
 		Start: start,
 		End:   end,
 	}
-	return p.IsValidWorkPeriod(start, period)
+	return p.IsValidWorkPeriod(period)
 }
 
 //AdjToValidStart : adjust to the next valid start time
@@ -487,7 +845,7 @@ func (p *Provider) AdjToValidStart(d time.Time, interval time.Duration) time.Tim
 			Start: d,
 			End:   d,
 		}
-		if !p.IsValidWorkPeriod(d, period) {
+		if !p.IsValidWorkPeriod(period) {
 			//probe the next interval
 			d = d.Add(interval)
 			continue
@@ -524,6 +882,46 @@ func (p *Provider) ListDaysOfWeekUnavailable() []int {
 	return days
 }
 
+//ListDatesUnavailable : list the dates over the given number of days that are unavailable due to an exception or a seasonal schedule,
+//along with the dates for the days of the week that have to be enabled because of an exception or a seasonal schedule
+func (p *Provider) ListDatesUnavailable(start time.Time, count int) ([]int, []string) {
+	days := p.ListDaysOfWeekUnavailable()
+	schedule := p.GetSchedule()
+	if schedule == nil || (len(schedule.Exceptions) == 0 && len(schedule.Seasons) == 0) {
+		return days, nil
+	}
+	start = GetBeginningOfDay(start)
+
+	//find the unavailable days of the week that are opened by an exception or a seasonal schedule
+	datesUnavailable := make([]bool, count)
+	daysOpen := make(map[time.Weekday]bool, len(days))
+	for i := 0; i < count; i++ {
+		date := start.AddDate(0, 0, i)
+		datesUnavailable[i] = schedule.IsDateUnavailable(date)
+		if !datesUnavailable[i] && p.IsUnavailable(date.Weekday()) {
+			daysOpen[date.Weekday()] = true
+		}
+	}
+	daysUnavailable := make([]int, 0, len(days))
+	daysClosed := make(map[time.Weekday]bool, len(days))
+	for _, day := range days {
+		if !daysOpen[time.Weekday(day)] {
+			daysUnavailable = append(daysUnavailable, day)
+			daysClosed[time.Weekday(day)] = true
+		}
+	}
+
+	//list the individual dates that are unavailable
+	dates := make([]string, 0)
+	for i := 0; i < count; i++ {
+		date := start.AddDate(0, 0, i)
+		if datesUnavailable[i] && !daysClosed[date.Weekday()] {
+			dates = append(dates, date.Format(layoutDate))
+		}
+	}
+	return daysUnavailable, dates
+}
+
 //FormatUserID : get the user id string
 func (p *Provider) FormatUserID() string {
 	if p.User == nil || p.User.ID == nil {
@@ -752,6 +1150,7 @@ func loadProvider(ctx context.Context, db *DB, whereStmt string, args ...interfa
 	provider.User = &user
 	provider.ID = &providerID
 	provider.URLName = urlName
+	provider.migrateSchedules()
 
 	//check for a valid friendly url name
 	if urlNameFriendly.Valid {
@@ -1319,6 +1718,23 @@ func CountProviders(ctx context.Context, db *DB) (context.Context, int, error) {
 	return ctx, count, nil
 }
 
+//CountPricedItemsByProviderID : count the services, coupons, packages, plans, and gift cards priced in the provider currency
+func CountPricedItemsByProviderID(ctx context.Context, db *DB, providerID *uuid.UUID) (context.Context, int, error) {
+	stmt := fmt.Sprintf("SELECT (SELECT COUNT(*) FROM %s WHERE deleted=0 AND provider_id=UUID_TO_BIN(?))+(SELECT COUNT(*) FROM %s WHERE deleted=0 AND provider_id=UUID_TO_BIN(?))+(SELECT COUNT(*) FROM %s WHERE deleted=0 AND provider_id=UUID_TO_BIN(?))+(SELECT COUNT(*) FROM %s WHERE deleted=0 AND provider_id=UUID_TO_BIN(?))+(SELECT COUNT(*) FROM %s WHERE deleted=0 AND provider_id=UUID_TO_BIN(?))", dbTableService, dbTableCoupon, dbTableSessionPackage, dbTableMembershipPlan, dbTableGiftCard)
+	ctx, row, err := db.QueryRow(ctx, stmt, providerID, providerID, providerID, providerID, providerID)
+	if err != nil {
+		return ctx, 0, errors.Wrap(err, "query row priced items count")
+	}
+
+	//read the row
+	var count int
+	err = row.Scan(&count)
+	if err != nil {
+		return ctx, 0, errors.Wrap(err, "select priced items count")
+	}
+	return ctx, count, nil
+}
+
 //FindLatestProvider : find the latest provider create time
 func FindLatestProvider(ctx context.Context, db *DB) (context.Context, *Provider, *time.Time, error) {
 	stmt := fmt.Sprintf("SELECT p.data,p.created FROM %s p INNER JOIN %s u ON u.id=p.user_id AND u.deleted=0 AND u.test=0 WHERE p.deleted=0 ORDER BY p.created DESC LIMIT 1", dbTableProvider, dbTableUser)
@@ -1441,7 +1857,7 @@ func providerUserQueryCreate(whereStmt string, orderStmt string, limit int) stri
 	if orderStmt == "" {
 		orderStmt = "pu.login"
 	}
-	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(pu.id),BIN_TO_UUID(pu.provider_id),pu.login,pu.data,BIN_TO_UUID(u.id),u.login,u.email,u.email_verified,u.disable_emails,u.is_oauth,u.token_zoom_data,u.data FROM %s pu LEFT JOIN %s u ON u.id=pu.user_id AND u.deleted=0 WHERE pu.deleted=0 AND %s ORDER BY %s", dbTableProviderUser, dbTableUser, whereStmt, orderStmt)
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(pu.id),BIN_TO_UUID(pu.provider_id),pu.login,pu.data,pou.data->>'$.TimeZone',BIN_TO_UUID(u.id),u.login,u.email,u.email_verified,u.disable_emails,u.is_oauth,u.token_zoom_data,u.data FROM %s pu INNER JOIN %s p ON p.id=pu.provider_id INNER JOIN %s pou ON pou.id=p.user_id LEFT JOIN %s u ON u.id=pu.user_id AND u.deleted=0 WHERE pu.deleted=0 AND %s ORDER BY %s", dbTableProviderUser, dbTableProvider, dbTableUser, dbTableUser, whereStmt, orderStmt)
 	if limit > 0 {
 		stmt = fmt.Sprintf("%s LIMIT %d", stmt, limit)
 	}
@@ -1455,6 +1871,7 @@ func providerUserQueryParse(rowFn ScanFn) (*ProviderUser, error) {
 	var providerIDStr string
 	var login string
 	var dataStr string
+	var providerTimeZone sql.NullString
 	var userIDStr sql.NullString
 	var userLogin sql.NullString
 	var email sql.NullString
@@ -1463,7 +1880,7 @@ func providerUserQueryParse(rowFn ScanFn) (*ProviderUser, error) {
 	var isOAuthBit sql.NullString
 	var tokenZoomData sql.NullString
 	var userDataStr sql.NullString
-	err := rowFn(&idStr, &providerIDStr, &login, &dataStr, &userIDStr, &userLogin, &email, &emailVerifiedBit, &disableEmailsBit, &isOAuthBit, &tokenZoomData, &userDataStr)
+	err := rowFn(&idStr, &providerIDStr, &login, &dataStr, &providerTimeZone, &userIDStr, &userLogin, &email, &emailVerifiedBit, &disableEmailsBit, &isOAuthBit, &tokenZoomData, &userDataStr)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -1490,6 +1907,11 @@ func providerUserQueryParse(rowFn ScanFn) (*ProviderUser, error) {
 	providerUser.ID = &id
 	providerUser.ProviderID = &providerID
 	providerUser.Login = login
+
+	//migrate a legacy schedule based on the timezone of the provider
+	if providerUser.Schedule != nil && providerTimeZone.Valid {
+		providerUser.Schedule.Migrate(providerTimeZone.String)
+	}
 	if userDataStr.Valid {
 		var user User
 		err = json.Unmarshal([]byte(userDataStr.String), &user)
@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/graham/rrule"
@@ -12,6 +14,18 @@ import (
 //RecurrenceRuleSeparator : separator between recurrence rules
 const RecurrenceRuleSeparator = "|"
 
+//RecurrenceExDatePrefix : prefix for a recurrence exception date, used to skip a single occurrence
+const RecurrenceExDatePrefix = "EXDATE:"
+
+//RecurrenceExDateFormat : format for a recurrence exception date
+const RecurrenceExDateFormat = "20060102T150405Z"
+
+//recurrence constants
+const (
+	RecurrenceIntervalMax = 52  //maximum repeat interval
+	RecurrenceCountMax    = 365 //maximum number of occurrences
+)
+
 //RecurrenceInterval : recurrence interval
 type RecurrenceInterval int
 
@@ -24,6 +38,16 @@ const (
 	RecurrenceIntervalMonthly
 )
 
+//RecurrenceScope : scope of a change to a recurring booking
+type RecurrenceScope int
+
+//recurrence scopes
+const (
+	RecurrenceScopeOnce      RecurrenceScope = iota //only the single occurrence
+	RecurrenceScopeFollowing                        //the occurrence and all following occurrences
+	RecurrenceScopeAll                              //all upcoming occurrences in the series
+)
+
 //RecurrenceFreq : recurrence frequency
 type RecurrenceFreq struct {
 	Label    string
@@ -40,6 +64,12 @@ var (
 		Freq:     0,
 		Interval: 1,
 	}
+	RecurrenceFreqDaily = RecurrenceFreq{
+		Label:    "Daily",
+		Value:    RecurrenceIntervalDaily,
+		Freq:     rrule.DAILY,
+		Interval: 1,
+	}
 	RecurrenceFreqWeekly = RecurrenceFreq{
 		Label:    "Weekly",
 		Value:    RecurrenceIntervalWeekly,
@@ -63,8 +93,87 @@ var (
 //RecurrenceFreqs : recurrence frequencies
 var RecurrenceFreqs []RecurrenceFreq = []RecurrenceFreq{
 	RecurrenceFreqOnce,
+	RecurrenceFreqDaily,
 	RecurrenceFreqWeekly,
 	RecurrenceFreqTwoWeeks,
+	RecurrenceFreqMonthly,
+}
+
+//RecurrenceOptions : additional options to customize a recurrence frequency
+type RecurrenceOptions struct {
+	Interval int            `json:"Interval"` //overrides the frequency interval if set
+	Days     []time.Weekday `json:"Days"`     //weekly only
+	Until    *time.Time     `json:"Until"`
+	Count    int            `json:"Count"`
+}
+
+//IsSet : check if any options are set
+func (r *RecurrenceOptions) IsSet() bool {
+	return r.Interval > 0 || len(r.Days) > 0 || r.Until != nil || r.Count > 0
+}
+
+//Format : format the options
+func (r *RecurrenceOptions) Format(freq *RecurrenceFreq, timeZone string) string {
+	parts := make([]string, 0, 3)
+	if r.Interval > 1 && freq != nil {
+		switch freq.Freq {
+		case rrule.DAILY:
+			parts = append(parts, fmt.Sprintf("every %d days", r.Interval))
+		case rrule.WEEKLY:
+			parts = append(parts, fmt.Sprintf("every %d weeks", r.Interval))
+		case rrule.MONTHLY:
+			parts = append(parts, fmt.Sprintf("every %d months", r.Interval))
+		}
+	}
+	if len(r.Days) > 0 && freq != nil && freq.Freq == rrule.WEEKLY {
+		days := make([]string, 0, len(r.Days))
+		for _, day := range r.Days {
+			days = append(days, day.String()[:3])
+		}
+		parts = append(parts, fmt.Sprintf("on %s", strings.Join(days, ", ")))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("%d times", r.Count))
+	} else if r.Until != nil {
+		parts = append(parts, fmt.Sprintf("until %s", FormatDateLocal(*r.Until, timeZone)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+//CreateRecurrenceOptions : create the recurrence options from the input, returning nil if no options are set
+func CreateRecurrenceOptions(intervalStr string, dayStrs []string, untilStr string, countStr string, timeZone string) *RecurrenceOptions {
+	opts := &RecurrenceOptions{}
+	if intervalStr != "" {
+		interval, err := strconv.Atoi(intervalStr)
+		if err == nil {
+			opts.Interval = interval
+		}
+	}
+	for _, dayStr := range dayStrs {
+		day, ok := ParseWeekDay(dayStr)
+		if ok {
+			opts.Days = append(opts.Days, day)
+		}
+	}
+	if countStr != "" {
+		count, err := strconv.Atoi(countStr)
+		if err == nil {
+			opts.Count = count
+		}
+	}
+
+	//use the end of the day for the until date
+	if untilStr != "" && opts.Count == 0 {
+		until := ParseDateLocal(untilStr, timeZone)
+		if !until.IsZero() {
+			until = GetEndOfDay(until).UTC()
+			opts.Until = &until
+		}
+	}
+	if !opts.IsSet() {
+		return nil
+	}
+	return opts
 }
 
 //LoadRecurrenceFreq : load a recurrence frequency by the interval
@@ -108,8 +217,8 @@ func FindRecurrenceRuleByDay(t time.Time) rrule.ForDay {
 	return dayOfWeek
 }
 
-//CreateRecurrenceRule : create a recurrence rule based on the frequency
-func CreateRecurrenceRule(freq *RecurrenceFreq, date time.Time) (string, error) {
+//CreateRecurrenceRule : create a recurrence rule based on the frequency and any additional options
+func CreateRecurrenceRule(freq *RecurrenceFreq, opts *RecurrenceOptions, date time.Time) (string, error) {
 	if freq == nil {
 		return "", nil
 	}
@@ -128,6 +237,28 @@ func CreateRecurrenceRule(freq *RecurrenceFreq, date time.Time) (string, error)
 	if rule.Frequency == rrule.MONTHLY {
 		rule.ByDay = []rrule.ForDay{FindRecurrenceRuleByDay(date)}
 	}
+
+	//apply the options
+	if opts != nil {
+		if opts.Interval > 0 {
+			rule.Interval = opts.Interval
+		}
+
+		//specific days are only supported weekly
+		if rule.Frequency == rrule.WEEKLY && len(opts.Days) > 0 {
+			rule.ByDay = make([]rrule.ForDay, 0, len(opts.Days))
+			for _, day := range opts.Days {
+				rule.ByDay = append(rule.ByDay, rrule.ForDay{
+					Weekday: day,
+				})
+			}
+		}
+		if opts.Count > 0 {
+			rule.Count = opts.Count
+		} else if opts.Until != nil {
+			rule.Until = opts.Until.UTC()
+		}
+	}
 	return rule.RecurString(), nil
 }
 
@@ -145,3 +276,71 @@ func ParseRecurrenceRule(ruleStr string) (*RecurrenceRule, error) {
 	ruleWrapper := &RecurrenceRule{rule}
 	return ruleWrapper, nil
 }
+
+//CreateRecurrenceExDate : create a recurrence exception date to skip the occurrence at the time
+func CreateRecurrenceExDate(t time.Time) string {
+	return RecurrenceExDatePrefix + t.UTC().Format(RecurrenceExDateFormat)
+}
+
+//ParseRecurrenceExDate : parse a recurrence exception date, returning false if the rule is not an exception date
+func ParseRecurrenceExDate(ruleStr string) (time.Time, bool) {
+	if !strings.HasPrefix(ruleStr, RecurrenceExDatePrefix) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(RecurrenceExDateFormat, strings.TrimPrefix(ruleStr, RecurrenceExDatePrefix))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+//AddRecurrenceExDate : add an exception date to the recurrence rules
+func AddRecurrenceExDate(rules []string, t time.Time) []string {
+	exDate := CreateRecurrenceExDate(t)
+	for _, rule := range rules {
+		if rule == exDate {
+			return rules
+		}
+	}
+	return append(rules, exDate)
+}
+
+//TerminateRecurrenceRules : terminate the recurrence rules at the end date, preserving any exception dates
+func TerminateRecurrenceRules(rules []string, endDate time.Time) ([]string, error) {
+	terminatedRules := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		if _, ok := ParseRecurrenceExDate(rule); ok {
+			terminatedRules = append(terminatedRules, rule)
+			continue
+		}
+		parsedRule, err := ParseRecurrenceRule(rule)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("parse recurrence rule: %s", rule))
+		}
+		parsedRule.Count = 0
+		parsedRule.Until = endDate.UTC()
+		terminatedRules = append(terminatedRules, parsedRule.RecurString())
+	}
+	return terminatedRules, nil
+}
+
+//CountRecurrenceRules : count the occurrences of the recurrence rules starting at the start time and before the end time
+func CountRecurrenceRules(rules []string, start time.Time, end time.Time) (int, error) {
+	count := 0
+	for _, rule := range rules {
+		if _, ok := ParseRecurrenceExDate(rule); ok {
+			continue
+		}
+		parsedRule, err := ParseRecurrenceRule(rule)
+		if err != nil {
+			return 0, errors.Wrap(err, fmt.Sprintf("parse recurrence rule: %s", rule))
+		}
+		parsedRule.DtStart = start
+		var ruleTime time.Time
+		ruleIterator := parsedRule.Iterator().Before(end)
+		for ruleIterator.Step(&ruleTime) {
+			count++
+		}
+	}
+	return count, nil
+}
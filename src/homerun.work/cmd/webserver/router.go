@@ -107,7 +107,11 @@ func (s *Server) createRouter(ctx context.Context) *chi.Mux {
 				sr.Get(URICampaigns, s.handleDashboardCampaigns())
 				sr.Get(URICoupons, s.handleDashboardCoupons())
 				sr.Get(URIDefault, s.handleDashboardIndex())
+				sr.Get(URIGiftCardView, s.handleDashboardGiftCardView())
+				sr.Get(URIGiftCards, s.handleDashboardGiftCards())
 				sr.Get(URIIndex, s.handleDashboardIndex())
+				sr.Get(URIMemberships, s.handleDashboardMemberships())
+				sr.Get(URIPackages, s.handleDashboardPackages())
 				sr.Get(URIPayments, s.handleDashboardPayments())
 				sr.Get(URIUsers, s.handleDashboardUsers())
 
@@ -171,6 +175,18 @@ func (s *Server) createRouter(ctx context.Context) *chi.Mux {
 				sr.Get(URIHours, s.handleDashboardHours())
 				sr.Post(URIHours, s.handleDashboardHours())
 
+				sr.Get(URIMembershipAdd, s.handleDashboardMembershipAdd())
+				sr.Post(URIMembershipAdd, s.handleDashboardMembershipAdd())
+
+				sr.Get(URIMembershipEdit, s.handleDashboardMembershipEdit())
+				sr.Post(URIMembershipEdit, s.handleDashboardMembershipEdit())
+
+				sr.Get(URIPackageAdd, s.handleDashboardPackageAdd())
+				sr.Post(URIPackageAdd, s.handleDashboardPackageAdd())
+
+				sr.Get(URIPackageEdit, s.handleDashboardPackageEdit())
+				sr.Post(URIPackageEdit, s.handleDashboardPackageEdit())
+
 				sr.Get(URIPayment, s.handleDashboardPayment())
 				sr.Post(URIPayment, s.handleDashboardPayment())
 
@@ -180,6 +196,8 @@ func (s *Server) createRouter(ctx context.Context) *chi.Mux {
 				sr.Get(URIPaymentView, s.handleDashboardPaymentView())
 				sr.Post(URIPaymentView, s.handleDashboardPaymentView())
 
+				sr.Get(URIPaymentPDF, s.handleDashboardPaymentPDF())
+
 				sr.Get(URIProfile, s.handleDashboardProfile())
 				sr.Post(URIProfile, s.handleDashboardProfile())
 
@@ -198,6 +216,9 @@ func (s *Server) createRouter(ctx context.Context) *chi.Mux {
 				sr.Get(URISvcs, s.handleDashboardServices())
 				sr.Post(URISvcs, s.handleDashboardServices())
 
+				sr.Get(URITaxes, s.handleDashboardTaxes())
+				sr.Post(URITaxes, s.handleDashboardTaxes())
+
 				sr.Get(URITestimonialAdd, s.handleDashboardTestimonialAdd())
 				sr.Post(URITestimonialAdd, s.handleDashboardTestimonialAdd())
 
@@ -207,6 +228,9 @@ func (s *Server) createRouter(ctx context.Context) *chi.Mux {
 				sr.Get(URITestimonials, s.handleDashboardTestimonials())
 				sr.Post(URITestimonials, s.handleDashboardTestimonials())
 
+				sr.Get(URITimeOff, s.handleDashboardTimeOff())
+				sr.Post(URITimeOff, s.handleDashboardTimeOff())
+
 				sr.Get(URIUserAdd, s.handleDashboardUserAdd())
 				sr.Post(URIUserAdd, s.handleDashboardUserAdd())
 
@@ -230,10 +254,17 @@ func (s *Server) createRouter(ctx context.Context) *chi.Mux {
 			r.Get(URIFaq, s.handleClientFaq())
 			r.Get(URIDefault, s.handleClientIndex())
 			r.Get(URIIndex, s.handleClientIndex())
+			r.Get(URIMembership, s.handleClientMembership())
 
 			r.Get(URIContact, s.handleClientContact())
 			r.Post(URIContact, s.handleClientContact())
 
+			r.Get(URIMembershipJoin, s.handleClientMembershipJoin())
+			r.Post(URIMembershipJoin, s.handleClientMembershipJoin())
+
+			r.Get(URIPackageBuy, s.handleClientPackageBuy())
+			r.Post(URIPackageBuy, s.handleClientPackageBuy())
+
 			r.Get(URIPaymentDirect, s.handleClientPaymentDirect())
 			r.Post(URIPaymentDirect, s.handleClientPaymentDirect())
 
@@ -256,6 +287,12 @@ func (s *Server) createRouter(ctx context.Context) *chi.Mux {
 				sr.Get(URIBookingSubmit, s.handleClientBookingSubmit())
 				sr.Post(URIBookingSubmit, s.handleClientBookingSubmit())
 
+				sr.Get(URIWaitlist, s.handleClientWaitlist())
+				sr.Post(URIWaitlist, s.handleClientWaitlist())
+
+				sr.Get(URIWaitlistClaim, s.handleClientWaitlistClaim())
+				sr.Post(URIWaitlistClaim, s.handleClientWaitlistClaim())
+
 				//provider booking routes
 				sr.Route(fmt.Sprintf("%s/{%s}", BaseClientServiceBookURL, URLParams.BookID), func(ssr chi.Router) {
 					ssr.Use(s.bookIDHdlr)
@@ -264,6 +301,11 @@ func (s *Server) createRouter(ctx context.Context) *chi.Mux {
 					ssr.Get(URICancel, s.handleClientBookingCancel())
 					ssr.Post(URICancel, s.handleClientBookingCancel())
 
+					ssr.Get(URIBookingReschedule, s.handleClientBookingReschedule())
+					ssr.Post(URIBookingReschedule, s.handleClientBookingReschedule())
+
+					ssr.Get(URIBookingCard, s.handleClientBookingCard())
+
 					ssr.Get(URIDefault, s.handleClientBookingView())
 					ssr.Post(URIDefault, s.handleClientBookingView())
 
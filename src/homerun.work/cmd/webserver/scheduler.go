@@ -32,7 +32,15 @@ func InitScheduler(ctx context.Context, server *Server) *Scheduler {
 	)
 
 	//add jobs
-	cron := GetCronProcessGoogle()
+	cron := GetCronProcessBookingHolds()
+	if cron != "" {
+		scheduler.Executor.AddFunc(cron, scheduler.ProcessBookingHolds)
+	}
+	cron = GetCronProcessBookingsUnpaid()
+	if cron != "" {
+		scheduler.Executor.AddFunc(cron, scheduler.ProcessBookingsUnpaid)
+	}
+	cron = GetCronProcessGoogle()
 	if cron != "" {
 		scheduler.Executor.AddFunc(cron, scheduler.ProcessGoogle)
 	}
@@ -48,6 +56,10 @@ func InitScheduler(ctx context.Context, server *Server) *Scheduler {
 	if cron != "" {
 		scheduler.Executor.AddFunc(cron, scheduler.ProcessNotifications)
 	}
+	cron = GetCronProcessPaymentReminders()
+	if cron != "" {
+		scheduler.Executor.AddFunc(cron, scheduler.ProcessPaymentReminders)
+	}
 	cron = GetCronProcessRecurringBookings()
 	if cron != "" {
 		scheduler.Executor.AddFunc(cron, scheduler.ProcessRecurringBookings)
@@ -108,6 +120,45 @@ func (s *Scheduler) Stop() {
 	s.server.logger.Infow("stop scheduler", "label", s.label)
 }
 
+//ProcessBookingHolds : release the expired holds on booking times
+func (s *Scheduler) ProcessBookingHolds() {
+	start := time.Now()
+	defer func() {
+		s.server.logger.Debugw("process booking holds", "elapsedMS", FormatElapsedMS(start))
+	}()
+	s.server.stats.AddTime(ServerStatProcessBookingHolds, start)
+	_, count, err := DeleteBookingHoldsExpired(s.ctx, s.server.getDB(), start)
+	if err != nil {
+		s.server.logger.Errorw("delete booking holds expired", "error", err)
+		return
+	}
+	s.server.logger.Debugw("delete booking holds expired", "count", count)
+}
+
+//ProcessBookingsUnpaid : cancel the bookings for which the payment required to book was not made in time
+func (s *Scheduler) ProcessBookingsUnpaid() {
+	start := time.Now()
+	defer func() {
+		s.server.logger.Debugw("process bookings unpaid", "elapsedMS", FormatElapsedMS(start))
+	}()
+	s.server.stats.AddTime(ServerStatProcessBookingsUnpaid, start)
+
+	//list the bookings to process
+	ctx, books, err := ListBookingsPaymentExpired(s.ctx, s.server.getDB(), start)
+	if err != nil {
+		s.server.logger.Errorw("list bookings payment expired", "error", err)
+		return
+	}
+
+	//cancel the bookings
+	for _, book := range books {
+		ctx, err = s.server.cancelBookingPaymentExpired(ctx, book, start)
+		if err != nil {
+			s.server.logger.Errorw("cancel booking payment expired", "error", err, "id", book.ID)
+		}
+	}
+}
+
 //ProcessGoogle : process Google calendars and events
 func (s *Scheduler) ProcessGoogle() {
 	start := time.Now()
@@ -356,7 +407,9 @@ func (s *Scheduler) sendMsg(ctx context.Context, msg *Message) (context.Context,
 	var client *Client
 	var paymentUI *paymentUI
 	var providerUI *providerUI
+	var svcUI *serviceUI
 	var user *User
+	var waitlist *Waitlist
 	switch msg.Type {
 	//booking-related
 	case MsgTypeBookingCancelClient:
@@ -367,6 +420,8 @@ func (s *Scheduler) sendMsg(ctx context.Context, msg *Message) (context.Context,
 		fallthrough
 	case MsgTypeBookingEditClient:
 		fallthrough
+	case MsgTypeBookingEditProvider:
+		fallthrough
 	case MsgTypeBookingNewClient:
 		fallthrough
 	case MsgTypeBookingNewProvider:
@@ -414,6 +469,10 @@ func (s *Scheduler) sendMsg(ctx context.Context, msg *Message) (context.Context,
 	case MsgTypePaymentClient:
 		fallthrough
 	case MsgTypePaymentProvider:
+		fallthrough
+	case MsgTypePaymentReminderClient:
+		fallthrough
+	case MsgTypeRefundClient:
 		var payment *Payment
 		ctx, payment, err = LoadPaymentByID(ctx, db, msg.SecondaryID)
 		if err != nil {
@@ -468,6 +527,25 @@ func (s *Scheduler) sendMsg(ctx context.Context, msg *Message) (context.Context,
 		}
 		providerUI = s.server.createProviderUI(provider)
 
+	//waitlist-related
+	case MsgTypeWaitlistOfferClient:
+		ctx, waitlist, err = LoadWaitlistByID(ctx, db, msg.SecondaryID)
+		if err != nil {
+			return ctx, errors.Wrap(err, fmt.Sprintf("load waitlist: %s", msg.SecondaryID))
+		}
+		var provider *Provider
+		ctx, provider, err = LoadProviderByID(ctx, db, waitlist.ProviderID)
+		if err != nil {
+			return ctx, errors.Wrap(err, fmt.Sprintf("load provider: %s", waitlist.ProviderID))
+		}
+		providerUI = s.server.createProviderUI(provider)
+		var svc *Service
+		ctx, svc, err = LoadServiceByProviderIDAndID(ctx, db, provider.ID, waitlist.ServiceID)
+		if err != nil {
+			return ctx, errors.Wrap(err, fmt.Sprintf("load service: %s", waitlist.ServiceID))
+		}
+		svcUI = s.server.createServiceUI(providerUI, svc)
+
 	case MsgTypePwdReset:
 	default:
 		return ctx, fmt.Errorf("invalid message type: %s", msg.Type)
@@ -539,6 +617,21 @@ func (s *Scheduler) sendMsg(ctx context.Context, msg *Message) (context.Context,
 			}
 			bodyText = GetSMSText(MsgTypeBookingEditClient, urlShort.URL)
 		}
+	case MsgTypeBookingEditProvider:
+		ctx, subject, bodyHTML, err = s.server.createEmailBookingEditProvider(ctx, bookUI)
+		if err != nil {
+			return ctx, errors.Wrap(err, fmt.Sprintf("create booking email edit provider: %s", msg.ID))
+		}
+
+		//set-up the SMS text
+		if msg.ToPhone != "" {
+			url := ForceURLAbs(ctx, bookUI.GetURLView())
+			ctx, urlShort, err := ShortenURLBitly(ctx, url)
+			if err != nil {
+				return ctx, errors.Wrap(err, fmt.Sprintf("create booking sms edit provider url shorten: %s", msg.ID))
+			}
+			bodyText = GetSMSText(MsgTypeBookingEditProvider, urlShort.URL)
+		}
 	case MsgTypeBookingNewClient:
 		ctx, subject, bodyHTML, err = s.server.createEmailBookingNewClient(ctx, bookUI, msg.IsClient)
 		if err != nil {
@@ -644,6 +737,7 @@ func (s *Scheduler) sendMsg(ctx context.Context, msg *Message) (context.Context,
 		if err != nil {
 			return ctx, errors.Wrap(err, fmt.Sprintf("create email invoice: %s", msg.ID))
 		}
+		ctx = s.attachPaymentPDF(ctx, msg, providerUI, paymentUI)
 
 		//set-up the SMS text
 		if msg.ToPhone != "" {
@@ -677,6 +771,7 @@ func (s *Scheduler) sendMsg(ctx context.Context, msg *Message) (context.Context,
 		if err != nil {
 			return ctx, errors.Wrap(err, fmt.Sprintf("create email payment client: %s", msg.ID))
 		}
+		ctx = s.attachPaymentPDF(ctx, msg, providerUI, paymentUI)
 	case MsgTypePaymentProvider:
 		ctx, subject, bodyHTML, err = s.server.createEmailPaymentProvider(ctx, providerUI, paymentUI)
 		if err != nil {
@@ -692,6 +787,12 @@ func (s *Scheduler) sendMsg(ctx context.Context, msg *Message) (context.Context,
 			}
 			bodyText = GetSMSText(MsgTypePaymentProvider, paymentUI.Name, urlShort.URL)
 		}
+	case MsgTypePaymentReminderClient:
+		ctx, subject, bodyHTML, err = s.server.createEmailPaymentReminderClient(ctx, providerUI, paymentUI)
+		if err != nil {
+			return ctx, errors.Wrap(err, fmt.Sprintf("create email payment reminder client: %s", msg.ID))
+		}
+		ctx = s.attachPaymentPDF(ctx, msg, providerUI, paymentUI)
 	case MsgTypePwdReset:
 		ctx, subject, bodyHTML, err = s.server.createEmailPwdReset(ctx, msg.TokenURL)
 		if err != nil {
@@ -702,6 +803,26 @@ func (s *Scheduler) sendMsg(ctx context.Context, msg *Message) (context.Context,
 		if err != nil {
 			return ctx, errors.Wrap(err, fmt.Sprintf("create email provider user invite: %s", msg.ID))
 		}
+	case MsgTypeRefundClient:
+		ctx, subject, bodyHTML, err = s.server.createEmailRefundClient(ctx, providerUI, paymentUI)
+		if err != nil {
+			return ctx, errors.Wrap(err, fmt.Sprintf("create email refund client: %s", msg.ID))
+		}
+	case MsgTypeWaitlistOfferClient:
+		ctx, subject, bodyHTML, err = s.server.createEmailWaitlistOfferClient(ctx, providerUI, svcUI, waitlist, msg.TokenURL)
+		if err != nil {
+			return ctx, errors.Wrap(err, fmt.Sprintf("create email waitlist offer client: %s", msg.ID))
+		}
+
+		//set-up the SMS text
+		if msg.ToPhone != "" {
+			url := ForceURLAbs(ctx, msg.TokenURL)
+			ctx, urlShort, err := ShortenURLBitly(ctx, url)
+			if err != nil {
+				return ctx, errors.Wrap(err, fmt.Sprintf("create sms waitlist offer client url shorten: %s", msg.ID))
+			}
+			bodyText = GetSMSText(MsgTypeWaitlistOfferClient, waitlist.FormatOfferExpiration(waitlist.Client.TimeZone), urlShort.URL)
+		}
 	case MsgTypeWelcome:
 		ctx, subject, bodyHTML, err = s.server.createEmailWelcome(ctx, providerUI)
 		if err != nil {
@@ -736,6 +857,21 @@ func (s *Scheduler) sendMsg(ctx context.Context, msg *Message) (context.Context,
 	return ctx, nil
 }
 
+//attach the pdf invoice or receipt for a payment to a message, still sending the message if the pdf fails
+func (s *Scheduler) attachPaymentPDF(ctx context.Context, msg *Message, provider *providerUI, payment *paymentUI) context.Context {
+	ctx, paymentPDF, err := s.server.createPaymentPDF(ctx, provider, payment)
+	if err != nil {
+		s.server.logger.Warnw("create payment pdf", "error", err, "id", payment.ID)
+		return ctx
+	}
+	msg.Attachments = append(msg.Attachments, &MessageAttachment{
+		FileName:    paymentPDF.FileName,
+		ContentType: pdfContentType,
+		Data:        paymentPDF.Data,
+	})
+	return ctx
+}
+
 //ProcessNotifications : process notifications
 func (s *Scheduler) ProcessNotifications() {
 	start := time.Now()
@@ -775,6 +911,32 @@ func (s *Scheduler) ProcessNotifications() {
 				continue
 			}
 			processedNotifications = append(processedNotifications, notification)
+		case NotificationTypeWaitlistOffer:
+			ctx, err = s.server.offerWaitlist(ctx, notification.Booking, now)
+			if err != nil {
+				s.server.logger.Errorw("offer waitlist", "error", err, "id", notification.SecondaryID)
+				continue
+			}
+			processedNotifications = append(processedNotifications, notification)
+		case NotificationTypePaymentReminder:
+			//ignore payments paid or deleted since the notification was created, or if the client has opted out
+			payment := notification.Payment
+			if payment == nil || payment.IsPaid() || payment.IsCaptured() || (payment.Client != nil && (payment.Client.DisablePaymentReminders || payment.Client.DisableEmails)) {
+				processedNotifications = append(processedNotifications, notification)
+				continue
+			}
+			ctx, err = s.server.queueEmailPaymentReminder(ctx, s.server.createPaymentUI(payment))
+			if err != nil {
+				s.server.logger.Errorw("queue email payment reminder", "error", err, "id", payment.ID)
+				continue
+			}
+			processedNotifications = append(processedNotifications, notification)
+
+			//record the reminder for display
+			ctx, err = SavePaymentReminder(ctx, db, payment.ID, now)
+			if err != nil {
+				s.server.logger.Errorw("save payment reminder", "error", err, "id", payment.ID)
+			}
 		default:
 			s.server.logger.Errorw("invalid notification type", "type", notification.Type)
 		}
@@ -787,6 +949,19 @@ func (s *Scheduler) ProcessNotifications() {
 	}
 }
 
+//ProcessPaymentReminders : create the notifications for reminders of unpaid invoices, which are sent when processing notifications
+func (s *Scheduler) ProcessPaymentReminders() {
+	start := time.Now()
+	defer func() {
+		s.server.logger.Debugw("process payment reminders", "elapsedMS", FormatElapsedMS(start))
+	}()
+	s.server.stats.AddTime(ServerStatProcessPaymentReminders, start)
+	_, err := CreatePaymentNotifications(s.ctx, s.server.getDB(), GetTimeNow(""), GetBatchSizeProcessPaymentReminders())
+	if err != nil {
+		s.server.logger.Errorw("create payment notifications", "error", err)
+	}
+}
+
 //ProcessRecurringBookings : process recurring bookings
 func (s *Scheduler) ProcessRecurringBookings() {
 	start := time.Now()
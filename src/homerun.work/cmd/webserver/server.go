@@ -116,10 +116,13 @@ const (
 	ServerStatLogWarnings               ServerStatKey = "Warnings"
 	ServerStatLogErrors                 ServerStatKey = "Errors"
 	ServerStatMaintenanceEnabled        ServerStatKey = "MaintenanceEnabled"
+	ServerStatProcessBookingHolds       ServerStatKey = "ProcessBookingHolds"
+	ServerStatProcessBookingsUnpaid     ServerStatKey = "ProcessBookingsUnpaid"
 	ServerStatProcessGoogle             ServerStatKey = "ProcessGoogle"
 	ServerStatProcessImgs               ServerStatKey = "ProcessImgs"
 	ServerStatProcessMsgs               ServerStatKey = "ProcessMsgs"
 	ServerStatProcessNotifications      ServerStatKey = "ProcessNotifications"
+	ServerStatProcessPaymentReminders   ServerStatKey = "ProcessPaymentReminders"
 	ServerStatProcessIncomingEmail      ServerStatKey = "ProcessIncomingEmail"
 	ServerStatProcessIncomingEmailCount ServerStatKey = "ProcessIncomingEmailCount"
 	ServerStatRecurringOrders           ServerStatKey = "ProcessRecurringOrders"
@@ -773,9 +776,9 @@ func (s *Server) loadProviderByURLName(w http.ResponseWriter, r *http.Request, t
 }
 
 //load the provider hours
-func (s *Server) loadTemplateProviderSchedule(w http.ResponseWriter, r *http.Request, tpl *template.Template, data templateData, provider *providerUI) bool {
+func (s *Server) loadTemplateProviderSchedule(w http.ResponseWriter, r *http.Request, tpl *template.Template, data templateData, providerSchedule *ProviderSchedule) bool {
 	ctx, logger := GetLogger(s.getCtx(r))
-	schedule, err := s.getSchedule(provider)
+	schedule, err := s.getSchedule(providerSchedule)
 	if err != nil {
 		logger.Errorw("get provider schedule", "error", err)
 		data[TplParamErr] = GetErrText(Err)
@@ -801,6 +804,20 @@ func (s *Server) loadTemplateServices(w http.ResponseWriter, r *http.Request, tp
 	return ctx, svcUIs, true
 }
 
+//find the services matching the ids, ignoring ids that do not match a service
+func findServicesByIDs(svcs []*serviceUI, ids []string) []*Service {
+	matchedSvcs := make([]*Service, 0, len(ids))
+	for _, svc := range svcs {
+		for _, id := range ids {
+			if svc.ID.String() == id {
+				matchedSvcs = append(matchedSvcs, svc.Service)
+				break
+			}
+		}
+	}
+	return matchedSvcs
+}
+
 //load a service
 func (s *Server) loadTemplateService(w http.ResponseWriter, r *http.Request, tpl *template.Template, data templateData, provider *providerUI, svcID *uuid.UUID, now time.Time) (context.Context, *serviceUI, bool) {
 	ctx, logger := GetLogger(s.getCtx(r))
@@ -897,12 +914,16 @@ func (s *Server) createService(provider *providerUI, form *ServiceForm) *Service
 		Type: ServiceTypeAppt,
 	}
 	svc.Provider = provider.Provider
-	svc.SetFields(form.ApptOnly, form.Name, form.Description, form.Note, form.Price, form.PriceType, form.Duration, form.LocationType, form.Location, form.Padding, form.PaddingInitial, form.PaddingInitialUnit, form.Interval, form.EnableZoom, form.URLVideo)
+	svc.SetFields(form.ApptOnly, form.Class, form.Capacity, form.Name, form.Description, form.Note, form.Price, form.PriceType, form.Duration, form.LocationType, form.Location, form.Padding, form.PaddingInitial, form.PaddingInitialUnit, form.Horizon, form.CancelCutoff, form.CancelCutoffUnit, form.Interval, form.EnableZoom, form.URLVideo)
+	svc.SetCancelPolicy(form.CancelFeeWindow, form.CancelFee, form.CancelFeeType, form.NoShowFee, form.NoShowFeeType)
+	svc.SetDeposit(form.Deposit, form.DepositType)
+	svc.PayToBook = form.PayToBook
+	svc.TaxExempt = form.TaxExempt
 	return svc
 }
 
 //CreateTimePeriods : create the time periods given a from and to time
-func (s *Server) createTimePeriods(now time.Time, minStart time.Time, date time.Time, provider *providerUI, svc *serviceUI, existingBooks []*Booking, busyTimes []*TimePeriod, isClient bool) (time.Time, []*TimePeriod) {
+func (s *Server) createTimePeriods(now time.Time, minStart time.Time, maxStart time.Time, date time.Time, provider *providerUI, svc *serviceUI, existingBooks []*Booking, busyTimes []*TimePeriod, isClient bool) (time.Time, []*TimePeriod) {
 	from, to := provider.GetBoundaryTimes(date)
 	if from.IsZero() || to.IsZero() {
 		return time.Time{}, nil
@@ -931,12 +952,20 @@ func (s *Server) createTimePeriods(now time.Time, minStart time.Time, date time.
 		if isClient && start.Before(minStart) {
 			//check if before the given date
 			timePeriod.Unavailable = true
-		} else if !provider.IsValidWorkPeriod(from, timePeriod) {
+		} else if isClient && !maxStart.IsZero() && !start.Before(maxStart) {
+			//check if beyond the booking horizon
+			timePeriod.Unavailable = true
+		} else if !provider.IsValidWorkPeriod(timePeriod) {
 			//check if the time falls in a valid period
 			timePeriod.Hidden = true
 		} else {
-			//check for conflicts with existing bookings
+			//check for conflicts with existing bookings, counting the seats taken in a class
+			seats := 0
 			for _, existingBook := range existingBooks {
+				if svc.IsClass() && existingBook.Service.ID.String() == svc.ID.String() && existingBook.TimeFrom.Equal(timePeriod.Start) {
+					seats++
+					continue
+				}
 				ok := timePeriod.IsOverlap(existingBook.TimeFromPadded, existingBook.TimeToPadded)
 				timePeriod.Unavailable = ok
 				if timePeriod.Unavailable {
@@ -944,6 +973,12 @@ func (s *Server) createTimePeriods(now time.Time, minStart time.Time, date time.
 				}
 			}
 
+			//check if the class is full
+			if !timePeriod.Unavailable && svc.IsClass() {
+				timePeriod.SeatsAvailable = svc.GetCapacity() - seats
+				timePeriod.Unavailable = timePeriod.SeatsAvailable <= 0
+			}
+
 			//check for conflicts with the busy times
 			if !timePeriod.Unavailable && busyTimes != nil {
 				for _, busyTime := range busyTimes {
@@ -971,7 +1006,7 @@ func (s *Server) createTimePeriods(now time.Time, minStart time.Time, date time.
 }
 
 //load the service and time slots
-func (s *Server) generateServiceTimes(ctx context.Context, provider *providerUI, svc *serviceUI, svcStartDate time.Time, date time.Time, isClient bool) (context.Context, time.Time, time.Time, []*TimePeriod, error) {
+func (s *Server) generateServiceTimes(ctx context.Context, provider *providerUI, svc *serviceUI, svcStartDate time.Time, svcEndDate time.Time, date time.Time, holdID *uuid.UUID, isClient bool) (context.Context, time.Time, time.Time, []*TimePeriod, error) {
 	var err error
 	var books []*Booking
 	var googleCalBusyTimes []*TimePeriod
@@ -985,7 +1020,7 @@ func (s *Server) generateServiceTimes(ctx context.Context, provider *providerUI,
 			if isClient && user == nil {
 				user = provider.User
 			}
-			ctx, books, err = ListBookingsByProviderIDAndTypeAndTime(ctx, s.getDB(), provider.ID, user, ServiceTypeAppt, from, to)
+			ctx, books, err = ListBookingsByProviderIDAndTypeAndTime(ctx, s.getDB(), provider.ID, user, ApptOnlyServiceTypes, from, to)
 			if err != nil {
 				return ctx, time.Time{}, time.Time{}, nil, errors.Wrap(err, fmt.Sprintf("load bookings: %s", provider.ID))
 			}
@@ -1006,22 +1041,30 @@ func (s *Server) generateServiceTimes(ctx context.Context, provider *providerUI,
 					return ctx, time.Time{}, time.Time{}, nil, errors.Wrap(err, fmt.Sprintf("load google busy times: %s", provider.ID))
 				}
 			}
+
+			//load the times held by other clients during checkout
+			var holdTimes []*TimePeriod
+			ctx, holdTimes, err = ListBookingHoldTimesByProviderIDAndTime(ctx, s.getDB(), provider.ID, user, holdID, from, to)
+			if err != nil {
+				return ctx, time.Time{}, time.Time{}, nil, errors.Wrap(err, fmt.Sprintf("load booking holds: %s", provider.ID))
+			}
+			googleCalBusyTimes = append(googleCalBusyTimes, holdTimes...)
 		}
 	}
 
 	//generate the available times
-	firstAvailableTime, timePeriods := s.createTimePeriods(date, svcStartDate, date, provider, svc, books, googleCalBusyTimes, isClient)
+	firstAvailableTime, timePeriods := s.createTimePeriods(date, svcStartDate, svcEndDate, date, provider, svc, books, googleCalBusyTimes, isClient)
 	return ctx, svcStartDate, firstAvailableTime, timePeriods, nil
 }
 
 //generate the time periods and find the date and first available service time given the date
-func (s *Server) generateTimes(ctx context.Context, provider *providerUI, svc *serviceUI, svcStartDate time.Time, date time.Time, isClient bool) (context.Context, time.Time, time.Time, []*TimePeriod, error) {
+func (s *Server) generateTimes(ctx context.Context, provider *providerUI, svc *serviceUI, svcStartDate time.Time, svcEndDate time.Time, date time.Time, holdID *uuid.UUID, isClient bool) (context.Context, time.Time, time.Time, []*TimePeriod, error) {
 	//check if any times are available and try the next date if necessary
 	var err error
 	var firstAvailableTime time.Time
 	var timePeriods []*TimePeriod
 	for i := 0; i < 7; i++ {
-		ctx, svcStartDate, firstAvailableTime, timePeriods, err = s.generateServiceTimes(ctx, provider, svc, svcStartDate, date, isClient)
+		ctx, svcStartDate, firstAvailableTime, timePeriods, err = s.generateServiceTimes(ctx, provider, svc, svcStartDate, svcEndDate, date, holdID, isClient)
 		if err != nil {
 			return ctx, time.Time{}, time.Time{}, nil, errors.Wrap(err, "generate times")
 		}
@@ -1040,18 +1083,32 @@ func (s *Server) generateTimes(ctx context.Context, provider *providerUI, svc *s
 func (s *Server) loadTemplateServiceTimes(w http.ResponseWriter, r *http.Request, tpl *template.Template, data templateData, errs map[string]string, provider *providerUI, svc *serviceUI, dateStr string, now time.Time, isClient bool) (context.Context, time.Time, time.Time, []*TimePeriod, bool) {
 	ctx, logger := GetLogger(s.getCtx(r))
 
-	//determine the days of week that should be disabled
-	days := provider.ListDaysOfWeekUnavailable()
+	//determine the days of week and the dates that should be disabled
+	days, dates := provider.ListDatesUnavailable(now.In(GetLocation(GetCtxTimeZone(ctx))), ProviderScheduleExceptionDays)
 	data[TplParamDaysOfWeek] = strings.Trim(strings.Replace(fmt.Sprint(days), " ", ",", -1), "[]")
+	data[TplParamDatesUnavailable] = strings.Join(dates, ",")
 
 	//sanity check the date and load the information for that date if set
 	svcStartDate := svc.ComputeStartTime(now)
+	svcEndDate := svc.ComputeEndTime(now)
+	if isClient && !svcEndDate.IsZero() {
+		data[TplParamSvcEndDate] = FormatDateLocal(svcEndDate, GetCtxTimeZone(ctx))
+	}
 	var err error
 	var firstAvailableTime time.Time
 	var timePeriods []*TimePeriod
 
+	//ignore any hold on a time by the client
+	var holdID *uuid.UUID
+	if isClient {
+		holdID, err = s.GetCookieBookingHold(r.WithContext(ctx))
+		if err != nil {
+			logger.Warnw("get cookie booking hold", "error", err)
+		}
+	}
+
 	//compute the relevant times for the first possible service date
-	ctx, svcStartDate, firstAvailableTime, timePeriods, err = s.generateTimes(ctx, provider, svc, svcStartDate, svcStartDate, isClient)
+	ctx, svcStartDate, firstAvailableTime, timePeriods, err = s.generateTimes(ctx, provider, svc, svcStartDate, svcEndDate, svcStartDate, holdID, isClient)
 	if err != nil {
 		logger.Errorw("generate times", "error", err, "id", provider.ID, "date", svcStartDate)
 		data[TplParamErr] = GetErrText(Err)
@@ -1080,10 +1137,18 @@ func (s *Server) loadTemplateServiceTimes(w http.ResponseWriter, r *http.Request
 				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
 				return ctx, time.Time{}, time.Time{}, nil, false
 			}
+
+			//check if the date is within the booking horizon
+			if !svcEndDate.IsZero() && !date.Before(svcEndDate) {
+				logger.Debugw("invalid service end date", "date", date, "test", svcEndDate)
+				errs[string(FieldErrDate)] = GetFieldErrText(string(FieldErrDate))
+				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+				return ctx, time.Time{}, time.Time{}, nil, false
+			}
 		}
 
 		//generate the relevant times for the date
-		ctx, _, firstAvailableTime, timePeriods, err = s.generateTimes(ctx, provider, svc, svcStartDate, date, isClient)
+		ctx, _, firstAvailableTime, timePeriods, err = s.generateTimes(ctx, provider, svc, svcStartDate, svcEndDate, date, holdID, isClient)
 		if err != nil {
 			logger.Errorw("generate times", "error", err, "id", provider.ID, "date", date)
 			data[TplParamErr] = GetErrText(Err)
@@ -1095,15 +1160,15 @@ func (s *Server) loadTemplateServiceTimes(w http.ResponseWriter, r *http.Request
 }
 
 //create the provider schedule
-func (s *Server) createSchedule(provider *providerUI, now time.Time, unavailMon bool, unavailTue bool, unavailWed bool, unavailThu bool, unavailFri bool, unavailSat bool, unavailSun bool, startStr string, duration int) error {
+func (s *Server) createSchedule(provider *providerUI, unavailMon bool, unavailTue bool, unavailWed bool, unavailThu bool, unavailFri bool, unavailSat bool, unavailSun bool, startStr string, duration int) error {
 	//sanity check the start
-	start := ParseTimeLocalAsUTC(startStr, now, provider.User.TimeZone)
-	if start == nil {
-		return fmt.Errorf("invalid start: %s", start)
+	start, ok := ParseTimeOfDay(startStr)
+	if !ok {
+		return fmt.Errorf("invalid start: %s", startStr)
 	}
 
 	//create a schedule for a day of the week
-	createDaySchedule := func(dayOfWeek time.Weekday, unavailable bool, start time.Time, duration int) *DaySchedule {
+	createDaySchedule := func(dayOfWeek time.Weekday, unavailable bool, start TimeOfDay, duration int) *DaySchedule {
 		schedule := &DaySchedule{
 			DayOfWeek:   dayOfWeek,
 			Unavailable: unavailable,
@@ -1121,17 +1186,23 @@ func (s *Server) createSchedule(provider *providerUI, now time.Time, unavailMon
 
 	//create the schedule
 	providerSchedules := make(map[time.Weekday]*DaySchedule, 7)
-	providerSchedules[time.Monday] = createDaySchedule(time.Monday, unavailMon, *start, duration)
-	providerSchedules[time.Tuesday] = createDaySchedule(time.Tuesday, unavailTue, *start, duration)
-	providerSchedules[time.Wednesday] = createDaySchedule(time.Wednesday, unavailWed, *start, duration)
-	providerSchedules[time.Thursday] = createDaySchedule(time.Thursday, unavailThu, *start, duration)
-	providerSchedules[time.Friday] = createDaySchedule(time.Friday, unavailFri, *start, duration)
-	providerSchedules[time.Saturday] = createDaySchedule(time.Saturday, unavailSat, *start, duration)
-	providerSchedules[time.Sunday] = createDaySchedule(time.Sunday, unavailSun, *start, duration)
+	providerSchedules[time.Monday] = createDaySchedule(time.Monday, unavailMon, start, duration)
+	providerSchedules[time.Tuesday] = createDaySchedule(time.Tuesday, unavailTue, start, duration)
+	providerSchedules[time.Wednesday] = createDaySchedule(time.Wednesday, unavailWed, start, duration)
+	providerSchedules[time.Thursday] = createDaySchedule(time.Thursday, unavailThu, start, duration)
+	providerSchedules[time.Friday] = createDaySchedule(time.Friday, unavailFri, start, duration)
+	providerSchedules[time.Saturday] = createDaySchedule(time.Saturday, unavailSat, start, duration)
+	providerSchedules[time.Sunday] = createDaySchedule(time.Sunday, unavailSun, start, duration)
 	providerSchedule := &ProviderSchedule{
+		TimeZone:     provider.User.TimeZone,
 		DaySchedules: providerSchedules,
 	}
-	errDays := providerSchedule.Process(now, provider.User.TimeZone)
+	schedule := provider.GetSchedule()
+	if schedule != nil {
+		providerSchedule.Exceptions = schedule.Exceptions
+		providerSchedule.Seasons = schedule.Seasons
+	}
+	errDays := providerSchedule.Validate()
 	if len(errDays) > 0 {
 		return fmt.Errorf("schedule: %v", errDays)
 	}
@@ -1139,8 +1210,90 @@ func (s *Server) createSchedule(provider *providerUI, now time.Time, unavailMon
 	return nil
 }
 
-//set the schedule from the form JSON, returning the days of the week that have a problem
-func (s *Server) setSchedule(ctx context.Context, provider *providerUI, inputJSON string, now time.Time, timeZone string) ([]int, error) {
+//create a tax rate from the form
+func (s *Server) createTaxRate(form *TaxRateForm) (*TaxRate, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, errors.Wrap(err, "new uuid tax rate")
+	}
+	rate, err := strconv.ParseFloat(form.TaxRate, 32)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("parse tax rate: %s", form.TaxRate))
+	}
+	taxRate := &TaxRate{
+		ID:     &id,
+		Name:   form.Name,
+		Region: form.Region,
+		Rate:   float32(rate),
+	}
+	return taxRate, nil
+}
+
+//create a schedule exception from the form
+func (s *Server) createScheduleException(form *ScheduleExceptionForm) (*ScheduleException, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, errors.Wrap(err, "new uuid schedule exception")
+	}
+	exception := &ScheduleException{
+		ID:     &id,
+		Date:   ParseDateUTC(form.Date),
+		Annual: form.Annual,
+		Closed: form.Closed,
+		Name:   form.Name,
+	}
+	if !exception.Closed {
+		start, ok := ParseTimeOfDay(form.Time)
+		if !ok {
+			return nil, fmt.Errorf("invalid start: %s", form.Time)
+		}
+		duration, err := strconv.ParseInt(form.Duration, 10, 32)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("parse duration: %s", form.Duration))
+		}
+		exception.TimeDurations = []*TimeDuration{
+			{
+				Start:    start,
+				Duration: int(duration),
+			},
+		}
+	}
+	return exception, nil
+}
+
+//create a seasonal schedule from the form, starting with the hours of the regular schedule
+func (s *Server) createScheduleSeason(form *ScheduleSeasonForm, schedule *ProviderSchedule) (*ProviderSchedule, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return nil, errors.Wrap(err, "new uuid schedule season")
+	}
+	season := &ProviderSchedule{
+		ID:           &id,
+		Name:         form.Name,
+		DateStart:    ParseDateUTC(form.Start),
+		DateEnd:      ParseDateUTC(form.End),
+		TimeZone:     schedule.TimeZone,
+		DaySchedules: make(map[time.Weekday]*DaySchedule, len(schedule.DaySchedules)),
+	}
+	for dayOfWeek, daySchedule := range schedule.DaySchedules {
+		timeDurations := make([]*TimeDuration, len(daySchedule.TimeDurations))
+		for idx, timeDuration := range daySchedule.TimeDurations {
+			timeDurations[idx] = &TimeDuration{
+				Start:    timeDuration.Start,
+				Duration: timeDuration.Duration,
+			}
+		}
+		season.DaySchedules[dayOfWeek] = &DaySchedule{
+			DayOfWeek:     daySchedule.DayOfWeek,
+			TimeDurations: timeDurations,
+			Unavailable:   daySchedule.Unavailable,
+		}
+	}
+	return season, nil
+}
+
+//set the schedule from the form JSON, returning the days of the week that have a problem, updating the seasonal schedule if specified
+func (s *Server) setSchedule(ctx context.Context, provider *providerUI, inputJSON string, timeZone string, season *ProviderSchedule) ([]int, error) {
 	_, logger := GetLogger(ctx)
 
 	//parse as json
@@ -1183,25 +1336,25 @@ func (s *Server) setSchedule(ctx context.Context, provider *providerUI, inputJSO
 					if !providerSchedule.Unavailable {
 						providerTimeDurations := make([]*TimeDuration, len(schedule.TimeDurations))
 						for idx, timeDuration := range schedule.TimeDurations {
-							start := ParseTimeLocalAsUTC(timeDuration.Start, now, timeZone)
-							if start == nil {
+							start, ok := ParseTimeOfDay(timeDuration.Start)
+							if !ok {
 								return nil, fmt.Errorf("invalid start: %s: %s", schedule.DayOfWeek, timeDuration.Start)
 							}
 							providerTimeDurations[idx] = &TimeDuration{
-								Start:    *start,
+								Start:    start,
 								Duration: timeDuration.Duration,
 							}
 						}
 
 						//sanity check the times, making sure the time durations are sorted
 						sort.SliceStable(providerTimeDurations, func(i int, j int) bool {
-							return providerTimeDurations[i].Start.Before(providerTimeDurations[j].Start)
+							return providerTimeDurations[i].Start < providerTimeDurations[j].Start
 						})
 						invalid := false
-						end := time.Time{}
+						end := TimeOfDay(0)
 						for _, timeDuration := range providerTimeDurations {
 							//given the sort, just check if the next start overlaps the previous end
-							if !end.IsZero() && timeDuration.Start.Before(end) {
+							if timeDuration.Start < end {
 								invalid = true
 								break
 							}
@@ -1228,11 +1381,12 @@ func (s *Server) setSchedule(ctx context.Context, provider *providerUI, inputJSO
 		return daysOfWeek, nil
 	}
 
-	//process the schedule to properly bucket by the day of the week
+	//validate the schedule across the days of the week
 	schedule := &ProviderSchedule{
+		TimeZone:     timeZone,
 		DaySchedules: providerSchedules,
 	}
-	days := schedule.Process(now, timeZone)
+	days := schedule.Validate()
 	if len(days) > 0 {
 		//convert the days of the week to indices based on the incoming schedule
 		for _, day := range days {
@@ -1245,13 +1399,25 @@ func (s *Server) setSchedule(ctx context.Context, provider *providerUI, inputJSO
 		}
 		return daysOfWeek, nil
 	}
+
+	//update only the hours of a seasonal schedule
+	if season != nil {
+		season.DaySchedules = schedule.DaySchedules
+		return nil, nil
+	}
+
+	//retain the existing exceptions and seasonal schedules
+	existingSchedule := provider.GetSchedule()
+	if existingSchedule != nil {
+		schedule.Exceptions = existingSchedule.Exceptions
+		schedule.Seasons = existingSchedule.Seasons
+	}
 	provider.SetSchedule(schedule)
 	return nil, nil
 }
 
 //get the schedule form json
-func (s *Server) getSchedule(provider *providerUI) (string, error) {
-	schedule := provider.GetSchedule()
+func (s *Server) getSchedule(schedule *ProviderSchedule) (string, error) {
 	if schedule == nil {
 		return "", fmt.Errorf("no schedule")
 	}
@@ -1279,7 +1445,7 @@ func (s *Server) getSchedule(provider *providerUI) (string, error) {
 			formSchedule.TimeDurations = formTimeDurations
 			for idx, timeDuration := range schedule.TimeDurations {
 				formTimeDurations[idx] = &TimeDurationForm{
-					Start:    FormatTimeLocal(timeDuration.Start, provider.User.TimeZone),
+					Start:    timeDuration.Start.Format(),
 					Duration: timeDuration.Duration,
 				}
 			}
@@ -1319,6 +1485,20 @@ func (s *Server) loadTemplateBook(w http.ResponseWriter, r *http.Request, tpl *t
 	return ctx, bookUI, true
 }
 
+//load the payments made against a booking
+func (s *Server) loadTemplateBookPayments(w http.ResponseWriter, r *http.Request, tpl *template.Template, data templateData, provider *providerUI, book *bookingUI) (context.Context, bool) {
+	ctx, logger := GetLogger(s.getCtx(r))
+	ctx, payments, err := ListPaymentsByProviderIDAndSecondaryID(ctx, s.getDB(), provider.ID, book.ID)
+	if err != nil {
+		logger.Errorw("list payments", "error", err, "id", book.ID)
+		data[TplParamErr] = GetErrText(Err)
+		s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+		return ctx, false
+	}
+	data[TplParamBookPayments] = s.createPaymentUIs(payments)
+	return ctx, true
+}
+
 //load the faqs for a provider
 func (s *Server) loadTemplateFaqs(w http.ResponseWriter, r *http.Request, tpl *template.Template, data templateData, provider *providerUI) (context.Context, []*Faq, bool) {
 	ctx, logger := GetLogger(s.getCtx(r))
@@ -1371,10 +1551,12 @@ func (s *Server) createTemplateData(r *http.Request) templateData {
 	data[TplParamDomainPublic] = GetDomain()
 	data[TplParamDurationsBooking] = ServiceDurationsBooking
 	data[TplParamDurationsOrder] = ServiceDurationsOrder
+	data[TplParamFeeTypes] = FeeTypes
 	data[TplParamFileCSS] = GetFileCSS()
 	data[TplParamFileJS] = GetFileJS()
 	data[TplParamInputs] = URLParams
 	data[TplParamIPPublic] = GetServerAddressPublicIP()
+	data[TplParamItemTypes] = PaymentItemTypes
 	data[TplParamMetaDesc] = GetMsgText(MsgMetaDesc)
 	data[TplParamMetaKeywords] = GetMsgText(MsgMetaKeywords)
 	data[TplParamPaddingUnits] = PaddingUnits
@@ -1431,9 +1613,12 @@ func (s *Server) createTemplateData(r *http.Request) templateData {
 	constants["campaignFeeFacebookAdAccount"] = FormatPrice(CampaignFeeFacebookAdAccount)
 	constants["campaignFeeFacebookPage"] = FormatPrice(CampaignFeeFacebookPage)
 	constants["campaignStatuses"] = CampaignStatuses
+	constants["capacityMax"] = ServiceCapacityMax
 	constants["cookieErr"] = CookieErr
 	constants["cookieMsg"] = CookieMsg
 	constants["cookieTimeZone"] = CookieTimeZone
+	constants["durationScheduleMax"] = durationScheduleMinutesMax
+	constants["durationScheduleMin"] = durationScheduleMinutesMin
 	constants["genderAll"] = GenderAll
 	constants["genderMen"] = GenderMen
 	constants["genderWomen"] = GenderWomen
@@ -1452,9 +1637,13 @@ func (s *Server) createTemplateData(r *http.Request) templateData {
 	constants["lenCampaignInterests"] = LenCampaignInterests
 	constants["lenCampaignLocations"] = LenCampaignLocations
 	constants["lenCodeCoupon"] = LenCodeCoupon
+	constants["lenCodeGiftCard"] = LenCodeGiftCard
 	constants["lenDescBook"] = LenDescBook
 	constants["lenDescCoupon"] = LenDescCoupon
+	constants["lenDescMembership"] = LenDescMembership
+	constants["lenDescPackage"] = LenDescPackage
 	constants["lenDescPayment"] = LenDescPayment
+	constants["lenDescPaymentItem"] = LenDescPaymentItem
 	constants["lenDescProvider"] = LenDescProvider
 	constants["lenDescProviderNote"] = LenDescProviderNote
 	constants["lenDescSvc"] = LenDescSvc
@@ -1463,6 +1652,7 @@ func (s *Server) createTemplateData(r *http.Request) templateData {
 	constants["lenEmail"] = LenEmail
 	constants["lenLocation"] = LenLocation
 	constants["lenName"] = LenName
+	constants["lenPaymentItems"] = LenPaymentItems
 	constants["lenNoteSvc"] = LenNoteSvc
 	constants["lenTextCampaign"] = LenTextCampaign
 	constants["lenTextContact"] = LenTextContact
@@ -1474,7 +1664,10 @@ func (s *Server) createTemplateData(r *http.Request) templateData {
 	constants["oauthGoogle"] = OAuthGoogle
 	constants["paymentFilterAll"] = PaymentFilterAll
 	constants["paymentFilterUnPaid"] = PaymentFilterUnPaid
+	constants["recurrenceCountMax"] = RecurrenceCountMax
+	constants["recurrenceIntervalMax"] = RecurrenceIntervalMax
 	constants["serviceAreaEducationAndTraining"] = ServiceAreaEducationAndTraining
+	constants["weekDays"] = WeekDays
 	data[TplParamConstants] = constants
 
 	//facebook
@@ -1814,9 +2007,40 @@ func (s *Server) queueEmailsBookingEdit(ctx context.Context, provider *providerU
 		SenderName:  provider.Name,
 	}
 	ctx, err := SaveMsg(ctx, s.getDB(), msg)
+	if err != nil {
+		return ctx, errors.Wrap(err, "save email booking edit client")
+	}
+	if !isClient {
+		return ctx, nil
+	}
+
+	//queue the provider emails for a change by the client
+	msg = &Message{
+		SecondaryID:  book.ID,
+		FromClientID: book.Client.ID,
+		ToUserID:     provider.User.ID,
+		ToEmail:      provider.User.Email,
+		ToPhone:      provider.User.GetPhone(),
+		Type:         MsgTypeBookingEditProvider,
+	}
+	ctx, err = SaveMsg(ctx, s.getDB(), msg)
 	if err != nil {
 		return ctx, errors.Wrap(err, "save email booking edit provider")
 	}
+	if book.ProviderUser != nil && book.ProviderUser.User != nil {
+		msg = &Message{
+			SecondaryID:  book.ID,
+			FromClientID: book.Client.ID,
+			ToUserID:     book.ProviderUser.User.ID,
+			ToEmail:      book.ProviderUser.User.Email,
+			ToPhone:      book.ProviderUser.User.GetPhone(),
+			Type:         MsgTypeBookingEditProvider,
+		}
+		ctx, err = SaveMsg(ctx, s.getDB(), msg)
+		if err != nil {
+			return ctx, errors.Wrap(err, "save email booking edit provider")
+		}
+	}
 	return ctx, nil
 }
 
@@ -2015,6 +2239,37 @@ func (s *Server) queueEmailsPayment(ctx context.Context, provider *providerUI, p
 	return ctx, nil
 }
 
+//queue a payment reminder email to the client
+func (s *Server) queueEmailPaymentReminder(ctx context.Context, payment *paymentUI) (context.Context, error) {
+	msg := &Message{
+		SecondaryID: payment.ID,
+		ToEmail:     payment.Email,
+		Type:        MsgTypePaymentReminderClient,
+		SenderName:  payment.ProviderName,
+	}
+	ctx, err := SaveMsg(ctx, s.getDB(), msg)
+	if err != nil {
+		return ctx, errors.Wrap(err, "save email payment reminder")
+	}
+	return ctx, nil
+}
+
+//queue a refund email to the client
+func (s *Server) queueEmailRefundClient(ctx context.Context, provider *providerUI, payment *paymentUI) (context.Context, error) {
+	msg := &Message{
+		SecondaryID: payment.ID,
+		FromUserID:  provider.User.ID,
+		ToEmail:     payment.Email,
+		Type:        MsgTypeRefundClient,
+		SenderName:  provider.Name,
+	}
+	ctx, err := SaveMsg(ctx, s.getDB(), msg)
+	if err != nil {
+		return ctx, errors.Wrap(err, "save email refund client")
+	}
+	return ctx, nil
+}
+
 //queue campaign add emails
 func (s *Server) queueEmailsCampaignAdd(ctx context.Context, provider *providerUI, campaign *campaignUI) (context.Context, error) {
 	//queue an email to the provider
@@ -2136,6 +2391,33 @@ func (s *Server) queueEmailPwdReset(ctx context.Context, user *User, token strin
 	return ctx, nil
 }
 
+//queue a waitlist offer email
+func (s *Server) queueEmailWaitlistOffer(ctx context.Context, book *Booking, waitlist *Waitlist) (context.Context, error) {
+	//create the claim url
+	claimURL := createProviderServiceURL(book.Provider.URLName, book.Service.ID, URIWaitlistClaim)
+	tokenURL, err := CreateURLRelParams(claimURL, URLParams.Token, *waitlist.Token)
+	if err != nil {
+		return ctx, errors.Wrap(err, "token url")
+	}
+
+	//queue the email
+	msg := &Message{
+		SecondaryID: waitlist.ID,
+		FromUserID:  book.Provider.User.ID,
+		ToClientID:  waitlist.Client.ID,
+		ToEmail:     waitlist.Client.GetEmail(),
+		ToPhone:     waitlist.GetClientPhoneSMS(),
+		Type:        MsgTypeWaitlistOfferClient,
+		SenderName:  book.Provider.Name,
+		TokenURL:    tokenURL,
+	}
+	ctx, err = SaveMsg(ctx, s.getDB(), msg)
+	if err != nil {
+		return ctx, errors.Wrap(err, "save email waitlist offer")
+	}
+	return ctx, nil
+}
+
 //queue a provider user invite
 func (s *Server) queueEmailProviderUserInvite(ctx context.Context, provider *providerUI, user *ProviderUser) (context.Context, error) {
 	msg := &Message{
@@ -2221,7 +2503,7 @@ func (s *Server) saveUser(w http.ResponseWriter, r *http.Request, user *User, pw
 }
 
 //save a booking
-func (s *Server) saveBooking(w http.ResponseWriter, r *http.Request, tpl *template.Template, data templateData, errs map[string]string, provider *providerUI, providerUser *ProviderUser, svc *serviceUI, bookUI *bookingUI, now time.Time, changeAllFollowing bool, form *ClientBookingForm, isClient bool) (*bookingUI, bool) {
+func (s *Server) saveBooking(w http.ResponseWriter, r *http.Request, tpl *template.Template, data templateData, errs map[string]string, provider *providerUI, providerUser *ProviderUser, svc *serviceUI, bookUI *bookingUI, now time.Time, scope RecurrenceScope, form *ClientBookingForm, isClient bool) (*bookingUI, bool) {
 	ctx, logger := GetLogger(s.getCtx(r))
 	timeFrom := ParseTimeUnixLocal(form.Time, form.TimeZone)
 	if timeFrom.IsZero() {
@@ -2290,6 +2572,7 @@ func (s *Server) saveBooking(w http.ResponseWriter, r *http.Request, tpl *templa
 	book.SetProvider(provider.Provider)
 	book.SetService(svc.Service)
 	book.SetLocation(form.Location)
+	book.SetTaxRate()
 	book.SetTimeFrom(timeFrom)
 	if form.DescriptionSet {
 		book.SetDescription(form.Description)
@@ -2306,17 +2589,43 @@ func (s *Server) saveBooking(w http.ResponseWriter, r *http.Request, tpl *templa
 	}
 	user := book.GetUser()
 
+	//use the hold on the time by the client
+	if isClient {
+		holdID, err := s.GetCookieBookingHold(r.WithContext(ctx))
+		if err != nil {
+			logger.Warnw("get cookie booking hold", "error", err)
+		}
+		book.HoldID = holdID
+	}
+
 	//sanity check the date
 	if book.IsApptOnly() {
 		if book.TimeChange {
 			//check if the time is available
-			ctx, count, err := CountBookingsForProviderAndTime(ctx, s.getDB(), provider.ID, user, book.TimeFrom, book.TimeTo, ServiceTypeAppt)
+			ctx, count, err := CountBookingsForProviderAndTime(ctx, s.getDB(), provider.ID, user, book.TimeFrom, book.TimeTo, ApptOnlyServiceTypes)
 			if err != nil {
 				logger.Errorw("count bookings", "error", err, "id", provider.ID, "from", book.TimeFrom, "to", book.TimeTo)
 				data[TplParamErr] = GetErrText(Err)
 				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
 				return nil, false
 			}
+
+			//other clients in the same class do not conflict, provided seats remain
+			if book.IsClass() {
+				ctx, seats, err := CountBookingsForServiceAndTime(ctx, s.getDB(), svc.ID, book.ProviderUserID, book.TimeFrom)
+				if err != nil {
+					logger.Errorw("count bookings seats", "error", err, "id", svc.ID, "from", book.TimeFrom)
+					data[TplParamErr] = GetErrText(Err)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return nil, false
+				}
+				if seats >= svc.GetCapacity() {
+					data[TplParamErr] = GetErrText(ErrBookingFull)
+					s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+					return nil, false
+				}
+				count -= seats
+			}
 			if count > 0 {
 				data[TplParamErr] = GetErrText(ErrBookingTime)
 				s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
@@ -2330,7 +2639,8 @@ func (s *Server) saveBooking(w http.ResponseWriter, r *http.Request, tpl *templa
 	//process the recurrence frequency
 	if form.FreqSet {
 		recurrenceFreq := ParseRecurrenceFreq(&form.Freq)
-		err := book.SetRecurrenceFreq(recurrenceFreq, false)
+		recurrenceOpts := CreateRecurrenceOptions(form.FreqInterval, form.FreqDays, form.FreqUntil, form.FreqCount, form.TimeZone)
+		err := book.SetRecurrenceFreq(recurrenceFreq, recurrenceOpts, false)
 		if err != nil {
 			logger.Errorw("set recurrence freq", "error", err, "freq", recurrenceFreq)
 			data[TplParamErr] = GetErrText(Err)
@@ -2366,15 +2676,31 @@ func (s *Server) saveBooking(w http.ResponseWriter, r *http.Request, tpl *templa
 		}
 	}
 
-	//update the booking, forcing a change-all if the recurrence frequency has changed
-	changeAllFollowing = changeAllFollowing || book.RecurrenceFreqChange
-	bookUI, err := s.updateServiceBooking(ctx, provider, svc, book, now, changeAllFollowing, form.Confirmed, form.ClientCreated, false)
+	//keep a new booking pending until the client makes the payment required to book
+	if isClient && createBook && svc.PayToBook && provider.SupportsPaymentOnline() && book.SupportsPayment() {
+		book.SetPaymentExpiration(now)
+	}
+
+	//update the booking, forcing a change to the following occurrences if the recurrence frequency has changed
+	if book.RecurrenceFreqChange && scope == RecurrenceScopeOnce {
+		scope = RecurrenceScopeFollowing
+	}
+	bookUI, err := s.updateServiceBooking(ctx, provider, svc, book, now, scope, form.Confirmed, form.ClientCreated, false)
 	if err != nil {
+		//check if the time was taken while the client was booking
+		if errors.Cause(err) == ErrBookingTimeTaken {
+			data[TplParamErr] = GetErrText(ErrBookingTime)
+			s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
+			return nil, false
+		}
 		logger.Errorw("update service booking", "error", err)
 		data[TplParamErr] = GetErrText(Err)
 		s.renderWebTemplate(w, r.WithContext(ctx), tpl, data)
 		return nil, false
 	}
+	if isClient {
+		s.DeleteCookieBookingHold(w)
+	}
 	data[TplParamBook] = bookUI
 	data[TplParamSvcTime] = bookUI.FormatDateTime(form.TimeZone)
 
@@ -2389,7 +2715,7 @@ func (s *Server) saveBooking(w http.ResponseWriter, r *http.Request, tpl *templa
 				return nil, false
 			}
 		} else {
-			ctx, err := s.queueEmailsBookingEdit(ctx, provider, svc, bookUI, form.ClientCreated)
+			ctx, err := s.queueEmailsBookingEdit(ctx, provider, svc, bookUI, isClient)
 			if err != nil {
 				logger.Errorw("queue email booking edit", "error", err)
 				data[TplParamErr] = GetErrText(Err)
@@ -2401,12 +2727,27 @@ func (s *Server) saveBooking(w http.ResponseWriter, r *http.Request, tpl *templa
 	return bookUI, true
 }
 
+//load the first upcoming occurrence of a recurring series, to which a change to all upcoming occurrences is applied
+func (s *Server) loadBookingSeriesUpcoming(ctx context.Context, bookUI *bookingUI, now time.Time) (context.Context, *bookingUI, error) {
+	if bookUI.ParentID == nil {
+		return ctx, bookUI, nil
+	}
+	ctx, book, err := LoadBookingUpcomingByParentID(ctx, s.getDB(), bookUI.ParentID, now)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, fmt.Sprintf("load booking upcoming: %s", bookUI.ParentID))
+	}
+	if book == nil {
+		return ctx, bookUI, nil
+	}
+	return ctx, s.createBookingUI(book), nil
+}
+
 //cancel a booking
-func (s *Server) cancelServiceBooking(w http.ResponseWriter, r *http.Request, tpl *template.Template, data templateData, errs map[string]string, provider *providerUI, svc *serviceUI, bookUI *bookingUI, now time.Time, changeAllFollowing bool) bool {
+func (s *Server) cancelServiceBooking(w http.ResponseWriter, r *http.Request, tpl *template.Template, data templateData, errs map[string]string, provider *providerUI, svc *serviceUI, bookUI *bookingUI, now time.Time, scope RecurrenceScope) bool {
 	ctx, logger := GetLogger(s.getCtx(r))
 
 	//update the booking
-	bookUI, err := s.updateServiceBooking(ctx, provider, svc, bookUI.Booking, now, changeAllFollowing, bookUI.Confirmed, bookUI.ClientCreated, true)
+	bookUI, err := s.updateServiceBooking(ctx, provider, svc, bookUI.Booking, now, scope, bookUI.Confirmed, bookUI.ClientCreated, true)
 	if err != nil {
 		logger.Errorw("update service booking", "error", err)
 		data[TplParamErr] = GetErrText(Err)
@@ -2425,8 +2766,89 @@ func (s *Server) cancelServiceBooking(w http.ResponseWriter, r *http.Request, tp
 	return true
 }
 
+//queue a notification to offer a booking time to the waitlist
+func (s *Server) queueNotificationWaitlistOffer(ctx context.Context, book *Booking, sendTime time.Time) (context.Context, error) {
+	notification := &Notification{
+		UserID:      book.Provider.User.ID,
+		SecondaryID: book.ID,
+		Type:        NotificationTypeWaitlistOffer,
+		TimeStart:   book.TimeFrom.Format(time.RFC3339),
+		TimeEnd:     book.TimeTo.Format(time.RFC3339),
+	}
+	ctx, err := CreateNotification(ctx, s.getDB(), notification, sendTime)
+	if err != nil {
+		return ctx, errors.Wrap(err, fmt.Sprintf("create notification: %s", book.ID))
+	}
+	return ctx, nil
+}
+
+//offer a booking time to the next client on the waitlist
+func (s *Server) offerWaitlist(ctx context.Context, book *Booking, now time.Time) (context.Context, error) {
+	//ignore times that have passed
+	if !book.TimeFrom.After(now) {
+		return ctx, nil
+	}
+
+	//check if the time is still available
+	ctx, count, err := CountBookingsForProviderAndTime(ctx, s.getDB(), book.Provider.ID, book.GetUser(), book.TimeFrom, book.TimeTo, ApptOnlyServiceTypes)
+	if err != nil {
+		return ctx, errors.Wrap(err, fmt.Sprintf("count bookings: %s", book.Provider.ID))
+	}
+
+	//a class is available if a seat is open
+	if book.IsClass() {
+		ctx, seats, err := CountBookingsForServiceAndTime(ctx, s.getDB(), book.Service.ID, book.ProviderUserID, book.TimeFrom)
+		if err != nil {
+			return ctx, errors.Wrap(err, fmt.Sprintf("count bookings seats: %s", book.Service.ID))
+		}
+		if seats >= book.Service.GetCapacity() {
+			return ctx, nil
+		}
+		count -= seats
+	}
+	if count > 0 {
+		return ctx, nil
+	}
+
+	//find the next client
+	ctx, waitlist, err := FindWaitlistForOffer(ctx, s.getDB(), book.Service.ID, book.ProviderUserID, book.TimeFrom, book.TimeTo, now)
+	if err != nil {
+		return ctx, errors.Wrap(err, fmt.Sprintf("find waitlist: %s", book.Service.ID))
+	}
+	if waitlist == nil {
+		return ctx, nil
+	}
+
+	//hold the time for the client
+	token, err := CreateWaitlistToken()
+	if err != nil {
+		return ctx, errors.Wrap(err, "waitlist token")
+	}
+	offerTime := book.TimeFrom.UTC()
+	expiration := now.Add(time.Duration(GetWaitlistOfferExpirationMin()) * time.Minute).UTC()
+	waitlist.Token = &token
+	waitlist.OfferTime = &offerTime
+	waitlist.OfferExpiration = &expiration
+	waitlist.OfferProviderUserID = book.ProviderUserID
+	ctx, err = UpdateWaitlistOffer(ctx, s.getDB(), waitlist)
+	if err != nil {
+		return ctx, errors.Wrap(err, fmt.Sprintf("update waitlist offer: %s", waitlist.ID))
+	}
+	ctx, err = s.queueEmailWaitlistOffer(ctx, book, waitlist)
+	if err != nil {
+		return ctx, errors.Wrap(err, "queue email waitlist offer")
+	}
+
+	//offer to the next client if the time is not claimed
+	ctx, err = s.queueNotificationWaitlistOffer(ctx, book, expiration)
+	if err != nil {
+		return ctx, errors.Wrap(err, "queue notification waitlist offer")
+	}
+	return ctx, nil
+}
+
 //save a booking
-func (s *Server) updateServiceBooking(ctx context.Context, provider *providerUI, svc *serviceUI, book *Booking, now time.Time, changeAllFollowing bool, confirmed bool, isClient bool, cancel bool) (*bookingUI, error) {
+func (s *Server) updateServiceBooking(ctx context.Context, provider *providerUI, svc *serviceUI, book *Booking, now time.Time, scope RecurrenceScope, confirmed bool, isClient bool, cancel bool) (*bookingUI, error) {
 	//check for a coupon
 	if !cancel && book.CouponCodeChange {
 		_, coupon, err := LoadCouponByProviderIDAndCode(ctx, s.getDB(), provider.ID, book.CouponCode, &now)
@@ -2437,10 +2859,19 @@ func (s *Server) updateServiceBooking(ctx context.Context, provider *providerUI,
 	}
 
 	//save the booking
-	ctx, err := SaveBooking(ctx, s.getDB(), provider.Provider, svc.Service, book, now, changeAllFollowing, confirmed, isClient, cancel)
+	ctx, err := SaveBooking(ctx, s.getDB(), provider.Provider, svc.Service, book, now, scope, confirmed, isClient, cancel)
 	if err != nil {
 		return nil, errors.Wrap(err, fmt.Sprintf("save booking: %s", book.ID))
 	}
+
+	//offer the time freed by a cancellation to the waitlist, which does not undo the cancellation if it fails
+	if cancel && book.IsApptOnly() && book.TimeFrom.After(now) {
+		_, err = s.queueNotificationWaitlistOffer(ctx, book, now)
+		if err != nil {
+			_, logger := GetLogger(ctx)
+			logger.Errorw("queue notification waitlist offer", "error", err, "id", book.ID)
+		}
+	}
 	bookUI := s.createBookingUI(book)
 	return bookUI, nil
 }
@@ -2680,6 +3111,7 @@ func (s *Server) savePaymentBooking(ctx context.Context, provider *providerUI, b
 		Phone:           form.Phone,
 		ProviderID:      provider.ID,
 		ProviderName:    provider.Name,
+		Currency:        provider.GetCurrency(),
 		SecondaryID:     book.ID,
 		Type:            PaymentTypeBooking,
 		URL:             book.GetURLPaymentClient(),
@@ -2687,8 +3119,13 @@ func (s *Server) savePaymentBooking(ctx context.Context, provider *providerUI, b
 		DirectCapture:   form.DirectCapture,
 		Invoiced:        &now,
 	}
-	price, _ := strconv.ParseFloat(form.Price, 32)
-	payment.SetAmount(float32(price))
+	if len(form.Items) > 0 {
+		items, taxRate := createPaymentItems(form, payment.GetCurrency())
+		payment.SetItems(items, taxRate)
+	} else {
+		price, _ := strconv.ParseFloat(form.Price, 32)
+		payment.SetAmount(float32(price))
+	}
 
 	//mark paid if necessary
 	if form.DirectCapture {
@@ -2704,6 +3141,52 @@ func (s *Server) savePaymentBooking(ctx context.Context, provider *providerUI, b
 	return ctx, payment, nil
 }
 
+//create the line items and tax rate for a payment from the form
+func createPaymentItems(form *PaymentForm, currency Currency) ([]*PaymentItem, float32) {
+	items := make([]*PaymentItem, 0, len(form.Items))
+	for _, itemForm := range form.Items {
+		quantity, _ := strconv.ParseFloat(itemForm.ItemQuantity, 32)
+		price, _ := strconv.ParseFloat(itemForm.ItemPrice, 32)
+		items = append(items, NewPaymentItem(currency, ParsePaymentItemType(itemForm.ItemType), itemForm.ItemDesc, float32(quantity), float32(price)))
+	}
+	taxRate, _ := strconv.ParseFloat(form.TaxRate, 32)
+	return items, float32(taxRate)
+}
+
+//create the line item forms from the line items
+func createPaymentItemForms(items []*PaymentItem, currency Currency) []*PaymentItemForm {
+	itemForms := make([]*PaymentItemForm, 0, len(items))
+	for _, item := range items {
+		itemForms = append(itemForms, &PaymentItemForm{
+			ItemType:     string(item.Type),
+			ItemDesc:     item.Description,
+			ItemQuantity: item.FormatQuantity(),
+			ItemPrice:    FormatFloat(item.GetPrice(currency)),
+		})
+	}
+	return itemForms
+}
+
+//create the form for a payment of the balance of a booking, itemizing the tax if necessary
+func createPaymentFormBooking(book *bookingUI, clientInitiated bool, directCapture bool) *PaymentForm {
+	form := &PaymentForm{
+		EmailForm: EmailForm{
+			Email: book.Client.Email,
+		},
+		NameForm: NameForm{
+			Name: book.Client.Name,
+		},
+		Price:           book.GetCurrency().FormatDecimal(book.ComputeServicePriceBalance()),
+		ClientInitiated: clientInitiated,
+		DirectCapture:   directCapture,
+	}
+	if book.HasTax() {
+		form.Items = createPaymentItemForms(book.CreatePaymentItems(), book.GetCurrency())
+		form.TaxRate = FormatRate(book.ServiceTaxRate)
+	}
+	return form
+}
+
 //create and save a campaign payment
 func (s *Server) savePaymentCampaign(ctx context.Context, campaign *campaignUI, form *CampaignPaymentForm, now *time.Time) (context.Context, *Payment, error) {
 	//load the provider
@@ -2727,6 +3210,7 @@ func (s *Server) savePaymentCampaign(ctx context.Context, campaign *campaignUI,
 		Note:         form.Description,
 		ProviderID:   provider.ID,
 		ProviderName: provider.Name,
+		Currency:     CurrencyDefault,
 		SecondaryID:  campaign.ID,
 		Type:         PaymentTypeCampaign,
 		URL:          campaign.GetURLPayment(&paymentID),
@@ -2744,6 +3228,18 @@ func (s *Server) savePaymentCampaign(ctx context.Context, campaign *campaignUI,
 	return ctx, payment, nil
 }
 
+//find the tax rate for a direct payment based on the provider location, unless the service is tax exempt
+func findTaxRateDirect(provider *providerUI, svc *Service) *TaxRate {
+	if svc != nil && svc.TaxExempt {
+		return nil
+	}
+	taxRate := provider.FindTaxRate(provider.Location)
+	if taxRate == nil || taxRate.Rate <= 0 {
+		return nil
+	}
+	return taxRate
+}
+
 //create and save a direct payment
 func (s *Server) savePaymentDirect(ctx context.Context, provider *providerUI, svc *Service, form *PaymentForm, now time.Time, timeZone string) (context.Context, *Payment, error) {
 	//save the client
@@ -2777,6 +3273,7 @@ func (s *Server) savePaymentDirect(ctx context.Context, provider *providerUI, sv
 		Phone:           form.Phone,
 		ProviderID:      provider.ID,
 		ProviderName:    provider.Name,
+		Currency:        provider.GetCurrency(),
 		SecondaryID:     client.ID,
 		Type:            PaymentTypeDirect,
 		URL:             createProviderPaymentURL(provider.GetURLName(), &id),
@@ -2784,8 +3281,29 @@ func (s *Server) savePaymentDirect(ctx context.Context, provider *providerUI, sv
 		DirectCapture:   form.DirectCapture,
 		Invoiced:        &now,
 	}
+
+	//a gift card is sold without tax, which applies when the gift card is redeemed
 	price, _ := strconv.ParseFloat(form.Price, 32)
-	payment.SetAmount(float32(price))
+	if form.GiftCard {
+		payment.Description = "gift card"
+		payment.Type = PaymentTypeGiftCard
+		payment.SetAmount(float32(price))
+		if form.DirectCapture {
+			payment.Paid = &now
+			payment.Captured = &now
+		}
+
+		//save the payment and issue the gift card
+		card := &GiftCard{
+			Name:  form.Name,
+			Email: form.Email,
+		}
+		ctx, err = SavePaymentGiftCard(ctx, s.getDB(), payment, card)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "save payment gift card")
+		}
+		return ctx, payment, nil
+	}
 
 	//apply service information
 	if svc != nil {
@@ -2793,6 +3311,18 @@ func (s *Server) savePaymentDirect(ctx context.Context, provider *providerUI, sv
 		payment.ServiceID = svc.ID.String()
 	}
 
+	//itemize the tax if necessary
+	taxRate := findTaxRateDirect(provider, svc)
+	if taxRate != nil {
+		item := NewPaymentItem(payment.GetCurrency(), PaymentItemTypeOther, "Payment", 1, float32(price))
+		if svc != nil {
+			item = NewPaymentItem(payment.GetCurrency(), PaymentItemTypeService, svc.Name, 1, float32(price))
+		}
+		payment.SetItems([]*PaymentItem{item}, taxRate.Rate)
+	} else {
+		payment.SetAmount(float32(price))
+	}
+
 	//mark paid if necessary
 	if form.DirectCapture {
 		payment.Paid = &now
@@ -2807,10 +3337,382 @@ func (s *Server) savePaymentDirect(ctx context.Context, provider *providerUI, sv
 	return ctx, payment, nil
 }
 
+//create and save the payment for a session package bought by a client, issuing the package to the client
+func (s *Server) savePaymentPackage(ctx context.Context, provider *providerUI, pkg *SessionPackage, form *PackageBuyForm, now time.Time, timeZone string) (context.Context, *Payment, error) {
+	//save the client
+	client := &Client{
+		ProviderID: provider.ID,
+		Email:      form.Email,
+		Name:       form.Name,
+		Phone:      form.Phone,
+		TimeZone:   timeZone,
+	}
+	ctx, err := SaveClient(ctx, s.getDB(), client)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "save client")
+	}
+
+	//generate the payment id
+	id, err := uuid.NewV4()
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "new uuid payment")
+	}
+
+	//create the payment
+	payment := &Payment{
+		ID:              &id,
+		Description:     fmt.Sprintf("%s package", pkg.Name),
+		Email:           form.Email,
+		Name:            form.Name,
+		Phone:           form.Phone,
+		ProviderID:      provider.ID,
+		ProviderName:    provider.Name,
+		Currency:        provider.GetCurrency(),
+		SecondaryID:     client.ID,
+		Type:            PaymentTypePackage,
+		URL:             createProviderPaymentURL(provider.GetURLName(), &id),
+		ClientInitiated: true,
+		Invoiced:        &now,
+	}
+
+	//itemize the tax if necessary
+	taxRate := findTaxRateDirect(provider, nil)
+	if taxRate != nil {
+		item := NewPaymentItem(payment.GetCurrency(), PaymentItemTypeService, pkg.Name, 1, pkg.Price)
+		payment.SetItems([]*PaymentItem{item}, taxRate.Rate)
+	} else {
+		payment.SetAmount(pkg.Price)
+	}
+
+	//save the payment and issue the package
+	ctx, _, err = SavePaymentClientPackage(ctx, s.getDB(), payment, pkg, now)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "save payment client package")
+	}
+	return ctx, payment, nil
+}
+
+//create and save a fee payment for a booking, such as a late cancellation or no-show fee, and charge the client
+func (s *Server) savePaymentFee(ctx context.Context, provider *providerUI, book *bookingUI, desc string, amount float32, now time.Time) (context.Context, *Payment, error) {
+	//generate the payment id
+	id, err := uuid.NewV4()
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "new uuid payment")
+	}
+
+	//save the payment
+	payment := &Payment{
+		ID:           &id,
+		Description:  fmt.Sprintf("%s for %s", desc, book.FormatServicePaymentDescription(provider.User.TimeZone)),
+		Email:        book.Client.Email,
+		Name:         book.Client.Name,
+		Phone:        book.GetClientPhoneSMS(),
+		ProviderID:   provider.ID,
+		ProviderName: provider.Name,
+		Currency:     provider.GetCurrency(),
+		SecondaryID:  book.ID,
+		Type:         PaymentTypeFee,
+		URL:          createProviderPaymentURL(provider.GetURLName(), &id),
+		Invoiced:     &now,
+	}
+	payment.SetAmount(amount)
+	if book.Service != nil {
+		payment.ServiceID = book.Service.ID.String()
+	}
+	ctx, err = SavePayment(ctx, s.getDB(), payment, false, false)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "save payment")
+	}
+
+	//charge the saved card if available, falling back to an invoice
+	ctx, client, err := LoadClientByID(ctx, s.getDB(), book.Client.ID)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "load client")
+	}
+	ctx, ok, err := s.chargePaymentCard(ctx, provider, client, payment, now)
+	if err != nil {
+		_, logger := GetLogger(ctx)
+		logger.Warnw("charge card", "error", err, "id", payment.ID)
+	}
+	if ok {
+		return ctx, payment, nil
+	}
+
+	//send the invoice
+	ctx, err = s.queueEmailInvoice(ctx, provider.Name, s.createPaymentUI(payment))
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "queue email invoice")
+	}
+	return ctx, payment, nil
+}
+
+//create and save the deposit payment for a booking, and invoice the client
+func (s *Server) savePaymentDeposit(ctx context.Context, provider *providerUI, book *bookingUI, now time.Time) (context.Context, *Payment, error) {
+	//generate the payment id
+	id, err := uuid.NewV4()
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "new uuid payment")
+	}
+
+	//save the payment, replacing any previous deposit
+	payment := &Payment{
+		ID:              &id,
+		Description:     fmt.Sprintf("Deposit for %s", book.FormatServicePaymentDescription(provider.User.TimeZone)),
+		Email:           book.Client.Email,
+		Name:            book.Client.Name,
+		Phone:           book.GetClientPhoneSMS(),
+		ProviderID:      provider.ID,
+		ProviderName:    provider.Name,
+		Currency:        provider.GetCurrency(),
+		SecondaryID:     book.ID,
+		Type:            PaymentTypeDeposit,
+		URL:             createProviderPaymentURL(provider.GetURLName(), &id),
+		ClientInitiated: true,
+		Invoiced:        &now,
+	}
+	payment.SetAmount(book.ComputeDeposit())
+	if book.Service != nil {
+		payment.ServiceID = book.Service.ID.String()
+	}
+	ctx, err = SavePayment(ctx, s.getDB(), payment, false, true)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "save payment")
+	}
+	book.Deposit = payment
+
+	//send the invoice
+	ctx, err = s.queueEmailInvoice(ctx, provider.Name, s.createPaymentUI(payment))
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "queue email invoice")
+	}
+	return ctx, payment, nil
+}
+
+//confirm a booking pending the payment required to book once the payment has been captured
+func (s *Server) confirmBookingPaymentCaptured(ctx context.Context, payment *Payment, now time.Time) (context.Context, error) {
+	ctx, logger := GetLogger(ctx)
+	if payment == nil || payment.Type != PaymentTypeBooking || payment.SecondaryID == nil {
+		return ctx, nil
+	}
+	ctx, book, err := LoadBookingByID(ctx, s.getDB(), payment.SecondaryID, false, true)
+	if err != nil {
+		return ctx, errors.Wrap(err, fmt.Sprintf("load booking: %s", payment.SecondaryID))
+	}
+	if book.Confirmed || book.PaymentExpiration == nil {
+		return ctx, nil
+	}
+
+	//refund a late payment for a booking already cancelled, since the checkout may still be completed after the cancellation
+	if book.Deleted {
+		if payment.IsRefundedFull() {
+			return ctx, nil
+		}
+		logger.Warnw("payment captured for cancelled booking", "id", book.ID, "paymentId", payment.ID)
+		amount := payment.GetCurrency().FromMinorUnits(payment.Amount - payment.ComputeAmountRefunded())
+		ctx, err = s.refundPayment(ctx, s.createProviderUI(book.Provider), payment, amount, GetMsgText(MsgPaymentExpiredRefund), now)
+		if err != nil {
+			return ctx, errors.Wrap(err, fmt.Sprintf("refund payment: %s", payment.ID))
+		}
+		return ctx, nil
+	}
+
+	//mark the booking confirmed
+	ctx, err = MarkBookingConfirmed(ctx, s.getDB(), book.Service, book, now)
+	if err != nil {
+		return ctx, errors.Wrap(err, fmt.Sprintf("confirm booking: %s", book.ID))
+	}
+	ctx, err = s.queueEmailsBookingConfirm(ctx, s.createBookingUI(book))
+	if err != nil {
+		return ctx, errors.Wrap(err, "queue email booking confirm")
+	}
+	return ctx, nil
+}
+
+//cancel a booking for which the payment required to book was not made in time, freeing the time and keeping the unpaid invoice to refund a late payment
+func (s *Server) cancelBookingPaymentExpired(ctx context.Context, book *Booking, now time.Time) (context.Context, error) {
+	provider := s.createProviderUI(book.Provider)
+	svc := s.createServiceUI(provider, book.Service)
+	bookUI, err := s.updateServiceBooking(ctx, provider, svc, book, now, RecurrenceScopeOnce, book.Confirmed, book.ClientCreated, true)
+	if err != nil {
+		return ctx, errors.Wrap(err, fmt.Sprintf("update service booking: %s", book.ID))
+	}
+
+	//queue the emails
+	ctx, err = s.queueEmailsBookingCancel(ctx, provider, svc, bookUI, false)
+	if err != nil {
+		return ctx, errors.Wrap(err, "queue email booking cancel")
+	}
+	return ctx, nil
+}
+
+//save a membership plan, creating a new Stripe plan if the price has changed, where existing subscriptions keep their price
+func (s *Server) saveMembershipPlan(ctx context.Context, provider *providerUI, plan *MembershipPlan) (context.Context, error) {
+	//generate an id if necessary, used to tag the stripe plan
+	if plan.ID == nil {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return ctx, errors.Wrap(err, "new uuid membership plan")
+		}
+		plan.ID = &id
+	}
+
+	//create the stripe plan
+	currency := provider.GetCurrency()
+	if !plan.IsStripePlanCurrent(currency) {
+		stripePlanID, err := CreatePlanStripe(ctx, provider.StripeToken, fmt.Sprintf("%s - %s", provider.Name, plan.Name), plan.ID.String(), currency, currency.ToMinorUnits(plan.Price))
+		if err != nil {
+			return ctx, errors.Wrap(err, "create stripe plan")
+		}
+		plan.SetStripePlan(stripePlanID, currency)
+	}
+	ctx, err := SaveMembershipPlan(ctx, s.getDB(), plan)
+	if err != nil {
+		return ctx, errors.Wrap(err, "save membership plan")
+	}
+	return ctx, nil
+}
+
+//save a client and a membership for the plan waiting on the client to subscribe
+func (s *Server) saveClientMembership(ctx context.Context, provider *providerUI, plan *MembershipPlan, form *MembershipJoinForm, timeZone string) (context.Context, *ClientMembership, error) {
+	//save the client
+	client := &Client{
+		ProviderID: provider.ID,
+		Email:      form.Email,
+		Name:       form.Name,
+		Phone:      form.Phone,
+		TimeZone:   timeZone,
+	}
+	ctx, err := SaveClient(ctx, s.getDB(), client)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "save client")
+	}
+
+	//save the membership
+	membership := &ClientMembership{
+		ProviderID:       provider.ID,
+		MembershipPlanID: plan.ID,
+		ClientID:         client.ID,
+		Name:             plan.Name,
+		Price:            plan.Price,
+		Currency:         plan.StripePlanCurrency,
+		Sessions:         plan.Sessions,
+		ServiceIDs:       plan.ServiceIDs,
+		ServiceNames:     plan.ServiceNames,
+		StripePlanID:     plan.StripePlanID,
+	}
+	ctx, err = SaveClientMembership(ctx, s.getDB(), membership)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "save client membership")
+	}
+	return ctx, membership, nil
+}
+
+//activate a client membership for the period paid by a Stripe subscription invoice, recording the payment
+func (s *Server) saveClientMembershipInvoiceStripe(ctx context.Context, invoice *InvoiceStripe, stripeAccountID string, body string, now time.Time) (context.Context, error) {
+	ctx, logger := GetLogger(ctx)
+	subscriptionID := invoice.GetSubscriptionID()
+	if subscriptionID == "" {
+		return ctx, nil
+	}
+
+	//load the membership, falling back to the metadata if the checkout has not yet been processed
+	ctx, membership, err := LoadClientMembershipBySubscriptionID(ctx, s.getDB(), subscriptionID)
+	if err != nil {
+		return ctx, errors.Wrap(err, fmt.Sprintf("load client membership: %s", subscriptionID))
+	}
+	if membership == nil {
+		membershipID := uuid.FromStringOrNil(invoice.GetMembershipID())
+		if membershipID != uuid.Nil {
+			ctx, membership, err = LoadClientMembershipByID(ctx, s.getDB(), &membershipID)
+			if err != nil {
+				return ctx, errors.Wrap(err, fmt.Sprintf("load client membership: %s", membershipID))
+			}
+		}
+	}
+	if membership == nil {
+		logger.Warnw("invoice for unknown membership", "id", subscriptionID, "invoiceId", invoice.ID)
+		return ctx, nil
+	}
+	start, end, ok := invoice.GetPeriod()
+	if !ok {
+		return ctx, fmt.Errorf("no invoice period: %s", invoice.ID)
+	}
+
+	//record the payment, ignoring invoices without a charge and those already recorded
+	var payment *Payment
+	var provider *Provider
+	intentID := invoice.GetPaymentIntentID()
+	if invoice.AmountPaid > 0 && intentID != "" {
+		var existingPayment *Payment
+		ctx, existingPayment, err = LoadPaymentByExternalID(ctx, s.getDB(), &intentID)
+		if err != nil {
+			return ctx, errors.Wrap(err, fmt.Sprintf("load payment: %s", intentID))
+		}
+		if existingPayment == nil {
+			ctx, provider, err = LoadProviderByID(ctx, s.getDB(), membership.ProviderID)
+			if err != nil {
+				return ctx, errors.Wrap(err, fmt.Sprintf("load provider: %s", membership.ProviderID))
+			}
+			id, err := uuid.NewV4()
+			if err != nil {
+				return ctx, errors.Wrap(err, "new uuid payment")
+			}
+			payment = &Payment{
+				ID:              &id,
+				Amount:          int(invoice.AmountPaid),
+				Description:     fmt.Sprintf("%s membership", membership.Name),
+				Email:           membership.ClientEmail,
+				Name:            membership.ClientName,
+				ProviderID:      membership.ProviderID,
+				ProviderName:    provider.Name,
+				Currency:        membership.Currency,
+				SecondaryID:     membership.ClientID,
+				Type:            PaymentTypeMembership,
+				URL:             createProviderPaymentURL(provider.GetURLName(), &id),
+				ClientInitiated: true,
+				Invoiced:        &now,
+				Paid:            &now,
+				Captured:        &now,
+			}
+		}
+	}
+
+	//activate the membership
+	ctx, err = SaveClientMembershipPeriod(ctx, s.getDB(), membership, subscriptionID, start, end, payment, intentID, stripeAccountID, body)
+	if err != nil {
+		return ctx, errors.Wrap(err, fmt.Sprintf("save client membership period: %s", membership.ID))
+	}
+
+	//queue the emails
+	if payment != nil {
+		ctx, err = s.queueEmailsPayment(ctx, s.createProviderUI(provider), s.createPaymentUI(payment))
+		if err != nil {
+			return ctx, errors.Wrap(err, "queue email payment")
+		}
+	}
+	return ctx, nil
+}
+
+//cancel the subscription for a client membership
+func (s *Server) cancelClientMembership(ctx context.Context, provider *providerUI, membership *ClientMembership) (context.Context, error) {
+	if !membership.IsCancellable() {
+		return ctx, fmt.Errorf("membership not cancellable: %s", membership.ID)
+	}
+	err := CancelSubscriptionStripe(ctx, provider.StripeToken, *membership.StripeSubscriptionID)
+	if err != nil {
+		return ctx, errors.Wrap(err, "cancel stripe subscription")
+	}
+	ctx, err = UpdateClientMembershipStatusBySubscriptionID(ctx, s.getDB(), *membership.StripeSubscriptionID, MembershipStatusCancelled)
+	if err != nil {
+		return ctx, errors.Wrap(err, "update client membership status")
+	}
+	return ctx, nil
+}
+
 //create the payment data for paypal
 func (s *Server) createPaymentPayPal(ctx context.Context, payeeEmail *string, payment *Payment) error {
 	//create a paypal order
-	order, err := CreateOrderPayPal(ctx, payeeEmail, payment.ProviderName, payment.Description, payment.ID.String(), payment.SecondaryID.String(), payment.GetAmount())
+	order, err := CreateOrderPayPal(ctx, payeeEmail, payment.ProviderName, payment.Description, payment.ID.String(), payment.SecondaryID.String(), payment.GetCurrency(), payment.GetAmount())
 	if err != nil {
 		return errors.Wrap(err, "create paypal order")
 	}
@@ -2832,7 +3734,7 @@ func (s *Server) createPaymentPayPal(ctx context.Context, payeeEmail *string, pa
 //create the payment data for stripe
 func (s *Server) createPaymentStripe(ctx context.Context, token *TokenStripe, payment *Payment) error {
 	//create a stripe session
-	session, err := CreateSessionStripe(ctx, token, payment.ProviderName, payment.Description, payment.ID.String(), payment.SecondaryID.String(), payment.Amount, payment.URL)
+	session, err := CreateSessionStripe(ctx, token, payment.ProviderName, payment.Description, payment.ID.String(), payment.SecondaryID.String(), payment.GetCurrency(), payment.Amount, payment.URL)
 	if err != nil {
 		return errors.Wrap(err, "create stripe session")
 	}
@@ -2863,6 +3765,31 @@ func (s *Server) createPaymentStripe(ctx context.Context, token *TokenStripe, pa
 	return nil
 }
 
+//update the checkout created for the previous amount of a payment, changing the amount of the paypal order and expiring the stripe session, which cannot be changed
+func (s *Server) updatePaymentCheckout(ctx context.Context, token *TokenStripe, payment *Payment) (context.Context, error) {
+	//an order cannot be changed to nothing owed, but is no longer shown once the invoice is paid
+	if payment.PayPalID != nil && payment.Amount > 0 {
+		err := UpdateOrderAmountPayPal(ctx, *payment.PayPalID, payment.GetCurrency(), payment.GetAmount())
+		if err != nil {
+			return ctx, errors.Wrap(err, "update paypal order")
+		}
+	}
+	if payment.StripeSessionID != nil {
+		err := ExpireSessionStripe(ctx, token, *payment.StripeSessionID)
+		if err != nil {
+			return ctx, errors.Wrap(err, "expire stripe session")
+		}
+		ctx, err = UpdatePaymentStripeID(ctx, s.getDB(), payment.ID, nil, nil, nil, nil)
+		if err != nil {
+			return ctx, errors.Wrap(err, "clear stripe session")
+		}
+		payment.StripeID = nil
+		payment.StripeSessionID = nil
+		payment.StripeAccountID = nil
+	}
+	return ctx, nil
+}
+
 //create the payment for stripe ach
 func (s *Server) createPaymentStripeACH(ctx context.Context, token *TokenStripe, payment *Payment, plaidData string) error {
 	//create the payment
@@ -2878,7 +3805,7 @@ func (s *Server) createPaymentStripeACH(ctx context.Context, token *TokenStripe,
 	if err != nil {
 		return errors.Wrap(err, "plaid stripe token")
 	}
-	chargeData, err := CreateStripeCharge(ctx, token, payment.ProviderName, payment.Description, payment.ID.String(), payment.Amount, stripeData.StripeBankAccountToken)
+	chargeData, err := CreateStripeCharge(ctx, token, payment.ProviderName, payment.Description, payment.ID.String(), payment.GetCurrency(), payment.Amount, stripeData.StripeBankAccountToken)
 	if err != nil {
 		return errors.Wrap(err, "stripe charge")
 	}
@@ -2905,6 +3832,136 @@ func (s *Server) createPaymentStripeACH(ctx context.Context, token *TokenStripe,
 	return nil
 }
 
+//charge a payment to the card saved for a client, returning false if no card is available
+func (s *Server) chargePaymentCard(ctx context.Context, provider *providerUI, client *Client, payment *Payment, now time.Time) (context.Context, bool, error) {
+	if provider.StripeToken == nil || client == nil || !client.HasCard() {
+		return ctx, false, nil
+	}
+
+	//charge the card
+	intent, err := CreatePaymentIntentStripe(ctx, provider.StripeToken, payment.Description, payment.ID.String(), *client.StripeCustomerID, client.PaymentMethodStripe.ID, payment.GetCurrency(), payment.Amount)
+	if err != nil {
+		return ctx, false, errors.Wrap(err, "stripe payment intent")
+	}
+	stripeAccountID, err := provider.StripeToken.GetStripeUserID()
+	if err != nil {
+		return ctx, false, errors.Wrap(err, "get stripe account id")
+	}
+
+	//store the data
+	intentData, err := json.Marshal(intent)
+	if err != nil {
+		return ctx, false, errors.Wrap(err, "json stripe payment intent")
+	}
+	intentJSON := string(intentData)
+	payment.StripeAccountID = &stripeAccountID
+	payment.StripeID = &intent.ID
+	ctx, err = UpdatePaymentStripeID(ctx, s.getDB(), payment.ID, payment.StripeID, nil, payment.StripeAccountID, &intentJSON)
+	if err != nil {
+		return ctx, false, errors.Wrap(err, "save stripe payment intent")
+	}
+
+	//mark the payment, which is otherwise captured by the webhook
+	payment.Paid = &now
+	if string(intent.Status) == StripePaymentIntentStatusSuccess {
+		payment.Captured = &now
+	}
+	ctx, err = UpdatePaymentPaid(ctx, s.getDB(), payment.ID, payment.Paid, payment.Captured)
+	if err != nil {
+		return ctx, false, errors.Wrap(err, "mark payment paid")
+	}
+
+	//queue the emails
+	ctx, err = s.queueEmailsPayment(ctx, provider, s.createPaymentUI(payment))
+	if err != nil {
+		return ctx, false, errors.Wrap(err, "queue email payment")
+	}
+	return ctx, true, nil
+}
+
+//refund all or part of a payment through the service used to pay, recording the refund against the payment
+func (s *Server) refundPayment(ctx context.Context, provider *providerUI, payment *Payment, amount float32, reason string, now time.Time) (context.Context, error) {
+	if !payment.AllowRefundAmount(amount) {
+		return ctx, fmt.Errorf("invalid refund amount: %f", amount)
+	}
+	refund := &PaymentRefund{
+		Amount:  payment.GetCurrency().ToMinorUnits(amount),
+		Reason:  reason,
+		Created: now,
+	}
+
+	//refund the payment, otherwise the refund is simply recorded for a payment made directly
+	if payment.StripeID != nil {
+		refundStripe, err := CreateRefundStripe(ctx, provider.StripeToken, *payment.StripeID, payment.ID.String(), refund.Amount, reason)
+		if err != nil {
+			return ctx, errors.Wrap(err, "stripe refund")
+		}
+		if refundStripe.IsFailed() {
+			return ctx, fmt.Errorf("stripe refund failed: %s", refundStripe.ID)
+		}
+		refund.ExternalID = refundStripe.ID
+	} else if payment.PayPalID != nil {
+		ctx, externalData, err := LoadPaymentExternalDataByID(ctx, s.getDB(), payment.ID)
+		if err != nil {
+			return ctx, errors.Wrap(err, "load payment external data")
+		}
+		if externalData == nil {
+			return ctx, fmt.Errorf("no paypal capture: %s", payment.ID)
+		}
+		captureID, err := ParseCaptureIDPayPal([]byte(*externalData))
+		if err != nil {
+			return ctx, errors.Wrap(err, "parse paypal capture id")
+		}
+		refundPayPal, err := RefundCapturePayPal(ctx, captureID, payment.ID.String(), payment.GetCurrency(), refund.GetAmount(payment.GetCurrency()), reason)
+		if err != nil {
+			return ctx, errors.Wrap(err, "paypal refund")
+		}
+		refund.ExternalID = refundPayPal.ID
+	}
+
+	//record the refund, which the webhook may have already recorded along with queueing the email
+	ctx, added, err := SavePaymentRefund(ctx, s.getDB(), payment.ID, refund)
+	if err != nil {
+		return ctx, errors.Wrap(err, "save payment refund")
+	}
+	if !added {
+		return ctx, nil
+	}
+	payment.Refunds = append(payment.Refunds, refund)
+
+	//queue the email
+	ctx, err = s.queueEmailRefundClient(ctx, provider, s.createPaymentUI(payment))
+	if err != nil {
+		return ctx, errors.Wrap(err, "queue email refund client")
+	}
+	return ctx, nil
+}
+
+//record a refund made outside of the dashboard, such as directly in Stripe or PayPal
+func (s *Server) savePaymentRefundExternal(ctx context.Context, payment *Payment, refund *PaymentRefund) (context.Context, error) {
+	ctx, added, err := SavePaymentRefund(ctx, s.getDB(), payment.ID, refund)
+	if err != nil {
+		return ctx, errors.Wrap(err, "save payment refund")
+	}
+
+	//refunds made from the dashboard have already been recorded
+	if !added {
+		return ctx, nil
+	}
+	payment.Refunds = append(payment.Refunds, refund)
+
+	//queue the email
+	ctx, provider, err := LoadProviderByID(ctx, s.getDB(), payment.ProviderID)
+	if err != nil {
+		return ctx, errors.Wrap(err, fmt.Sprintf("load provider: %s", payment.ProviderID))
+	}
+	ctx, err = s.queueEmailRefundClient(ctx, s.createProviderUI(provider), s.createPaymentUI(payment))
+	if err != nil {
+		return ctx, errors.Wrap(err, "queue email refund client")
+	}
+	return ctx, nil
+}
+
 //check the permissions
 func (s *Server) checkPermission(w http.ResponseWriter, r *http.Request, provider *providerUI, requiresAdmin bool) bool {
 	if requiresAdmin {
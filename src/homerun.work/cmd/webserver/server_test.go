@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+//create a booking awaiting the payment required to book
+func createFakeBookingPaymentPending(t *testing.T, now time.Time) *Booking {
+	user := &User{ID: newFakeID(t), Email: "provider@example.com"}
+	provider := &Provider{ID: newFakeID(t), User: user, Name: "Provider", URLName: "provider"}
+	svc := &Service{ID: newFakeID(t), Type: ServiceTypeAppt, Provider: provider}
+	client := &Client{ID: newFakeID(t), Email: "client@example.com"}
+	expiration := now.Add(-time.Hour)
+	book := &Booking{
+		ID:                newFakeID(t),
+		Provider:          provider,
+		Service:           svc,
+		ServiceType:       svc.Type,
+		Client:            client,
+		TimeFrom:          now.Add(24 * time.Hour),
+		TimeTo:            now.Add(25 * time.Hour),
+		PaymentExpiration: &expiration,
+		Created:           now.Add(-2 * time.Hour),
+	}
+	book.Payment = &Payment{
+		ID:          newFakeID(t),
+		ProviderID:  provider.ID,
+		SecondaryID: book.ID,
+		Type:        PaymentTypeBooking,
+		Amount:      5000,
+		Invoiced:    &book.Created,
+		Captured:    &now,
+		Email:       client.Email,
+	}
+	return book
+}
+
+func TestConfirmBookingPaymentCapturedLate(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	tests := []struct {
+		name      string
+		confirmed bool
+		deleted   bool
+		refunds   []*PaymentRefund
+		refunded  int
+	}{
+		{"booking cancelled after the payment expired", false, true, nil, 5000},
+		{"booking cancelled and partially refunded", false, true, []*PaymentRefund{{ExternalID: "re_test", Amount: 1000}}, 4000},
+		{"booking cancelled and fully refunded", false, true, []*PaymentRefund{{ExternalID: "re_test", Amount: 5000}}, 0},
+		{"booking already confirmed", true, false, nil, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db, s := newFakeDB(t)
+			book := createFakeBookingPaymentPending(t, now)
+			book.Confirmed = test.confirmed
+			book.Deleted = test.deleted
+			payment := book.Payment
+			payment.Refunds = test.refunds
+			db.onRows(fmt.Sprintf("FROM %s b INNER JOIN", dbTableBooking), fakeBookingRow(t, book))
+			db.onRows(fmt.Sprintf("SELECT amount,data FROM %s WHERE id=UUID_TO_BIN(?) FOR UPDATE", dbTablePayment), []driver.Value{int64(payment.Amount), fakeJSON(t, payment)})
+			db.onExec(fmt.Sprintf("UPDATE %s SET data=?", dbTablePayment), 1)
+			db.onRows(fmt.Sprintf("FROM %s WHERE deleted=0 AND refund=0", dbTableGiftCardEntry))
+			db.onExec(fmt.Sprintf("INSERT INTO %s", dbTableMessage), 1)
+
+			_, err := s.confirmBookingPaymentCaptured(context.Background(), payment, now)
+			if err != nil {
+				t.Fatalf("confirm booking payment captured: %v", err)
+			}
+
+			//the booking is never confirmed, and the rest of the payment is refunded with an email to the client
+			if len(db.executed("confirmed=1")) != 0 {
+				t.Errorf("booking confirmed")
+			}
+			updates := db.executed(fmt.Sprintf("UPDATE %s SET data=?", dbTablePayment))
+			emails := db.executed(fmt.Sprintf("INSERT INTO %s", dbTableMessage))
+			if test.refunded == 0 {
+				if len(updates) != 0 || len(emails) != 0 {
+					t.Errorf("got %d updates and %d emails, want none", len(updates), len(emails))
+				}
+				return
+			}
+			if len(updates) != 1 || len(emails) != 1 {
+				t.Fatalf("got %d updates and %d emails, want 1", len(updates), len(emails))
+			}
+			data, _ := updates[0].args[0].Value.([]byte)
+			want := fmt.Sprintf(`"Amount":%d,"Reason":"%s"`, test.refunded, GetMsgText(MsgPaymentExpiredRefund))
+			if !strings.Contains(string(data), want) {
+				t.Errorf("refund not recorded: %s", data)
+			}
+		})
+	}
+}
@@ -109,8 +109,8 @@ type providerUI struct {
 	*Provider
 }
 
-//create a schedule
-func (p *providerUI) createSchedule(bucket1 []*providerSchedule, bucket2 []*providerSchedule, schedule *DaySchedule) ([]*providerSchedule, []*providerSchedule) {
+//create a schedule, resolving the local hours on the given date
+func (p *providerUI) createSchedule(bucket1 []*providerSchedule, bucket2 []*providerSchedule, schedule *DaySchedule, date time.Time) ([]*providerSchedule, []*providerSchedule) {
 	if schedule.Unavailable {
 		return bucket1, bucket2
 	}
@@ -119,10 +119,7 @@ func (p *providerUI) createSchedule(bucket1 []*providerSchedule, bucket2 []*prov
 		Times:     make([]*TimePeriod, len(schedule.TimeDurations)),
 	}
 	for idx, timeDuration := range schedule.TimeDurations {
-		bucketItem.Times[idx] = &TimePeriod{
-			Start: timeDuration.Start,
-			End:   timeDuration.GetEnd(),
-		}
+		bucketItem.Times[idx] = timeDuration.Resolve(date)
 	}
 	if len(bucket1) < providerBucket1Size {
 		bucket1 = append(bucket1, bucketItem)
@@ -140,13 +137,14 @@ func (p *providerUI) GetScheduleBuckets() ([]*providerSchedule, []*providerSched
 	if schedule == nil {
 		return bucket1, bucket2
 	}
-	bucket1, bucket2 = p.createSchedule(bucket1, bucket2, schedule.DaySchedules[time.Monday])
-	bucket1, bucket2 = p.createSchedule(bucket1, bucket2, schedule.DaySchedules[time.Tuesday])
-	bucket1, bucket2 = p.createSchedule(bucket1, bucket2, schedule.DaySchedules[time.Wednesday])
-	bucket1, bucket2 = p.createSchedule(bucket1, bucket2, schedule.DaySchedules[time.Thursday])
-	bucket1, bucket2 = p.createSchedule(bucket1, bucket2, schedule.DaySchedules[time.Friday])
-	bucket1, bucket2 = p.createSchedule(bucket1, bucket2, schedule.DaySchedules[time.Saturday])
-	bucket1, bucket2 = p.createSchedule(bucket1, bucket2, schedule.DaySchedules[time.Sunday])
+	date := time.Now().In(GetLocation(schedule.TimeZone))
+	bucket1, bucket2 = p.createSchedule(bucket1, bucket2, schedule.DaySchedules[time.Monday], date)
+	bucket1, bucket2 = p.createSchedule(bucket1, bucket2, schedule.DaySchedules[time.Tuesday], date)
+	bucket1, bucket2 = p.createSchedule(bucket1, bucket2, schedule.DaySchedules[time.Wednesday], date)
+	bucket1, bucket2 = p.createSchedule(bucket1, bucket2, schedule.DaySchedules[time.Thursday], date)
+	bucket1, bucket2 = p.createSchedule(bucket1, bucket2, schedule.DaySchedules[time.Friday], date)
+	bucket1, bucket2 = p.createSchedule(bucket1, bucket2, schedule.DaySchedules[time.Saturday], date)
+	bucket1, bucket2 = p.createSchedule(bucket1, bucket2, schedule.DaySchedules[time.Sunday], date)
 	return bucket1, bucket2
 }
 
@@ -385,11 +383,46 @@ func (p *providerUI) GetURLFaqs() string {
 	return createDashboardURL(URIFaqs)
 }
 
+//GetURLGiftCardView : get the URL for the provider view gift card page
+func (p *providerUI) GetURLGiftCardView(id *uuid.UUID) string {
+	url := createDashboardURL(URIGiftCardView)
+	if id == nil {
+		return url
+	}
+	url, err := CreateURLRelParams(url, URLParams.ID, id)
+	if err != nil {
+		_, logger := GetLogger(nil)
+		logger.Errorf("create url", "url", url)
+		return ""
+	}
+	return url
+}
+
+//GetURLGiftCards : get the URL for the provider gift cards page
+func (p *providerUI) GetURLGiftCards() string {
+	return createDashboardURL(URIGiftCards)
+}
+
 //GetURLHours : get the URL for the provider hours page
 func (p *providerUI) GetURLHours() string {
 	return createDashboardURL(URIHours)
 }
 
+//GetURLHoursSeason : get the URL for the provider hours page for a seasonal schedule
+func (p *providerUI) GetURLHoursSeason(id *uuid.UUID) string {
+	url := createDashboardURL(URIHours)
+	if id == nil {
+		return url
+	}
+	url, err := CreateURLRelParams(url, URLParams.ID, id)
+	if err != nil {
+		_, logger := GetLogger(nil)
+		logger.Errorf("create url", "url", url)
+		return ""
+	}
+	return url
+}
+
 //GetURLLinks : get the URL for the provider links page
 func (p *providerUI) GetURLLinks() string {
 	return createDashboardURL(URILinks)
@@ -403,6 +436,77 @@ func (p *providerUI) GetURLMap() string {
 	return ""
 }
 
+//GetURLMembershipAdd : get the URL for the provider add membership page
+func (p *providerUI) GetURLMembershipAdd() string {
+	return createDashboardURL(URIMembershipAdd)
+}
+
+//GetURLMembershipClient : get the URL for the provider membership page seen by the client
+func (p *providerUI) GetURLMembershipClient(id *uuid.UUID) string {
+	url, err := CreateURLRelParams(createProviderURL(p.GetURLName(), URIMembership), URLParams.ID, id)
+	if err != nil {
+		_, logger := GetLogger(nil)
+		logger.Errorf("create url", "url", url)
+		return ""
+	}
+	return url
+}
+
+//GetURLMembershipEdit : get the URL for the provider edit membership page
+func (p *providerUI) GetURLMembershipEdit(id *uuid.UUID) string {
+	url := createDashboardURL(URIMembershipEdit)
+	if id == nil {
+		return url
+	}
+	url, err := CreateURLRelParams(url, URLParams.ID, id)
+	if err != nil {
+		_, logger := GetLogger(nil)
+		logger.Errorf("create url", "url", url)
+		return ""
+	}
+	return url
+}
+
+//GetURLMembershipJoinClient : get the URL for the provider join membership page seen by the client
+func (p *providerUI) GetURLMembershipJoinClient() string {
+	return createProviderURL(p.GetURLName(), URIMembershipJoin)
+}
+
+//GetURLMemberships : get the URL for the provider memberships page
+func (p *providerUI) GetURLMemberships() string {
+	return createDashboardURL(URIMemberships)
+}
+
+//GetURLPackageAdd : get the URL for the provider add package page
+func (p *providerUI) GetURLPackageAdd() string {
+	return createDashboardURL(URIPackageAdd)
+}
+
+//GetURLPackageBuyClient : get the URL for the provider buy package page seen by the client
+func (p *providerUI) GetURLPackageBuyClient() string {
+	return createProviderURL(p.GetURLName(), URIPackageBuy)
+}
+
+//GetURLPackageEdit : get the URL for the provider edit package page
+func (p *providerUI) GetURLPackageEdit(id *uuid.UUID) string {
+	url := createDashboardURL(URIPackageEdit)
+	if id == nil {
+		return url
+	}
+	url, err := CreateURLRelParams(url, URLParams.ID, id)
+	if err != nil {
+		_, logger := GetLogger(nil)
+		logger.Errorf("create url", "url", url)
+		return ""
+	}
+	return url
+}
+
+//GetURLPackages : get the URL for the provider packages page
+func (p *providerUI) GetURLPackages() string {
+	return createDashboardURL(URIPackages)
+}
+
 //GetURLPaymentDirect : get the URL for the provider direct payment page seen by the client
 func (p *providerUI) GetURLPaymentDirectClient() string {
 	return createProviderURL(p.GetURLName(), URIPaymentDirect)
@@ -526,6 +630,11 @@ func (p *providerUI) GetURLServices() string {
 	return createDashboardURL(URISvcs)
 }
 
+//GetURLTaxes : get the URL for the provider sales tax page
+func (p *providerUI) GetURLTaxes() string {
+	return createDashboardURL(URITaxes)
+}
+
 //GetURLTestimonialAdd : get the URL for the provider add testimonial page
 func (p *providerUI) GetURLTestimonialAdd() string {
 	return createDashboardURL(URITestimonialAdd)
@@ -541,6 +650,11 @@ func (p *providerUI) GetURLTestimonials() string {
 	return createDashboardURL(URITestimonials)
 }
 
+//GetURLTimeOff : get the URL for the provider time off page
+func (p *providerUI) GetURLTimeOff() string {
+	return createDashboardURL(URITimeOff)
+}
+
 //GetURLUserAdd : get the URL for the provider add user page
 func (p *providerUI) GetURLUserAdd() string {
 	return createDashboardURL(URIUserAdd)
@@ -682,6 +796,16 @@ func (s *serviceUI) GetURLService() string {
 	return createProviderServiceURL(s.Provider.URLName, s.ID, URIDefault)
 }
 
+//GetURLWaitlist : return the URL to join the waitlist for the service
+func (s *serviceUI) GetURLWaitlist() string {
+	return createProviderServiceURL(s.Provider.URLName, s.ID, URIWaitlist)
+}
+
+//GetURLWaitlistClaim : return the URL to claim a waitlist offer for the service
+func (s *serviceUI) GetURLWaitlistClaim() string {
+	return createProviderServiceURL(s.Provider.URLName, s.ID, URIWaitlistClaim)
+}
+
 //GetURLImgMain : get the URLs for the service main image
 func (s *serviceUI) GetURLImgMain() string {
 	img := s.ImgMain
@@ -874,6 +998,16 @@ func (b *bookingUI) GetURLCancelClient() string {
 	return createProviderServiceBookURL(b.Provider.URLName, b.Service.ID, b.ID, URIBookingCancel)
 }
 
+//GetURLRescheduleClient : return the URL to reschedule the booking by a client
+func (b *bookingUI) GetURLRescheduleClient() string {
+	return createProviderServiceBookURL(b.Provider.URLName, b.Service.ID, b.ID, URIBookingReschedule)
+}
+
+//GetURLCardClient : return the URL to save a card by a client
+func (b *bookingUI) GetURLCardClient() string {
+	return createProviderServiceBookURL(b.Provider.URLName, b.Service.ID, b.ID, URIBookingCard)
+}
+
 //GetURLocationMap : return the URL to map the location
 func (b *bookingUI) GetURLocationMap() string {
 	if b.Location != "" {
@@ -962,6 +1096,17 @@ func (p *paymentUI) GetURLView() string {
 	return url
 }
 
+//GetURLPDF : get the URL to download the pdf invoice or receipt
+func (p *paymentUI) GetURLPDF() string {
+	url, err := CreateURLRelParams(createDashboardURL(URIPaymentPDF), URLParams.PaymentID, p.ID)
+	if err != nil {
+		_, logger := GetLogger(nil)
+		logger.Errorf("create url", "url", createDashboardURL(URIPaymentPDF))
+		return ""
+	}
+	return url
+}
+
 //testimonial wrapper used for the ui
 type testimonialUI struct {
 	*Testimonial
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofrs/uuid"
@@ -22,6 +23,8 @@ const (
 const (
 	ServiceDurationDefault = 60 //minutes
 	ServiceIntervalDefault = 15 //minutes
+	ServiceCapacityDefault = 1
+	ServiceCapacityMax     = 100
 )
 
 //order service duration
@@ -139,11 +142,23 @@ type ServiceType int
 const (
 	ServiceTypeAppt ServiceType = iota + 1
 	ServiceTypeOnDemand
+	ServiceTypeClass
 )
 
+//ApptOnlyServiceTypes : service types that require an appointment
+var ApptOnlyServiceTypes []ServiceType = []ServiceType{
+	ServiceTypeAppt,
+	ServiceTypeClass,
+}
+
 //IsApptOnly : check if the service type is appointment only
 func IsApptOnly(serviceType ServiceType) bool {
-	return serviceType == ServiceTypeAppt
+	return serviceType == ServiceTypeAppt || serviceType == ServiceTypeClass
+}
+
+//IsClass : check if the service type is a group class
+func IsClass(serviceType ServiceType) bool {
+	return serviceType == ServiceTypeClass
 }
 
 //ServiceInterval : definition of a service interval
@@ -290,13 +305,13 @@ func ParsePaddingUnit(in string) PaddingUnit {
 type PriceType string
 
 //Format : format a price
-func (p *PriceType) Format(price float32) string {
+func (p *PriceType) Format(price float32, currency Currency) string {
 	var priceStr string
 	if price == 0 {
 		priceStr = "FREE"
 		return priceStr
 	}
-	priceStr = FormatPrice(price)
+	priceStr = currency.FormatAmount(price)
 	if *p == PriceTypeHourly {
 		priceStr = fmt.Sprintf("%s/hour", priceStr)
 	}
@@ -343,21 +358,86 @@ func ParsePriceType(in string) PriceType {
 	return ""
 }
 
+//FeeType : type of fee
+type FeeType string
+
+//Label : label for the fee type, showing the currency for a fixed amount
+func (f FeeType) Label(currency Currency) string {
+	if f == FeeTypeAmount {
+		return currency.Code()
+	}
+	return string(f)
+}
+
+//Format : format a fee
+func (f *FeeType) Format(fee float32, currency Currency) string {
+	if *f == FeeTypePercentage {
+		return fmt.Sprintf("%s%%", FormatFloat(fee))
+	}
+	return currency.FormatAmount(fee)
+}
+
+//Compute : compute a fee based on the price
+func (f *FeeType) Compute(fee float32, price float32) float32 {
+	if *f == FeeTypePercentage {
+		return price * (fee / 100)
+	}
+	return fee
+}
+
+//fee types, where the value of a fixed amount is kept for existing services
+const (
+	FeeTypePercentage FeeType = "%"
+	FeeTypeAmount             = "USD"
+)
+
+//FeeTypes : fee types
+var FeeTypes []FeeType = []FeeType{
+	FeeTypePercentage,
+	FeeTypeAmount,
+}
+
+//ParseFeeType : parse a fee type
+func ParseFeeType(in string) FeeType {
+	if in == "" {
+		return ""
+	}
+	switch in {
+	case string(FeeTypePercentage):
+		return FeeTypePercentage
+	case string(FeeTypeAmount):
+		return FeeTypeAmount
+	}
+	return ""
+}
+
 //Service : service definition
 type Service struct {
 	ID                 *uuid.UUID          `json:"-"`
 	UserID             *uuid.UUID          `json:"-"`
 	Type               ServiceType         `json:"-"`
+	CancelCutoff       int                 `json:"CancelCutoff"`
+	CancelCutoffUnit   PaddingUnit         `json:"CancelCutoffUnit"`
+	CancelFee          float32             `json:"CancelFee"`
+	CancelFeeType      FeeType             `json:"CancelFeeType"`
+	CancelFeeWindow    int                 `json:"CancelFeeWindow"` //hours
+	Capacity           int                 `json:"Capacity"`
+	Deposit            float32             `json:"Deposit"`
+	DepositType        FeeType             `json:"DepositType"`
+	PayToBook          bool                `json:"PayToBook"`
 	ImgMain            *Img                `json:"-"`
 	Imgs               []*Img              `json:"-"`
 	Provider           *Provider           `json:"-"`
 	Description        string              `json:"Description"`
 	Duration           int                 `json:"Duration"` //minutes
 	EnableZoom         bool                `json:"EnableZoom"`
+	Horizon            int                 `json:"Horizon"` //days
 	Interval           int                 `json:"Interval"`
 	Location           string              `json:"Location"`
 	LocationType       ServiceLocationType `json:"LocationType"`
 	Name               string              `json:"Name"`
+	NoShowFee          float32             `json:"NoShowFee"`
+	NoShowFeeType      FeeType             `json:"NoShowFeeType"`
 	Note               string              `json:"Note"`
 	Padding            int                 `json:"Padding"` //minutes
 	PaddingChanged     bool                `json:"-"`
@@ -365,6 +445,7 @@ type Service struct {
 	PaddingInitialUnit PaddingUnit         `json:"PaddingInitialUnit"`
 	Price              float32             `json:"Price"` //dollars
 	PriceType          PriceType           `json:"PriceType"`
+	TaxExempt          bool                `json:"TaxExempt"`
 	URLVideo           string              `json:"UrlVideo"`
 	HTMLVideoPlayer    string              `json:"HtmlVideoPlayer"`
 }
@@ -375,7 +456,7 @@ func (s *Service) ComputePrice() float32 {
 }
 
 //SetFields : set service values
-func (s *Service) SetFields(apptOnly bool, name string, desc string, note string, priceStr string, priceTypeStr string, durationStr string, locTypeStr string, loc string, paddingStr string, paddingInitialStr string, paddingInitialUnitStr string, intervalStr string, enableZoom bool, urlVideo string) {
+func (s *Service) SetFields(apptOnly bool, class bool, capacityStr string, name string, desc string, note string, priceStr string, priceTypeStr string, durationStr string, locTypeStr string, loc string, paddingStr string, paddingInitialStr string, paddingInitialUnitStr string, horizonStr string, cancelCutoffStr string, cancelCutoffUnitStr string, intervalStr string, enableZoom bool, urlVideo string) {
 	s.SetApptOnly(apptOnly)
 	s.Description = desc
 	s.EnableZoom = enableZoom && apptOnly
@@ -383,6 +464,10 @@ func (s *Service) SetFields(apptOnly bool, name string, desc string, note string
 	s.Name = name
 	s.Note = note
 
+	//parse the capacity for a class
+	capacity, _ := strconv.ParseInt(capacityStr, 10, 32)
+	s.SetClass(apptOnly && class, int(capacity))
+
 	//parse the duration
 	duration, _ := strconv.ParseInt(durationStr, 10, 32)
 	s.Duration = int(duration)
@@ -410,6 +495,14 @@ func (s *Service) SetFields(apptOnly bool, name string, desc string, note string
 	paddingInitialUnit := ParsePaddingUnit(paddingInitialUnitStr)
 	s.PaddingInitialUnit = paddingInitialUnit
 
+	//parse the booking horizon and the cancellation cutoff
+	horizon, _ := strconv.ParseInt(horizonStr, 10, 32)
+	s.Horizon = int(horizon)
+	cancelCutoff, _ := strconv.ParseInt(cancelCutoffStr, 10, 32)
+	s.CancelCutoff = int(cancelCutoff)
+	cancelCutoffUnit := ParsePaddingUnit(cancelCutoffUnitStr)
+	s.CancelCutoffUnit = cancelCutoffUnit
+
 	//parse the interval
 	interval := ParseServiceInterval(intervalStr)
 	s.Interval = interval.Value
@@ -418,6 +511,25 @@ func (s *Service) SetFields(apptOnly bool, name string, desc string, note string
 	s.SetURLVideo(urlVideo)
 }
 
+//SetCancelPolicy : set the cancellation policy
+func (s *Service) SetCancelPolicy(cancelFeeWindowStr string, cancelFeeStr string, cancelFeeTypeStr string, noShowFeeStr string, noShowFeeTypeStr string) {
+	cancelFeeWindow, _ := strconv.ParseInt(cancelFeeWindowStr, 10, 32)
+	s.CancelFeeWindow = int(cancelFeeWindow)
+	cancelFee, _ := strconv.ParseFloat(cancelFeeStr, 32)
+	s.CancelFee = float32(cancelFee)
+	s.CancelFeeType = ParseFeeType(cancelFeeTypeStr)
+	noShowFee, _ := strconv.ParseFloat(noShowFeeStr, 32)
+	s.NoShowFee = float32(noShowFee)
+	s.NoShowFeeType = ParseFeeType(noShowFeeTypeStr)
+}
+
+//SetDeposit : set the deposit required when booking
+func (s *Service) SetDeposit(depositStr string, depositTypeStr string) {
+	deposit, _ := strconv.ParseFloat(depositStr, 32)
+	s.Deposit = float32(deposit)
+	s.DepositType = ParseFeeType(depositTypeStr)
+}
+
 //SetPadding : set the padding
 func (s *Service) SetPadding(padding int) {
 	if s.Padding != padding {
@@ -440,15 +552,46 @@ func (s *Service) SetApptOnly(apptOnly bool) {
 	}
 }
 
+//IsClass : check if a group class
+func (s *Service) IsClass() bool {
+	return IsClass(s.Type)
+}
+
+//SetClass : set the service as a group class with the given capacity
+func (s *Service) SetClass(class bool, capacity int) {
+	if !class {
+		s.Capacity = ServiceCapacityDefault
+		return
+	}
+	s.Type = ServiceTypeClass
+	s.Capacity = capacity
+}
+
+//GetCapacity : get the number of clients that can book the same time
+func (s *Service) GetCapacity() int {
+	if !s.IsClass() || s.Capacity < ServiceCapacityDefault {
+		return ServiceCapacityDefault
+	}
+	return s.Capacity
+}
+
 //SetURLVideo : set the video URL
 func (s *Service) SetURLVideo(url string) {
 	s.URLVideo = url
 	s.HTMLVideoPlayer = GenerateYouTubePlayerHTML(url)
 }
 
+//GetCurrency : get the currency of the provider
+func (s *Service) GetCurrency() Currency {
+	if s.Provider == nil {
+		return CurrencyDefault
+	}
+	return s.Provider.GetCurrency()
+}
+
 //FormatPrice : format the price
 func (s *Service) FormatPrice() string {
-	return s.PriceType.Format(s.Price)
+	return s.PriceType.Format(s.Price, s.GetCurrency())
 }
 
 //IsDurationVariable : check if the duration is variable
@@ -485,6 +628,82 @@ func (s *Service) computePaddingInitialMinutes() int {
 	return 0
 }
 
+//compute the cancellation cutoff in minutes
+func (s *Service) computeCancelCutoffMinutes() int {
+	switch s.CancelCutoffUnit {
+	case PaddingUnitHours:
+		return s.CancelCutoff * 60
+	case PaddingUnitDays:
+		return s.CancelCutoff * 24 * 60
+	}
+	return 0
+}
+
+//GetCancelCutoffUnit : get the cancellation cutoff unit, defaulting to hours
+func (s *Service) GetCancelCutoffUnit() PaddingUnit {
+	if s.CancelCutoffUnit == "" {
+		return PaddingUnitHours
+	}
+	return s.CancelCutoffUnit
+}
+
+//FormatCancelCutoff : format the cancellation cutoff
+func (s *Service) FormatCancelCutoff() string {
+	return fmt.Sprintf("%d %s", s.CancelCutoff, strings.ToLower(string(s.GetCancelCutoffUnit())))
+}
+
+//ComputeEndTime : compute the maximum start time based on the booking horizon, which is zero if unlimited
+func (s *Service) ComputeEndTime(now time.Time) time.Time {
+	if s.Horizon <= 0 {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, s.Horizon)
+}
+
+//CheckCancelTime : check if a client can still cancel or reschedule a booking starting at the given time
+func (s *Service) CheckCancelTime(now time.Time, start time.Time) bool {
+	if s.CancelCutoff <= 0 {
+		return true
+	}
+	cutoff := time.Duration(s.computeCancelCutoffMinutes()) * time.Minute
+	return now.Add(cutoff).Before(start)
+}
+
+//GetCancelFeeType : get the late cancellation fee type, defaulting to a percentage
+func (s *Service) GetCancelFeeType() FeeType {
+	if s.CancelFeeType == "" {
+		return FeeTypePercentage
+	}
+	return s.CancelFeeType
+}
+
+//GetNoShowFeeType : get the no-show fee type, defaulting to a percentage
+func (s *Service) GetNoShowFeeType() FeeType {
+	if s.NoShowFeeType == "" {
+		return FeeTypePercentage
+	}
+	return s.NoShowFeeType
+}
+
+//GetDepositType : get the deposit type, defaulting to a percentage
+func (s *Service) GetDepositType() FeeType {
+	if s.DepositType == "" {
+		return FeeTypePercentage
+	}
+	return s.DepositType
+}
+
+//HasDeposit : check if a deposit is required
+func (s *Service) HasDeposit() bool {
+	return s.Deposit > 0
+}
+
+//FormatDeposit : format the deposit
+func (s *Service) FormatDeposit() string {
+	depositType := s.GetDepositType()
+	return depositType.Format(s.Deposit, s.GetCurrency())
+}
+
 //GetInterval : get the service interval
 func (s *Service) GetInterval() time.Duration {
 	if s.Interval == 0 {
@@ -514,6 +733,12 @@ func (s *Service) CheckValidTime(provider *Provider, now time.Time, start time.T
 		return false
 	}
 
+	//check if the time is within the booking horizon
+	svcEnd := s.ComputeEndTime(now)
+	if !svcEnd.IsZero() && !start.Before(svcEnd) {
+		return false
+	}
+
 	//check against the provider schedule
 	check := provider.CheckValidTime(now, start, end)
 	if !check {
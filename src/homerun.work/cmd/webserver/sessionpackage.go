@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+//session package db tables
+const (
+	dbTableSessionPackage = "session_package"
+)
+
+//SessionPackage : definition of a prepaid package of sessions sold by a provider
+type SessionPackage struct {
+	ID             *uuid.UUID   `json:"-"`
+	ProviderID     *uuid.UUID   `json:"-"`
+	Name           string       `json:"Name"`
+	Description    string       `json:"Description"`
+	Price          float32      `json:"Price"`
+	Sessions       int          `json:"Sessions"`
+	ExpirationDays int          `json:"ExpirationDays"` //zero indicates no expiration
+	ServiceIDs     []*uuid.UUID `json:"ServiceIDs"`
+	ServiceNames   []string     `json:"ServiceNames"`
+}
+
+//FormatPrice : format the price
+func (s *SessionPackage) FormatPrice(currency Currency) string {
+	return currency.FormatAmount(s.Price)
+}
+
+//FormatSessions : format the number of sessions
+func (s *SessionPackage) FormatSessions() string {
+	if s.Sessions == 1 {
+		return "1 Session"
+	}
+	return fmt.Sprintf("%d Sessions", s.Sessions)
+}
+
+//FormatExpiration : format the expiration period
+func (s *SessionPackage) FormatExpiration() string {
+	if s.ExpirationDays == 0 {
+		return "No Expiration"
+	}
+	if s.ExpirationDays == 1 {
+		return "Expires after 1 day"
+	}
+	return fmt.Sprintf("Expires after %d days", s.ExpirationDays)
+}
+
+//FormatExpirationDays : format the expiration period for the form
+func (s *SessionPackage) FormatExpirationDays() string {
+	if s.ExpirationDays == 0 {
+		return ""
+	}
+	return strconv.Itoa(s.ExpirationDays)
+}
+
+//FormatServices : format the services covered by the package
+func (s *SessionPackage) FormatServices() string {
+	return strings.Join(s.ServiceNames, ", ")
+}
+
+//FormatServiceIDs : format the ids of the services covered by the package for the form
+func (s *SessionPackage) FormatServiceIDs() []string {
+	ids := make([]string, 0, len(s.ServiceIDs))
+	for _, id := range s.ServiceIDs {
+		ids = append(ids, id.String())
+	}
+	return ids
+}
+
+//SetServices : set the services covered by the package
+func (s *SessionPackage) SetServices(svcs []*Service) {
+	s.ServiceIDs = make([]*uuid.UUID, 0, len(svcs))
+	s.ServiceNames = make([]string, 0, len(svcs))
+	for _, svc := range svcs {
+		s.ServiceIDs = append(s.ServiceIDs, svc.ID)
+		s.ServiceNames = append(s.ServiceNames, svc.Name)
+	}
+}
+
+//ComputeExpiration : compute the expiration of a package purchased at the given time
+func (s *SessionPackage) ComputeExpiration(now time.Time) *time.Time {
+	if s.ExpirationDays == 0 {
+		return nil
+	}
+	expiration := now.AddDate(0, 0, s.ExpirationDays)
+	return &expiration
+}
+
+//LoadSessionPackageByProviderIDAndID : load a session package by provider id and id
+func LoadSessionPackageByProviderIDAndID(ctx context.Context, db *DB, providerID *uuid.UUID, id *uuid.UUID) (context.Context, *SessionPackage, error) {
+	stmt := fmt.Sprintf("SELECT data FROM %s WHERE deleted=0 AND provider_id=UUID_TO_BIN(?) AND id=UUID_TO_BIN(?)", dbTableSessionPackage)
+	ctx, row, err := db.QueryRow(ctx, stmt, providerID, id)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "query row session package")
+	}
+
+	//read the row
+	var dataStr string
+	err = row.Scan(&dataStr)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx, nil, fmt.Errorf("no session package: %s: %s", providerID, id)
+		}
+		return ctx, nil, errors.Wrap(err, "select session package")
+	}
+
+	//unmarshal the data
+	var pkg SessionPackage
+	err = json.Unmarshal([]byte(dataStr), &pkg)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "unjson session package")
+	}
+	pkg.ID = id
+	pkg.ProviderID = providerID
+	return ctx, &pkg, nil
+}
+
+//SaveSessionPackage : save a session package
+func SaveSessionPackage(ctx context.Context, db *DB, pkg *SessionPackage) (context.Context, error) {
+	//generate an id if necessary
+	if pkg.ID == nil {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return ctx, errors.Wrap(err, "new uuid session package")
+		}
+		pkg.ID = &id
+	}
+
+	//json encode the session package data
+	dataJSON, err := json.Marshal(pkg)
+	if err != nil {
+		return ctx, errors.Wrap(err, "json session package")
+	}
+
+	//save to the db
+	stmt := fmt.Sprintf("INSERT INTO %s(id,provider_id,data) VALUES (UUID_TO_BIN(?),UUID_TO_BIN(?),?) ON DUPLICATE KEY UPDATE data=VALUES(data)", dbTableSessionPackage)
+	ctx, result, err := db.Exec(ctx, stmt, pkg.ID, pkg.ProviderID, dataJSON)
+	if err != nil {
+		return ctx, errors.Wrap(err, "insert session package")
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return ctx, errors.Wrap(err, "insert session package rows affected")
+	}
+
+	//0 indicated no update, 1 an insert, 2 an update
+	if count < 0 || count > 2 {
+		return ctx, fmt.Errorf("unable to insert session package: %s", pkg.ProviderID)
+	}
+	return ctx, nil
+}
+
+//DeleteSessionPackage : delete a session package, leaving any purchased packages available to the clients
+func DeleteSessionPackage(ctx context.Context, db *DB, providerID *uuid.UUID, id *uuid.UUID) (context.Context, error) {
+	stmt := fmt.Sprintf("UPDATE %s SET deleted=1 WHERE provider_id=UUID_TO_BIN(?) AND id=UUID_TO_BIN(?)", dbTableSessionPackage)
+	ctx, result, err := db.Exec(ctx, stmt, providerID, id)
+	if err != nil {
+		return ctx, errors.Wrap(err, "delete session package")
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return ctx, errors.Wrap(err, "delete session package rows affected")
+	}
+	if count == 0 {
+		return ctx, fmt.Errorf("delete session package error: %s", id)
+	}
+	return ctx, nil
+}
+
+//CountSessionPackagesByProviderID : count the session packages for the provider
+func CountSessionPackagesByProviderID(ctx context.Context, db *DB, providerID *uuid.UUID) (context.Context, int, error) {
+	stmt := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE deleted=0 AND provider_id=UUID_TO_BIN(?)", dbTableSessionPackage)
+	ctx, row, err := db.QueryRow(ctx, stmt, providerID)
+	if err != nil {
+		return ctx, 0, errors.Wrap(err, "count session packages")
+	}
+
+	//read the rows
+	var count int
+	err = row.Scan(&count)
+	if err != nil {
+		return ctx, 0, errors.Wrap(err, "row scan count session packages")
+	}
+	return ctx, count, nil
+}
+
+//ListSessionPackagesByProviderID : list all session packages for the provider
+func ListSessionPackagesByProviderID(ctx context.Context, db *DB, providerID *uuid.UUID) (context.Context, []*SessionPackage, error) {
+	ctx, logger := GetLogger(ctx)
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(id),data FROM %s WHERE deleted=0 AND provider_id=UUID_TO_BIN(?) ORDER BY created", dbTableSessionPackage)
+	ctx, rows, err := db.Query(ctx, stmt, providerID)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "select session packages")
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			logger.Warnw("rows close", "error", err)
+		}
+	}()
+
+	//read the rows
+	pkgs := make([]*SessionPackage, 0, 2)
+	var idStr string
+	var dataStr string
+	for rows.Next() {
+		err := rows.Scan(&idStr, &dataStr)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "rows scan session packages")
+		}
+
+		//parse the uuid
+		id, err := uuid.FromString(idStr)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "parse uuid")
+		}
+
+		//unmarshal the data
+		var pkg SessionPackage
+		err = json.Unmarshal([]byte(dataStr), &pkg)
+		if err != nil {
+			return ctx, nil, errors.Wrap(err, "unjson session package")
+		}
+		pkg.ID = &id
+		pkg.ProviderID = providerID
+		pkgs = append(pkgs, &pkg)
+	}
+	return ctx, pkgs, nil
+}
@@ -13,7 +13,12 @@ import (
 	"github.com/stripe/stripe-go"
 	"github.com/stripe/stripe-go/charge"
 	"github.com/stripe/stripe-go/checkout/session"
+	"github.com/stripe/stripe-go/customer"
 	"github.com/stripe/stripe-go/oauth"
+	"github.com/stripe/stripe-go/paymentintent"
+	"github.com/stripe/stripe-go/plan"
+	"github.com/stripe/stripe-go/refund"
+	"github.com/stripe/stripe-go/sub"
 	"github.com/stripe/stripe-go/webhook"
 )
 
@@ -24,11 +29,22 @@ const (
 
 //stripe constants
 const (
+	StripeEventTypeChargeRefunded           = "charge.refunded"
 	StripeEventTypeCheckoutSessionCompleted = "checkout.session.completed"
+	StripeEventTypeInvoicePaid              = "invoice.paid"
+	StripeEventTypeInvoicePaymentFailed     = "invoice.payment_failed"
 	StripeEventTypePaymentIntentSucceeded   = "payment_intent.succeeded"
+	StripeEventTypeSubscriptionDeleted      = "customer.subscription.deleted"
 	StripeHeaderSignature                   = "Stripe-Signature"
+	StripeMetadataMembershipID              = "membershipId"
+	StripeModePayment                       = "payment"
+	StripeModeSetup                         = "setup"
+	StripeModeSubscription                  = "subscription"
 	StripeOAuthURL                          = "https://connect.stripe.com/oauth/authorize"
 	StripePaymentIntentStatusSuccess        = "succeeded"
+	StripePlanIntervalMonth                 = "month"
+	StripePrefixPaymentIntent               = "pi_"
+	StripeRefundMetadataReason              = "reason"
 	StripeURLParamSessionID                 = "{CHECKOUT_SESSION_ID}"
 )
 
@@ -78,6 +94,71 @@ type PaymentIntentStripe struct {
 	*stripe.PaymentIntent
 }
 
+//PaymentMethodStripe : wrapper for a Stripe payment method
+type PaymentMethodStripe struct {
+	*stripe.PaymentMethod
+}
+
+//FormatCard : format the card for display
+func (p *PaymentMethodStripe) FormatCard() string {
+	if p.PaymentMethod == nil || p.Card == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s ending in %s", strings.Title(string(p.Card.Brand)), p.Card.Last4)
+}
+
+//InvoiceStripe : wrapper for a Stripe invoice
+type InvoiceStripe struct {
+	*stripe.Invoice
+}
+
+//GetSubscriptionID : get the id of the subscription billed by the invoice
+func (i *InvoiceStripe) GetSubscriptionID() string {
+	if i.Subscription == nil {
+		return ""
+	}
+	return i.Subscription.ID
+}
+
+//GetPaymentIntentID : get the id of the payment intent that paid the invoice
+func (i *InvoiceStripe) GetPaymentIntentID() string {
+	if i.PaymentIntent == nil {
+		return ""
+	}
+	return i.PaymentIntent.ID
+}
+
+//GetMembershipID : get the id of the membership from the subscription line, which carries the subscription metadata
+func (i *InvoiceStripe) GetMembershipID() string {
+	if i.Lines == nil {
+		return ""
+	}
+	for _, line := range i.Lines.Data {
+		if line.Type == stripe.InvoiceLineTypeSubscription {
+			return line.Metadata[StripeMetadataMembershipID]
+		}
+	}
+	return ""
+}
+
+//GetPeriod : get the subscription period billed by the invoice
+func (i *InvoiceStripe) GetPeriod() (time.Time, time.Time, bool) {
+	if i.Lines == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	for _, line := range i.Lines.Data {
+		if line.Type == stripe.InvoiceLineTypeSubscription && line.Period != nil {
+			return time.Unix(line.Period.Start, 0), time.Unix(line.Period.End, 0), true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+//SubscriptionStripe : wrapper for a Stripe subscription
+type SubscriptionStripe struct {
+	*stripe.Subscription
+}
+
 //EventStripe : wrapper for a Stripe event
 type EventStripe struct {
 	*stripe.Event
@@ -167,7 +248,7 @@ func RevokeOAuthTokenStripe(ctx context.Context, token *TokenStripe) error {
 }
 
 //CreateSessionStripe : create a Stripe checkout session
-func CreateSessionStripe(ctx context.Context, token *TokenStripe, providerName string, desc string, paymentID string, bookID string, amount int, url string) (*SessionStripe, error) {
+func CreateSessionStripe(ctx context.Context, token *TokenStripe, providerName string, desc string, paymentID string, bookID string, currency Currency, amount int, url string) (*SessionStripe, error) {
 	ctx, logger := GetLogger(ctx)
 	start := time.Now()
 	defer func() {
@@ -193,7 +274,7 @@ func CreateSessionStripe(ctx context.Context, token *TokenStripe, providerName s
 		ClientReferenceID: stripe.String(paymentID),
 		SuccessURL:        stripe.String(urlSuccess),
 		CancelURL:         stripe.String(url),
-		Mode:              stripe.String("payment"),
+		Mode:              stripe.String(StripeModePayment),
 		SubmitType:        stripe.String("pay"),
 		PaymentMethodTypes: stripe.StringSlice([]string{
 			"card",
@@ -201,7 +282,7 @@ func CreateSessionStripe(ctx context.Context, token *TokenStripe, providerName s
 		LineItems: []*stripe.CheckoutSessionLineItemParams{
 			{
 				Amount:      stripe.Int64(int64(amount)),
-				Currency:    stripe.String(string(stripe.CurrencyUSD)),
+				Currency:    stripe.String(currency.CodeStripe()),
 				Quantity:    stripe.Int64(1),
 				Name:        stripe.String(providerName),
 				Description: stripe.String(desc),
@@ -227,13 +308,299 @@ func CreateSessionStripe(ctx context.Context, token *TokenStripe, providerName s
 	return session, nil
 }
 
+//ExpireSessionStripe : expire a Stripe checkout session so that it can no longer be paid
+func ExpireSessionStripe(ctx context.Context, token *TokenStripe, sessionID string) error {
+	ctx, logger := GetLogger(ctx)
+	start := time.Now()
+	defer func() {
+		logger.Debugw("stripe expire session", "elapsedMS", FormatElapsedMS(start))
+		AddCtxStatsAPI(ctx, ServerStatAPIStripe, "stripe expire session", time.Since(start))
+	}()
+
+	//get the stripe user id
+	params := &stripe.CheckoutSessionParams{}
+	if token != nil {
+		stripeUserID, err := token.GetStripeUserID()
+		if err != nil {
+			return errors.Wrap(err, "stripe get user id")
+		}
+		params.SetStripeAccount(stripeUserID)
+	}
+
+	//expire the session, which the client library does not support
+	path := stripe.FormatURLPath("/v1/checkout/sessions/%s/expire", sessionID)
+	err := stripe.GetBackend(stripe.APIBackend).Call(http.MethodPost, path, stripe.Key, params, &stripe.CheckoutSession{})
+	if err != nil {
+		return errors.Wrap(err, "stripe expire session")
+	}
+	return nil
+}
+
+//CreatePlanStripe : create a Stripe plan billed monthly, along with its product, used for subscriptions
+func CreatePlanStripe(ctx context.Context, token *TokenStripe, name string, membershipPlanID string, currency Currency, amount int) (string, error) {
+	ctx, logger := GetLogger(ctx)
+	start := time.Now()
+	defer func() {
+		logger.Debugw("stripe create plan", "elapsedMS", FormatElapsedMS(start))
+		AddCtxStatsAPI(ctx, ServerStatAPIStripe, "stripe create plan", time.Since(start))
+	}()
+
+	//create the plan
+	params := &stripe.PlanParams{
+		Amount:   stripe.Int64(int64(amount)),
+		Currency: stripe.String(currency.CodeStripe()),
+		Interval: stripe.String(StripePlanIntervalMonth),
+		Product: &stripe.PlanProductParams{
+			Name: stripe.String(name),
+		},
+	}
+	params.AddMetadata("membershipPlanId", membershipPlanID)
+	stripeUserID, err := token.GetStripeUserID()
+	if err != nil {
+		return "", errors.Wrap(err, "stripe get user id")
+	}
+	params.SetStripeAccount(stripeUserID)
+	result, err := plan.New(params)
+	if err != nil {
+		return "", errors.Wrap(err, "stripe create plan")
+	}
+	return result.ID, nil
+}
+
+//CreateSessionSubscriptionStripe : create a Stripe checkout session to subscribe a customer to a plan
+func CreateSessionSubscriptionStripe(ctx context.Context, token *TokenStripe, customerID string, planID string, membershipID string, url string) (*SessionStripe, error) {
+	ctx, logger := GetLogger(ctx)
+	start := time.Now()
+	defer func() {
+		logger.Debugw("stripe create subscription session", "elapsedMS", FormatElapsedMS(start))
+		AddCtxStatsAPI(ctx, ServerStatAPIStripe, "stripe create subscription session", time.Since(start))
+	}()
+
+	//ensure the session id is returned on success
+	var err error
+	url, err = CreateURLAbs(ctx, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "create url")
+	}
+	var urlSuccess string
+	if strings.Contains(url, "?") {
+		urlSuccess = fmt.Sprintf("%s&%s=%s", url, URLParams.StripeID, StripeURLParamSessionID)
+	} else {
+		urlSuccess = fmt.Sprintf("%s?%s=%s", url, URLParams.StripeID, StripeURLParamSessionID)
+	}
+
+	//prepare a session for the subscription, tagging it with the membership to match the invoices
+	subData := &stripe.CheckoutSessionSubscriptionDataParams{
+		Items: []*stripe.CheckoutSessionSubscriptionDataItemsParams{
+			{
+				Plan:     stripe.String(planID),
+				Quantity: stripe.Int64(1),
+			},
+		},
+	}
+	subData.AddMetadata(StripeMetadataMembershipID, membershipID)
+	params := &stripe.CheckoutSessionParams{
+		ClientReferenceID: stripe.String(membershipID),
+		Customer:          stripe.String(customerID),
+		SuccessURL:        stripe.String(urlSuccess),
+		CancelURL:         stripe.String(url),
+		Mode:              stripe.String(StripeModeSubscription),
+		PaymentMethodTypes: stripe.StringSlice([]string{
+			"card",
+		}),
+		SubscriptionData: subData,
+	}
+	stripeUserID, err := token.GetStripeUserID()
+	if err != nil {
+		return nil, errors.Wrap(err, "stripe get user id")
+	}
+	params.SetStripeAccount(stripeUserID)
+
+	//create the session
+	result, err := session.New(params)
+	if err != nil {
+		return nil, errors.Wrap(err, "stripe create subscription session")
+	}
+	session := &SessionStripe{result}
+	return session, nil
+}
+
+//CancelSubscriptionStripe : cancel a Stripe subscription immediately
+func CancelSubscriptionStripe(ctx context.Context, token *TokenStripe, subscriptionID string) error {
+	ctx, logger := GetLogger(ctx)
+	start := time.Now()
+	defer func() {
+		logger.Debugw("stripe cancel subscription", "elapsedMS", FormatElapsedMS(start))
+		AddCtxStatsAPI(ctx, ServerStatAPIStripe, "stripe cancel subscription", time.Since(start))
+	}()
+
+	//cancel the subscription
+	params := &stripe.SubscriptionCancelParams{}
+	stripeUserID, err := token.GetStripeUserID()
+	if err != nil {
+		return errors.Wrap(err, "stripe get user id")
+	}
+	params.SetStripeAccount(stripeUserID)
+	_, err = sub.Cancel(subscriptionID, params)
+	if err != nil {
+		return errors.Wrap(err, "stripe cancel subscription")
+	}
+	return nil
+}
+
+//CreateCustomerStripe : create a Stripe customer used to save a card
+func CreateCustomerStripe(ctx context.Context, token *TokenStripe, email string, name string, clientID string) (string, error) {
+	ctx, logger := GetLogger(ctx)
+	start := time.Now()
+	defer func() {
+		logger.Debugw("stripe create customer", "elapsedMS", FormatElapsedMS(start))
+		AddCtxStatsAPI(ctx, ServerStatAPIStripe, "stripe create customer", time.Since(start))
+	}()
+
+	//create the customer
+	params := &stripe.CustomerParams{
+		Email: stripe.String(email),
+		Name:  stripe.String(name),
+	}
+	params.AddMetadata("clientId", clientID)
+	if token != nil {
+		stripeUserID, err := token.GetStripeUserID()
+		if err != nil {
+			return "", errors.Wrap(err, "stripe get user id")
+		}
+		params.SetStripeAccount(stripeUserID)
+	}
+	result, err := customer.New(params)
+	if err != nil {
+		return "", errors.Wrap(err, "stripe create customer")
+	}
+	return result.ID, nil
+}
+
+//CreateSessionSetupStripe : create a Stripe checkout session to save a card for a customer using a setup intent
+func CreateSessionSetupStripe(ctx context.Context, token *TokenStripe, customerID string, clientID string, url string) (*SessionStripe, error) {
+	ctx, logger := GetLogger(ctx)
+	start := time.Now()
+	defer func() {
+		logger.Debugw("stripe create setup session", "elapsedMS", FormatElapsedMS(start))
+		AddCtxStatsAPI(ctx, ServerStatAPIStripe, "stripe create setup session", time.Since(start))
+	}()
+
+	//ensure the session id is returned on success
+	var err error
+	url, err = CreateURLAbs(ctx, url, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "create url")
+	}
+	var urlSuccess string
+	if strings.Contains(url, "?") {
+		urlSuccess = fmt.Sprintf("%s&%s=%s", url, URLParams.StripeID, StripeURLParamSessionID)
+	} else {
+		urlSuccess = fmt.Sprintf("%s?%s=%s", url, URLParams.StripeID, StripeURLParamSessionID)
+	}
+
+	//prepare a session for saving the card
+	params := &stripe.CheckoutSessionParams{
+		ClientReferenceID: stripe.String(clientID),
+		Customer:          stripe.String(customerID),
+		SuccessURL:        stripe.String(urlSuccess),
+		CancelURL:         stripe.String(url),
+		Mode:              stripe.String(StripeModeSetup),
+		PaymentMethodTypes: stripe.StringSlice([]string{
+			"card",
+		}),
+	}
+
+	//get the stripe user id
+	if token != nil {
+		stripeUserID, err := token.GetStripeUserID()
+		if err != nil {
+			return nil, errors.Wrap(err, "stripe get user id")
+		}
+		params.SetStripeAccount(stripeUserID)
+	}
+
+	//create the session
+	result, err := session.New(params)
+	if err != nil {
+		return nil, errors.Wrap(err, "stripe create setup session")
+	}
+	session := &SessionStripe{result}
+	return session, nil
+}
+
+//RetrievePaymentMethodStripe : retrieve the payment method saved by a Stripe checkout setup session
+func RetrievePaymentMethodStripe(ctx context.Context, token *TokenStripe, sessionID string) (*PaymentMethodStripe, error) {
+	ctx, logger := GetLogger(ctx)
+	start := time.Now()
+	defer func() {
+		logger.Debugw("stripe retrieve payment method", "elapsedMS", FormatElapsedMS(start))
+		AddCtxStatsAPI(ctx, ServerStatAPIStripe, "stripe retrieve payment method", time.Since(start))
+	}()
+
+	//load the session with the setup intent
+	params := &stripe.CheckoutSessionParams{}
+	params.AddExpand("setup_intent.payment_method")
+	if token != nil {
+		stripeUserID, err := token.GetStripeUserID()
+		if err != nil {
+			return nil, errors.Wrap(err, "stripe get user id")
+		}
+		params.SetStripeAccount(stripeUserID)
+	}
+	result, err := session.Get(sessionID, params)
+	if err != nil {
+		return nil, errors.Wrap(err, "stripe get session")
+	}
+	if result.SetupIntent == nil || result.SetupIntent.PaymentMethod == nil {
+		return nil, fmt.Errorf("no stripe payment method: %s", sessionID)
+	}
+	paymentMethod := &PaymentMethodStripe{result.SetupIntent.PaymentMethod}
+	return paymentMethod, nil
+}
+
+//CreatePaymentIntentStripe : create and confirm a Stripe payment intent charging a saved card without the customer present
+func CreatePaymentIntentStripe(ctx context.Context, token *TokenStripe, desc string, paymentID string, customerID string, paymentMethodID string, currency Currency, amount int) (*PaymentIntentStripe, error) {
+	ctx, logger := GetLogger(ctx)
+	start := time.Now()
+	defer func() {
+		logger.Debugw("stripe create payment intent", "elapsedMS", FormatElapsedMS(start))
+		AddCtxStatsAPI(ctx, ServerStatAPIStripe, "stripe create payment intent", time.Since(start))
+	}()
+
+	//create the payment intent
+	params := &stripe.PaymentIntentParams{
+		Amount:        stripe.Int64(int64(amount)),
+		Currency:      stripe.String(currency.CodeStripe()),
+		Customer:      stripe.String(customerID),
+		Description:   stripe.String(desc),
+		PaymentMethod: stripe.String(paymentMethodID),
+		Confirm:       stripe.Bool(true),
+		OffSession:    stripe.Bool(true),
+	}
+	params.AddMetadata("paymentId", paymentID)
+	if token != nil {
+		stripeUserID, err := token.GetStripeUserID()
+		if err != nil {
+			return nil, errors.Wrap(err, "stripe get user id")
+		}
+		params.SetStripeAccount(stripeUserID)
+	}
+	result, err := paymentintent.New(params)
+	if err != nil {
+		return nil, errors.Wrap(err, "stripe create payment intent")
+	}
+	intent := &PaymentIntentStripe{result}
+	return intent, nil
+}
+
 //ChargeStripe : Stripe charge
 type ChargeStripe struct {
 	*stripe.Charge
 }
 
 //CreateStripeCharge : create a Stripe direct charge
-func CreateStripeCharge(ctx context.Context, token *TokenStripe, providerName string, desc string, paymentID string, amount int, tokenSrc string) (*ChargeStripe, error) {
+func CreateStripeCharge(ctx context.Context, token *TokenStripe, providerName string, desc string, paymentID string, currency Currency, amount int, tokenSrc string) (*ChargeStripe, error) {
 	ctx, logger := GetLogger(ctx)
 	start := time.Now()
 	defer func() {
@@ -246,7 +613,7 @@ func CreateStripeCharge(ctx context.Context, token *TokenStripe, providerName st
 		StatementDescriptor: stripe.String(providerName),
 		Description:         stripe.String(desc),
 		Amount:              stripe.Int64(int64(amount)),
-		Currency:            stripe.String(string(stripe.CurrencyUSD)),
+		Currency:            stripe.String(currency.CodeStripe()),
 		Source: &stripe.SourceParams{
 			Token: stripe.String(tokenSrc),
 		},
@@ -269,6 +636,65 @@ func CreateStripeCharge(ctx context.Context, token *TokenStripe, providerName st
 	return charge, nil
 }
 
+//RefundStripe : wrapper for a Stripe refund
+type RefundStripe struct {
+	*stripe.Refund
+}
+
+//GetReason : get the reason for the refund, preferring the reason entered by the provider
+func (r *RefundStripe) GetReason() string {
+	reason, ok := r.Metadata[StripeRefundMetadataReason]
+	if ok {
+		return reason
+	}
+	return string(r.Reason)
+}
+
+//IsFailed : check if the refund was not made
+func (r *RefundStripe) IsFailed() bool {
+	return r.Status == stripe.RefundStatusFailed || r.Status == stripe.RefundStatusCanceled
+}
+
+//CreateRefundStripe : refund all or part of a Stripe payment intent or charge
+func CreateRefundStripe(ctx context.Context, token *TokenStripe, stripeID string, paymentID string, amount int, reason string) (*RefundStripe, error) {
+	ctx, logger := GetLogger(ctx)
+	start := time.Now()
+	defer func() {
+		logger.Debugw("stripe create refund", "elapsedMS", FormatElapsedMS(start))
+		AddCtxStatsAPI(ctx, ServerStatAPIStripe, "stripe create refund", time.Since(start))
+	}()
+
+	//create the refund
+	params := &stripe.RefundParams{
+		Amount: stripe.Int64(int64(amount)),
+	}
+	params.AddMetadata("paymentId", paymentID)
+	if reason != "" {
+		params.AddMetadata(StripeRefundMetadataReason, reason)
+	}
+
+	//payment intents are made directly on the connected account, whereas charges are transferred to it
+	if strings.HasPrefix(stripeID, StripePrefixPaymentIntent) {
+		params.PaymentIntent = stripe.String(stripeID)
+		if token != nil {
+			stripeUserID, err := token.GetStripeUserID()
+			if err != nil {
+				return nil, errors.Wrap(err, "stripe get user id")
+			}
+			params.SetStripeAccount(stripeUserID)
+		}
+	} else {
+		params.Charge = stripe.String(stripeID)
+		params.ReverseTransfer = stripe.Bool(token != nil)
+	}
+	result, err := refund.New(params)
+	if err != nil {
+		return nil, errors.Wrap(err, "stripe create refund")
+	}
+	refund := &RefundStripe{result}
+	return refund, nil
+}
+
 //VerifyWebHookSignatureStripe : verify the payload signature for a Stripe webhook event
 func VerifyWebHookSignatureStripe(w http.ResponseWriter, r *http.Request, secret string) (*EventStripe, string, error) {
 	ctx, logger := GetLogger(r.Context())
@@ -318,6 +744,50 @@ func ParsePaymentIntentStripe(in []byte) (*PaymentIntentStripe, error) {
 	return intent, nil
 }
 
+//ParseChargeStripe : parse a Stripe charge
+func ParseChargeStripe(in []byte) (*ChargeStripe, error) {
+	var result stripe.Charge
+	err := json.Unmarshal(in, &result)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse stripe charge")
+	}
+	charge := &ChargeStripe{&result}
+	return charge, nil
+}
+
+//ParseInvoiceStripe : parse a Stripe invoice
+func ParseInvoiceStripe(in []byte) (*InvoiceStripe, error) {
+	var result stripe.Invoice
+	err := json.Unmarshal(in, &result)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse stripe invoice")
+	}
+	invoice := &InvoiceStripe{&result}
+	return invoice, nil
+}
+
+//ParseSubscriptionStripe : parse a Stripe subscription
+func ParseSubscriptionStripe(in []byte) (*SubscriptionStripe, error) {
+	var result stripe.Subscription
+	err := json.Unmarshal(in, &result)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse stripe subscription")
+	}
+	subscription := &SubscriptionStripe{&result}
+	return subscription, nil
+}
+
+//ParsePaymentMethodStripe : parse a Stripe payment method
+func ParsePaymentMethodStripe(in []byte) (*PaymentMethodStripe, error) {
+	var result stripe.PaymentMethod
+	err := json.Unmarshal(in, &result)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse stripe payment method")
+	}
+	paymentMethod := &PaymentMethodStripe{&result}
+	return paymentMethod, nil
+}
+
 //SaveEventStripe : save a Stripe event
 func SaveEventStripe(ctx context.Context, db *DB, event *EventStripe) (context.Context, error) {
 	//json encode the message data
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gofrs/uuid"
+)
+
+//TaxRate : sales tax rate for a region, such as a state or zip code
+type TaxRate struct {
+	ID     *uuid.UUID `json:"ID"`
+	Name   string     `json:"Name"`
+	Region string     `json:"Region"` //empty for the default rate
+	Rate   float32    `json:"Rate"`   //percent
+}
+
+//IsDefault : check if the rate applies to all locations without a matching region
+func (t *TaxRate) IsDefault() bool {
+	return t.Region == ""
+}
+
+//IsZipCode : check if the region is a zip code
+func (t *TaxRate) IsZipCode() bool {
+	if t.Region == "" {
+		return false
+	}
+	for _, r := range t.Region {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+//IsMatch : check if the region appears in the location as a whole word, ignoring case and punctuation
+func (t *TaxRate) IsMatch(location string) bool {
+	if t.IsDefault() {
+		return false
+	}
+	return strings.Contains(normalizeTaxLocation(location), normalizeTaxLocation(t.Region))
+}
+
+//FormatRegion : format the region
+func (t *TaxRate) FormatRegion() string {
+	if t.IsDefault() {
+		return "All other locations"
+	}
+	return t.Region
+}
+
+//FormatRate : format the rate
+func (t *TaxRate) FormatRate() string {
+	return fmt.Sprintf("%s%%", FormatRate(t.Rate))
+}
+
+//normalize a location into space-delimited upper-case words for matching
+func normalizeTaxLocation(location string) string {
+	words := strings.FieldsFunc(strings.ToUpper(location), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return fmt.Sprintf(" %s ", strings.Join(words, " "))
+}
+
+//TaxPeriodType : type of period for reporting tax
+type TaxPeriodType string
+
+//tax period types
+const (
+	TaxPeriodTypeMonthly   TaxPeriodType = "Monthly"
+	TaxPeriodTypeQuarterly TaxPeriodType = "Quarterly"
+	TaxPeriodTypeAnnually  TaxPeriodType = "Annually"
+)
+
+//TaxPeriodTypes : tax period types
+var TaxPeriodTypes = []TaxPeriodType{
+	TaxPeriodTypeMonthly,
+	TaxPeriodTypeQuarterly,
+	TaxPeriodTypeAnnually,
+}
+
+//ParseTaxPeriodType : parse a tax period type, defaulting to monthly
+func ParseTaxPeriodType(in string) TaxPeriodType {
+	for _, periodType := range TaxPeriodTypes {
+		if string(periodType) == in {
+			return periodType
+		}
+	}
+	return TaxPeriodTypeMonthly
+}
+
+//GetStart : get the start of the period containing the given time
+func (t TaxPeriodType) GetStart(in time.Time) time.Time {
+	y, m, _ := in.Date()
+	switch t {
+	case TaxPeriodTypeQuarterly:
+		m = m - ((m - 1) % 3)
+	case TaxPeriodTypeAnnually:
+		m = time.January
+	}
+	return time.Date(y, m, 1, 0, 0, 0, 0, in.Location())
+}
+
+//Format : format the period starting at the given time
+func (t TaxPeriodType) Format(start time.Time) string {
+	switch t {
+	case TaxPeriodTypeQuarterly:
+		return fmt.Sprintf("Q%d %d", (int(start.Month())-1)/3+1, start.Year())
+	case TaxPeriodTypeAnnually:
+		return fmt.Sprintf("%d", start.Year())
+	}
+	return start.Format(layoutMonthLong)
+}
+
+//TaxReportPeriod : sales, tax and tips collected during a period, net of refunds
+type TaxReportPeriod struct {
+	Label       string
+	Start       time.Time
+	Currency    Currency
+	Count       int
+	AmountSales int //non-decimal, excluding tax and tips
+	AmountTax   int //non-decimal
+	AmountTips  int //non-decimal
+}
+
+//FormatAmountSales : format the sales
+func (t *TaxReportPeriod) FormatAmountSales() string {
+	return t.Currency.FormatAmountMinorUnits(t.AmountSales)
+}
+
+//FormatAmountTax : format the tax collected
+func (t *TaxReportPeriod) FormatAmountTax() string {
+	return t.Currency.FormatAmountMinorUnits(t.AmountTax)
+}
+
+//FormatAmountTips : format the tips collected
+func (t *TaxReportPeriod) FormatAmountTips() string {
+	return t.Currency.FormatAmountMinorUnits(t.AmountTips)
+}
+
+//CreateTaxReport : summarize the sales, tax and tips collected by period for captured payments in the currency, including a total
+func CreateTaxReport(payments []*Payment, periodType TaxPeriodType, currency Currency, timeZone string) ([]*TaxReportPeriod, *TaxReportPeriod) {
+	loc := GetLocation(timeZone)
+	periods := make([]*TaxReportPeriod, 0, 12)
+	total := &TaxReportPeriod{
+		Label:    "Total",
+		Currency: currency,
+	}
+	for _, payment := range payments {
+		if payment.Captured == nil || payment.GetCurrency() != currency {
+			continue
+		}
+
+		//find the period
+		start := periodType.GetStart(payment.Captured.In(loc))
+		var period *TaxReportPeriod
+		for _, existing := range periods {
+			if existing.Start.Equal(start) {
+				period = existing
+				break
+			}
+		}
+		if period == nil {
+			period = &TaxReportPeriod{
+				Label:    periodType.Format(start),
+				Start:    start,
+				Currency: currency,
+			}
+			periods = append(periods, period)
+		}
+
+		//reduce the tax and tips proportionally to any refunds
+		amountNet := payment.Amount - payment.ComputeAmountRefunded()
+		amountTax := computeAmountNetOfRefunds(payment, payment.ComputeAmountTax())
+		amountTips := computeAmountNetOfRefunds(payment, payment.ComputeAmountTip())
+		for _, p := range []*TaxReportPeriod{period, total} {
+			p.Count++
+			p.AmountSales += amountNet - amountTax - amountTips
+			p.AmountTax += amountTax
+			p.AmountTips += amountTips
+		}
+	}
+
+	//sort by the start of the period
+	sort.SliceStable(periods, func(i int, j int) bool {
+		return periods[i].Start.Before(periods[j].Start)
+	})
+	return periods, total
+}
+
+//reduce a portion of the payment proportionally to any refunds
+func computeAmountNetOfRefunds(payment *Payment, amount int) int {
+	amountNet := payment.Amount - payment.ComputeAmountRefunded()
+	if payment.Amount > 0 && amountNet < payment.Amount {
+		return int(math.Round(float64(amount) * float64(amountNet) / float64(payment.Amount)))
+	}
+	return amount
+}
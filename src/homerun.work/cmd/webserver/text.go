@@ -13,6 +13,9 @@ const (
 	MsgBookingCancel         MsgKey = "bookingCancel"
 	MsgBookingNewSingle      MsgKey = "bookingNewSingle"
 	MsgBookingNewMultiple    MsgKey = "bookingNewMultiple"
+	MsgBookingReschedule     MsgKey = "bookingReschedule"
+	MsgCardCharged           MsgKey = "cardCharged"
+	MsgCardSaved             MsgKey = "cardSaved"
 	MsgClientAdd             MsgKey = "clientAdd"
 	MsgClientDel             MsgKey = "clientDel"
 	MsgClientDelConfirm      MsgKey = "clientDelConfirm"
@@ -31,16 +34,33 @@ const (
 	MsgFaqDelConfirm         MsgKey = "faqDelConfirm"
 	MsgFaqEdit               MsgKey = "faqEdit"
 	MsgForgotPwd             MsgKey = "fogotPwd"
+	MsgGiftCardRedeem        MsgKey = "giftCardRedeem"
+	MsgMembershipAdd         MsgKey = "membershipAdd"
+	MsgMembershipCancel      MsgKey = "membershipCancel"
+	MsgMembershipDel         MsgKey = "membershipDel"
+	MsgMembershipDelConfirm  MsgKey = "membershipDelConfirm"
+	MsgMembershipEdit        MsgKey = "membershipEdit"
+	MsgMembershipJoin        MsgKey = "membershipJoin"
 	MsgMetaDesc              MsgKey = "metaDesc"
 	MsgMetaKeywords          MsgKey = "metaKeywords"
+	MsgPackageAdd            MsgKey = "packageAdd"
+	MsgPackageDel            MsgKey = "packageDel"
+	MsgPackageDelConfirm     MsgKey = "packageDelConfirm"
+	MsgPackageEdit           MsgKey = "packageEdit"
 	MsgPageTitle             MsgKey = "pageTitle"
 	MsgPaymentMarkPaid       MsgKey = "paymentMarkPaid"
 	MsgPaymentMarkUnPaid     MsgKey = "paymentMarkUnPaid"
+	MsgPaymentRefund         MsgKey = "paymentRefund"
 	MsgPaymentClientSuccess  MsgKey = "paymentClientSuccess"
+	MsgPaymentExpiredRefund  MsgKey = "paymentExpiredRefund"
 	MsgPaymentSuccess        MsgKey = "paymentSuccess"
 	MsgPayPalActivate        MsgKey = "paypalActivate"
 	MsgPayPalRemove          MsgKey = "paypalRemove"
 	MsgPwdReset              MsgKey = "passwordReset"
+	MsgSeasonAdd             MsgKey = "seasonAdd"
+	MsgSeasonDel             MsgKey = "seasonDel"
+	MsgSeasonDelConfirm      MsgKey = "seasonDelConfirm"
+	MsgSeatsAvailable        MsgKey = "seatsAvailable"
 	MsgSignUpEmailErr        MsgKey = "signUpEmailErr"
 	MsgSignUpSuccess         MsgKey = "signUpSuccess"
 	MsgStripeActivate        MsgKey = "stripeActivate"
@@ -48,16 +68,25 @@ const (
 	MsgStripeSuccess         MsgKey = "stripeSuccess"
 	MsgSvcAdd                MsgKey = "svcAdd"
 	MsgSvcDelConfirm         MsgKey = "svcDelConfirm"
+	MsgTaxRateAdd            MsgKey = "taxRateAdd"
+	MsgTaxRateDel            MsgKey = "taxRateDel"
+	MsgTaxRateDelConfirm     MsgKey = "taxRateDelConfirm"
 	MsgTestimonialAdd        MsgKey = "testimonialAdd"
 	MsgTestimonialDel        MsgKey = "testimonialDel"
 	MsgTestimonialDelConfirm MsgKey = "testimonialDelConfirm"
 	MsgTestimonialEdit       MsgKey = "testimonialEdit"
+	MsgTimeOffAdd            MsgKey = "timeOffAdd"
+	MsgTimeOffDel            MsgKey = "timeOffDel"
+	MsgTimeOffDelConfirm     MsgKey = "timeOffDelConfirm"
+	MsgTipAdd                MsgKey = "tipAdd"
+	MsgTipDel                MsgKey = "tipDel"
 	MsgUnavailable           MsgKey = "unavailable"
 	MsgUpdateSuccess         MsgKey = "updateSuccess"
 	MsgUserAdd               MsgKey = "userAdd"
 	MsgUserAddNew            MsgKey = "userAddNew"
 	MsgUserDel               MsgKey = "userDel"
 	MsgUserDelConfirm        MsgKey = "userDelConfirm"
+	MsgWaitlistAdd           MsgKey = "waitlistAdd"
 	MsgZelleActivate         MsgKey = "zelleActivate"
 	MsgZelleRemove           MsgKey = "zelleRemove"
 	MsgZoomSuccess           MsgKey = "zoomSuccess"
@@ -68,6 +97,9 @@ var msgText = map[MsgKey]string{
 	MsgBookingCancel:         "Service order has been cancelled.",
 	MsgBookingNewSingle:      "You have a new order.",
 	MsgBookingNewMultiple:    "You have %d new orders.",
+	MsgBookingReschedule:     "Your order has been rescheduled.",
+	MsgCardCharged:           "The card on file has been charged.",
+	MsgCardSaved:             "Your card has been saved.",
 	MsgClientAdd:             "%s has been added.",
 	MsgClientDel:             "%s has been deleted.",
 	MsgClientDelConfirm:      "Are you sure you want to delete the client?",
@@ -86,16 +118,33 @@ var msgText = map[MsgKey]string{
 	MsgFaqDelConfirm:         "Are you sure you want to delete the FAQ?",
 	MsgFaqEdit:               "FAQ has been updated.",
 	MsgForgotPwd:             "An email to reset your password has been sent to %s. Please check your email and follow the steps to reset your password.",
+	MsgGiftCardRedeem:        "The gift card has been applied to the invoice.",
+	MsgMembershipAdd:         "Membership has been added.",
+	MsgMembershipCancel:      "Membership has been cancelled.",
+	MsgMembershipDel:         "Membership has been deleted.",
+	MsgMembershipDelConfirm:  "Are you sure you want to delete the membership? Existing members continue to be charged until cancelled.",
+	MsgMembershipEdit:        "Membership has been updated.",
+	MsgMembershipJoin:        "Thank you for subscribing. The membership becomes active once the first payment is confirmed.",
 	MsgMetaDesc:              "HomeRun helps service professionals manage their service schedules, orders, invoices and payments in one place. It provides the essential tools to run service business without paying commissions.",
 	MsgMetaKeywords:          "Service, professional, independent, self-employed, home-based, freelancer, worker, business, local, online, client, appointment, schedule, order, website builder, on-demand, invoice, payment, remote, live meeting, zoom, management, all-in-one, platform, marketing, designer, consultant, landscaper, trainer, teacher, tutor, handyman, repair, cleaning, cleaner, caretaker, caregiver, gardener, babysitter, nurse, specialist, developer, marketer, locksmith, roofer, artist, translator, assistant, copywriter, doctor, therapist, storyteller, musician, accountant, expert, agent, broker, carpenter, driver, delivery, manager, dietitian, hygienist, hairdresser, hair stylist, instructor, administrator, planner, maker, cook, chef, contractor, actor, entertainer, lawyer, support, technician, engineer, narrator, writer, photographer, producer, composer, pianist, singer, model, painter, carpenter, electrician, beautician, manicures, manicurist, adviser, florist, bookkeeper, strategist, seamstress, connoisseur, sommelier, blogger, tailor, buyer, builder, paralegal, coach, concierge, shopper, guide, caterer, mechanic, editor, architect, printer, plumber, massager, attorney, auditor, assessor, interpreter, veterinarian, nutritionist, courier",
+	MsgPackageAdd:            "Package has been added.",
+	MsgPackageDel:            "Package has been deleted.",
+	MsgPackageDelConfirm:     "Are you sure you want to delete the package?",
+	MsgPackageEdit:           "Package has been updated.",
 	MsgPageTitle:             "Online scheduling, invoices and payment tools for service professionals",
 	MsgPaymentMarkPaid:       "Are you sure you want to mark the order as paid?",
 	MsgPaymentMarkUnPaid:     "Are you sure you want to mark the order as unpaid?",
+	MsgPaymentRefund:         "The refund has been issued.",
 	MsgPaymentClientSuccess:  "Your payment has been submitted.",
+	MsgPaymentExpiredRefund:  "The booking was cancelled because the payment was not made in time.",
 	MsgPaymentSuccess:        "The invoice has been sent to the recipient for payment.",
 	MsgPayPalActivate:        "Are you sure you want to activate PayPal?",
 	MsgPayPalRemove:          "Are you sure you want to deactivate PayPal?",
 	MsgPwdReset:              "Password has been reset.",
+	MsgSeasonAdd:             "Seasonal schedule has been added.",
+	MsgSeasonDel:             "Seasonal schedule has been deleted.",
+	MsgSeasonDelConfirm:      "Are you sure you want to delete the seasonal schedule?",
+	MsgSeatsAvailable:        "%d seat(s) left",
 	MsgSignUpEmailErr:        "Your account has been created, but a confirmation email could not be sent to %s. Please make sure to verify your email later.",
 	MsgSignUpSuccess:         "Your account has been created, and a confirmation email has been sent to %s. Please check your email and follow the steps in the confirmation email to confirm your account.",
 	MsgStripeActivate:        "Are you sure you want to activate Stripe?",
@@ -103,16 +152,25 @@ var msgText = map[MsgKey]string{
 	MsgStripeSuccess:         "Your Stripe account has been activated.",
 	MsgSvcAdd:                "The service is added and now available for ordering on your web page. To support direct ordering, you can get the order URL by editing the service.",
 	MsgSvcDelConfirm:         "Are you sure you want to delete the service?",
+	MsgTaxRateAdd:            "Tax rate has been added.",
+	MsgTaxRateDel:            "Tax rate has been deleted.",
+	MsgTaxRateDelConfirm:     "Are you sure you want to delete the tax rate?",
 	MsgTestimonialAdd:        "Testimonial by %s has been added.",
 	MsgTestimonialDel:        "Testimonial by %s has been deleted.",
 	MsgTestimonialDelConfirm: "Are you sure you want to delete the testimonial?",
 	MsgTestimonialEdit:       "Testimonial by %s has been updated.",
+	MsgTimeOffAdd:            "Time off has been added.",
+	MsgTimeOffDel:            "Time off has been deleted.",
+	MsgTimeOffDelConfirm:     "Are you sure you want to delete the time off?",
+	MsgTipAdd:                "Thank you! The tip has been added to the invoice.",
+	MsgTipDel:                "The tip has been removed from the invoice.",
 	MsgUnavailable:           "Unavailable",
 	MsgUpdateSuccess:         "Your changes have been saved successfully.",
 	MsgUserAdd:               "%s has been added and notified by email.",
 	MsgUserAddNew:            "%s has been added and notified by email. The user needs to follow the instructions in the email to register and complete the setup.",
 	MsgUserDel:               "%s has been deleted.",
 	MsgUserDelConfirm:        "Are you sure you want to delete the user?",
+	MsgWaitlistAdd:           "You have been added to the waitlist. We will notify you when a time opens up.",
 	MsgZelleActivate:         "Are you sure you want to activate Zelle?",
 	MsgZelleRemove:           "Are you sure you want to deactivate Zelle?",
 	MsgZoomSuccess:           "Your Zoom account has been activated.",
@@ -150,12 +208,18 @@ type ErrKey string
 //error keys
 const (
 	Err                    ErrKey = "error"
+	ErrBookingCutoff       ErrKey = "bookingCutoff"
 	ErrBookingExist        ErrKey = "bookingExist"
+	ErrBookingFull         ErrKey = "bookingFull"
 	ErrBookingTime         ErrKey = "bookingTime"
+	ErrCardCharge          ErrKey = "cardCharge"
 	ErrClientEmailDup      ErrKey = "clientEmailDup"
+	ErrDepositUnpaid       ErrKey = "depositUnpaid"
 	ErrClientInvite        ErrKey = "clientInvite"
 	ErrCouponCodeDup       ErrKey = "couponCodeDup"
 	ErrCredentials         ErrKey = "credentials"
+	ErrCurrencyConfirm     ErrKey = "currencyConfirm"
+	ErrCurrencyUnPaid      ErrKey = "currencyUnPaid"
 	ErrDomainDup           ErrKey = "domainDup"
 	ErrEmailDelete         ErrKey = "emailDelete"
 	ErrEmailDup            ErrKey = "emailDup"
@@ -164,7 +228,11 @@ const (
 	ErrEmailVerify         ErrKey = "emailVerify"
 	ErrEmailVerifyEmail    ErrKey = "emailVerifyEmail"
 	ErrEmailVerifyToken    ErrKey = "emailVerifyToken"
+	ErrGiftCardAttempts    ErrKey = "giftCardAttempts"
+	ErrGiftCardInvalid     ErrKey = "giftCardInvalid"
+	ErrGiftCardRefund      ErrKey = "giftCardRefund"
 	ErrInvoicePaid         ErrKey = "invoicePaid"
+	ErrMembershipStripe    ErrKey = "membershipStripe"
 	ErrOAuthFacebook       ErrKey = "oauthFacebook"
 	ErrOAuthFacebookEmail  ErrKey = "oauthFacebookEmail"
 	ErrOAuthFacebookSignUp ErrKey = "oauthFacebookSignUp"
@@ -176,20 +244,30 @@ const (
 	ErrID                  ErrKey = "id"
 	ErrPayPalEmail         ErrKey = "paypalEmail"
 	ErrPwdResetToken       ErrKey = "resetPasswordToken"
+	ErrRefund              ErrKey = "refund"
+	ErrRefundAmount        ErrKey = "refundAmount"
+	ErrSeasonOverlap       ErrKey = "seasonOverlap"
 	ErrSvcExist            ErrKey = "svcExist"
 	ErrSvcImgCount         ErrKey = "svcImgCount"
 	ErrURLNameDup          ErrKey = "urlNameDup"
+	ErrWaitlistOffer       ErrKey = "waitlistOffer"
 )
 
 //errors
 var errText = map[ErrKey]string{
 	Err:                    "We are experiencing technical difficulties. Please try again.",
+	ErrBookingCutoff:       "Unfortunately, the order can no longer be changed online. Please contact us directly.",
 	ErrBookingExist:        "The client cannot be deleted due to having %d booking(s).",
+	ErrBookingFull:         "Unfortunately, the selected time is fully booked. Please try another time.",
 	ErrBookingTime:         "Unforutanely, the selected time is already taken. Please try again.",
+	ErrCardCharge:          "The card on file could not be charged. Please send an invoice instead.",
 	ErrClientEmailDup:      "Client email already exists.",
+	ErrDepositUnpaid:       "The order cannot be confirmed until the deposit has been paid.",
 	ErrClientInvite:        "We have encountered an error sending the invitation. Please try again.",
 	ErrCouponCodeDup:       "Coupon code already exists.",
 	ErrCredentials:         "Please enter a valid email address and password.",
+	ErrCurrencyConfirm:     "Existing prices for %d services, coupons, packages, plans, and gift cards are not converted. Please confirm the currency change.",
+	ErrCurrencyUnPaid:      "The currency cannot be changed while %d orders are unpaid.",
 	ErrDomainDup:           "The domain already exists. Please use a different domain.",
 	ErrEmailDelete:         "Email address does not have an account.",
 	ErrEmailDup:            "Email address has already been registered. Please enter another email address.",
@@ -198,8 +276,12 @@ var errText = map[ErrKey]string{
 	ErrEmailVerify:         "We encountered technical difficulties when sending a verification email. Please try again later.",
 	ErrEmailVerifyEmail:    "A confirmation email could not be sent to %s. Please make sure to verify your email later.",
 	ErrEmailVerifyToken:    "Your email verification is no longer valid. Please try again.",
+	ErrGiftCardAttempts:    "Too many invalid gift card codes have been entered. Please try again later.",
+	ErrGiftCardInvalid:     "Gift card code is not valid or has no remaining balance.",
+	ErrGiftCardRefund:      "The refund cannot exceed the remaining gift card balance of %s.",
 	ErrID:                  "We have encountered technical difficulties. Please try again.",
 	ErrInvoicePaid:         "Invoice has already been paid.",
+	ErrMembershipStripe:    "Memberships are billed through Stripe. Please connect a Stripe account in the payment settings.",
 	ErrOAuthFacebook:       "We have encountered an error logging-in with Facebook. Please try again.",
 	ErrOAuthFacebookEmail:  "Please login using Facebook.",
 	ErrOAuthFacebookSignUp: "Please sign up using your Facebook account. Thanks.",
@@ -210,9 +292,13 @@ var errText = map[ErrKey]string{
 	ErrOAuthZoom:           "We have encountered an error logging-in with Zoom. Please try again.",
 	ErrPayPalEmail:         "Please use a valid PayPal email address.",
 	ErrPwdResetToken:       "Your reset password request is no longer valid. Please try again.",
+	ErrRefund:              "The refund could not be issued. Please try again.",
+	ErrRefundAmount:        "The refund cannot be more than the amount paid, less any previous refunds.",
+	ErrSeasonOverlap:       "The dates overlap another seasonal schedule. Please choose different dates.",
 	ErrSvcExist:            "The service cannot be deleted due to having %d booking(s).",
 	ErrSvcImgCount:         "You have too many images for your service. The maximum number of images allowed is %d.",
 	ErrURLNameDup:          "The name already exists. Please use a different name.",
+	ErrWaitlistOffer:       "Unfortunately, the offer is no longer available. You remain on the waitlist.",
 }
 
 //GetErrOAuth : returns the appropraite OAuth error
@@ -252,25 +338,49 @@ const (
 	FieldErrAnswer             fieldErrKey = "Answer"
 	FieldErrBiography          fieldErrKey = "Biography"
 	FieldErrBudget             fieldErrKey = "Budget"
+	FieldErrCancelCutoff       fieldErrKey = "CancelCutoff"
+	FieldErrCancelCutoffUnit   fieldErrKey = "CancelCutoffUnit"
+	FieldErrCancelFee          fieldErrKey = "CancelFee"
+	FieldErrCancelFeeType      fieldErrKey = "CancelFeeType"
+	FieldErrCancelFeeWindow    fieldErrKey = "CancelFeeWindow"
+	FieldErrCapacity           fieldErrKey = "Capacity"
 	FieldErrClientID           fieldErrKey = "ClientID"
 	FieldErrCode               fieldErrKey = "Code"
+	FieldErrCurrency           fieldErrKey = "Currency"
 	FieldErrDate               fieldErrKey = "Date"
+	FieldErrDeposit            fieldErrKey = "Deposit"
+	FieldErrDepositType        fieldErrKey = "DepositType"
 	FieldErrDesc               fieldErrKey = "Description"
 	FieldErrDomain             fieldErrKey = "Domain"
 	FieldErrDuration           fieldErrKey = "Duration"
 	FieldErrEducation          fieldErrKey = "Education"
 	FieldErrExperience         fieldErrKey = "Experience"
+	FieldErrExpiration         fieldErrKey = "Expiration"
 	FieldErrEmail              fieldErrKey = "Email"
 	FieldErrEnd                fieldErrKey = "End"
 	FieldErrFreq               fieldErrKey = "Freq"
+	FieldErrFreqCount          fieldErrKey = "FreqCount"
+	FieldErrFreqDays           fieldErrKey = "FreqDays"
+	FieldErrFreqInterval       fieldErrKey = "FreqInterval"
+	FieldErrFreqUntil          fieldErrKey = "FreqUntil"
 	FieldErrFirstName          fieldErrKey = "FirstName"
+	FieldErrFirstBookings      fieldErrKey = "FirstBookings"
 	FieldErrGender             fieldErrKey = "Gender"
+	FieldErrHorizon            fieldErrKey = "Horizon"
 	FieldErrID                 fieldErrKey = "ID"
 	FieldErrImg                fieldErrKey = "Img"
+	FieldErrItemDesc           fieldErrKey = "ItemDesc"
+	FieldErrItemPrice          fieldErrKey = "ItemPrice"
+	FieldErrItemQuantity       fieldErrKey = "ItemQuantity"
+	FieldErrItems              fieldErrKey = "Items"
+	FieldErrItemType           fieldErrKey = "ItemType"
 	FieldErrLastName           fieldErrKey = "LastName"
 	FieldErrLocation           fieldErrKey = "Location"
 	FieldErrLocationType       fieldErrKey = "LocationType"
+	FieldErrMinAmount          fieldErrKey = "MinAmount"
 	FieldErrName               fieldErrKey = "Name"
+	FieldErrNoShowFee          fieldErrKey = "NoShowFee"
+	FieldErrNoShowFeeType      fieldErrKey = "NoShowFeeType"
 	FieldErrPadding            fieldErrKey = "Padding"
 	FieldErrPaddingInitial     fieldErrKey = "PaddingInitial"
 	FieldErrPaddingInitialUnit fieldErrKey = "PaddingInitialUnit"
@@ -280,12 +390,20 @@ const (
 	FieldErrPriceType          fieldErrKey = "PriceType"
 	FieldErrPwd                fieldErrKey = "Password"
 	FieldErrQuestion           fieldErrKey = "Question"
+	FieldErrRegion             fieldErrKey = "Region"
+	FieldErrReminderDays       fieldErrKey = "ReminderDays"
+	FieldErrSessions           fieldErrKey = "Sessions"
 	FieldErrStart              fieldErrKey = "Start"
 	FieldErrSvcID              fieldErrKey = "ServiceID"
+	FieldErrSvcIDs             fieldErrKey = "ServiceIDs"
 	FieldErrSvcArea            fieldErrKey = "ServiceArea"
+	FieldErrTaxRate            fieldErrKey = "TaxRate"
 	FieldErrText               fieldErrKey = "Text"
 	FieldErrTime               fieldErrKey = "Time"
 	FieldErrTimeZone           fieldErrKey = "TimeZone"
+	FieldErrTip                fieldErrKey = "Tip"
+	FieldErrTipPercent         fieldErrKey = "TipPercent"
+	FieldErrTipPercents        fieldErrKey = "TipPercents"
 	FieldErrTitle              fieldErrKey = "Title"
 	FieldErrType               fieldErrKey = "Type"
 	FieldErrURLFacebook        fieldErrKey = "URLFacebook"
@@ -295,6 +413,8 @@ const (
 	FieldErrURLTwitter         fieldErrKey = "URLTwitter"
 	FieldErrURLVideo           fieldErrKey = "URLVideo"
 	FieldErrURLWeb             fieldErrKey = "URLWeb"
+	FieldErrUsageLimit         fieldErrKey = "UsageLimit"
+	FieldErrUsageLimitClient   fieldErrKey = "UsageLimitClient"
 	FieldErrUserID             fieldErrKey = "UserID"
 	FieldErrValue              fieldErrKey = "Value"
 	FieldErrZelleID            fieldErrKey = "ZelleID"
@@ -307,25 +427,49 @@ var fieldErrText = map[fieldErrKey]string{
 	FieldErrAnswer:             "Please enter a valid question.",
 	FieldErrBiography:          "Please enter a valid biography.",
 	FieldErrBudget:             "Please enter a valid value for the budget.",
+	FieldErrCancelCutoff:       "Please enter a valid cancellation cutoff.",
+	FieldErrCancelCutoffUnit:   "Please enter valid cancellation cutoff units.",
+	FieldErrCancelFee:          "Please enter a valid late cancellation fee.",
+	FieldErrCancelFeeType:      "Please enter a valid late cancellation fee type.",
+	FieldErrCancelFeeWindow:    "Please enter a valid number of hours.",
+	FieldErrCapacity:           "Please enter a valid number of seats.",
 	FieldErrClientID:           "Please choose a client.",
 	FieldErrCode:               "Please enter a valid code.",
+	FieldErrCurrency:           "Please choose a valid currency.",
 	FieldErrDate:               "Please enter a valid date.",
+	FieldErrDeposit:            "Please enter a valid deposit.",
+	FieldErrDepositType:        "Please enter a valid deposit type.",
 	FieldErrDesc:               "Please enter a valid description.",
 	FieldErrDomain:             "Please enter a valid domain.",
 	FieldErrDuration:           "Please enter a valid duration.",
 	FieldErrEducation:          "Please enter valid education text",
 	FieldErrExperience:         "Please enter valid experience text",
+	FieldErrExpiration:         "Please enter a valid number of days.",
 	FieldErrEmail:              "Please enter a valid email address.",
 	FieldErrEnd:                "Please enter a valid end date.",
 	FieldErrFreq:               "Please enter a valid repeat frequency.",
+	FieldErrFreqCount:          "Please enter a valid number of occurrences, or an end date but not both.",
+	FieldErrFreqDays:           "Please select valid repeat days.",
+	FieldErrFreqInterval:       "Please enter a valid repeat interval.",
+	FieldErrFreqUntil:          "Please enter a valid repeat end date.",
 	FieldErrFirstName:          "Please enter a valid first name.",
+	FieldErrFirstBookings:      "Please enter a valid number of bookings.",
 	FieldErrGender:             "Please choose a valid gender.",
+	FieldErrHorizon:            "Please enter a valid number of days.",
 	FieldErrID:                 "Please enter a valid ID.",
 	FieldErrImg:                "Please select an image.",
+	FieldErrItemDesc:           "Please enter a valid description for each line item.",
+	FieldErrItemPrice:          "Please enter a valid price for each line item.",
+	FieldErrItemQuantity:       "Please enter a valid quantity for each line item.",
+	FieldErrItems:              "Please enter no more than 50 line items.",
+	FieldErrItemType:           "Please choose a valid type for each line item.",
 	FieldErrLastName:           "Please enter a valid last name.",
 	FieldErrLocation:           "Please enter a valid location.",
 	FieldErrLocationType:       "Please enter a valid location type.",
+	FieldErrMinAmount:          "Please enter a valid minimum amount.",
 	FieldErrName:               "Please enter a valid name.",
+	FieldErrNoShowFee:          "Please enter a valid no-show fee.",
+	FieldErrNoShowFeeType:      "Please enter a valid no-show fee type.",
 	FieldErrPadding:            "Please enter valid padding.",
 	FieldErrPaddingInitial:     "Please enter valid advance notice.",
 	FieldErrPaddingInitialUnit: "Please enter valid advance notice units.",
@@ -335,12 +479,20 @@ var fieldErrText = map[fieldErrKey]string{
 	FieldErrProviderName:       "Please enter a valid name.",
 	FieldErrPwd:                "Please enter a password that is at least 8 characters long, including lower-case and upper-case letters, at least one number, and a symbol.",
 	FieldErrQuestion:           "Please enter a valid question.",
+	FieldErrRegion:             "Please enter a valid state or zip code.",
+	FieldErrReminderDays:       "Please enter up to 5 numbers of days between 1 and 90, separated by commas.",
+	FieldErrSessions:           "Please enter a valid number of sessions.",
 	FieldErrStart:              "Please enter a valid start date.",
 	FieldErrSvcID:              "Please choose a service.",
+	FieldErrSvcIDs:             "Please choose at least one service.",
 	FieldErrSvcArea:            "Please select a valid service area.",
+	FieldErrTaxRate:            "Please enter a valid tax rate.",
 	FieldErrText:               "Please enter valid text.",
 	FieldErrTime:               "Please enter a valid time.",
 	FieldErrTimeZone:           "We are having problems detecting your timezone. Please try again.",
+	FieldErrTip:                "Please enter a valid tip amount.",
+	FieldErrTipPercent:         "Please choose a valid tip.",
+	FieldErrTipPercents:        "Please enter up to 5 percentages between 1 and 100, separated by commas.",
 	FieldErrTitle:              "Please use a valid title.",
 	FieldErrType:               "Please use a valid type.",
 	FieldErrURLFacebook:        "Please use a valid URL.",
@@ -350,6 +502,8 @@ var fieldErrText = map[fieldErrKey]string{
 	FieldErrURLTwitter:         "Please use a valid URL.",
 	FieldErrURLVideo:           "Please use a valid YouTube URL.",
 	FieldErrURLWeb:             "Please use a valid URL.",
+	FieldErrUsageLimit:         "Please enter a valid number of uses.",
+	FieldErrUsageLimitClient:   "Please enter a valid number of uses per client.",
 	FieldErrUserID:             "Please choose a user.",
 	FieldErrValue:              "Please use a valid value.",
 	FieldErrZelleID:            "Please enter a valid email or phone number.",
@@ -370,6 +524,7 @@ var smsText = map[MsgType]string{
 	MsgTypeBookingCancelProvider:   "A service order with a client has been cancelled. See the order here: %s",
 	MsgTypeBookingConfirmClient:    "Your service order has been confirmed. See the order here: %s",
 	MsgTypeBookingEditClient:       "Your service order has been updated. See the order here: %s",
+	MsgTypeBookingEditProvider:     "A client has rescheduled a service order. See the order here: %s",
 	MsgTypeBookingNewClient:        "Your service order has been created. You will be notified after it is confirmed. See the order here: %s",
 	MsgTypeBookingNewProvider:      "You have received a new service order. Please review and confirm the order. See the order here: %s",
 	MsgTypeBookingReminderClient:   "Your service order is coming up. See the order here: %s",
@@ -382,7 +537,10 @@ var smsText = map[MsgType]string{
 	MsgTypeMessage:                 "",
 	MsgTypePaymentClient:           "",
 	MsgTypePaymentProvider:         "You have received the payment from %s. See the payment here: %s",
+	MsgTypePaymentReminderClient:   "",
 	MsgTypePwdReset:                "",
+	MsgTypeRefundClient:            "",
+	MsgTypeWaitlistOfferClient:     "A time has opened up for your service. Claim it before %s here: %s",
 	MsgTypeWelcome:                 "",
 }
 
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+//TipReportUser : tips collected for a team member, net of refunds
+type TipReportUser struct {
+	UserID     *uuid.UUID
+	Name       string
+	Currency   Currency
+	Count      int
+	AmountTips int //non-decimal
+}
+
+//FormatAmountTips : format the tips collected
+func (t *TipReportUser) FormatAmountTips() string {
+	return t.Currency.FormatAmountMinorUnits(t.AmountTips)
+}
+
+//CreateTipReport : summarize the tips collected by team member for captured payments in the currency, attributing tips without a team member to the provider
+func CreateTipReport(payments []*Payment, currency Currency, providerName string) []*TipReportUser {
+	users := make([]*TipReportUser, 0, 2)
+	for _, payment := range payments {
+		if payment.Captured == nil || payment.GetCurrency() != currency || !payment.HasTip() {
+			continue
+		}
+
+		//find the team member
+		var user *TipReportUser
+		for _, existing := range users {
+			if (existing.UserID == nil && payment.TipUserID == nil) || (existing.UserID != nil && payment.TipUserID != nil && *existing.UserID == *payment.TipUserID) {
+				user = existing
+				break
+			}
+		}
+		if user == nil {
+			user = &TipReportUser{
+				UserID:   payment.TipUserID,
+				Name:     payment.TipUserName,
+				Currency: currency,
+			}
+			if user.UserID == nil {
+				user.Name = providerName
+			}
+			users = append(users, user)
+		}
+		user.Count++
+		user.AmountTips += computeAmountNetOfRefunds(payment, payment.ComputeAmountTip())
+	}
+
+	//sort by name
+	sort.SliceStable(users, func(i int, j int) bool {
+		return strings.ToLower(users[i].Name) < strings.ToLower(users[j].Name)
+	})
+	return users
+}
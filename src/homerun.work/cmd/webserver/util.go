@@ -22,6 +22,7 @@ const (
 	layoutDate      = "01/02/2006"
 	layoutDateLong  = "January 02, 2006"
 	layoutDateTime  = "01/02/2006 3:04 PM"
+	layoutDayMonth  = "January 02"
 	layoutMonthLong = "January 2006"
 	layoutTime      = "3:04 PM"
 )
@@ -32,21 +33,65 @@ const defaultCountry = "US"
 //MaxTime : maximum time
 var MaxTime = time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
 
-//TimeDuration : definition of a time duration
+//maximum minutes in a day
+const minutesDay = 24 * 60
+
+//TimeOfDay : a local wall-clock time as the minutes after midnight, which is resolved against a date when needed
+type TimeOfDay int
+
+//ParseTimeOfDay : parse a string as a time of day
+func ParseTimeOfDay(in string) (TimeOfDay, bool) {
+	v, err := time.Parse(layoutTime, strings.ToUpper(in))
+	if err != nil {
+		return 0, false
+	}
+	return TimeOfDay(v.Hour()*60 + v.Minute()), true
+}
+
+//On : resolve the time of day on the date of the given time, using the location of the given time
+func (t TimeOfDay) On(date time.Time) time.Time {
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, int(t), 0, 0, date.Location())
+}
+
+//Format : format the time of day
+func (t TimeOfDay) Format() string {
+	return t.On(time.Time{}).Format(layoutTime)
+}
+
+//TimeDuration : definition of a time duration starting at a local wall-clock time
 type TimeDuration struct {
-	Start    time.Time
-	Duration int //minutes
+	Start    TimeOfDay  `json:"StartTime"`
+	Duration int        `json:"Duration"`        //minutes
+	StartUTC *time.Time `json:"Start,omitempty"` //legacy start stored as a utc time
 }
 
-//GetEnd : get the end date
-func (t *TimeDuration) GetEnd() time.Time {
-	end := t.Start.Add(time.Duration(t.Duration) * time.Minute)
-	return end
+//GetEnd : get the end as a time of day, which extends past midnight if the duration crosses into the next day
+func (t *TimeDuration) GetEnd() TimeOfDay {
+	return t.Start + TimeOfDay(t.Duration)
+}
+
+//Resolve : resolve the duration as a time period on the date of the given time
+func (t *TimeDuration) Resolve(date time.Time) *TimePeriod {
+	return &TimePeriod{
+		Start: t.Start.On(date),
+		End:   t.GetEnd().On(date),
+	}
 }
 
 //FormatTimePeriod : format the time duration as a period
-func (t *TimeDuration) FormatTimePeriod(timeZone string) string {
-	return fmt.Sprintf("%s-%s", FormatTimeLocal(t.Start, timeZone), FormatTimeLocal(t.GetEnd(), timeZone))
+func (t *TimeDuration) FormatTimePeriod() string {
+	return fmt.Sprintf("%s-%s", t.Start.Format(), (t.GetEnd() % minutesDay).Format())
+}
+
+//migrate a legacy duration stored as a utc time to the wall-clock time in the location
+func (t *TimeDuration) migrate(loc *time.Location) {
+	if t.StartUTC == nil {
+		return
+	}
+	start := t.StartUTC.In(loc)
+	t.Start = TimeOfDay(start.Hour()*60 + start.Minute())
+	t.StartUTC = nil
 }
 
 //DaySchedule : definition of time durations for a specific day
@@ -54,15 +99,15 @@ type DaySchedule struct {
 	DayOfWeek     time.Weekday    `json:"DayOfWeek"`
 	TimeDurations []*TimeDuration `json:"TimeDurations"`
 	Unavailable   bool            `json:"Unavailable"`
-	TimePeriods   []*TimePeriod   `json:"TimePeriods"`
 }
 
 //TimePeriod : definition of a time period
 type TimePeriod struct {
-	Start       time.Time
-	End         time.Time
-	Unavailable bool
-	Hidden      bool
+	Start          time.Time
+	End            time.Time
+	Unavailable    bool
+	Hidden         bool
+	SeatsAvailable int
 }
 
 //IsOverlap : check if the incoming time period overlaps
@@ -81,6 +126,9 @@ func (t *TimePeriod) FormatPeriodLocal(isAppt bool, timeZone string) string {
 		return fmt.Sprintf("%s (%s)", t.FormatStartLocal(timeZone), GetMsgText(MsgUnavailable))
 	}
 	if isAppt {
+		if t.SeatsAvailable > 0 {
+			return fmt.Sprintf("%s - %s (%s)", t.FormatStartLocal(timeZone), t.FormatEndLocal(timeZone), GetMsgText(MsgSeatsAvailable, t.SeatsAvailable))
+		}
 		return fmt.Sprintf("%s - %s", t.FormatStartLocal(timeZone), t.FormatEndLocal(timeZone))
 	}
 	return t.FormatStartLocal(timeZone)
@@ -374,6 +422,17 @@ var DaysOfWeek map[string]time.Weekday = map[string]time.Weekday{
 	time.Saturday.String():  time.Saturday,
 }
 
+//WeekDays : ordered list of days of the week
+var WeekDays []time.Weekday = []time.Weekday{
+	time.Sunday,
+	time.Monday,
+	time.Tuesday,
+	time.Wednesday,
+	time.Thursday,
+	time.Friday,
+	time.Saturday,
+}
+
 //ParseWeekDay : parse the day of the week
 func ParseWeekDay(in string) (time.Weekday, bool) {
 	dayOfWeek, ok := DaysOfWeek[in]
@@ -469,9 +528,14 @@ func FormatFloat(val float32) string {
 	return str
 }
 
-//FormatPrice : format the price
+//FormatRate : format a rate, such as a percentage, keeping all significant decimals
+func FormatRate(val float32) string {
+	return strconv.FormatFloat(float64(val), 'f', -1, 32)
+}
+
+//FormatPrice : format a platform price, which is in the default currency
 func FormatPrice(price float32) string {
-	return fmt.Sprintf("$%s", FormatFloat(price))
+	return CurrencyDefault.FormatAmount(price)
 }
 
 //FileNoExt : filename with no extension
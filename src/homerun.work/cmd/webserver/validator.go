@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/gofrs/uuid"
 	"github.com/nyaruka/phonenumbers"
 	"github.com/pkg/errors"
 )
@@ -15,17 +16,31 @@ import (
 //validation constants
 const (
 	budgetMax                  = 300
+	cancelCutoffServiceMin     = 0
+	cancelCutoffServiceMax     = 72
+	cancelFeeWindowServiceMin  = 0   //0 for no fee
+	cancelFeeWindowServiceMax  = 168 //7 days
 	durationCampaignDaysMin    = 1 * 24 * time.Hour
 	durationScheduleMinutesMin = 10
 	durationScheduleMinutesMax = 1380  //23 hours
 	durationServiceMinutesMin  = 0     //0 for variable
 	durationServiceMinutesMax  = 10080 //7 days
+	feePercentageMax           = 100   //percent
+	horizonServiceDaysMin      = 0     //0 for unlimited
+	horizonServiceDaysMax      = 730   //2 years
 	paddingInitialServiceMin   = 0
 	paddingInitialServiceMax   = 24
 	paddingServiceMinutesMin   = 0
 	paddingServiceMinutesMax   = 480 //8 hours
 	priceMin                   = 0
 	priceMax                   = 50000
+	quantityMin                = 0 //exclusive
+	quantityMax                = 10000
+	reminderDaysCount          = 5
+	reminderDaysMax            = 90
+	taxRateMax                 = 100 //percent
+	tipPercentMax              = 100
+	tipPercentsCount           = 5
 	unixTimeMin                = 1546300800 // 01/01/2019 12am
 )
 
@@ -42,8 +57,10 @@ func InitValidator() *Validator {
 	vdtor.Validator.RegisterValidation("campaignDateGT", validateFieldCampaignDateGT)
 	vdtor.Validator.RegisterValidation("campaignStatus", validateFieldCampaignStatus)
 	vdtor.Validator.RegisterValidation("couponType", validateFieldCouponType)
+	vdtor.Validator.RegisterValidation("currency", validateFieldCurrency)
 	vdtor.Validator.RegisterValidation("date", validateFieldDate)
 	vdtor.Validator.RegisterValidation("dateGT", validateFieldDateGT)
+	vdtor.Validator.RegisterValidation("dateGTE", validateFieldDateGTE)
 	vdtor.Validator.RegisterValidation("domain", validateFieldDomain)
 	vdtor.Validator.RegisterValidation("durationSchedule", validateFieldDurationSchedule)
 	vdtor.Validator.RegisterValidation("durationScheduleStr", validateFieldDurationScheduleStr)
@@ -51,22 +68,38 @@ func InitValidator() *Validator {
 	vdtor.Validator.RegisterValidation("durations", validateFieldDurations)
 	vdtor.Validator.RegisterValidation("gender", validateFieldGender)
 	vdtor.Validator.RegisterValidation("password", validateFieldPassword)
+	vdtor.Validator.RegisterValidation("paymentItemType", validateFieldPaymentItemType)
 	vdtor.Validator.RegisterValidation("phone", validateFieldPhone)
 	vdtor.Validator.RegisterValidation("price", validateFieldPrice)
 	vdtor.Validator.RegisterValidation("priceType", validateFieldPriceType)
+	vdtor.Validator.RegisterValidation("quantity", validateFieldQuantity)
+	vdtor.Validator.RegisterValidation("recCount", validateFieldRecurrenceCount)
 	vdtor.Validator.RegisterValidation("recFreq", validateFieldRecurrenceFreq)
+	vdtor.Validator.RegisterValidation("recInterval", validateFieldRecurrenceInterval)
+	vdtor.Validator.RegisterValidation("reminderDays", validateFieldReminderDays)
+	vdtor.Validator.RegisterValidation("svcCancelCutoff", validateFieldServiceCancelCutoff)
+	vdtor.Validator.RegisterValidation("svcCancelFeeWindow", validateFieldServiceCancelFeeWindow)
+	vdtor.Validator.RegisterValidation("svcCapacity", validateFieldServiceCapacity)
+	vdtor.Validator.RegisterValidation("svcFee", validateFieldServiceFee)
+	vdtor.Validator.RegisterValidation("svcFeeType", validateFieldServiceFeeType)
+	vdtor.Validator.RegisterValidation("svcHorizon", validateFieldServiceHorizon)
 	vdtor.Validator.RegisterValidation("svcInterval", validateFieldServiceInterval)
 	vdtor.Validator.RegisterValidation("svcLoc", validateFieldServiceLocation)
 	vdtor.Validator.RegisterValidation("svcLocType", validateFieldServiceLocationType)
 	vdtor.Validator.RegisterValidation("svcPadding", validateFieldServicePadding)
 	vdtor.Validator.RegisterValidation("svcPaddingInitial", validateFieldServicePaddingInitial)
 	vdtor.Validator.RegisterValidation("svcPaddingUnit", validateFieldServicePaddingUnit)
+	vdtor.Validator.RegisterValidation("taxRate", validateFieldTaxRate)
+	vdtor.Validator.RegisterValidation("tipPercent", validateFieldTipPercent)
+	vdtor.Validator.RegisterValidation("tipPercents", validateFieldTipPercents)
 	vdtor.Validator.RegisterValidation("time", validateFieldTime)
 	vdtor.Validator.RegisterValidation("timeGT", validateFieldTimeGT)
 	vdtor.Validator.RegisterValidation("timeUnix", validateFieldTimeUnix)
 	vdtor.Validator.RegisterValidation("timeZone", validateFieldTimeZone)
 	vdtor.Validator.RegisterValidation("urlVideo", validateFieldURLVideo)
+	vdtor.Validator.RegisterValidation("uuids", validateFieldUUIDs)
 	vdtor.Validator.RegisterValidation("weekDay", validateFieldWeekDay)
+	vdtor.Validator.RegisterValidation("weekDays", validateFieldWeekDays)
 	return vdtor
 }
 
@@ -169,6 +202,12 @@ func validateFieldCouponType(fl validator.FieldLevel) bool {
 	return v != nil
 }
 
+//validate a field as a currency
+func validateFieldCurrency(fl validator.FieldLevel) bool {
+	v := ParseCurrency(fl.Field().String())
+	return v != ""
+}
+
 //validate a field as a date
 func validateFieldDate(fl validator.FieldLevel) bool {
 	v := ParseDateUTC(fl.Field().String())
@@ -197,6 +236,25 @@ func validateFieldDateGT(fl validator.FieldLevel) bool {
 	return fieldTime.After(paramTime)
 }
 
+//validate a field as a date that is greater than or equal to the parameter field
+func validateFieldDateGTE(fl validator.FieldLevel) bool {
+	fieldTime := ParseDateUTC(fl.Field().String())
+	if fieldTime.IsZero() {
+		return false
+	}
+
+	//read the parameter field
+	param, _, _, ok := fl.GetStructFieldOK2()
+	if !ok {
+		return false
+	}
+	paramTime := ParseDateUTC(param.String())
+	if paramTime.IsZero() {
+		return false
+	}
+	return !fieldTime.Before(paramTime)
+}
+
 //validate a field as a domain
 func validateFieldDomain(fl validator.FieldLevel) bool {
 	var o sync.Once
@@ -312,6 +370,82 @@ func validateFieldPriceType(fl validator.FieldLevel) bool {
 	return v != ""
 }
 
+//validate a field as a payment item type, excluding tax which is computed and tips which are added by the client
+func validateFieldPaymentItemType(fl validator.FieldLevel) bool {
+	v := ParsePaymentItemType(fl.Field().String())
+	return v != "" && v != PaymentItemTypeTax && v != PaymentItemTypeTip
+}
+
+//validate a field as a quantity
+func validateFieldQuantity(fl validator.FieldLevel) bool {
+	v, err := strconv.ParseFloat(fl.Field().String(), 32)
+	if err != nil {
+		return false
+	}
+	if v <= quantityMin || v > quantityMax {
+		return false
+	}
+	return true
+}
+
+//validate a field as a tax rate
+func validateFieldTaxRate(fl validator.FieldLevel) bool {
+	v, err := strconv.ParseFloat(fl.Field().String(), 32)
+	if err != nil {
+		return false
+	}
+	if v < 0 || v > taxRateMax {
+		return false
+	}
+	return true
+}
+
+//validate a field as a tip percentage
+func validateFieldTipPercent(fl validator.FieldLevel) bool {
+	v, err := strconv.Atoi(fl.Field().String())
+	if err != nil {
+		return false
+	}
+	if v < 1 || v > tipPercentMax {
+		return false
+	}
+	return true
+}
+
+//validate a field as a comma-separated list of tip percentages
+func validateFieldTipPercents(fl validator.FieldLevel) bool {
+	percents, err := ParseTipPercents(fl.Field().String())
+	if err != nil {
+		return false
+	}
+	if len(percents) > tipPercentsCount {
+		return false
+	}
+	for _, percent := range percents {
+		if percent < 1 || percent > tipPercentMax {
+			return false
+		}
+	}
+	return true
+}
+
+//validate a field as a comma-separated list of days for reminders
+func validateFieldReminderDays(fl validator.FieldLevel) bool {
+	days, err := ParsePaymentReminderDays(fl.Field().String())
+	if err != nil {
+		return false
+	}
+	if len(days) > reminderDaysCount {
+		return false
+	}
+	for _, day := range days {
+		if day < 1 || day > reminderDaysMax {
+			return false
+		}
+	}
+	return true
+}
+
 //validate a field as a recurrence frequency
 func validateFieldRecurrenceFreq(fl validator.FieldLevel) bool {
 	s := fl.Field().String()
@@ -319,6 +453,30 @@ func validateFieldRecurrenceFreq(fl validator.FieldLevel) bool {
 	return v != nil
 }
 
+//validate a field as a recurrence interval
+func validateFieldRecurrenceInterval(fl validator.FieldLevel) bool {
+	v, err := strconv.ParseInt(fl.Field().String(), 10, 32)
+	if err != nil {
+		return false
+	}
+	if v < 1 || v > RecurrenceIntervalMax {
+		return false
+	}
+	return true
+}
+
+//validate a field as a recurrence count
+func validateFieldRecurrenceCount(fl validator.FieldLevel) bool {
+	v, err := strconv.ParseInt(fl.Field().String(), 10, 32)
+	if err != nil {
+		return false
+	}
+	if v < 1 || v > RecurrenceCountMax {
+		return false
+	}
+	return true
+}
+
 //validate a field as service interval
 func validateFieldServiceInterval(fl validator.FieldLevel) bool {
 	v := ParseServiceInterval(fl.Field().String())
@@ -373,6 +531,97 @@ func validateFieldServicePadding(fl validator.FieldLevel) bool {
 	return true
 }
 
+//validate a field as a service capacity
+func validateFieldServiceCapacity(fl validator.FieldLevel) bool {
+	v, err := strconv.ParseInt(fl.Field().String(), 10, 32)
+	if err != nil {
+		return false
+	}
+	if v < ServiceCapacityDefault {
+		return false
+	}
+	if v > ServiceCapacityMax {
+		return false
+	}
+	return true
+}
+
+//validate a field as a service cancellation cutoff
+func validateFieldServiceCancelCutoff(fl validator.FieldLevel) bool {
+	v, err := strconv.ParseInt(fl.Field().String(), 10, 32)
+	if err != nil {
+		return false
+	}
+	if v < cancelCutoffServiceMin {
+		return false
+	}
+	if v > cancelCutoffServiceMax {
+		return false
+	}
+	return true
+}
+
+//validate a field as a service late cancellation fee window in hours
+func validateFieldServiceCancelFeeWindow(fl validator.FieldLevel) bool {
+	v, err := strconv.ParseInt(fl.Field().String(), 10, 32)
+	if err != nil {
+		return false
+	}
+	if v < cancelFeeWindowServiceMin {
+		return false
+	}
+	if v > cancelFeeWindowServiceMax {
+		return false
+	}
+	return true
+}
+
+//validate a field as a service fee based on the fee type
+func validateFieldServiceFee(fl validator.FieldLevel) bool {
+	//read the parameter field
+	param, _, _, ok := fl.GetStructFieldOK2()
+	if !ok {
+		return false
+	}
+
+	//check the range based on the fee type
+	v, err := strconv.ParseFloat(fl.Field().String(), 32)
+	if err != nil {
+		return false
+	}
+	if v < priceMin {
+		return false
+	}
+	switch ParseFeeType(param.String()) {
+	case FeeTypePercentage:
+		return v <= feePercentageMax
+	case FeeTypeAmount:
+		return v <= priceMax
+	}
+	return false
+}
+
+//validate a field as a service fee type
+func validateFieldServiceFeeType(fl validator.FieldLevel) bool {
+	v := ParseFeeType(fl.Field().String())
+	return v != ""
+}
+
+//validate a field as a service booking horizon in days
+func validateFieldServiceHorizon(fl validator.FieldLevel) bool {
+	v, err := strconv.ParseInt(fl.Field().String(), 10, 32)
+	if err != nil {
+		return false
+	}
+	if v < horizonServiceDaysMin {
+		return false
+	}
+	if v > horizonServiceDaysMax {
+		return false
+	}
+	return true
+}
+
 //validate a field as initial service padding
 func validateFieldServicePaddingInitial(fl validator.FieldLevel) bool {
 	v, err := strconv.ParseInt(fl.Field().String(), 10, 32)
@@ -454,3 +703,32 @@ func validateFieldWeekDay(fl validator.FieldLevel) bool {
 	}
 	return true
 }
+
+//validate a field as a list of week days
+func validateFieldWeekDays(fl validator.FieldLevel) bool {
+	days, ok := fl.Field().Interface().([]string)
+	if !ok {
+		return false
+	}
+	for _, day := range days {
+		_, ok := ParseWeekDay(day)
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+//validate a field as a list of uuids
+func validateFieldUUIDs(fl validator.FieldLevel) bool {
+	ids, ok := fl.Field().Interface().([]string)
+	if !ok {
+		return false
+	}
+	for _, id := range ids {
+		if uuid.FromStringOrNil(id) == uuid.Nil {
+			return false
+		}
+	}
+	return true
+}
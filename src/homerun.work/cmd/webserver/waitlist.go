@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+//waitlist db tables
+const (
+	dbTableWaitlist = "waitlist"
+)
+
+//Waitlist : definition of a client waiting for an opening for a service
+type Waitlist struct {
+	ID                  *uuid.UUID `json:"-"`
+	ProviderID          *uuid.UUID `json:"-"`
+	ServiceID           *uuid.UUID `json:"-"`
+	ProviderUserID      *uuid.UUID `json:"-"`
+	Client              *Client    `json:"-"`
+	EnableClientPhone   bool       `json:"EnableClientPhone"`
+	TimeStart           time.Time  `json:"-"`
+	TimeEnd             time.Time  `json:"-"`
+	Token               *string    `json:"-"`
+	OfferTime           *time.Time `json:"-"`
+	OfferExpiration     *time.Time `json:"-"`
+	OfferProviderUserID *uuid.UUID `json:"OfferProviderUserId"`
+	Claimed             bool       `json:"-"`
+	Created             time.Time  `json:"-"`
+}
+
+//GetClientPhoneSMS : get the client phone to use for SMS
+func (w *Waitlist) GetClientPhoneSMS() string {
+	if w.EnableClientPhone {
+		return w.Client.Phone
+	}
+	return ""
+}
+
+//IsOfferExpired : check if the offer has expired
+func (w *Waitlist) IsOfferExpired(now time.Time) bool {
+	return w.OfferExpiration == nil || !now.Before(*w.OfferExpiration)
+}
+
+//FormatOfferTime : format the offered time
+func (w *Waitlist) FormatOfferTime(timeZone string) string {
+	if w.OfferTime == nil {
+		return ""
+	}
+	return FormatDateTimeLocal(*w.OfferTime, timeZone)
+}
+
+//FormatOfferExpiration : format the offer expiration
+func (w *Waitlist) FormatOfferExpiration(timeZone string) string {
+	if w.OfferExpiration == nil {
+		return ""
+	}
+	return FormatDateTimeLocal(*w.OfferExpiration, timeZone)
+}
+
+//waitlist query
+func waitlistQueryCreate(whereStmt string, orderStmt string, limit int) string {
+	if orderStmt == "" {
+		orderStmt = "w.created"
+	}
+	stmt := fmt.Sprintf("SELECT BIN_TO_UUID(w.id),BIN_TO_UUID(w.provider_id),BIN_TO_UUID(w.service_id),BIN_TO_UUID(w.provider_user_id),w.time_start,w.time_end,w.token,w.offer_time,w.offer_expiration,w.claimed,w.created,w.data,BIN_TO_UUID(c.id),c.email,c.disable_emails,c.data FROM %s w INNER JOIN %s c ON c.id=w.client_id WHERE %s ORDER BY %s", dbTableWaitlist, dbTableClient, whereStmt, orderStmt)
+	if limit > 0 {
+		stmt = fmt.Sprintf("%s LIMIT %d", stmt, limit)
+	}
+	return stmt
+}
+
+//parse a waitlist
+func waitlistQueryParse(rowFn ScanFn) (*Waitlist, error) {
+	//read the row
+	var idStr string
+	var providerIDStr string
+	var svcIDStr string
+	var providerUserIDStr sql.NullString
+	var timeStart time.Time
+	var timeEnd time.Time
+	var token sql.NullString
+	var offerTime sql.NullTime
+	var offerExpiration sql.NullTime
+	var claimedBit string
+	var created time.Time
+	var dataStr string
+	var clientIDStr string
+	var clientEmail string
+	var clientDisableEmailsBit string
+	var clientDataStr string
+	err := rowFn(&idStr, &providerIDStr, &svcIDStr, &providerUserIDStr, &timeStart, &timeEnd, &token, &offerTime, &offerExpiration, &claimedBit, &created, &dataStr, &clientIDStr, &clientEmail, &clientDisableEmailsBit, &clientDataStr)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "rows scan waitlist")
+	}
+
+	//parse the uuid
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse uuid waitlist id")
+	}
+	providerID, err := uuid.FromString(providerIDStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse uuid provider id")
+	}
+	svcID, err := uuid.FromString(svcIDStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse uuid service id")
+	}
+	clientID, err := uuid.FromString(clientIDStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse uuid client id")
+	}
+
+	//unmarshal the data
+	var waitlist Waitlist
+	err = json.Unmarshal([]byte(dataStr), &waitlist)
+	if err != nil {
+		return nil, errors.Wrap(err, "unjson waitlist")
+	}
+	waitlist.ID = &id
+	waitlist.ProviderID = &providerID
+	waitlist.ServiceID = &svcID
+	if providerUserIDStr.Valid {
+		providerUserID, err := uuid.FromString(providerUserIDStr.String)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse uuid provider user id")
+		}
+		waitlist.ProviderUserID = &providerUserID
+	}
+	waitlist.TimeStart = timeStart
+	waitlist.TimeEnd = timeEnd
+	if token.Valid {
+		waitlist.Token = &token.String
+	}
+	if offerTime.Valid {
+		waitlist.OfferTime = &offerTime.Time
+	}
+	if offerExpiration.Valid {
+		waitlist.OfferExpiration = &offerExpiration.Time
+	}
+	waitlist.Claimed = claimedBit == "\x01"
+	waitlist.Created = created
+
+	//unmarshal the client
+	var client Client
+	err = json.Unmarshal([]byte(clientDataStr), &client)
+	if err != nil {
+		return nil, errors.Wrap(err, "unjson client")
+	}
+	client.ID = &clientID
+	client.ProviderID = &providerID
+	client.Email = clientEmail
+	client.DisableEmails = clientDisableEmailsBit == "\x01"
+	waitlist.Client = &client
+	return &waitlist, nil
+}
+
+//load a waitlist
+func loadWaitlist(ctx context.Context, db *DB, whereStmt string, args ...interface{}) (context.Context, *Waitlist, error) {
+	stmt := waitlistQueryCreate(whereStmt, "", 0)
+	ctx, row, err := db.QueryRow(ctx, stmt, args...)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "query row waitlist")
+	}
+	waitlist, err := waitlistQueryParse(row.Scan)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "waitlist parse")
+	}
+	return ctx, waitlist, nil
+}
+
+//LoadWaitlistByID : load a waitlist by id
+func LoadWaitlistByID(ctx context.Context, db *DB, id *uuid.UUID) (context.Context, *Waitlist, error) {
+	whereStmt := "w.deleted=0 AND w.id=UUID_TO_BIN(?)"
+	ctx, waitlist, err := loadWaitlist(ctx, db, whereStmt, id)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, fmt.Sprintf("no waitlist: %s", id))
+	}
+	if waitlist == nil {
+		return ctx, nil, fmt.Errorf("no waitlist: %s", id)
+	}
+	return ctx, waitlist, nil
+}
+
+//LoadWaitlistByProviderIDAndToken : load a waitlist by the provider id and the offer token
+func LoadWaitlistByProviderIDAndToken(ctx context.Context, db *DB, providerID *uuid.UUID, token string) (context.Context, *Waitlist, error) {
+	whereStmt := "w.deleted=0 AND w.provider_id=UUID_TO_BIN(?) AND w.token=?"
+	return loadWaitlist(ctx, db, whereStmt, providerID, token)
+}
+
+//FindWaitlistForOffer : find the next waitlist that can be offered the given time
+func FindWaitlistForOffer(ctx context.Context, db *DB, svcID *uuid.UUID, providerUserID *uuid.UUID, timeFrom time.Time, timeTo time.Time, now time.Time) (context.Context, *Waitlist, error) {
+	//skip clients with an outstanding offer or that have already been offered the time
+	whereStmt := "w.deleted=0 AND w.claimed=0 AND w.service_id=UUID_TO_BIN(?) AND (w.provider_user_id IS NULL OR w.provider_user_id=UUID_TO_BIN(?)) AND w.time_start<=? AND w.time_end>=? AND (w.offer_expiration IS NULL OR (w.offer_expiration<=? AND w.offer_time<>?))"
+	stmt := waitlistQueryCreate(whereStmt, "", 1)
+	ctx, row, err := db.QueryRow(ctx, stmt, svcID, providerUserID, timeFrom.UTC(), timeTo.UTC(), now.UTC(), timeFrom.UTC())
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "query row waitlist offer")
+	}
+	waitlist, err := waitlistQueryParse(row.Scan)
+	if err != nil {
+		return ctx, nil, errors.Wrap(err, "waitlist parse")
+	}
+	return ctx, waitlist, nil
+}
+
+//SaveWaitlist : save a waitlist
+func SaveWaitlist(ctx context.Context, db *DB, waitlist *Waitlist) (context.Context, error) {
+	ctx, err := db.ProcessTx(ctx, "save waitlist", func(ctx context.Context, db *DB) (context.Context, error) {
+		//save the client
+		waitlist.Client.ProviderID = waitlist.ProviderID
+		ctx, err := SaveClient(ctx, db, waitlist.Client)
+		if err != nil {
+			return ctx, errors.Wrap(err, "waitlist save client")
+		}
+
+		//generate an id if necessary
+		if waitlist.ID == nil {
+			id, err := uuid.NewV4()
+			if err != nil {
+				return ctx, errors.Wrap(err, "new uuid waitlist")
+			}
+			waitlist.ID = &id
+		}
+
+		//json encode the waitlist data
+		dataJSON, err := json.Marshal(waitlist)
+		if err != nil {
+			return ctx, errors.Wrap(err, "json waitlist")
+		}
+
+		//save to the db
+		stmt := fmt.Sprintf("INSERT INTO %s(id,provider_id,service_id,provider_user_id,client_id,time_start,time_end,data) VALUES (UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),UUID_TO_BIN(?),?,?,?) ON DUPLICATE KEY UPDATE time_start=VALUES(time_start),time_end=VALUES(time_end),data=VALUES(data)", dbTableWaitlist)
+		ctx, result, err := db.Exec(ctx, stmt, waitlist.ID, waitlist.ProviderID, waitlist.ServiceID, waitlist.ProviderUserID, waitlist.Client.ID, waitlist.TimeStart.UTC(), waitlist.TimeEnd.UTC(), dataJSON)
+		if err != nil {
+			return ctx, errors.Wrap(err, "insert waitlist")
+		}
+		count, err := result.RowsAffected()
+		if err != nil {
+			return ctx, errors.Wrap(err, "insert waitlist rows affected")
+		}
+
+		//0 indicated no update, 1 an insert, 2 an update
+		if count < 0 || count > 2 {
+			return ctx, fmt.Errorf("unable to insert waitlist: %s", waitlist.ID)
+		}
+		return ctx, nil
+	})
+	if err != nil {
+		return ctx, errors.Wrap(err, "save waitlist")
+	}
+	return ctx, nil
+}
+
+//UpdateWaitlistOffer : update the offer for a waitlist
+func UpdateWaitlistOffer(ctx context.Context, db *DB, waitlist *Waitlist) (context.Context, error) {
+	//json encode the waitlist data
+	dataJSON, err := json.Marshal(waitlist)
+	if err != nil {
+		return ctx, errors.Wrap(err, "json waitlist")
+	}
+	stmt := fmt.Sprintf("UPDATE %s SET token=?,offer_time=?,offer_expiration=?,data=? WHERE deleted=0 AND claimed=0 AND id=UUID_TO_BIN(?)", dbTableWaitlist)
+	ctx, result, err := db.Exec(ctx, stmt, waitlist.Token, waitlist.OfferTime, waitlist.OfferExpiration, dataJSON, waitlist.ID)
+	if err != nil {
+		return ctx, errors.Wrap(err, "update waitlist offer")
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return ctx, errors.Wrap(err, "update waitlist offer rows affected")
+	}
+	if count != 1 {
+		return ctx, fmt.Errorf("unable to update waitlist offer: %s", waitlist.ID)
+	}
+	return ctx, nil
+}
+
+//MarkWaitlistClaimed : mark a waitlist as claimed
+func MarkWaitlistClaimed(ctx context.Context, db *DB, id *uuid.UUID) (context.Context, error) {
+	stmt := fmt.Sprintf("UPDATE %s SET claimed=1 WHERE deleted=0 AND claimed=0 AND id=UUID_TO_BIN(?)", dbTableWaitlist)
+	ctx, result, err := db.Exec(ctx, stmt, id)
+	if err != nil {
+		return ctx, errors.Wrap(err, "mark waitlist claimed")
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return ctx, errors.Wrap(err, "mark waitlist claimed rows affected")
+	}
+	if count != 1 {
+		return ctx, fmt.Errorf("unable to mark waitlist claimed: %s", id)
+	}
+	return ctx, nil
+}
@@ -390,11 +390,21 @@ function setupSvcImgWidget(containerId, description, prompt, inputName, imgUrlsS
   createSvcImgWidget(servicePictureCount, containerId, description, prompt, inputName, "", "", 0);
   servicePictureCount++;
 }
-function handleServiceType(inputId, bookingId, orderId, zoomId) {
+function handleServiceType(inputId, bookingId, orderId, zoomId, capacityId) {
   $(inputId).change(function () {
-    var isApptOnly = $(this).val() == "on";
+    var isApptOnly = $(this).val() != "off";
     toggleServiceDurations(isApptOnly);
+    toggleServiceCapacity($(this).val() == "class");
   });
+  function toggleServiceCapacity(isClass) {
+    if (isClass) {
+      $(capacityId).show();
+      $(capacityId).prop("disabled", false);
+    } else {
+      $(capacityId).hide();
+      $(capacityId).prop("disabled", true);
+    }
+  }
   function toggleServiceDurations(isApptOnly) {
     if (isApptOnly) {
       $(bookingId).show();
@@ -455,7 +465,9 @@ function formatRecurrenceFreq(dateStr, freq) {
   //Repeat on code
   var repeatOn = date;
   var repeatTxt = "";
-  if (freq == "Weekly") {
+  if (freq == "Daily") {
+    repeatTxt = "Every Day";
+  } else if (freq == "Weekly") {
     repeatOn.setDate(repeatOn.getDate() + 7);
     var repeatOnD = moment(repeatOn).format("dddd");
     repeatTxt = "Every " + repeatOnD;
@@ -499,6 +511,52 @@ function setupSvcLocation(selectId, inputProviderId, inputClientId, inputFlexId,
     handleLocationType(this.value);
   });
 }
+function setupPaymentItems(listId, templateId, addId, taxRateId, priceId, typeName, quantityName, priceName, digits) {
+  //amounts are summed in the minor unit of the currency
+  var factor = Math.pow(10, digits);
+  function computeTotal() {
+    var items = $(listId).find(".payment-item");
+    if (items.length == 0) {
+      $(priceId).prop("readonly", false);
+      return;
+    }
+
+    //sum the items, where discounts and credits are deducted and credits are not taxed
+    var total = 0;
+    var taxable = 0;
+    items.each(function () {
+      var type = $(this).find(`[name="${typeName}"]`).val();
+      var quantity = parseFloat($(this).find(`[name="${quantityName}"]`).val()) || 0;
+      var price = parseFloat($(this).find(`[name="${priceName}"]`).val()) || 0;
+      var amount = Math.round(quantity * price * factor);
+      if (type == "Discount" || type == "Credit") {
+        amount = -amount;
+      }
+      total += amount;
+      if (type != "Credit") {
+        taxable += amount;
+      }
+    });
+    var taxRate = parseFloat($(taxRateId).val()) || 0;
+    if (taxRate > 0 && taxable > 0) {
+      total += Math.round((taxable * taxRate) / 100);
+    }
+    total = Math.max(total, 0);
+    $(priceId).prop("readonly", true);
+    $(priceId).val((total / factor).toFixed(digits));
+  }
+  $(addId).click(function (evt) {
+    $(listId).append($(templateId).html());
+    computeTotal();
+  });
+  $(listId).on("click", ".payment-item-remove", function (evt) {
+    $(this).closest(".payment-item").remove();
+    computeTotal();
+  });
+  $(listId).on("change keyup", "input,select", computeTotal);
+  $(taxRateId).on("change keyup", computeTotal);
+  computeTotal();
+}
 function clipLink(linkId, copiedId) {
   $("#" + linkId).show();
   var text = document.getElementById(linkId);
@@ -83,16 +83,21 @@ $(document).on("change",".upload-box-input",function(e){const id=parseInt($(this
 $(document).on("click",".btn-trash",function(){const id=parseInt($(this).attr("data-id"));if(servicePictureCount<=1){$(this).parents(".upload-box").find(".upload-box-container").show();$(this).parents(".upload-box").find(".upload-box-preview").addClass("d-none");$(this).parents(".upload-box").find(".upload-box-preview").attr("src","#");$(this).parents(".upload-box").find(".upload-box-input").val("");$("#btn-trash-"+id).hide();servicePictureCount=1;}else{for(var i=id+1;i<servicePictureCount;i++){changeOrder(i,i-1);}
 $(this).parents(".upload-box").remove();servicePictureCount--;}});$(containerId).sortable({});$(containerId).disableSelection();if(imgUrlsStr.length>0){var imgUrls=JSON.parse(imgUrlsStr);for(var i=0;i<imgUrls.length;i++){createSvcImgWidget(i,containerId,description,prompt,inputName,imgUrls[i],inputIdxName,i);servicePictureCount++;$(`#file-img-${i}`).parents(".upload-box").find(`#upload-box-container-${i}`).hide();$(`#file-img-${i}`).parents(".upload-box").find(`#upload-box-preview-${i}`).removeClass("d-none");$("#btn-trash-"+i).show();$("#div-upload-"+i).hide();}}
 createSvcImgWidget(servicePictureCount,containerId,description,prompt,inputName,"","",0);servicePictureCount++;}
-function handleServiceType(inputId,bookingId,orderId,zoomId){$(inputId).change(function(){var isApptOnly=$(this).val()=="on";toggleServiceDurations(isApptOnly);});function toggleServiceDurations(isApptOnly){if(isApptOnly){$(bookingId).show();$(bookingId).prop("disabled",false);$(orderId).hide();$(orderId).prop("disabled",true);$(zoomId).show();$(zoomId).prop("disabled",false);}else{$(bookingId).hide();$(bookingId).prop("disabled",true);$(orderId).show();$(orderId).prop("disabled",false);$(zoomId).hide();$(zoomId).prop("disabled",true);}}
+function handleServiceType(inputId,bookingId,orderId,zoomId,capacityId){$(inputId).change(function(){var isApptOnly=$(this).val()!="off";toggleServiceDurations(isApptOnly);toggleServiceCapacity($(this).val()=="class");});function toggleServiceCapacity(isClass){if(isClass){$(capacityId).show();$(capacityId).prop("disabled",false);}else{$(capacityId).hide();$(capacityId).prop("disabled",true);}}function toggleServiceDurations(isApptOnly){if(isApptOnly){$(bookingId).show();$(bookingId).prop("disabled",false);$(orderId).hide();$(orderId).prop("disabled",true);$(zoomId).show();$(zoomId).prop("disabled",false);}else{$(bookingId).hide();$(bookingId).prop("disabled",true);$(orderId).show();$(orderId).prop("disabled",false);$(zoomId).hide();$(zoomId).prop("disabled",true);}}
 $(inputId).trigger("change");}
 function formatRecurrenceFreq(dateStr,freq){if(freq.length==0){return "";}else if(freq=="One-Time Only"){return "";}
 var date=new Date(dateStr);var weekday=new Array(7);weekday[0]="Sunday";weekday[1]="Monday";weekday[2]="Tuesday";weekday[3]="Wednesday";weekday[4]="Thursday";weekday[5]="Friday";weekday[6]="Saturday";var wom=new Array(6);wom[0]="first";wom[1]="second";wom[2]="third";wom[3]="fourth";wom[4]="fifth";wom[5]="sixth";var month=date.getMonth()+1;var year=date.getFullYear();var dayOfMonth=date.getDate();var day=date.getDay();var weekOfMonth=Math.ceil((dayOfMonth-1-day)/7);var lastDayOfMonth=new Date(date.getFullYear(),date.getMonth()+1,0).getDate();if(lastDayOfMonth-dayOfMonth<7){wom[weekOfMonth]="last";}
-var repeatOn=date;var repeatTxt="";if(freq=="Weekly"){repeatOn.setDate(repeatOn.getDate()+7);var repeatOnD=moment(repeatOn).format("dddd");repeatTxt="Every "+repeatOnD;}else if(freq=="Every Two Weeks"){repeatOn.setDate(repeatOn.getDate()+7);var repeatOnD=moment(repeatOn).format("dddd");repeatTxt="Every Other "+repeatOnD;}else if(freq=="Monthly"){repeatTxt="on the "+wom[weekOfMonth]+" "+weekday[date.getDay()];}
+var repeatOn=date;var repeatTxt="";if(freq=="Daily"){repeatTxt="Every Day";}else if(freq=="Weekly"){repeatOn.setDate(repeatOn.getDate()+7);var repeatOnD=moment(repeatOn).format("dddd");repeatTxt="Every "+repeatOnD;}else if(freq=="Every Two Weeks"){repeatOn.setDate(repeatOn.getDate()+7);var repeatOnD=moment(repeatOn).format("dddd");repeatTxt="Every Other "+repeatOnD;}else if(freq=="Monthly"){repeatTxt="on the "+wom[weekOfMonth]+" "+weekday[date.getDay()];}
 if(repeatTxt.length>0){repeatTxt="(Repeating "+repeatTxt+")";}
 return repeatTxt;}
 function setupSvcLocation(selectId,inputProviderId,inputClientId,inputFlexId,locType1,locType2,zoomId){function handleLocationType(locType){$(zoomId).hide();if(locType==locType1){$(inputProviderId).prop("disabled",false);$(inputProviderId).show();$(inputClientId).hide();$(inputFlexId).hide();return;}else if(locType==locType2){$(inputProviderId).prop("disabled",true);$(inputProviderId).hide();$(inputClientId).show();$(inputFlexId).hide();return;}else{$(zoomId).show();}
 $(inputProviderId).prop("disabled",true);$(inputProviderId).hide();$(inputClientId).hide();$(inputFlexId).show();}
 handleLocationType($(selectId).val());$(selectId).change(function(event){handleLocationType(this.value);});}
+function setupPaymentItems(listId,templateId,addId,taxRateId,priceId,typeName,quantityName,priceName,digits){var factor=Math.pow(10,digits);function computeTotal(){var items=$(listId).find(".payment-item");if(items.length==0){$(priceId).prop("readonly",false);return;}
+var total=0;var taxable=0;items.each(function(){var type=$(this).find(`[name="${typeName}"]`).val();var quantity=parseFloat($(this).find(`[name="${quantityName}"]`).val())||0;var price=parseFloat($(this).find(`[name="${priceName}"]`).val())||0;var amount=Math.round(quantity*price*factor);if(type=="Discount"||type=="Credit"){amount=-amount;}
+total+=amount;if(type!="Credit"){taxable+=amount;}});var taxRate=parseFloat($(taxRateId).val())||0;if(taxRate>0&&taxable>0){total+=Math.round((taxable*taxRate)/100);}
+total=Math.max(total,0);$(priceId).prop("readonly",true);$(priceId).val((total/factor).toFixed(digits));}
+$(addId).click(function(evt){$(listId).append($(templateId).html());computeTotal();});$(listId).on("click",".payment-item-remove",function(evt){$(this).closest(".payment-item").remove();computeTotal();});$(listId).on("change keyup","input,select",computeTotal);$(taxRateId).on("change keyup",computeTotal);computeTotal();}
 function clipLink(linkId,copiedId){$("#"+linkId).show();var text=document.getElementById(linkId);text.select();text.setSelectionRange(0,99999);document.execCommand("copy");$("#"+linkId).hide();$(copiedId).text("copied");}
 function submitFilter(formId,inputId,inputVal){$(inputId).val(inputVal);$(formId).submit();}
 function createSchedule(divId,schedules,errDays){const MAX_PERIOD_COUNT=3;$(function(){filterScheduleData();initSchedules(errDays);initTimePicker();initEvents();});function filterScheduleData(){schedules.forEach((s)=>{if(s.working_hours){s.working_hours.forEach((slot)=>{var dt=moment(slot.from,["h:mm A"]).format("HH:mm");slot.from=dt;});}});}
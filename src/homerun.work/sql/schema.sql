@@ -54,6 +54,8 @@ CREATE TABLE `client` (
   `email` varchar(100) NOT NULL,
   `invited` datetime DEFAULT NULL,
   `disable_emails` bit(1) NOT NULL DEFAULT b'0',
+  `stripe_customer_id` varchar(100) DEFAULT NULL,
+  `stripe_data` json DEFAULT NULL,
   `data` json DEFAULT NULL,
   `deleted` bit(1) NOT NULL DEFAULT b'0',
   `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -67,6 +69,109 @@ CREATE TABLE `client` (
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 /*!40101 SET character_set_client = @saved_cs_client */;
 
+--
+-- Table structure for table `client_membership`
+--
+
+DROP TABLE IF EXISTS `client_membership`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `client_membership` (
+  `id` binary(16) NOT NULL,
+  `provider_id` binary(16) NOT NULL,
+  `membership_plan_id` binary(16) NOT NULL,
+  `client_id` binary(16) NOT NULL,
+  `stripe_subscription_id` varchar(100) DEFAULT NULL,
+  `status` smallint NOT NULL,
+  `period_start` datetime DEFAULT NULL,
+  `period_end` datetime DEFAULT NULL,
+  `data` json DEFAULT NULL,
+  `deleted` bit(1) NOT NULL DEFAULT b'0',
+  `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uq.client_membership.stripe_subscription_id` (`stripe_subscription_id`),
+  KEY `idx.client_membership.client_id` (`client_id`),
+  KEY `idx.client_membership.membership_plan_id` (`membership_plan_id`),
+  KEY `idx.client_membership.provider_id` (`provider_id`),
+  CONSTRAINT `fk.client_membership.client_id` FOREIGN KEY (`client_id`) REFERENCES `client` (`id`),
+  CONSTRAINT `fk.client_membership.membership_plan_id` FOREIGN KEY (`membership_plan_id`) REFERENCES `membership_plan` (`id`),
+  CONSTRAINT `fk.client_membership.provider_id` FOREIGN KEY (`provider_id`) REFERENCES `provider` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `client_membership_use`
+--
+
+DROP TABLE IF EXISTS `client_membership_use`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `client_membership_use` (
+  `id` binary(16) NOT NULL,
+  `client_membership_id` binary(16) NOT NULL,
+  `booking_id` binary(16) NOT NULL,
+  `deleted` bit(1) NOT NULL DEFAULT b'0',
+  `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uq.client_membership_use.booking_id` (`booking_id`),
+  KEY `idx.client_membership_use.client_membership_id` (`client_membership_id`),
+  CONSTRAINT `fk.client_membership_use.client_membership_id` FOREIGN KEY (`client_membership_id`) REFERENCES `client_membership` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `client_package`
+--
+
+DROP TABLE IF EXISTS `client_package`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `client_package` (
+  `id` binary(16) NOT NULL,
+  `provider_id` binary(16) NOT NULL,
+  `session_package_id` binary(16) NOT NULL,
+  `client_id` binary(16) NOT NULL,
+  `payment_id` binary(16) NOT NULL,
+  `sessions` int NOT NULL,
+  `expiration` datetime DEFAULT NULL,
+  `data` json DEFAULT NULL,
+  `deleted` bit(1) NOT NULL DEFAULT b'0',
+  `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uq.client_package.payment_id` (`payment_id`),
+  KEY `idx.client_package.client_id` (`client_id`),
+  KEY `idx.client_package.provider_id` (`provider_id`),
+  CONSTRAINT `fk.client_package.client_id` FOREIGN KEY (`client_id`) REFERENCES `client` (`id`),
+  CONSTRAINT `fk.client_package.payment_id` FOREIGN KEY (`payment_id`) REFERENCES `payment` (`id`),
+  CONSTRAINT `fk.client_package.provider_id` FOREIGN KEY (`provider_id`) REFERENCES `provider` (`id`),
+  CONSTRAINT `fk.client_package.session_package_id` FOREIGN KEY (`session_package_id`) REFERENCES `session_package` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `client_package_use`
+--
+
+DROP TABLE IF EXISTS `client_package_use`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `client_package_use` (
+  `id` binary(16) NOT NULL,
+  `client_package_id` binary(16) NOT NULL,
+  `booking_id` binary(16) NOT NULL,
+  `deleted` bit(1) NOT NULL DEFAULT b'0',
+  `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uq.client_package_use.booking_id` (`booking_id`),
+  KEY `idx.client_package_use.client_package_id` (`client_package_id`),
+  CONSTRAINT `fk.client_package_use.client_package_id` FOREIGN KEY (`client_package_id`) REFERENCES `client_package` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
 --
 -- Table structure for table `content`
 --
@@ -109,6 +214,36 @@ CREATE TABLE `coupon` (
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 /*!40101 SET character_set_client = @saved_cs_client */;
 
+--
+-- Table structure for table `coupon_redemption`
+--
+
+DROP TABLE IF EXISTS `coupon_redemption`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `coupon_redemption` (
+  `id` binary(16) NOT NULL,
+  `coupon_id` binary(16) NOT NULL,
+  `provider_id` binary(16) NOT NULL,
+  `client_id` binary(16) NOT NULL,
+  `booking_id` binary(16) NOT NULL,
+  `payment_id` binary(16) DEFAULT NULL,
+  `amount` int NOT NULL,
+  `deleted` bit(1) NOT NULL DEFAULT b'0',
+  `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uq.coupon_redemption.booking_id` (`booking_id`),
+  KEY `idx.coupon_redemption.coupon_id` (`coupon_id`),
+  KEY `idx.coupon_redemption.provider_id` (`provider_id`) /*!80000 INVISIBLE */,
+  KEY `idx.coupon_redemption.client_id` (`client_id`),
+  KEY `idx.coupon_redemption.payment_id` (`payment_id`),
+  CONSTRAINT `fk.coupon_redemption.client_id` FOREIGN KEY (`client_id`) REFERENCES `client` (`id`),
+  CONSTRAINT `fk.coupon_redemption.coupon_id` FOREIGN KEY (`coupon_id`) REFERENCES `coupon` (`id`),
+  CONSTRAINT `fk.coupon_redemption.provider_id` FOREIGN KEY (`provider_id`) REFERENCES `provider` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
 --
 -- Table structure for table `email_verify_token`
 --
@@ -151,6 +286,75 @@ CREATE TABLE `faq` (
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 /*!40101 SET character_set_client = @saved_cs_client */;
 
+--
+-- Table structure for table `gift_card`
+--
+
+DROP TABLE IF EXISTS `gift_card`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `gift_card` (
+  `id` binary(16) NOT NULL,
+  `provider_id` binary(16) NOT NULL,
+  `payment_id` binary(16) NOT NULL,
+  `code` varchar(12) NOT NULL,
+  `amount` int NOT NULL,
+  `data` json DEFAULT NULL,
+  `deleted` bit(1) NOT NULL DEFAULT b'0',
+  `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uq.gift_card.provider_id_code` (`provider_id`,`code`),
+  UNIQUE KEY `uq.gift_card.payment_id` (`payment_id`),
+  CONSTRAINT `fk.gift_card.payment_id` FOREIGN KEY (`payment_id`) REFERENCES `payment` (`id`),
+  CONSTRAINT `fk.gift_card.provider_id` FOREIGN KEY (`provider_id`) REFERENCES `provider` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `gift_card_attempt`
+--
+
+DROP TABLE IF EXISTS `gift_card_attempt`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `gift_card_attempt` (
+  `id` binary(16) NOT NULL,
+  `provider_id` binary(16) NOT NULL,
+  `email` varchar(100) NOT NULL,
+  `ip` varchar(45) NOT NULL,
+  `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx.gift_card_attempt.provider_id_email_created` (`provider_id`,`email`,`created`),
+  KEY `idx.gift_card_attempt.ip_created` (`ip`,`created`),
+  CONSTRAINT `fk.gift_card_attempt.provider_id` FOREIGN KEY (`provider_id`) REFERENCES `provider` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Table structure for table `gift_card_entry`
+--
+
+DROP TABLE IF EXISTS `gift_card_entry`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `gift_card_entry` (
+  `id` binary(16) NOT NULL,
+  `gift_card_id` binary(16) NOT NULL,
+  `payment_id` binary(16) NOT NULL,
+  `amount` int NOT NULL,
+  `refund` bit(1) NOT NULL DEFAULT b'0',
+  `deleted` bit(1) NOT NULL DEFAULT b'0',
+  `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uq.gift_card_entry.gift_card_id_payment_id_refund` (`gift_card_id`,`payment_id`,`refund`),
+  KEY `idx.gift_card_entry.payment_id` (`payment_id`),
+  CONSTRAINT `fk.gift_card_entry.gift_card_id` FOREIGN KEY (`gift_card_id`) REFERENCES `gift_card` (`id`),
+  CONSTRAINT `fk.gift_card_entry.payment_id` FOREIGN KEY (`payment_id`) REFERENCES `payment` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
 --
 -- Table structure for table `img`
 --
@@ -183,6 +387,26 @@ CREATE TABLE `img` (
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 /*!40101 SET character_set_client = @saved_cs_client */;
 
+--
+-- Table structure for table `membership_plan`
+--
+
+DROP TABLE IF EXISTS `membership_plan`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `membership_plan` (
+  `id` binary(16) NOT NULL,
+  `provider_id` binary(16) NOT NULL,
+  `data` json DEFAULT NULL,
+  `deleted` bit(1) NOT NULL DEFAULT b'0',
+  `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx.membership_plan.provider_id` (`provider_id`),
+  CONSTRAINT `fk.membership_plan.provider_id` FOREIGN KEY (`provider_id`) REFERENCES `provider` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
 --
 -- Table structure for table `message`
 --
@@ -431,6 +655,7 @@ CREATE TABLE `service_booking` (
   `recurrence_instance_end` datetime DEFAULT NULL,
   `recurrence_processing` bit(1) NOT NULL DEFAULT b'0',
   `recurrence_processing_time` datetime DEFAULT NULL,
+  `payment_expiration` datetime DEFAULT NULL,
   `event_google_id` varchar(100) DEFAULT NULL,
   `event_google_update` bit(1) NOT NULL DEFAULT b'0',
   `event_google_delete` bit(1) NOT NULL DEFAULT b'0',
@@ -454,6 +679,7 @@ CREATE TABLE `service_booking` (
   KEY `idx.service_booking.parent_id` (`parent_id`),
   KEY `idx.service_booking.provider_id` (`provider_id`),
   KEY `idx.service_booking.provider_user_id` (`provider_user_id`),
+  KEY `idx.service_booking.payment_expiration` (`payment_expiration`),
   CONSTRAINT `fk.service_booking.client_id` FOREIGN KEY (`client_id`) REFERENCES `client` (`id`),
   CONSTRAINT `fk.service_booking.provider_id` FOREIGN KEY (`provider_id`) REFERENCES `provider` (`id`),
   CONSTRAINT `fk.service_booking.provider_user_id` FOREIGN KEY (`provider_user_id`) REFERENCES `provider_user` (`id`),
@@ -461,6 +687,34 @@ CREATE TABLE `service_booking` (
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 /*!40101 SET character_set_client = @saved_cs_client */;
 
+--
+-- Table structure for table `service_booking_hold`
+--
+
+DROP TABLE IF EXISTS `service_booking_hold`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `service_booking_hold` (
+  `id` binary(16) NOT NULL,
+  `provider_id` binary(16) NOT NULL,
+  `user_id` binary(16) NOT NULL,
+  `service_id` binary(16) NOT NULL,
+  `time_start` datetime NOT NULL,
+  `time_end` datetime NOT NULL,
+  `time_start_padded` datetime NOT NULL,
+  `time_end_padded` datetime NOT NULL,
+  `expiration` datetime NOT NULL,
+  `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx.service_booking_hold.provider_id` (`provider_id`),
+  KEY `idx.service_booking_hold.expiration` (`expiration`),
+  CONSTRAINT `fk.service_booking_hold.provider_id` FOREIGN KEY (`provider_id`) REFERENCES `provider` (`id`),
+  CONSTRAINT `fk.service_booking_hold.service_id` FOREIGN KEY (`service_id`) REFERENCES `service` (`id`),
+  CONSTRAINT `fk.service_booking_hold.user_id` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
 --
 -- Table structure for table `service_provider_user`
 --
@@ -486,6 +740,26 @@ CREATE TABLE `service_provider_user` (
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 /*!40101 SET character_set_client = @saved_cs_client */;
 
+--
+-- Table structure for table `session_package`
+--
+
+DROP TABLE IF EXISTS `session_package`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `session_package` (
+  `id` binary(16) NOT NULL,
+  `provider_id` binary(16) NOT NULL,
+  `data` json DEFAULT NULL,
+  `deleted` bit(1) NOT NULL DEFAULT b'0',
+  `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx.session_package.provider_id` (`provider_id`),
+  CONSTRAINT `fk.session_package.provider_id` FOREIGN KEY (`provider_id`) REFERENCES `provider` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
 --
 -- Table structure for table `stripe_event`
 --
@@ -568,6 +842,40 @@ CREATE TABLE `user` (
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
 /*!40101 SET character_set_client = @saved_cs_client */;
 
+--
+-- Table structure for table `waitlist`
+--
+
+DROP TABLE IF EXISTS `waitlist`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `waitlist` (
+  `id` binary(16) NOT NULL,
+  `provider_id` binary(16) NOT NULL,
+  `service_id` binary(16) NOT NULL,
+  `provider_user_id` binary(16) DEFAULT NULL,
+  `client_id` binary(16) NOT NULL,
+  `time_start` datetime NOT NULL,
+  `time_end` datetime NOT NULL,
+  `token` varchar(100) DEFAULT NULL,
+  `offer_time` datetime DEFAULT NULL,
+  `offer_expiration` datetime DEFAULT NULL,
+  `claimed` bit(1) NOT NULL DEFAULT b'0',
+  `data` json DEFAULT NULL,
+  `deleted` bit(1) NOT NULL DEFAULT b'0',
+  `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `updated` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  KEY `idx.waitlist.provider_id` (`provider_id`) /*!80000 INVISIBLE */,
+  KEY `idx.waitlist.service_id` (`service_id`),
+  KEY `idx.waitlist.client_id` (`client_id`) /*!80000 INVISIBLE */,
+  KEY `idx.waitlist.token` (`token`),
+  CONSTRAINT `fk.waitlist.client_id` FOREIGN KEY (`client_id`) REFERENCES `client` (`id`),
+  CONSTRAINT `fk.waitlist.provider_id` FOREIGN KEY (`provider_id`) REFERENCES `provider` (`id`),
+  CONSTRAINT `fk.waitlist.service_id` FOREIGN KEY (`service_id`) REFERENCES `service` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
 --
 -- Table structure for table `zoom_event`
 --